@@ -18,6 +18,10 @@ Expand the name of the chart.
 {{ include "renderImageName" (dict "image" .Values.sidecars.livenessProbe.image "eksImage" "/eks/livenessprobe" "isEKSAddon" .Values.isEKSAddon ) }}
 {{- end -}}
 
+{{- define "provisionerImageName" -}}
+{{ include "renderImageName" (dict "image" .Values.sidecars.provisioner.image "eksImage" "/eks/csi-provisioner" "isEKSAddon" .Values.isEKSAddon ) }}
+{{- end -}}
+
 {{- define "renderImageName" -}}
 {{ printf "%s%s:%s" (default "" .image.containerRegistry) (ternary .image.repository .eksImage (empty .isEKSAddon)) .image.tag }}
 {{- end -}}
@@ -0,0 +1,43 @@
+package csicontroller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons of Kubernetes Events recorded on workload Pods, so users can see how their volumes are provided in `kubectl describe pod`.
+const (
+	// EventReasonPVCNotBound is recorded when a PVC of the workload is not bound to a PV yet.
+	EventReasonPVCNotBound = "PVCNotBound"
+	// EventReasonMountpointPodAssigned is recorded when a volume of the workload is assigned to a Mountpoint Pod.
+	EventReasonMountpointPodAssigned = "MountpointPodAssigned"
+	// EventReasonMountpointPodSpawnFailed is recorded when a Mountpoint Pod cannot be spawned for a volume of the workload.
+	EventReasonMountpointPodSpawnFailed = "MountpointPodSpawnFailed"
+	// EventReasonHeadroomPodCreated is recorded when a Headroom Pod is created to reserve space for a Mountpoint Pod of the workload.
+	EventReasonHeadroomPodCreated = "HeadroomPodCreated"
+	// EventReasonInvalidVolume is recorded when volume attributes of a volume of the workload are invalid.
+	EventReasonInvalidVolume = "InvalidVolume"
+	// EventReasonMountPolicyViolated is recorded when mount options of a volume of the workload violate a MountpointS3MountPolicy.
+	EventReasonMountPolicyViolated = "MountPolicyViolated"
+)
+
+// recordEvent records an Event on `workloadPod` if the reconciler has an event recorder.
+func (r *Reconciler) recordEvent(workloadPod *corev1.Pod, eventType, reason, messageFmt string, args ...any) {
+	if r.recorder == nil {
+		return
+	}
+	r.recorder.Eventf(workloadPod, eventType, reason, messageFmt, args...)
+}
+
+// recordMountpointPodAssigned records an Event on `workloadPod` about its volume `vol` being assigned to Mountpoint Pod `mpPodName`.
+func (r *Reconciler) recordMountpointPodAssigned(workloadPod *corev1.Pod, vol *workloadVolume, mpPodName string) {
+	r.recordEvent(workloadPod, corev1.EventTypeNormal, EventReasonMountpointPodAssigned,
+		"Assigned %s to Mountpoint Pod %s/%s", volumeDescription(vol), r.mountpointPodConfig.Namespace, mpPodName)
+}
+
+// volumeDescription returns a human-readable description of `vol` to use in Events.
+func volumeDescription(vol *workloadVolume) string {
+	if vol.isInline() {
+		return "inline volume " + vol.inlineVolumeName
+	}
+	return "PVC " + vol.pvc.Name
+}
@@ -24,7 +24,9 @@ func newExpectations() *expectations {
 // This is typically used when a create operation is initiated.
 func (e *expectations) setPending(fieldFilters client.MatchingFields) {
 	key := deriveExpectationKeyFromFilters(fieldFilters)
-	e.pending.Store(key, struct{}{})
+	if _, loaded := e.pending.LoadOrStore(key, struct{}{}); !loaded {
+		pendingS3PAExpectations.Inc()
+	}
 }
 
 // isPending checks if a resource is marked as pending based on the given field filters.
@@ -39,7 +41,9 @@ func (e *expectations) isPending(fieldFilters client.MatchingFields) bool {
 // This is typically called when an expected operation has been confirmed as completed.
 func (e *expectations) clear(fieldFilters client.MatchingFields) {
 	key := deriveExpectationKeyFromFilters(fieldFilters)
-	e.pending.Delete(key)
+	if _, loaded := e.pending.LoadAndDelete(key); loaded {
+		pendingS3PAExpectations.Dec()
+	}
 }
 
 // deriveExpectationKeyFromFilters generates a deterministic string key from a map of field filters.
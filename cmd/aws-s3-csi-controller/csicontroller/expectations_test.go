@@ -3,8 +3,10 @@ package csicontroller
 import (
 	"testing"
 
-	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
 
 func TestDeriveExpectationKeyFromFilters(t *testing.T) {
@@ -103,3 +105,17 @@ func TestExpectations(t *testing.T) {
 		})
 	}
 }
+
+func TestExpectationsReportPendingCount(t *testing.T) {
+	pendingS3PAExpectations.Set(0)
+	e := newExpectations()
+
+	e.setPending(client.MatchingFields{"key1": "value1"})
+	e.setPending(client.MatchingFields{"key1": "value1"}) // Already pending, shouldn't be counted twice
+	e.setPending(client.MatchingFields{"key2": "value2"})
+	assert.Equals(t, float64(2), promtestutil.ToFloat64(pendingS3PAExpectations))
+
+	e.clear(client.MatchingFields{"key1": "value1"})
+	e.clear(client.MatchingFields{"key1": "value1"}) // Not pending anymore, shouldn't be counted twice
+	assert.Equals(t, float64(1), promtestutil.ToFloat64(pendingS3PAExpectations))
+}
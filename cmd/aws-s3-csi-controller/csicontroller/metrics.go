@@ -0,0 +1,73 @@
+package csicontroller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics of the reconciler, they're served alongside controller-runtime's own metrics by the manager's metrics server.
+
+const metricsNamespace = "s3_csi_controller"
+
+// Operations on MountpointS3PodAttachments and Headroom Pods.
+const (
+	operationCreate = "create"
+	operationUpdate = "update"
+	operationDelete = "delete"
+)
+
+var (
+	s3paOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "s3pa_operations_total",
+		Help:      "Total number of successful MountpointS3PodAttachment operations, partitioned by operation (\"create\", \"update\" or \"delete\").",
+	}, []string{"operation"})
+
+	duplicateS3PARecoveries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "duplicate_s3pa_recoveries_total",
+		Help:      "Total number of attempts to recover from duplicate MountpointS3PodAttachments for the same volume.",
+	})
+
+	mountpointPodsSpawned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "mountpoint_pods_spawned_total",
+		Help:      "Total number of Mountpoint Pods spawned for workloads, partitioned by priority class kind (\"default\" or \"preempting\").",
+	}, []string{"priority_class_kind"})
+
+	headroomPodOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "headroom_pod_operations_total",
+		Help:      "Total number of Headroom Pods created or deleted, partitioned by operation (\"create\" or \"delete\").",
+	}, []string{"operation"})
+
+	pendingS3PAExpectations = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "pending_s3pa_expectations",
+		Help:      "Number of MountpointS3PodAttachments created by the controller but not observed in its cache yet.",
+	})
+
+	staleWorkloadsRemoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "stale_workloads_removed_total",
+		Help:      "Total number of workloads removed from MountpointS3PodAttachments by the stale attachment cleaner as they no longer exist.",
+	})
+
+	mountpointPodRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "mountpoint_pod_retries_total",
+		Help:      "Total number of failed Mountpoint Pods retried by spawning a new Mountpoint Pod.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		s3paOperations,
+		duplicateS3PARecoveries,
+		mountpointPodsSpawned,
+		headroomPodOperations,
+		pendingS3PAExpectations,
+		staleWorkloadsRemoved,
+		mountpointPodRetries,
+	)
+}
@@ -0,0 +1,71 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
+)
+
+// mountPolicyViolationRequeueInterval is the interval to check workloads violating MountpointS3MountPolicies again,
+// as changes to the policies do not trigger reconciliation of workloads.
+const mountPolicyViolationRequeueInterval = time.Minute
+
+// errMountPolicyViolated is returned if mount options of a volume violate MountpointS3MountPolicies matching the workload.
+var errMountPolicyViolated = errors.New("mount options violate MountpointS3MountPolicy")
+
+// mountPoliciesKey returns the key of MountpointS3MountPolicies matching `workloadPod`, see [mountpolicy.Enforcer.PoliciesKey].
+// Workloads with different keys might end up with different mount options, so they do not share Mountpoint Pods.
+func (r *Reconciler) mountPoliciesKey(ctx context.Context, workloadPod *corev1.Pod) (string, error) {
+	if r.mountPolicies == nil {
+		return "", nil
+	}
+
+	key, err := r.mountPolicies.PoliciesKey(ctx, mountpolicy.Workload{
+		Namespace: workloadPod.Namespace,
+		Name:      workloadPod.Name,
+		Labels:    workloadPod.Labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to find MountpointS3MountPolicies matching the workload: %w", err)
+	}
+	return key, nil
+}
+
+// violatesMountPolicy returns whether mount options of `vol` use any mount options denied by MountpointS3MountPolicies
+// matching `workloadPod`. Violations are recorded as Events on `workloadPod`, and such workloads are not assigned
+// to Mountpoint Pods. The CSI Driver Node Pod enforces the rest of the policies (i.e., defaults and required mount options)
+// while mounting the volume, as some of the mount options are only known at that point.
+func (r *Reconciler) violatesMountPolicy(ctx context.Context, workloadPod *corev1.Pod, vol *workloadVolume, log logr.Logger) (bool, error) {
+	if r.mountPolicies == nil {
+		return false, nil
+	}
+
+	args := mountpoint.ParseArgs(vol.pv.Spec.MountOptions)
+	err := r.mountPolicies.CheckDenied(ctx, mountpolicy.Workload{
+		Namespace: workloadPod.Namespace,
+		Name:      workloadPod.Name,
+		Labels:    workloadPod.Labels,
+	}, &args)
+
+	var violation *crdv2.MountPolicyViolationError
+	if errors.As(err, &violation) {
+		log.Info("Mount options violate MountpointS3MountPolicy - not assigning workload to a Mountpoint Pod",
+			"policy", violation.Policy, "reason", violation.Reason)
+		r.recordEvent(workloadPod, corev1.EventTypeWarning, EventReasonMountPolicyViolated,
+			"Mount options of %s violate MountpointS3MountPolicy %s: %s", volumeDescription(vol), violation.Policy, violation.Reason)
+		return true, nil
+	}
+	if err != nil {
+		log.Error(err, "Failed to check MountpointS3MountPolicies")
+		return false, err
+	}
+	return false, nil
+}
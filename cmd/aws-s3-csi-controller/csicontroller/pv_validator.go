@@ -0,0 +1,135 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// PersistentVolumeValidatorPath is the path the validating admission webhook for PersistentVolumes is served at.
+const PersistentVolumeValidatorPath = "/validate--v1-persistentvolume"
+
+// A PersistentVolumeValidator validates PersistentVolumes of the CSI Driver at admission time,
+// so misconfigurations are reported on `kubectl apply` rather than while mounting the volume for a workload.
+//
+// It uses the same validation the CSI Driver Node Pod and the controller use while mounting the volume,
+// i.e., [volumecontext.Parse], [volumecontext.ValidateMountOptions], [volumecontext.ApplyCacheEmptyDirSizeLimit]
+// and [mppod.Creator.MountpointPod]. Unlike them, it also rejects unknown volume attributes,
+// which dynamically provisioned volumes never have as the provisioner rejects unknown StorageClass parameters.
+type PersistentVolumeValidator struct {
+	mountpointPodCreator *mppod.Creator
+	log                  logr.Logger
+}
+
+var _ admission.CustomValidator = &PersistentVolumeValidator{}
+
+// NewPersistentVolumeValidator returns a new [PersistentVolumeValidator] validating volumes against Mountpoint Pods created with `podConfig`.
+func NewPersistentVolumeValidator(podConfig mppod.Config, log logr.Logger) *PersistentVolumeValidator {
+	// Warnings the creator would log are returned to the user instead.
+	creator := mppod.NewCreator(podConfig, logr.Discard())
+	return &PersistentVolumeValidator{mountpointPodCreator: creator, log: log}
+}
+
+// SetupWebhookWithManager registers the validator as a validating admission webhook for PersistentVolumes with `mgr`.
+func (v *PersistentVolumeValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.PersistentVolume{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates a newly created PersistentVolume.
+func (v *PersistentVolumeValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		return nil, fmt.Errorf("expected a PersistentVolume but got %T", obj)
+	}
+	return v.validate(pv)
+}
+
+// ValidateUpdate validates an updated PersistentVolume.
+// Updates not touching mount options or the CSI spec are always allowed, so existing volumes can still be bound,
+// relabeled or deleted even if they were created before the webhook was enabled.
+func (v *PersistentVolumeValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPV, ok := oldObj.(*corev1.PersistentVolume)
+	if !ok {
+		return nil, fmt.Errorf("expected a PersistentVolume but got %T", oldObj)
+	}
+	pv, ok := newObj.(*corev1.PersistentVolume)
+	if !ok {
+		return nil, fmt.Errorf("expected a PersistentVolume but got %T", newObj)
+	}
+
+	if pv.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if reflect.DeepEqual(oldPV.Spec.MountOptions, pv.Spec.MountOptions) && reflect.DeepEqual(oldPV.Spec.CSI, pv.Spec.CSI) {
+		return nil, nil
+	}
+	return v.validate(pv)
+}
+
+// ValidateDelete always allows deleting PersistentVolumes.
+func (v *PersistentVolumeValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns an error if `pv` cannot be mounted due to its mount options or volume attributes,
+// and warnings for deprecated or potentially harmful configurations.
+func (v *PersistentVolumeValidator) validate(pv *corev1.PersistentVolume) (admission.Warnings, error) {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != mountpointCSIDriverName {
+		return nil, nil
+	}
+
+	log := v.log.WithValues("pv", pv.Name)
+	volumeAttrs, err := volumecontext.Parse(mppod.ExtractVolumeAttributes(pv))
+	if err != nil {
+		log.Info("Rejecting PersistentVolume with invalid volume attributes", "error", err)
+		return nil, err
+	}
+	args := mountpoint.ParseArgs(pv.Spec.MountOptions)
+
+	if err := volumecontext.ValidateMountOptions(&args, volumeAttrs); err != nil {
+		log.Info("Rejecting PersistentVolume with invalid mount options", "error", err)
+		return nil, err
+	}
+	if err := volumecontext.ApplyCacheEmptyDirSizeLimit(&args, volumeAttrs); err != nil {
+		log.Info("Rejecting PersistentVolume with invalid cache configuration", "error", err)
+		return nil, err
+	}
+	if _, err := v.mountpointPodCreator.MountpointPod("", pv, mppod.DefaultPriorityClass); err != nil {
+		log.Info("Rejecting PersistentVolume that a Mountpoint Pod cannot be spawned for", "error", err)
+		return nil, err
+	}
+
+	return volumeWarnings(&args, volumeAttrs), nil
+}
+
+// volumeWarnings returns warnings for a valid volume with mount options `args` and volume attributes `volumeAttrs`.
+func volumeWarnings(args *mountpoint.Args, volumeAttrs volumecontext.VolumeAttributes) admission.Warnings {
+	var warnings admission.Warnings
+
+	cache := volumeAttrs.Cache
+	if args.Has(mountpoint.ArgCache) {
+		cache = &volumecontext.CacheConfig{Type: volumecontext.CacheTypeEmptyDir}
+		warnings = append(warnings, fmt.Sprintf("Configuring cache via `mountOptions` is deprecated, please use %q volume attribute instead. "+
+			"See https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CACHING.md for more details.", volumecontext.Cache))
+	}
+
+	if cache != nil && cache.IsDiskBackedEmptyDir() && cache.EmptyDirSizeLimit == nil {
+		warnings = append(warnings, fmt.Sprintf("%q is not set for disk-backed emptyDir cache. "+
+			"Mountpoint may consume excessive node storage and cause pod eviction.", volumecontext.CacheEmptyDirSizeLimit))
+	}
+
+	return warnings
+}
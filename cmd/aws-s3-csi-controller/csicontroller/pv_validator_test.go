@@ -0,0 +1,222 @@
+package csicontroller
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/provisioner"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestValidatingPersistentVolumes(t *testing.T) {
+	newPV := func(mountOptions []string, volumeAttributes map[string]string) *corev1.PersistentVolume {
+		pv := newTestPV()
+		pv.Spec.MountOptions = mountOptions
+		pv.Spec.CSI.VolumeAttributes = volumeAttributes
+		return pv
+	}
+
+	for name, test := range map[string]struct {
+		pv       *corev1.PersistentVolume
+		err      string
+		warnings int
+	}{
+		"valid volume": {
+			pv: newPV([]string{"allow-delete", "region us-west-2"}, map[string]string{"bucketName": "test-bucket"}),
+		},
+		"valid emptyDir cache": {
+			pv: newPV([]string{"max-cache-size=512"}, map[string]string{
+				volumecontext.Cache:                  volumecontext.CacheTypeEmptyDir,
+				volumecontext.CacheEmptyDirSizeLimit: "1Gi",
+			}),
+		},
+		"volume of another driver": {
+			pv: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "other"},
+				Spec: corev1.PersistentVolumeSpec{
+					MountOptions: []string{"-o ro"},
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol"},
+					},
+				},
+			},
+		},
+		"-o mount option": {
+			pv:  newPV([]string{"-o ro"}, nil),
+			err: "mount flag -o is not supported",
+		},
+		"--ca-bundle mount option": {
+			pv:  newPV([]string{"ca-bundle /etc/ssl/ca.pem"}, nil),
+			err: "--ca-bundle is not supported",
+		},
+		"CA bundle from ConfigMap": {
+			pv: newPV(nil, map[string]string{volumecontext.CABundleConfigMap: "corp-ca"}),
+		},
+		"CA bundle from both ConfigMap and Secret": {
+			pv:  newPV(nil, map[string]string{volumecontext.CABundleConfigMap: "corp-ca", volumecontext.CABundleSecret: "corp-ca"}),
+			err: "only one of",
+		},
+		"S3-compatible endpoint": {
+			pv: newPV([]string{"no-sign-request"}, map[string]string{
+				volumecontext.EndpointURL:     "http://minio.storage.svc:9000",
+				volumecontext.AddressingStyle: volumecontext.AddressingStylePath,
+				volumecontext.Region:          "us-east-1",
+			}),
+		},
+		"role to assume": {
+			pv: newPV(nil, map[string]string{
+				volumecontext.RoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+				volumecontext.RoleExternalID: "team-a-external-id",
+			}),
+		},
+		"invalid role to assume": {
+			pv:  newPV(nil, map[string]string{volumecontext.RoleARN: "data-lake"}),
+			err: `invalid "roleArn"`,
+		},
+		"IAM Roles Anywhere without secret authentication": {
+			pv: newPV(nil, map[string]string{
+				volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+				volumecontext.RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+				volumecontext.RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+			}),
+			err: "IAM Roles Anywhere can only be configured with `authenticationSource: secret`",
+		},
+		"region conflicting with mount options": {
+			pv:  newPV([]string{"region us-west-2"}, map[string]string{volumecontext.Region: "us-east-1"}),
+			err: `conflicts with "region" volume attribute`,
+		},
+		"cache in both mount options and volume attributes": {
+			pv:  newPV([]string{"cache /tmp/cache"}, map[string]string{volumecontext.Cache: volumecontext.CacheTypeEmptyDir}),
+			err: "Cache configured with both `mountOptions` and `volumeAttributes`",
+		},
+		"unknown cache type": {
+			pv:  newPV(nil, map[string]string{volumecontext.Cache: "hostPath"}),
+			err: `unsupported local-cache type: "hostPath"`,
+		},
+		"invalid cacheEmptyDirSizeLimit": {
+			pv: newPV(nil, map[string]string{
+				volumecontext.Cache:                  volumecontext.CacheTypeEmptyDir,
+				volumecontext.CacheEmptyDirSizeLimit: "lots",
+			}),
+			err: `failed to parse quantity "lots" for "cacheEmptyDirSizeLimit"`,
+		},
+		"max-cache-size exceeding cacheEmptyDirSizeLimit": {
+			pv: newPV([]string{"max-cache-size=2048"}, map[string]string{
+				volumecontext.Cache:                  volumecontext.CacheTypeEmptyDir,
+				volumecontext.CacheEmptyDirSizeLimit: "1Gi",
+			}),
+			err: "--max-cache-size (2048 MiB) exceeds cacheEmptyDirSizeLimit",
+		},
+		"unknown volume attribute": {
+			pv:  newPV(nil, map[string]string{"bucketName": "test-bucket", "cacheSizeLimit": "1Gi"}),
+			err: "unknown volume attributes: cacheSizeLimit",
+		},
+		"invalid resource request": {
+			pv:  newPV(nil, map[string]string{volumecontext.MountpointContainerResourcesRequestsCpu: "a lot"}),
+			err: "failed to parse quantity",
+		},
+		"deprecated cache mount option": {
+			pv:       newPV([]string{"cache /tmp/cache"}, nil),
+			warnings: 2,
+		},
+		"disk-backed emptyDir cache without size limit": {
+			pv:       newPV(nil, map[string]string{volumecontext.Cache: volumecontext.CacheTypeEmptyDir}),
+			warnings: 1,
+		},
+		"memory-backed emptyDir cache without size limit": {
+			pv: newPV(nil, map[string]string{
+				volumecontext.Cache:               volumecontext.CacheTypeEmptyDir,
+				volumecontext.CacheEmptyDirMedium: string(corev1.StorageMediumMemory),
+			}),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			validator := NewPersistentVolumeValidator(testPodConfig(), logr.Discard())
+
+			warnings, err := validator.ValidateCreate(context.Background(), test.pv)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("Expected error containing %q, got %v", test.err, err)
+			}
+			assert.Equals(t, test.warnings, len(warnings))
+		})
+	}
+}
+
+func TestValidatingProvisionedPersistentVolumes(t *testing.T) {
+	validator := NewPersistentVolumeValidator(testPodConfig(), logr.Discard())
+
+	resp, err := provisioner.New(nil).CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name: "pvc-1234",
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+		}},
+		Parameters: map[string]string{
+			provisioner.ParamBucketName:          "test-bucket",
+			provisioner.ParamBasePrefix:          "tenants",
+			provisioner.ParamDeletionPolicy:      "delete",
+			provisioner.ParamRegion:              "eu-west-1",
+			volumecontext.AuthenticationSource:   volumecontext.AuthenticationSourcePod,
+			volumecontext.Cache:                  volumecontext.CacheTypeEmptyDir,
+			volumecontext.CacheEmptyDirSizeLimit: "1Gi",
+			"csi.storage.k8s.io/pvc/name":        "data",
+			"csi.storage.k8s.io/pvc/namespace":   "team-a",
+		},
+	})
+	assert.NoError(t, err)
+
+	// The PersistentVolume the external-provisioner creates for the provisioned volume
+	pv := newTestPV()
+	pv.Spec.MountOptions = []string{"allow-delete"}
+	pv.Spec.CSI.VolumeHandle = resp.GetVolume().GetVolumeId()
+	pv.Spec.CSI.VolumeAttributes = maps.Clone(resp.GetVolume().GetVolumeContext())
+	pv.Spec.CSI.VolumeAttributes["storage.kubernetes.io/csiProvisionerIdentity"] = "1700000000000-8081-s3.csi.aws.com"
+
+	warnings, err := validator.ValidateCreate(context.Background(), pv)
+	assert.NoError(t, err)
+	assert.Equals(t, 0, len(warnings))
+}
+
+func TestValidatingPersistentVolumeUpdates(t *testing.T) {
+	validator := NewPersistentVolumeValidator(testPodConfig(), logr.Discard())
+
+	invalidPV := newTestPV()
+	invalidPV.Spec.MountOptions = []string{"-o ro"}
+
+	t.Run("Allows updates not touching mount options or volume attributes", func(t *testing.T) {
+		updated := invalidPV.DeepCopy()
+		updated.Labels = map[string]string{"team": "a"}
+		_, err := validator.ValidateUpdate(context.Background(), invalidPV, updated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Allows updates of volumes being deleted", func(t *testing.T) {
+		updated := invalidPV.DeepCopy()
+		updated.Spec.MountOptions = append(updated.Spec.MountOptions, "allow-delete")
+		updated.DeletionTimestamp = new(metav1.Now())
+		_, err := validator.ValidateUpdate(context.Background(), invalidPV, updated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects updates introducing invalid mount options", func(t *testing.T) {
+		_, err := validator.ValidateUpdate(context.Background(), newTestPV(), invalidPV)
+		if err == nil {
+			t.Fatal("Expected update to be rejected")
+		}
+	})
+
+	t.Run("Always allows deletion", func(t *testing.T) {
+		_, err := validator.ValidateDelete(context.Background(), invalidPV)
+		assert.NoError(t, err)
+	})
+}
@@ -2,8 +2,11 @@ package csicontroller
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +15,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,6 +24,7 @@ import (
 	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/go-logr/logr"
 )
@@ -27,6 +32,11 @@ import (
 const debugLevel = 4
 
 const mountpointCSIDriverName = "s3.csi.aws.com"
+
+const (
+	inlineVolumeNamePrefix   = "inline-"
+	csiVolumeAttributePrefix = "csi.storage.k8s.io/"
+)
 const defaultServiceAccount = "default"
 
 const (
@@ -61,18 +71,36 @@ type Reconciler struct {
 	//
 	// Note: Reconcile() processes events sequentially, eliminating concurrency concerns.
 	s3paExpectations *expectations
+
+	// retryPolicy controls how failed Mountpoint Pods are retried, see [Reconciler.SetRetryPolicy].
+	retryPolicy RetryPolicy
+
+	// mountPolicies checks mount options of volumes against MountpointS3MountPolicies before assigning workloads to Mountpoint Pods.
+	mountPolicies *mountpolicy.Enforcer
+
+	// recorder records Events on workload Pods, it's set in [Reconciler.SetupWithManager].
+	recorder record.EventRecorder
+
 	client.Client
 }
 
 // NewReconciler returns a new reconciler created from `client` and `podConfig`.
 func NewReconciler(client client.Client, podConfig mppod.Config, log logr.Logger) *Reconciler {
 	creator := mppod.NewCreator(podConfig, log)
-	return &Reconciler{Client: client, mountpointPodConfig: podConfig, mountpointPodCreator: creator, s3paExpectations: newExpectations()}
+	return &Reconciler{
+		Client:               client,
+		mountpointPodConfig:  podConfig,
+		mountpointPodCreator: creator,
+		s3paExpectations:     newExpectations(),
+		retryPolicy:          DefaultRetryPolicy,
+		mountPolicies:        mountpolicy.New(client),
+	}
 }
 
 // SetupWithManager configures reconciler to run with given `mgr`.
 // It automatically configures reconciler to reconcile Pods in the cluster.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor(Name)
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(Name).
 		For(&corev1.Pod{}).
@@ -81,7 +109,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 // Reconcile reconciles either a Mountpoint- or a workload-Pod.
 //
-// For Mountpoint Pods, it deletes completed Pods and logs each status change.
+// For Mountpoint Pods, it deletes completed Pods, retries failed Pods and logs each status change.
 // For workload Pods, it decides if it needs to spawn a Mountpoint/Headroom Pod to provide a volume for the workload Pod.
 func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	log := logf.FromContext(ctx).WithValues("pod", req.NamespacedName)
@@ -131,10 +159,8 @@ func (r *Reconciler) reconcileMountpointPod(ctx context.Context, pod *corev1.Pod
 		}
 		log.Info("Pod succeeded and successfully deleted")
 	case corev1.PodFailed:
-		// TODO: We should probably delete failed Pods after some time to trigger a retry on the whole operation.
-		//       Maybe just returning a `reconcile.Result{RequeueAfter: ...}`
-		//       and deleting in next cycle would be a good way?
 		log.Info("Pod failed", "reason", pod.Status.Reason)
+		return r.handleFailedMountpointPod(ctx, pod, log)
 	}
 
 	return reconcile.Result{}, nil
@@ -169,16 +195,31 @@ func (r *Reconciler) reconcileWorkloadPod(ctx context.Context, pod *corev1.Pod)
 	}
 
 	var errs []error
+	var requeueAfter time.Duration
 	numHeadroomPods, numRemovedHeadroomPods := 0, 0
 
 	for i, vol := range volumes {
-		pv, pvc := vol.pv, vol.pvc
+		pv := vol.pv
 
 		if scheduled {
-			log.V(debugLevel).Info("Found bound PV for PVC", "pvc", pvc.Name, "volumeName", pv.Name)
+			if vol.isInline() {
+				log.V(debugLevel).Info("Found inline volume", "inlineVolume", vol.inlineVolumeName, "volumeName", pv.Name)
+			} else {
+				log.V(debugLevel).Info("Found bound PV for PVC", "pvc", vol.pvc.Name, "volumeName", pv.Name)
+			}
 
-			needsRequeue, err := r.spawnOrDeleteMountpointPodIfNeeded(ctx, pod, pvc, pv, priorityClassKind)
+			needsRequeue, err := r.spawnOrDeleteMountpointPodIfNeeded(ctx, pod, vol, priorityClassKind)
 			requeue = requeue || needsRequeue
+			if errors.Is(err, errMountPolicyViolated) {
+				// Violations are only resolved by changing the volume or the policies, no need to check them again right away
+				requeueAfter = mountPolicyViolationRequeueInterval
+				continue
+			}
+			if errors.Is(err, errInvalidVolumeAttributes) {
+				// Invalid volume attributes are only resolved by re-creating the volume, retrying won't help
+				log.Error(err, "Not spawning a Mountpoint Pod for a volume with invalid attributes", "volumeName", pv.Name)
+				continue
+			}
 			if err != nil {
 				errs = append(errs, err)
 				continue
@@ -240,7 +281,10 @@ func (r *Reconciler) reconcileWorkloadPod(ctx context.Context, pod *corev1.Pod)
 		}
 	}
 
-	return reconcile.Result{Requeue: requeue}, nil
+	if requeue {
+		return reconcile.Result{Requeue: requeue}, nil
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // getWorkloadVolumes returns list of volumes of the workload that ready to process.
@@ -254,6 +298,11 @@ func (r *Reconciler) getWorkloadVolumes(
 	var volumes []*workloadVolume
 
 	for _, vol := range workloadPod.Spec.Volumes {
+		if inlineVolume := inlineWorkloadVolume(vol); inlineVolume != nil {
+			volumes = append(volumes, inlineVolume)
+			continue
+		}
+
 		podPVC := vol.PersistentVolumeClaim
 		if podPVC == nil {
 			continue
@@ -265,6 +314,8 @@ func (r *Reconciler) getWorkloadVolumes(
 		pvc, pv, err := r.getBoundPVForPodClaim(ctx, workloadPod, podPVC)
 		if err != nil {
 			if errors.Is(err, errPVCIsNotBoundToAPV) {
+				r.recordEvent(workloadPod, corev1.EventTypeNormal, EventReasonPVCNotBound,
+					"PersistentVolumeClaim %q is not bound to a PersistentVolume yet, waiting to spawn a Mountpoint Pod", podPVC.ClaimName)
 				status = Requeue
 			} else {
 				errs = append(errs, err)
@@ -277,17 +328,84 @@ func (r *Reconciler) getWorkloadVolumes(
 			continue
 		}
 
-		volumes = append(volumes, &workloadVolume{pv, pvc, csiSpec})
+		volumes = append(volumes, &workloadVolume{pv: pv, pvc: pvc, csiSpec: csiSpec})
 	}
 
 	return volumes, status, errors.Join(errs...)
 }
 
 // A workloadVolume represents a workload's volume backed by the CSI Driver.
+//
+// For CSI ephemeral inline volumes, `pv` is synthesized from the inline volume source (see [inlineWorkloadVolume]),
+// `pvc` is nil, and `inlineVolumeName` is the name of the volume in the workload pod's spec.
 type workloadVolume struct {
-	pv      *corev1.PersistentVolume
-	pvc     *corev1.PersistentVolumeClaim
-	csiSpec *corev1.CSIPersistentVolumeSource
+	pv               *corev1.PersistentVolume
+	pvc              *corev1.PersistentVolumeClaim
+	csiSpec          *corev1.CSIPersistentVolumeSource
+	inlineVolumeName string
+}
+
+// isInline returns whether the volume is a CSI ephemeral inline volume.
+func (v *workloadVolume) isInline() bool {
+	return v.inlineVolumeName != ""
+}
+
+// inlineWorkloadVolume returns a [workloadVolume] for given pod volume `vol` if it's a CSI ephemeral inline volume
+// backed by the CSI Driver, or nil otherwise.
+//
+// There is no PersistentVolume for inline volumes, but the rest of the reconciler and Mountpoint Pod creation
+// works on PersistentVolumes. So we synthesize one with a name and volume handle derived from the inline volume's
+// attributes, this way workloads using inline volumes with the same attributes share Mountpoint Pods
+// the same way workloads using the same PersistentVolume do.
+func inlineWorkloadVolume(vol corev1.Volume) *workloadVolume {
+	if vol.CSI == nil || vol.CSI.Driver != mountpointCSIDriverName {
+		return nil
+	}
+
+	readOnly := vol.CSI.ReadOnly != nil && *vol.CSI.ReadOnly
+	var secretName string
+	if vol.CSI.NodePublishSecretRef != nil {
+		secretName = vol.CSI.NodePublishSecretRef.Name
+	}
+	name := inlineVolumeNameFor(vol.CSI.VolumeAttributes, readOnly, secretName)
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:           vol.CSI.Driver,
+					VolumeHandle:     name,
+					ReadOnly:         readOnly,
+					VolumeAttributes: vol.CSI.VolumeAttributes,
+				},
+			},
+		},
+	}
+
+	return &workloadVolume{pv: pv, csiSpec: pv.Spec.CSI, inlineVolumeName: vol.Name}
+}
+
+// inlineVolumeNameFor returns a deterministic name for a CSI ephemeral inline volume with given `volumeAttributes`, `readOnly`
+// and `secretName` of its `nodePublishSecretRef` (might be empty).
+// The name is a valid label value, as it's used in labels of Headroom Pods.
+func inlineVolumeNameFor(volumeAttributes map[string]string, readOnly bool, secretName string) string {
+	keys := slices.Sorted(maps.Keys(volumeAttributes))
+
+	h := sha256.New224()
+	for _, k := range keys {
+		if strings.HasPrefix(k, csiVolumeAttributePrefix) {
+			// Pod info injected by kubelet, not part of the volume's identity
+			continue
+		}
+		fmt.Fprintf(h, "%s=%s\n", k, volumeAttributes[k])
+	}
+	fmt.Fprintf(h, "readOnly=%t", readOnly)
+	if secretName != "" {
+		// Workloads with different Secrets must not share Mountpoint Pods
+		fmt.Fprintf(h, "\nnodePublishSecretRef=%s", secretName)
+	}
+
+	return fmt.Sprintf("%s%x", inlineVolumeNamePrefix, h.Sum(nil))
 }
 
 // spawnOrDeleteMountpointPodIfNeeded spawns or deletes existing Mountpoint Pod for given `workloadPod` and volume if needed.
@@ -305,17 +423,26 @@ type workloadVolume struct {
 func (r *Reconciler) spawnOrDeleteMountpointPodIfNeeded(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pvc *corev1.PersistentVolumeClaim,
-	pv *corev1.PersistentVolume,
+	vol *workloadVolume,
 	priorityClassKind mppod.PriorityClassKind,
 ) (bool, error) {
+	pv := vol.pv
 	workloadUID := string(workloadPod.UID)
 	roleArn, err := r.findIRSAServiceAccountRole(ctx, workloadPod)
 	if err != nil {
 		return Requeue, err
 	}
-	fieldFilters := r.buildFieldFilters(workloadPod, pv, roleArn)
-	log := r.setupLogger(ctx, workloadPod, pvc, workloadUID, fieldFilters)
+	mountPolicies, err := r.mountPoliciesKey(ctx, workloadPod)
+	if err != nil {
+		return Requeue, err
+	}
+	fieldFilters, err := r.buildFieldFilters(workloadPod, pv, roleArn, mountPolicies)
+	if err != nil {
+		r.recordEvent(workloadPod, corev1.EventTypeWarning, EventReasonInvalidVolume,
+			"Invalid volume attributes of %s: %v", volumeDescription(vol), err)
+		return DontRequeue, fmt.Errorf("%w: %w", errInvalidVolumeAttributes, err)
+	}
+	log := r.setupLogger(ctx, workloadPod, vol, workloadUID, fieldFilters)
 	s3pa, err := r.getExistingS3PodAttachment(ctx, fieldFilters, log)
 	if err != nil {
 		return Requeue, err
@@ -328,27 +455,41 @@ func (r *Reconciler) spawnOrDeleteMountpointPodIfNeeded(
 		return r.handleInactivePod(ctx, s3pa, workloadUID, fieldFilters, log)
 	}
 
+	if s3pa == nil || !s3paContainsWorkload(s3pa, workloadUID, vol.inlineVolumeName) {
+		violated, err := r.violatesMountPolicy(ctx, workloadPod, vol, log)
+		if err != nil {
+			return Requeue, err
+		}
+		if violated {
+			return DontRequeue, errMountPolicyViolated
+		}
+	}
+
 	if s3pa != nil {
-		return r.handleExistingS3PodAttachment(ctx, workloadPod, pv, s3pa, fieldFilters, priorityClassKind, log)
+		return r.handleExistingS3PodAttachment(ctx, workloadPod, vol, s3pa, fieldFilters, priorityClassKind, log)
 	} else {
-		return r.handleNewS3PodAttachment(ctx, workloadPod, pv, roleArn, fieldFilters, priorityClassKind, log)
+		return r.handleNewS3PodAttachment(ctx, workloadPod, vol, roleArn, fieldFilters, priorityClassKind, log)
 	}
 }
 
-// setupLogger creates and configures logger that includes pod namespace/name, PVC name, and workload UID fields,
-// plus any provided fieldFilters.
+// setupLogger creates and configures logger that includes pod namespace/name, PVC name (or inline volume name),
+// and workload UID fields, plus any provided fieldFilters.
 func (r *Reconciler) setupLogger(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pvc *corev1.PersistentVolumeClaim,
+	vol *workloadVolume,
 	workloadUID string,
 	fieldFilters client.MatchingFields,
 ) logr.Logger {
 	logger := logf.FromContext(ctx).WithValues(
 		"workloadPod", types.NamespacedName{Namespace: workloadPod.Namespace, Name: workloadPod.Name},
-		"pvc", pvc.Name,
 		"workloadUID", workloadUID,
 	)
+	if vol.isInline() {
+		logger = logger.WithValues("inlineVolume", vol.inlineVolumeName)
+	} else {
+		logger = logger.WithValues("pvc", vol.pvc.Name)
+	}
 
 	var keyValues []any
 	for k, v := range fieldFilters {
@@ -363,8 +504,16 @@ func (r *Reconciler) setupLogger(
 }
 
 // buildFieldFilters build appropriate matching field filters for List operation on MountpointS3PodAttachments
-func (r *Reconciler) buildFieldFilters(workloadPod *corev1.Pod, pv *corev1.PersistentVolume, roleArn string) client.MatchingFields {
-	authSource := r.getAuthSource(pv)
+// Workloads matching different MountpointS3MountPolicies (i.e., with different `mountPolicies` keys) do not share Mountpoint Pods,
+// as the policies might result in different mount options.
+// It returns an error if volume attributes of `pv` are invalid, so workloads of invalid volumes are never matched into
+// existing Mountpoint Pods by the partially parsed attributes.
+func (r *Reconciler) buildFieldFilters(workloadPod *corev1.Pod, pv *corev1.PersistentVolume, roleArn, mountPolicies string) (client.MatchingFields, error) {
+	volumeAttrs, err := mppod.ParseVolumeAttributes(pv)
+	if err != nil {
+		return nil, err
+	}
+	authSource := volumeAttrs.AuthenticationSource
 	fsGroup := r.getFSGroup(workloadPod)
 
 	fieldFilters := client.MatchingFields{
@@ -372,28 +521,39 @@ func (r *Reconciler) buildFieldFilters(workloadPod *corev1.Pod, pv *corev1.Persi
 		crdv2.FieldPersistentVolumeName: pv.Name,
 		crdv2.FieldVolumeID:             pv.Spec.CSI.VolumeHandle,
 		crdv2.FieldMountOptions:         strings.Join(pv.Spec.MountOptions, ","),
+		crdv2.FieldMountPolicies:        mountPolicies,
 		crdv2.FieldWorkloadFSGroup:      fsGroup,
 		crdv2.FieldAuthenticationSource: authSource,
+		// Mountpoint Pods are not shared across different assumed roles
+		crdv2.FieldAssumeRoleARN: assumeRoleARN(volumeAttrs),
 	}
 
-	if authSource == credentialprovider.AuthenticationSourcePod {
+	switch authSource {
+	case credentialprovider.AuthenticationSourcePod:
 		fieldFilters[crdv2.FieldWorkloadNamespace] = workloadPod.Namespace
 		fieldFilters[crdv2.FieldWorkloadServiceAccountName] = getServiceAccountName(workloadPod)
 		fieldFilters[crdv2.FieldWorkloadServiceAccountIAMRoleARN] = roleArn
+	case credentialprovider.AuthenticationSourceSecret:
+		// The Secret might be looked up in the workload's namespace, so only share Mountpoint Pods within the same namespace
+		fieldFilters[crdv2.FieldWorkloadNamespace] = workloadPod.Namespace
+	default:
+		if credentialprovider.IsCredentialPlugin(authSource) {
+			// Credential plugins receive the workload's identity, so only share Mountpoint Pods within the same identity
+			fieldFilters[crdv2.FieldWorkloadNamespace] = workloadPod.Namespace
+			fieldFilters[crdv2.FieldWorkloadServiceAccountName] = getServiceAccountName(workloadPod)
+		}
 	}
 
-	return fieldFilters
+	return fieldFilters, nil
 }
 
-// getAuthSource returns authentication source from given PV.
-// Defaults to `driver` if `authenticationSource` is not found in volume attributes.
-func (r *Reconciler) getAuthSource(pv *corev1.PersistentVolume) string {
-	volumeAttributes := mppod.ExtractVolumeAttributes(pv)
-	authSource := volumeAttributes[volumecontext.AuthenticationSource]
-	if authSource == credentialprovider.AuthenticationSourceUnspecified {
-		return credentialprovider.AuthenticationSourceDriver
+// assumeRoleARN returns the ARN of the role to assume from given volume attributes.
+// Returns an empty string if `roleArn` is not found in volume attributes.
+func assumeRoleARN(volumeAttrs volumecontext.VolumeAttributes) string {
+	if volumeAttrs.AssumeRole == nil {
+		return ""
 	}
-	return authSource
+	return volumeAttrs.AssumeRole.RoleARN
 }
 
 // getFSGroup returns the FSGroup value from the pod's security context as a string.
@@ -437,6 +597,7 @@ func (r *Reconciler) getExistingS3PodAttachment(ctx context.Context, fieldFilter
 //   - an error, if more than one duplicate references Mountpoint Pods
 func (r *Reconciler) recoverFromDuplicateS3PodAttachments(ctx context.Context, duplicates []crdv2.MountpointS3PodAttachment, fieldFilters client.MatchingFields, log logr.Logger) (*crdv2.MountpointS3PodAttachment, error) {
 	log.Info(fmt.Sprintf("Found %d MountpointS3PodAttachments for the same field-tuple, attempting recovery", len(duplicates)))
+	duplicateS3PARecoveries.Inc()
 
 	var withMountpointPods []*crdv2.MountpointS3PodAttachment
 	var noMountpointPods []*crdv2.MountpointS3PodAttachment
@@ -487,7 +648,7 @@ func (r *Reconciler) handleInactivePod(ctx context.Context, s3pa *crdv2.Mountpoi
 func (r *Reconciler) handleExistingS3PodAttachment(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pv *corev1.PersistentVolume,
+	vol *workloadVolume,
 	s3pa *crdv2.MountpointS3PodAttachment,
 	fieldFilters client.MatchingFields,
 	priorityClassKind mppod.PriorityClassKind,
@@ -498,12 +659,12 @@ func (r *Reconciler) handleExistingS3PodAttachment(
 		r.s3paExpectations.clear(fieldFilters)
 	}
 
-	if s3paContainsWorkload(s3pa, string(workloadPod.UID)) {
+	if s3paContainsWorkload(s3pa, string(workloadPod.UID), vol.inlineVolumeName) {
 		log.Info("MountpointS3PodAttachment already has this workload UID")
 		return DontRequeue, nil
 	}
 
-	return r.addWorkloadToS3PodAttachment(ctx, workloadPod, pv, s3pa, priorityClassKind, log)
+	return r.addWorkloadToS3PodAttachment(ctx, workloadPod, vol, s3pa, priorityClassKind, log)
 }
 
 // addWorkloadToS3PodAttachment adds workload UID to the first suitable Mountpoint Pod in the map.
@@ -511,14 +672,14 @@ func (r *Reconciler) handleExistingS3PodAttachment(
 func (r *Reconciler) addWorkloadToS3PodAttachment(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pv *corev1.PersistentVolume,
+	vol *workloadVolume,
 	s3pa *crdv2.MountpointS3PodAttachment,
 	priorityClassKind mppod.PriorityClassKind,
 	log logr.Logger,
 ) (bool, error) {
 	log.Info("Adding workload UID to MountpointS3PodAttachment")
 
-	shouldRequeue, err := r.assignWorkloadToAnExistingMountpointPod(ctx, s3pa, string(workloadPod.UID), log)
+	shouldRequeue, err := r.assignWorkloadToAnExistingMountpointPod(ctx, s3pa, workloadPod, vol, log)
 	if err == nil {
 		// Successfully assigned workload to an existing Mountpoint Pod
 		return shouldRequeue, nil
@@ -530,18 +691,15 @@ func (r *Reconciler) addWorkloadToS3PodAttachment(
 	}
 
 	// There is no suitable Mountpoint Pod for the workload, we need to create a new one
-	mpPod, err := r.spawnMountpointPod(ctx, workloadPod, pv, priorityClassKind, log)
+	mpPod, err := r.spawnMountpointPod(ctx, workloadPod, vol.pv, priorityClassKind, log)
 	if err != nil {
 		log.Error(err, "Failed to spawn Mountpoint Pod")
+		r.recordEvent(workloadPod, corev1.EventTypeWarning, EventReasonMountpointPodSpawnFailed,
+			"Failed to spawn Mountpoint Pod for %s: %v", volumeDescription(vol), err)
 		return Requeue, err
 	}
-	s3pa.Spec.MountpointS3PodAttachments[mpPod.Name] = []crdv2.WorkloadAttachment{
-		{
-			WorkloadPodUID: string(workloadPod.UID),
-			AttachmentTime: metav1.NewTime(time.Now().UTC()),
-		},
-	}
-	err = r.Update(ctx, s3pa)
+	s3pa.Spec.MountpointS3PodAttachments[mpPod.Name] = []crdv2.WorkloadAttachment{newWorkloadAttachment(workloadPod, vol)}
+	err = r.updateS3PodAttachment(ctx, s3pa)
 	if err != nil {
 		log.Error(err, "Failed to update MountpointS3PodAttachment, deleting spawned Mountpoint Pod", "mountpointPodName", mpPod.Name)
 
@@ -561,6 +719,8 @@ func (r *Reconciler) addWorkloadToS3PodAttachment(
 	}
 
 	log.Info("A new Mountpoint Pod is successfully created for the workload and MountpointS3PodAttachment is successfully updated", "mountpointPodName", mpPod.Name)
+	r.recordMountpointPodAssigned(workloadPod, vol, mpPod.Name)
+	r.setMountpointPodPhase(ctx, s3pa, mpPod, crdv2.MountpointS3PodPending, crdv2.ReasonMountpointPodScheduled, mountpointPodPendingMessage, log)
 
 	return DontRequeue, nil
 }
@@ -569,12 +729,13 @@ func (r *Reconciler) addWorkloadToS3PodAttachment(
 // to indicate that a new Mountpoint Pod should be created to assign the workload for.
 var errNoSuitableMountpointPodForTheWorkload = errors.New("no suitable Mountpoint Pod found for the workload")
 
-// assignWorkloadToAnExistingMountpointPod tries to assign given `workloadUID` to an existing Mountpoint Pod.
+// assignWorkloadToAnExistingMountpointPod tries to assign given `workloadPod`'s volume `vol` to an existing Mountpoint Pod.
 // It returns `errNoSuitableMountpointPodForTheWorkload` if there isn't any suitable Mountpoint Pod to assign this new workload.
-func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, workloadUID string, log logr.Logger) (bool, error) {
+func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, workloadPod *corev1.Pod, vol *workloadVolume, log logr.Logger) (bool, error) {
 	log.Info("Trying to assign workload to an existing Mountpoint Pod")
 
-	found := false
+	attachment := newWorkloadAttachment(workloadPod, vol)
+	assignedMpPodName := ""
 
 	for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
 		mpPodLog := log.WithValues("mountpointPodName", mpPodName)
@@ -592,20 +753,17 @@ func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context
 			continue
 		}
 
-		s3pa.Spec.MountpointS3PodAttachments[mpPodName] = append(s3pa.Spec.MountpointS3PodAttachments[mpPodName], crdv2.WorkloadAttachment{
-			WorkloadPodUID: workloadUID,
-			AttachmentTime: metav1.NewTime(time.Now().UTC()),
-		})
-		found = true
+		s3pa.Spec.MountpointS3PodAttachments[mpPodName] = append(s3pa.Spec.MountpointS3PodAttachments[mpPodName], attachment)
+		assignedMpPodName = mpPodName
 		mpPodLog.Info("Found a suitable Mountpoint Pod to assign new workload")
 		break
 	}
 
-	if !found {
+	if assignedMpPodName == "" {
 		return DontRequeue, errNoSuitableMountpointPodForTheWorkload
 	}
 
-	err := r.Update(ctx, s3pa)
+	err := r.updateS3PodAttachment(ctx, s3pa)
 	if err != nil {
 		if apierrors.IsConflict(err) {
 			log.Info("Failed to update MountpointS3PodAttachment - resource conflict - requeue")
@@ -615,6 +773,7 @@ func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context
 		return Requeue, err
 	}
 
+	r.recordMountpointPodAssigned(workloadPod, vol, assignedMpPodName)
 	return DontRequeue, nil
 }
 
@@ -634,7 +793,7 @@ func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa
 		}
 		if found {
 			s3pa.Spec.MountpointS3PodAttachments[mpPodName] = filteredUIDs
-			err := r.Update(ctx, s3pa)
+			err := r.updateS3PodAttachment(ctx, s3pa)
 			if err != nil {
 				if apierrors.IsConflict(err) {
 					log.Info("Failed to remove workload pod UID from existing MountpointS3PodAttachment due to resource conflict, requeuing")
@@ -661,7 +820,7 @@ func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa
 			log.Info("Mountpoint pod has zero workload UIDs. Will remove it from MountpointS3PodAttachment",
 				"mountpointPodName", mpPodName)
 			delete(s3pa.Spec.MountpointS3PodAttachments, mpPodName)
-			err = r.Update(ctx, s3pa)
+			err = r.updateS3PodAttachment(ctx, s3pa)
 			if err != nil {
 				if apierrors.IsConflict(err) {
 					log.Info("Failed to remove Mountpoint pod from MountpointS3PodAttachment due to resource conflict, requeuing",
@@ -671,6 +830,9 @@ func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa
 				log.Error(err, "Failed to update MountpointS3PodAttachment")
 				return Requeue, err
 			}
+			if len(s3pa.Spec.MountpointS3PodAttachments) > 0 {
+				r.removeMountpointPodStatus(ctx, s3pa, mpPodName, log)
+			}
 		}
 	}
 
@@ -701,7 +863,7 @@ func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa
 func (r *Reconciler) handleNewS3PodAttachment(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pv *corev1.PersistentVolume,
+	vol *workloadVolume,
 	roleArn string,
 	fieldFilters client.MatchingFields,
 	priorityClassKind mppod.PriorityClassKind,
@@ -720,7 +882,7 @@ func (r *Reconciler) handleNewS3PodAttachment(
 		return DontRequeue, nil
 	}
 
-	if err := r.createS3PodAttachmentWithMPPod(ctx, workloadPod, pv, roleArn, priorityClassKind, log); err != nil {
+	if err := r.createS3PodAttachmentWithMPPod(ctx, workloadPod, vol, roleArn, fieldFilters[crdv2.FieldMountPolicies], priorityClassKind, log); err != nil {
 		return Requeue, err
 	}
 
@@ -728,19 +890,27 @@ func (r *Reconciler) handleNewS3PodAttachment(
 	return Requeue, nil
 }
 
-// createS3PodAttachmentWithMPPod creates new MountpointS3PodAttachment resource and Mountpoint Pod for given workload and PV.
+// createS3PodAttachmentWithMPPod creates new MountpointS3PodAttachment resource and Mountpoint Pod for given workload and volume.
 func (r *Reconciler) createS3PodAttachmentWithMPPod(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
-	pv *corev1.PersistentVolume,
+	vol *workloadVolume,
 	roleArn string,
+	mountPolicies string,
 	priorityClassKind mppod.PriorityClassKind,
 	log logr.Logger,
 ) error {
-	authSource := r.getAuthSource(pv)
+	pv := vol.pv
+	volumeAttrs, err := mppod.ParseVolumeAttributes(pv)
+	if err != nil {
+		return err
+	}
+	authSource := volumeAttrs.AuthenticationSource
 	mpPod, err := r.spawnMountpointPod(ctx, workloadPod, pv, priorityClassKind, log)
 	if err != nil {
 		log.Error(err, "Failed to spawn Mountpoint Pod")
+		r.recordEvent(workloadPod, corev1.EventTypeWarning, EventReasonMountpointPodSpawnFailed,
+			"Failed to spawn Mountpoint Pod for %s: %v", volumeDescription(vol), err)
 		return err
 	}
 	s3pa := &crdv2.MountpointS3PodAttachment{
@@ -755,17 +925,27 @@ func (r *Reconciler) createS3PodAttachmentWithMPPod(
 			PersistentVolumeName: pv.Name,
 			VolumeID:             pv.Spec.CSI.VolumeHandle,
 			MountOptions:         strings.Join(pv.Spec.MountOptions, ","),
+			MountPolicies:        mountPolicies,
 			WorkloadFSGroup:      r.getFSGroup(workloadPod),
 			AuthenticationSource: authSource,
+			AssumeRoleARN:        assumeRoleARN(volumeAttrs),
 			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
-				mpPod.Name: {{WorkloadPodUID: string(workloadPod.UID), AttachmentTime: metav1.NewTime(time.Now().UTC())}},
+				mpPod.Name: {newWorkloadAttachment(workloadPod, vol)},
 			},
 		},
 	}
-	if authSource == credentialprovider.AuthenticationSourcePod {
+	switch authSource {
+	case credentialprovider.AuthenticationSourcePod:
 		s3pa.Spec.WorkloadNamespace = workloadPod.Namespace
 		s3pa.Spec.WorkloadServiceAccountName = getServiceAccountName(workloadPod)
 		s3pa.Spec.WorkloadServiceAccountIAMRoleARN = roleArn
+	case credentialprovider.AuthenticationSourceSecret:
+		s3pa.Spec.WorkloadNamespace = workloadPod.Namespace
+	default:
+		if credentialprovider.IsCredentialPlugin(authSource) {
+			s3pa.Spec.WorkloadNamespace = workloadPod.Namespace
+			s3pa.Spec.WorkloadServiceAccountName = getServiceAccountName(workloadPod)
+		}
 	}
 
 	err = r.Create(ctx, s3pa)
@@ -779,7 +959,10 @@ func (r *Reconciler) createS3PodAttachmentWithMPPod(
 		return err
 	}
 
+	s3paOperations.WithLabelValues(operationCreate).Inc()
 	log.Info("MountpointS3PodAttachment is created", "s3pa", s3pa.Name)
+	r.recordMountpointPodAssigned(workloadPod, vol, mpPod.Name)
+	r.setMountpointPodPhase(ctx, s3pa, mpPod, crdv2.MountpointS3PodPending, crdv2.ReasonMountpointPodScheduled, mountpointPodPendingMessage, log)
 	return nil
 }
 
@@ -789,7 +972,21 @@ func (r *Reconciler) createS3PodAttachmentWithMPPod(
 // Always use this instead of a bare r.Delete(ctx, s3pa) so the precondition is
 // not accidentally skipped.
 func (r *Reconciler) deleteS3PodAttachment(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) error {
-	return r.Delete(ctx, s3pa, client.Preconditions{ResourceVersion: &s3pa.ResourceVersion})
+	err := r.Delete(ctx, s3pa, client.Preconditions{ResourceVersion: &s3pa.ResourceVersion})
+	if err == nil {
+		s3paOperations.WithLabelValues(operationDelete).Inc()
+	}
+	return err
+}
+
+// updateS3PodAttachment updates the given S3PA.
+// Always use this instead of a bare r.Update(ctx, s3pa) so the update is recorded in metrics.
+func (r *Reconciler) updateS3PodAttachment(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) error {
+	err := r.Update(ctx, s3pa)
+	if err == nil {
+		s3paOperations.WithLabelValues(operationUpdate).Inc()
+	}
+	return err
 }
 
 // spawnMountpointPod spawns a new Mountpoint Pod for given `workloadPod` and volume.
@@ -815,6 +1012,7 @@ func (r *Reconciler) spawnMountpointPod(
 		return nil, err
 	}
 
+	mountpointPodsSpawned.WithLabelValues(priorityClassKind.String()).Inc()
 	log.Info("Mountpoint Pod spawned", "mountpointPodName", mpPod.Name)
 	return mpPod, nil
 }
@@ -851,6 +1049,11 @@ func (r *Reconciler) getMountpointPod(ctx context.Context, name string) (*corev1
 
 // shouldAssignNewWorkloadToMountpointPod returns whether a new workload should be assigned to the Mountpoint Pod `mpPod`.
 func (r *Reconciler) shouldAssignNewWorkloadToMountpointPod(mpPod *corev1.Pod, log logr.Logger) bool {
+	if mpPod.Status.Phase == corev1.PodFailed {
+		log.Info("Mountpoint Pod is failed - not suitable for a new workload")
+		return false
+	}
+
 	if mpPod.Annotations != nil {
 		if mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" {
 			log.Info("Mountpoint Pod is annotated as 'needs-unmount' - not suitable for a new workload")
@@ -875,6 +1078,10 @@ func (r *Reconciler) shouldAssignNewWorkloadToMountpointPod(mpPod *corev1.Pod, l
 	return true
 }
 
+// errInvalidVolumeAttributes is returned when volume attributes of a workload's volume are invalid.
+// This is a terminal error, as volume attributes of a volume can't be changed.
+var errInvalidVolumeAttributes = errors.New("invalid volume attributes")
+
 // errPVCIsNotBoundToAPV is returned when given PVC is not bound to a PV yet.
 // This is not a terminal error - as PVCs can be bound to PVs dynamically - and just a transient error
 // to be retried later.
@@ -1008,7 +1215,10 @@ func (r *Reconciler) createHeadroomPodForMountpointPod(ctx context.Context, work
 		return err
 	}
 
+	headroomPodOperations.WithLabelValues(operationCreate).Inc()
 	log.Info("Headroom Pod created", "headroomPodName", hrPod.Name)
+	r.recordEvent(workloadPod, corev1.EventTypeNormal, EventReasonHeadroomPodCreated,
+		"Created Headroom Pod %s to reserve space for the Mountpoint Pod of volume %s", hrPod.Name, pv.Name)
 	return nil
 }
 
@@ -1026,6 +1236,9 @@ func (r *Reconciler) deleteHeadroomPodForMountpointPod(ctx context.Context, work
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
+	if err == nil {
+		headroomPodOperations.WithLabelValues(operationDelete).Inc()
+	}
 
 	log.Info("Headroom Pod removed", "headroomPodName", hrPod.Name)
 	return nil
@@ -1135,10 +1348,11 @@ func isPodRunning(p *corev1.Pod) bool {
 }
 
 // s3paContainsWorkload checks whether MountpointS3PodAttachment has `workloadUID` in it.
-func s3paContainsWorkload(s3pa *crdv2.MountpointS3PodAttachment, workloadUID string) bool {
+// For CSI ephemeral inline volumes, `workloadVolumeName` is the name of the volume in the workload pod's spec, and is empty otherwise.
+func s3paContainsWorkload(s3pa *crdv2.MountpointS3PodAttachment, workloadUID, workloadVolumeName string) bool {
 	for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
 		for _, attachment := range attachments {
-			if attachment.WorkloadPodUID == workloadUID {
+			if attachment.WorkloadPodUID == workloadUID && attachment.WorkloadVolumeName == workloadVolumeName {
 				return true
 			}
 		}
@@ -1146,6 +1360,15 @@ func s3paContainsWorkload(s3pa *crdv2.MountpointS3PodAttachment, workloadUID str
 	return false
 }
 
+// newWorkloadAttachment returns a new attachment of `workloadPod` for volume `vol`.
+func newWorkloadAttachment(workloadPod *corev1.Pod, vol *workloadVolume) crdv2.WorkloadAttachment {
+	return crdv2.WorkloadAttachment{
+		WorkloadPodUID:     string(workloadPod.UID),
+		AttachmentTime:     metav1.NewTime(time.Now().UTC()),
+		WorkloadVolumeName: vol.inlineVolumeName,
+	}
+}
+
 // getServiceAccountName returns the pod's service account name or "default" if not specified
 func getServiceAccountName(pod *corev1.Pod) string {
 	if pod.Spec.ServiceAccountName != "" {
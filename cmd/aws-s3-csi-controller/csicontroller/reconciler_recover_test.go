@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -170,6 +171,8 @@ const (
 	testNodeName = "test-node"
 	testPVName   = "test-pv"
 	testVolumeID = "test-vol-id"
+
+	testCSIDriverVersion = "2.1.0"
 )
 
 func newS3PA(name string, attachments map[string][]crdv2.WorkloadAttachment) *crdv2.MountpointS3PodAttachment {
@@ -193,6 +196,20 @@ func newReconcilerWithObjects(t *testing.T, objs ...client.Object) (client.Clien
 	return c, &Reconciler{Client: c, mountpointPodConfig: testPodConfig(), s3paExpectations: newExpectations()}
 }
 
+func newTestPV() *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: testPVName},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       "s3.csi.aws.com",
+					VolumeHandle: testVolumeID,
+				},
+			},
+		},
+	}
+}
+
 func testPodConfig() mppod.Config {
 	return mppod.Config{
 		Namespace:         "mount-s3",
@@ -232,3 +249,27 @@ func assertS3PADeleted(t *testing.T, c client.Client, name string) {
 		t.Errorf("unexpected error checking S3PodAttachment %q: %v", name, err)
 	}
 }
+
+func getPod(t *testing.T, c client.Client, name string) *corev1.Pod {
+	t.Helper()
+	pod := &corev1.Pod{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: testPodConfig().Namespace, Name: name}, pod))
+	return pod
+}
+
+func assertMountpointPodCount(t *testing.T, c client.Client, expected int) {
+	t.Helper()
+	pods := &corev1.PodList{}
+	assert.NoError(t, c.List(context.Background(), pods, client.InNamespace(testPodConfig().Namespace)))
+	assert.Equals(t, expected, len(pods.Items))
+}
+
+func assertPodDeleted(t *testing.T, c client.Client, name string) {
+	t.Helper()
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: testPodConfig().Namespace, Name: name}, &corev1.Pod{})
+	if err == nil {
+		t.Errorf("expected Pod %q to be deleted, but it still exists", name)
+	} else if !apierrors.IsNotFound(err) {
+		t.Errorf("unexpected error checking Pod %q: %v", name, err)
+	}
+}
@@ -1,11 +1,28 @@
 package csicontroller
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/go-logr/logr"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
 
@@ -40,3 +57,468 @@ func TestIsPodActive(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilingWorkloadsWithInlineVolumes(t *testing.T) {
+	t.Run("creates a Mountpoint Pod and MountpointS3PodAttachment for an inline volume", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+
+		s3pa := getOnlyS3PA(t, c)
+		assert.Equals(t, inlineVolumeNameFor(map[string]string{"bucketName": "test-bucket"}, false, ""), s3pa.Spec.PersistentVolumeName)
+		assert.Equals(t, s3pa.Spec.PersistentVolumeName, s3pa.Spec.VolumeID)
+		assert.Equals(t, 1, len(s3pa.Spec.MountpointS3PodAttachments))
+		for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			assert.Equals(t, 1, len(attachments))
+			assert.Equals(t, string(workload.UID), attachments[0].WorkloadPodUID)
+			assert.Equals(t, "s3-data", attachments[0].WorkloadVolumeName)
+
+			mpPod := getPod(t, c, mpPodName)
+			assert.Equals(t, s3pa.Spec.PersistentVolumeName, mpPod.Annotations[mppod.AnnotationVolumeName])
+		}
+	})
+
+	t.Run("shares the Mountpoint Pod between workloads with the same inline volume attributes", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket"}
+		workload1 := newInlineVolumeWorkloadPod("workload-1", attributes)
+		workload2 := newInlineVolumeWorkloadPod("workload-2", attributes)
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		s3pa := getOnlyS3PA(t, c)
+		assert.Equals(t, 1, len(s3pa.Spec.MountpointS3PodAttachments))
+		for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			assert.Equals(t, 2, len(attachments))
+		}
+		assertMountpointPodCount(t, c, 1)
+	})
+
+	t.Run("does not share the Mountpoint Pod between workloads with different inline volume attributes", func(t *testing.T) {
+		workload1 := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket"})
+		workload2 := newInlineVolumeWorkloadPod("workload-2", map[string]string{"bucketName": "test-bucket", "prefix": "data/"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 2, len(s3paList.Items))
+		assertMountpointPodCount(t, c, 2)
+	})
+
+	t.Run("does not share the Mountpoint Pod between namespaces with secret authentication source", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket", "authenticationSource": "secret", "secretName": "s3-credentials"}
+		workload1 := newInlineVolumeWorkloadPod("workload-1", attributes)
+		workload2 := newInlineVolumeWorkloadPod("workload-2", attributes)
+		workload2.Namespace = "other"
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2,
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: defaultServiceAccount, Namespace: "other"}})
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 2, len(s3paList.Items))
+		namespaces := map[string]bool{}
+		for _, s3pa := range s3paList.Items {
+			assert.Equals(t, "secret", s3pa.Spec.AuthenticationSource)
+			namespaces[s3pa.Spec.WorkloadNamespace] = true
+		}
+		assert.Equals(t, map[string]bool{"default": true, "other": true}, namespaces)
+		assertMountpointPodCount(t, c, 2)
+	})
+
+	t.Run("does not share the Mountpoint Pod between service accounts with credential plugin authentication source", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket", "authenticationSource": "vault.example.com"}
+		workload1 := newInlineVolumeWorkloadPod("workload-1", attributes)
+		workload2 := newInlineVolumeWorkloadPod("workload-2", attributes)
+		workload2.Spec.ServiceAccountName = "other-sa"
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2,
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "other-sa", Namespace: "default"}})
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 2, len(s3paList.Items))
+		serviceAccounts := map[string]bool{}
+		for _, s3pa := range s3paList.Items {
+			assert.Equals(t, "vault.example.com", s3pa.Spec.AuthenticationSource)
+			assert.Equals(t, "default", s3pa.Spec.WorkloadNamespace)
+			serviceAccounts[s3pa.Spec.WorkloadServiceAccountName] = true
+		}
+		assert.Equals(t, map[string]bool{defaultServiceAccount: true, "other-sa": true}, serviceAccounts)
+		assertMountpointPodCount(t, c, 2)
+	})
+
+	t.Run("does not share the Mountpoint Pod between inline volumes with different nodePublishSecretRef", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket", "authenticationSource": "secret"}
+		workload1 := newInlineVolumeWorkloadPod("workload-1", attributes)
+		workload1.Spec.Volumes[0].CSI.NodePublishSecretRef = &corev1.LocalObjectReference{Name: "s3-credentials-1"}
+		workload2 := newInlineVolumeWorkloadPod("workload-2", attributes)
+		workload2.Spec.Volumes[0].CSI.NodePublishSecretRef = &corev1.LocalObjectReference{Name: "s3-credentials-2"}
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		assertMountpointPodCount(t, c, 2)
+	})
+
+	t.Run("does not share the Mountpoint Pod between volumes assuming different roles", func(t *testing.T) {
+		workload1 := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket", "roleArn": "arn:aws:iam::111122223333:role/data-lake-a"})
+		workload2 := newInlineVolumeWorkloadPod("workload-2", map[string]string{"bucketName": "test-bucket", "roleArn": "arn:aws:iam::111122223333:role/data-lake-b"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 2, len(s3paList.Items))
+		roles := map[string]bool{}
+		for _, s3pa := range s3paList.Items {
+			roles[s3pa.Spec.AssumeRoleARN] = true
+		}
+		assert.Equals(t, map[string]bool{
+			"arn:aws:iam::111122223333:role/data-lake-a": true,
+			"arn:aws:iam::111122223333:role/data-lake-b": true,
+		}, roles)
+		assertMountpointPodCount(t, c, 2)
+	})
+
+	t.Run("does not assign workloads of volumes with invalid attributes", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket", "roleArn": "data-lake"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload)
+		recorder := record.NewFakeRecorder(10)
+		r.recorder = recorder
+
+		// Reconciling again won't help, so it should neither fail nor requeue
+		res, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{}, res)
+
+		assertMountpointPodCount(t, c, 0)
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 0, len(s3paList.Items))
+		if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+EventReasonInvalidVolume+" ") {
+			t.Fatalf("Expected an %s event, but got %q", EventReasonInvalidVolume, event)
+		}
+		if len(recorder.Events) != 0 {
+			t.Fatalf("Expected the %s event to be recorded once, but got %q", EventReasonInvalidVolume, <-recorder.Events)
+		}
+	})
+
+	t.Run("ignores inline volumes of other CSI drivers", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket"})
+		workload.Spec.Volumes[0].CSI.Driver = "other.csi.k8s.io"
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+
+		assertMountpointPodCount(t, c, 0)
+	})
+}
+
+func TestRecordingEventsAndMetrics(t *testing.T) {
+	t.Run("records assignments to Mountpoint Pods", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket"}
+		workload1 := newInlineVolumeWorkloadPod("workload-1", attributes)
+		workload2 := newInlineVolumeWorkloadPod("workload-2", attributes)
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload1, workload2)
+		recorder := record.NewFakeRecorder(10)
+		r.recorder = recorder
+
+		spawned := promtestutil.ToFloat64(mountpointPodsSpawned.WithLabelValues(mppod.DefaultPriorityClass.String()))
+		created := promtestutil.ToFloat64(s3paOperations.WithLabelValues(operationCreate))
+		updated := promtestutil.ToFloat64(s3paOperations.WithLabelValues(operationUpdate))
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		var mpPodName string
+		for name := range getOnlyS3PA(t, c).Spec.MountpointS3PodAttachments {
+			mpPodName = name
+		}
+		expectedEvent := fmt.Sprintf("Normal %s Assigned inline volume s3-data to Mountpoint Pod %s/%s", EventReasonMountpointPodAssigned, testPodConfig().Namespace, mpPodName)
+		assert.Equals(t, expectedEvent, <-recorder.Events)
+		assert.Equals(t, expectedEvent, <-recorder.Events)
+
+		assert.Equals(t, spawned+1, promtestutil.ToFloat64(mountpointPodsSpawned.WithLabelValues(mppod.DefaultPriorityClass.String())))
+		assert.Equals(t, created+1, promtestutil.ToFloat64(s3paOperations.WithLabelValues(operationCreate)))
+		assert.Equals(t, updated+1, promtestutil.ToFloat64(s3paOperations.WithLabelValues(operationUpdate)))
+	})
+
+	t.Run("records unbound PVCs", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", nil)
+		workload.Spec.Volumes[0].VolumeSource = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "s3-pvc"},
+		}
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "s3-pvc", Namespace: workload.Namespace}}
+		_, r := newInlineVolumeReconcilerWithObjects(t, workload, pvc)
+		recorder := record.NewFakeRecorder(10)
+		r.recorder = recorder
+
+		result, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+		assert.Equals(t, true, result.Requeue)
+		assert.Equals(t, fmt.Sprintf(`Normal %s PersistentVolumeClaim "s3-pvc" is not bound to a PersistentVolume yet, waiting to spawn a Mountpoint Pod`, EventReasonPVCNotBound), <-recorder.Events)
+	})
+}
+
+func TestEnforcingMountPolicies(t *testing.T) {
+	newObjects := func(mountOptions ...string) (*corev1.Pod, []client.Object) {
+		workload := newInlineVolumeWorkloadPod("workload-1", nil)
+		workload.Spec.Volumes[0].VolumeSource = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "s3-pvc"},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3-pvc", Namespace: workload.Namespace},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: testPVName},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+		pv := newTestPV()
+		pv.Spec.ClaimRef = &corev1.ObjectReference{Name: pvc.Name, Namespace: pvc.Namespace}
+		pv.Spec.MountOptions = mountOptions
+		policy := &crdv2.MountpointS3MountPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-deletes"},
+			Spec:       crdv2.MountpointS3MountPolicySpec{DeniedMountOptions: []string{"--allow-delete"}},
+		}
+		return workload, []client.Object{workload, pvc, pv, policy}
+	}
+
+	t.Run("does not assign workloads with denied mount options", func(t *testing.T) {
+		workload, objs := newObjects("allow-delete", "region us-east-1")
+		c, r := newInlineVolumeReconcilerWithObjects(t, objs...)
+		recorder := record.NewFakeRecorder(10)
+		r.recorder = recorder
+
+		result, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{RequeueAfter: mountPolicyViolationRequeueInterval}, result)
+
+		assertMountpointPodCount(t, c, 0)
+		assert.Equals(t, fmt.Sprintf(`Warning %s Mount options of PVC s3-pvc violate MountpointS3MountPolicy no-deletes: denied mount options: --allow-delete`,
+			EventReasonMountPolicyViolated), <-recorder.Events)
+	})
+
+	t.Run("assigns workloads complying with policies", func(t *testing.T) {
+		workload, objs := newObjects("region us-east-1")
+		c, r := newInlineVolumeReconcilerWithObjects(t, objs...)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+
+		assertMountpointPodCount(t, c, 1)
+	})
+
+	t.Run("does not share the Mountpoint Pod between workloads matching different policies", func(t *testing.T) {
+		workload1, objs := newObjects("region us-east-1")
+		workload2 := workload1.DeepCopy()
+		workload2.Name, workload2.UID = "workload-2", "workload-2-uid"
+		workload2.Labels = map[string]string{"team": "data"}
+		readOnly := &crdv2.MountpointS3MountPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "read-only"},
+			Spec: crdv2.MountpointS3MountPolicySpec{
+				PodSelector:         &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}},
+				DefaultMountOptions: []string{"--read-only"},
+			},
+		}
+		c, r := newInlineVolumeReconcilerWithObjects(t, append(objs, workload2, readOnly)...)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload1)
+		assert.NoError(t, err)
+		_, err = r.reconcileWorkloadPod(context.Background(), workload2)
+		assert.NoError(t, err)
+
+		assertMountpointPodCount(t, c, 2)
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		var mountPolicies []string
+		for _, s3pa := range s3paList.Items {
+			mountPolicies = append(mountPolicies, s3pa.Spec.MountPolicies)
+		}
+		slices.Sort(mountPolicies)
+		assert.Equals(t, []string{"no-deletes/0", "no-deletes/0,read-only/0"}, mountPolicies)
+	})
+}
+
+func TestReportingMountpointPodStatus(t *testing.T) {
+	t.Run("reports spawned Mountpoint Pods as pending", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+
+		s3pa := getOnlyS3PA(t, c)
+		assert.Equals(t, "0/1", s3pa.Status.Ready)
+		for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+			status := s3pa.Status.MountpointS3PodStatuses[mpPodName]
+			assert.Equals(t, crdv2.MountpointS3PodPending, status.Phase)
+			assert.Equals(t, metav1.ConditionFalse, meta.FindStatusCondition(status.Conditions, crdv2.MountpointS3PodConditionReady).Status)
+		}
+	})
+
+	t.Run("reports failed Mountpoint Pods", func(t *testing.T) {
+		workload := newInlineVolumeWorkloadPod("workload-1", map[string]string{"bucketName": "test-bucket"})
+		c, r := newInlineVolumeReconcilerWithObjects(t, workload)
+
+		_, err := r.reconcileWorkloadPod(context.Background(), workload)
+		assert.NoError(t, err)
+
+		var mpPodName string
+		for name := range getOnlyS3PA(t, c).Spec.MountpointS3PodAttachments {
+			mpPodName = name
+		}
+		mpPod := getPod(t, c, mpPodName)
+		mpPod.CreationTimestamp = metav1.Now()
+		mpPod.Spec.NodeName = testNodeName
+		mpPod.Status.Phase = corev1.PodFailed
+		mpPod.Status.Message = "Pod was evicted"
+
+		_, err = r.reconcileMountpointPod(context.Background(), mpPod)
+		assert.NoError(t, err)
+
+		status := getOnlyS3PA(t, c).Status.MountpointS3PodStatuses[mpPodName]
+		assert.Equals(t, crdv2.MountpointS3PodFailed, status.Phase)
+		assert.Equals(t, "Mountpoint Pod failed: Pod was evicted", status.LastMountError)
+		assert.Equals(t, crdv2.ReasonMountpointPodFailed, meta.FindStatusCondition(status.Conditions, crdv2.MountpointS3PodConditionReady).Reason)
+	})
+
+	t.Run("removes status of Mountpoint Pods removed from the attachment", func(t *testing.T) {
+		attributes := map[string]string{"bucketName": "test-bucket"}
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			"mp-1": {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+			"mp-2": {{WorkloadPodUID: "uid-2", AttachmentTime: metav1.Now()}},
+		})
+		s3pa.SetMountpointS3PodPhase("mp-1", crdv2.MountpointS3PodMounted, "", crdv2.ReasonMounted, "")
+		s3pa.SetMountpointS3PodPhase("mp-2", crdv2.MountpointS3PodMounted, "", crdv2.ReasonMounted, "")
+		c, r := newInlineVolumeReconcilerWithObjects(t, newInlineVolumeWorkloadPod("workload-1", attributes), s3pa)
+		assert.Equals(t, "2/2", s3pa.Status.Ready)
+
+		_, err := r.removeWorkloadFromS3PodAttachment(context.Background(), s3pa, "uid-1", testFilters(), logr.Discard())
+		assert.NoError(t, err)
+
+		got := getOnlyS3PA(t, c)
+		assert.Equals(t, "1/1", got.Status.Ready)
+		if _, ok := got.Status.MountpointS3PodStatuses["mp-1"]; ok {
+			t.Fatalf("expected removed Mountpoint Pod to be removed from MountpointS3PodAttachment status")
+		}
+	})
+}
+
+func TestInlineVolumeNameFor(t *testing.T) {
+	name := inlineVolumeNameFor(map[string]string{"bucketName": "test-bucket", "prefix": "data/"}, false, "")
+	assert.Equals(t, true, len(name) <= validation.LabelValueMaxLength)
+	assert.Equals(t, 0, len(validation.IsValidLabelValue(name)))
+
+	// Pod info passed by kubelet is not part of the volume's identity
+	assert.Equals(t, name, inlineVolumeNameFor(map[string]string{
+		"bucketName":                  "test-bucket",
+		"prefix":                      "data/",
+		volumecontext.CSIPodNamespace: "default",
+	}, false, ""))
+
+	assert.Equals(t, false, name == inlineVolumeNameFor(map[string]string{"bucketName": "test-bucket", "prefix": "data/"}, true, ""))
+	assert.Equals(t, false, name == inlineVolumeNameFor(map[string]string{"bucketName": "test-bucket"}, false, ""))
+	assert.Equals(t, false, name == inlineVolumeNameFor(map[string]string{"bucketName": "test-bucket", "prefix": "data/"}, false, "s3-credentials"))
+}
+
+func newInlineVolumeReconcilerWithObjects(t *testing.T, objs ...client.Object) (client.Client, *Reconciler) {
+	t.Helper()
+	builder := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithObjects(objs...).
+		WithStatusSubresource(&crdv2.MountpointS3PodAttachment{}).
+		WithObjects(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: defaultServiceAccount, Namespace: "default"}})
+	for field, extract := range map[string]func(*crdv2.MountpointS3PodAttachment) string{
+		crdv2.FieldNodeName:             func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.NodeName },
+		crdv2.FieldPersistentVolumeName: func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.PersistentVolumeName },
+		crdv2.FieldVolumeID:             func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.VolumeID },
+		crdv2.FieldMountOptions:         func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.MountOptions },
+		crdv2.FieldMountPolicies:        func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.MountPolicies },
+		crdv2.FieldWorkloadFSGroup:      func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.WorkloadFSGroup },
+		crdv2.FieldAuthenticationSource: func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.AuthenticationSource },
+		crdv2.FieldWorkloadNamespace:    func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.WorkloadNamespace },
+		crdv2.FieldAssumeRoleARN:        func(s3pa *crdv2.MountpointS3PodAttachment) string { return s3pa.Spec.AssumeRoleARN },
+		crdv2.FieldWorkloadServiceAccountName: func(s3pa *crdv2.MountpointS3PodAttachment) string {
+			return s3pa.Spec.WorkloadServiceAccountName
+		},
+	} {
+		builder = builder.WithIndex(&crdv2.MountpointS3PodAttachment{}, field, func(obj client.Object) []string {
+			return []string{extract(obj.(*crdv2.MountpointS3PodAttachment))}
+		})
+	}
+	c := builder.Build()
+
+	config := testPodConfig()
+	config.CSIDriverVersion = testCSIDriverVersion
+	return c, &Reconciler{
+		Client:               c,
+		mountpointPodConfig:  config,
+		mountpointPodCreator: mppod.NewCreator(config, logr.Discard()),
+		s3paExpectations:     newExpectations(),
+		retryPolicy:          DefaultRetryPolicy,
+		mountPolicies:        mountpolicy.New(c),
+	}
+}
+
+func newInlineVolumeWorkloadPod(name string, volumeAttributes map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: corev1.PodSpec{
+			NodeName:        testNodeName,
+			SecurityContext: &corev1.PodSecurityContext{},
+			Volumes: []corev1.Volume{
+				{
+					Name: "s3-data",
+					VolumeSource: corev1.VolumeSource{
+						CSI: &corev1.CSIVolumeSource{
+							Driver:           mountpointCSIDriverName,
+							VolumeAttributes: volumeAttributes,
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+		},
+	}
+}
+
+func getOnlyS3PA(t *testing.T, c client.Client) *crdv2.MountpointS3PodAttachment {
+	t.Helper()
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	assert.NoError(t, c.List(context.Background(), s3paList))
+	assert.Equals(t, 1, len(s3paList.Items))
+	return &s3paList.Items[0]
+}
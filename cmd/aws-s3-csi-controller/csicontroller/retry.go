@@ -0,0 +1,231 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// retryPodDeletionRequeueInterval is the interval to check again whether a retry Mountpoint Pod of a previous attempt is deleted.
+const retryPodDeletionRequeueInterval = 5 * time.Second
+
+// maxRetryBackoff is the upper bound of the exponential backoff between retries of failed Mountpoint Pods.
+const maxRetryBackoff = 5 * time.Minute
+
+// A RetryPolicy controls how failed Mountpoint Pods are retried.
+type RetryPolicy struct {
+	// Backoff is the time to wait after the first failure before retrying a failed Mountpoint Pod.
+	// It's doubled with each successive failure of the retried Mountpoint Pods, up to 5 minutes.
+	Backoff time.Duration
+	// MaxRetries is the number of successive retries after which failed Mountpoint Pods are no longer retried.
+	MaxRetries int
+}
+
+// DefaultRetryPolicy is the [RetryPolicy] used unless [Reconciler.SetRetryPolicy] is called.
+var DefaultRetryPolicy = RetryPolicy{
+	Backoff:    10 * time.Second,
+	MaxRetries: 5,
+}
+
+// SetRetryPolicy sets the policy to retry failed Mountpoint Pods with.
+// It needs to be called before [Reconciler.SetupWithManager].
+func (r *Reconciler) SetRetryPolicy(policy RetryPolicy) {
+	r.retryPolicy = policy
+}
+
+// backoff returns the time to wait before retrying a Mountpoint Pod that failed after `retries` successive retries.
+func (p RetryPolicy) backoff(retries int32) time.Duration {
+	backoff := min(p.Backoff, maxRetryBackoff)
+	for range retries {
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+	return backoff
+}
+
+// handleFailedMountpointPod retries failed `mpPod` by spawning a new Mountpoint Pod after a backoff,
+// and moving the workloads of `mpPod` to the new Mountpoint Pod in MountpointS3PodAttachments.
+// The number of successive retries is taken from the status of `mpPod` in MountpointS3PodAttachments,
+// which is reset once it mounts the volume, or from its [mppod.AnnotationRetryCount] annotation if the status is missing.
+// Once the retry limit is reached, it's no longer retried and its status is marked with [crdv2.ReasonRetryLimitExceeded].
+// Failed Mountpoint Pods without any workloads are deleted right away.
+func (r *Reconciler) handleFailedMountpointPod(ctx context.Context, mpPod *corev1.Pod, log logr.Logger) (reconcile.Result, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	err := r.List(ctx, s3paList, client.MatchingFields{
+		crdv2.FieldNodeName:             mpPod.Spec.NodeName,
+		crdv2.FieldPersistentVolumeName: mpPod.Annotations[mppod.AnnotationVolumeName],
+	})
+	if err != nil {
+		log.Error(err, "Failed to list MountpointS3PodAttachments")
+		return reconcile.Result{}, err
+	}
+
+	var s3pas []*crdv2.MountpointS3PodAttachment
+	retries := mppod.RetryCountOf(mpPod)
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if _, ok := s3pa.Spec.MountpointS3PodAttachments[mpPod.Name]; !ok {
+			continue
+		}
+		s3pas = append(s3pas, s3pa)
+		if status, ok := s3pa.Status.MountpointS3PodStatuses[mpPod.Name]; ok {
+			retries = status.RetryCount
+		}
+	}
+
+	if len(s3pas) == 0 {
+		log.Info("Failed Mountpoint Pod has no workloads - deleting")
+		return reconcile.Result{}, r.deleteMountpointPod(ctx, mpPod)
+	}
+
+	failureMessage := mountpointPodFailureMessage(mpPod)
+	if int(retries) >= r.retryPolicy.MaxRetries {
+		log.Info("Failed Mountpoint Pod reached the retry limit - not retrying", "retries", retries)
+		for _, s3pa := range s3pas {
+			r.setMountpointPodPhase(ctx, s3pa, mpPod, crdv2.MountpointS3PodFailed, crdv2.ReasonRetryLimitExceeded,
+				fmt.Sprintf("%s, not retrying after %d retries", failureMessage, retries), log)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	for _, s3pa := range s3pas {
+		r.setMountpointPodPhase(ctx, s3pa, mpPod, crdv2.MountpointS3PodFailed, crdv2.ReasonMountpointPodFailed, failureMessage, log)
+	}
+
+	if wait := r.retryPolicy.backoff(retries) - time.Since(mountpointPodFailedAt(mpPod)); wait > 0 {
+		log.Info("Waiting before retrying failed Mountpoint Pod", "retries", retries, "after", wait)
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	pv, err := r.getVolumeOfMountpointPod(ctx, mpPod, s3pas)
+	if err != nil {
+		log.Error(err, "Failed to get volume of failed Mountpoint Pod")
+		return reconcile.Result{}, err
+	}
+
+	retryPod, err := r.mountpointPodCreator.RetryMountpointPod(mpPod, pv, retries+1)
+	if err != nil {
+		log.Error(err, "Failed to create Mountpoint Pod Spec to retry failed Mountpoint Pod")
+		return reconcile.Result{}, err
+	}
+	// The retry Mountpoint Pod has a deterministic name, it already exists if a previous attempt failed to move
+	// all workloads to it. In that case, the remaining workloads are moved to the existing retry Mountpoint Pod.
+	created := true
+	err = r.Create(ctx, retryPod)
+	if apierrors.IsAlreadyExists(err) {
+		created = false
+		err = r.Get(ctx, client.ObjectKeyFromObject(retryPod), retryPod)
+		if err == nil && retryPod.DeletionTimestamp != nil {
+			log.Info("Retry Mountpoint Pod of a previous attempt is being deleted - requeue", "retryMountpointPod", retryPod.Name)
+			return reconcile.Result{RequeueAfter: retryPodDeletionRequeueInterval}, nil
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to spawn Mountpoint Pod to retry failed Mountpoint Pod")
+		return reconcile.Result{}, err
+	}
+	log = log.WithValues("retryMountpointPod", retryPod.Name)
+
+	for i, s3pa := range s3pas {
+		attachments := s3pa.Spec.MountpointS3PodAttachments[mpPod.Name]
+		s3pa.Spec.MountpointS3PodAttachments[retryPod.Name] = append(s3pa.Spec.MountpointS3PodAttachments[retryPod.Name], attachments...)
+		delete(s3pa.Spec.MountpointS3PodAttachments, mpPod.Name)
+		err = r.updateS3PodAttachment(ctx, s3pa)
+		if err != nil {
+			if i == 0 && created {
+				// None of the workloads are moved yet, clean up spawned Mountpoint Pod, we'll retry with a new one
+				if deleteErr := r.deleteMountpointPod(ctx, retryPod); deleteErr != nil {
+					log.Error(deleteErr, "Failed to cleanup Mountpoint Pod after MountpointS3PodAttachment update failure")
+				}
+			}
+
+			if apierrors.IsConflict(err) {
+				log.Info("Failed to move workloads to retry Mountpoint Pod - resource conflict - requeue")
+				return reconcile.Result{Requeue: true}, nil
+			}
+			log.Error(err, "Failed to move workloads to retry Mountpoint Pod")
+			return reconcile.Result{}, err
+		}
+		log.Info("Moved workloads to retry Mountpoint Pod", "s3pa", s3pa.Name, "workloads", len(attachments))
+
+		r.updateS3PodAttachmentStatus(ctx, s3pa, log, func(s3pa *crdv2.MountpointS3PodAttachment) {
+			s3pa.RemoveMountpointS3PodStatus(mpPod.Name)
+			s3pa.SetMountpointS3PodPhase(retryPod.Name, crdv2.MountpointS3PodPending, retryPod.Labels[mppod.LabelMountpointVersion],
+				crdv2.ReasonMountpointPodScheduled, fmt.Sprintf("Retrying failed Mountpoint Pod %s", mpPod.Name))
+			s3pa.SetMountpointS3PodRetryCount(retryPod.Name, retries+1)
+		})
+	}
+
+	mountpointPodRetries.Inc()
+	log.Info("Retrying failed Mountpoint Pod", "retries", retries+1)
+
+	return reconcile.Result{}, r.deleteMountpointPod(ctx, mpPod)
+}
+
+// getVolumeOfMountpointPod returns the PersistentVolume `mpPod` serves. For CSI ephemeral inline volumes,
+// the PersistentVolume is synthesized from the inline volume of a workload attached to `mpPod` in `s3pas`.
+func (r *Reconciler) getVolumeOfMountpointPod(ctx context.Context, mpPod *corev1.Pod, s3pas []*crdv2.MountpointS3PodAttachment) (*corev1.PersistentVolume, error) {
+	pvName := mpPod.Annotations[mppod.AnnotationVolumeName]
+	if !strings.HasPrefix(pvName, inlineVolumeNamePrefix) {
+		pv := &corev1.PersistentVolume{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+			return nil, err
+		}
+		return pv, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		return nil, err
+	}
+	workloadPods := make(map[string]*corev1.Pod)
+	for i := range podList.Items {
+		workloadPods[string(podList.Items[i].UID)] = &podList.Items[i]
+	}
+
+	for _, s3pa := range s3pas {
+		for _, attachment := range s3pa.Spec.MountpointS3PodAttachments[mpPod.Name] {
+			workloadPod, ok := workloadPods[attachment.WorkloadPodUID]
+			if !ok {
+				continue
+			}
+			for _, vol := range workloadPod.Spec.Volumes {
+				if vol.Name != attachment.WorkloadVolumeName {
+					continue
+				}
+				if inlineVol := inlineWorkloadVolume(vol); inlineVol != nil && inlineVol.pv.Name == pvName {
+					return inlineVol.pv, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("none of the workloads of Mountpoint Pod %s uses inline volume %s", mpPod.Name, pvName)
+}
+
+// mountpointPodFailedAt returns the approximate time `mpPod` failed,
+// that's the latest transition time of its conditions or its containers' termination.
+func mountpointPodFailedAt(mpPod *corev1.Pod) time.Time {
+	failedAt := mpPod.CreationTimestamp.Time
+	for _, condition := range mpPod.Status.Conditions {
+		if condition.LastTransitionTime.After(failedAt) {
+			failedAt = condition.LastTransitionTime.Time
+		}
+	}
+	for _, status := range mpPod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(failedAt) {
+			failedAt = terminated.FinishedAt.Time
+		}
+	}
+	return failedAt
+}
@@ -0,0 +1,297 @@
+package csicontroller
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestRetryingFailedMountpointPods(t *testing.T) {
+	t.Run("waits for the backoff before retrying a failed Mountpoint Pod", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now())
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+		})
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa)
+
+		result, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+		if result.RequeueAfter <= 0 || result.RequeueAfter > DefaultRetryPolicy.Backoff {
+			t.Fatalf("expected to requeue within %v, got %v", DefaultRetryPolicy.Backoff, result.RequeueAfter)
+		}
+
+		assertMountpointPodCount(t, c, 1)
+		status := getOnlyS3PA(t, c).Status.MountpointS3PodStatuses[failedPod.Name]
+		assert.Equals(t, crdv2.MountpointS3PodFailed, status.Phase)
+		assert.Equals(t, crdv2.ReasonMountpointPodFailed, meta.FindStatusCondition(status.Conditions, crdv2.MountpointS3PodConditionReady).Reason)
+	})
+
+	t.Run("moves workloads of a failed Mountpoint Pod to a new Mountpoint Pod after the backoff", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Minute))
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {
+				{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()},
+				{WorkloadPodUID: "uid-2", AttachmentTime: metav1.Now()},
+			},
+		})
+		s3pa.SetMountpointS3PodPhase(failedPod.Name, crdv2.MountpointS3PodFailed, "", crdv2.ReasonMountpointPodFailed, "Mountpoint Pod failed")
+		s3pa.SetMountpointS3PodRetryCount(failedPod.Name, 1)
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa, newTestPV())
+		retriesBefore := promtestutil.ToFloat64(mountpointPodRetries)
+
+		result, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{}, result)
+
+		assertPodDeleted(t, c, failedPod.Name)
+		assertMountpointPodCount(t, c, 1)
+		assert.Equals(t, retriesBefore+1, promtestutil.ToFloat64(mountpointPodRetries))
+
+		got := getOnlyS3PA(t, c)
+		assert.Equals(t, 1, len(got.Spec.MountpointS3PodAttachments))
+		for retryPodName, attachments := range got.Spec.MountpointS3PodAttachments {
+			assert.Equals(t, 2, len(attachments))
+
+			retryPod := getPod(t, c, retryPodName)
+			assert.Equals(t, testPVName, retryPod.Annotations[mppod.AnnotationVolumeName])
+			assert.Equals(t, "2", retryPod.Annotations[mppod.AnnotationRetryCount])
+			assert.Equals(t, "", retryPod.Spec.NodeName)
+			assert.Equals(t, []string{testNodeName}, retryPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values)
+			assert.Equals(t, testPodConfig().Container.Image, retryPod.Spec.Containers[0].Image)
+
+			status := got.Status.MountpointS3PodStatuses[retryPodName]
+			assert.Equals(t, crdv2.MountpointS3PodPending, status.Phase)
+			assert.Equals(t, int32(2), status.RetryCount)
+		}
+		if _, ok := got.Status.MountpointS3PodStatuses[failedPod.Name]; ok {
+			t.Fatalf("expected status of failed Mountpoint Pod to be removed")
+		}
+		assert.Equals(t, "0/1", got.Status.Ready)
+	})
+
+	t.Run("stops retrying after the retry limit", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Hour))
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+		})
+		s3pa.SetMountpointS3PodRetryCount(failedPod.Name, int32(DefaultRetryPolicy.MaxRetries))
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa)
+
+		result, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{}, result)
+
+		assertMountpointPodCount(t, c, 1)
+		got := getOnlyS3PA(t, c)
+		assert.Equals(t, 1, len(got.Spec.MountpointS3PodAttachments[failedPod.Name]))
+		status := got.Status.MountpointS3PodStatuses[failedPod.Name]
+		assert.Equals(t, crdv2.MountpointS3PodFailed, status.Phase)
+		assert.Equals(t, crdv2.ReasonRetryLimitExceeded, meta.FindStatusCondition(status.Conditions, crdv2.MountpointS3PodConditionReady).Reason)
+	})
+
+	t.Run("takes the retry count from the annotation if the status is missing", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Hour))
+		failedPod.Annotations[mppod.AnnotationRetryCount] = strconv.Itoa(DefaultRetryPolicy.MaxRetries)
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+		})
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa, newTestPV())
+
+		_, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+
+		assertMountpointPodCount(t, c, 1)
+		status := getOnlyS3PA(t, c).Status.MountpointS3PodStatuses[failedPod.Name]
+		assert.Equals(t, crdv2.ReasonRetryLimitExceeded, meta.FindStatusCondition(status.Conditions, crdv2.MountpointS3PodConditionReady).Reason)
+	})
+
+	t.Run("takes the retry count from the status once it's reset", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Hour))
+		failedPod.Annotations[mppod.AnnotationRetryCount] = strconv.Itoa(DefaultRetryPolicy.MaxRetries)
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+		})
+		s3pa.SetMountpointS3PodPhase(failedPod.Name, crdv2.MountpointS3PodMounted, "", crdv2.ReasonMounted, "")
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa, newTestPV())
+
+		_, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+
+		assertPodDeleted(t, c, failedPod.Name)
+		for retryPodName := range getOnlyS3PA(t, c).Spec.MountpointS3PodAttachments {
+			assert.Equals(t, "1", getPod(t, c, retryPodName).Annotations[mppod.AnnotationRetryCount])
+		}
+	})
+
+	t.Run("retries a failed Mountpoint Pod of an inline volume", func(t *testing.T) {
+		volumeAttributes := map[string]string{"bucketName": "test-bucket"}
+		inlineVolumeName := inlineVolumeNameFor(volumeAttributes, false, "")
+		workloadPod := newInlineVolumeWorkloadPod("workload", volumeAttributes)
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Minute))
+		failedPod.Annotations[mppod.AnnotationVolumeName] = inlineVolumeName
+		failedPod.Annotations[mppod.AnnotationVolumeId] = inlineVolumeName
+		s3pa := newS3PA("s3pa", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: string(workloadPod.UID), WorkloadVolumeName: "s3-data", AttachmentTime: metav1.Now()}},
+		})
+		s3pa.Spec.PersistentVolumeName = inlineVolumeName
+		c, r := newRetryReconcilerWithObjects(t, failedPod, s3pa, workloadPod)
+
+		_, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+
+		assertPodDeleted(t, c, failedPod.Name)
+		for retryPodName := range getOnlyS3PA(t, c).Spec.MountpointS3PodAttachments {
+			retryPod := getPod(t, c, retryPodName)
+			assert.Equals(t, inlineVolumeName, retryPod.Annotations[mppod.AnnotationVolumeName])
+			assert.Equals(t, "1", retryPod.Annotations[mppod.AnnotationRetryCount])
+		}
+	})
+
+	t.Run("reuses the retry Mountpoint Pod if moving workloads is interrupted", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now().Add(-time.Minute))
+		s3paA := newS3PA("s3pa-a", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-1", AttachmentTime: metav1.Now()}},
+		})
+		s3paB := newS3PA("s3pa-b", map[string][]crdv2.WorkloadAttachment{
+			failedPod.Name: {{WorkloadPodUID: "uid-2", AttachmentTime: metav1.Now()}},
+		})
+		conflicts := 0
+		c, r := newRetryReconcilerWithInterceptor(t, interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if obj.GetName() == s3paB.Name && conflicts == 0 {
+					conflicts++
+					return apierrors.NewConflict(schema.GroupResource{Resource: "mountpoints3podattachments"}, obj.GetName(), nil)
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}, failedPod, s3paA, s3paB, newTestPV())
+
+		result, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{Requeue: true}, result)
+		assertMountpointPodCount(t, c, 2)
+
+		result, err = r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{}, result)
+
+		assertPodDeleted(t, c, failedPod.Name)
+		assertMountpointPodCount(t, c, 1)
+
+		retryPodName := "mp-" + string(failedPod.UID)
+		getPod(t, c, retryPodName)
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(context.Background(), s3paList))
+		assert.Equals(t, 2, len(s3paList.Items))
+		for _, s3pa := range s3paList.Items {
+			assert.Equals(t, 1, len(s3pa.Spec.MountpointS3PodAttachments))
+			assert.Equals(t, 1, len(s3pa.Spec.MountpointS3PodAttachments[retryPodName]))
+			assert.Equals(t, crdv2.MountpointS3PodPending, s3pa.Status.MountpointS3PodStatuses[retryPodName].Phase)
+		}
+	})
+
+	t.Run("deletes a failed Mountpoint Pod without workloads", func(t *testing.T) {
+		failedPod := newFailedMountpointPod("mp-failed", time.Now())
+		c, r := newRetryReconcilerWithObjects(t, failedPod)
+
+		_, err := r.reconcileMountpointPod(context.Background(), failedPod)
+		assert.NoError(t, err)
+
+		assertPodDeleted(t, c, failedPod.Name)
+	})
+
+	t.Run("does not assign new workloads to a failed Mountpoint Pod", func(t *testing.T) {
+		_, r := newRetryReconcilerWithObjects(t)
+
+		assert.Equals(t, false, r.shouldAssignNewWorkloadToMountpointPod(newFailedMountpointPod("mp-failed", time.Now()), logr.Discard()))
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 10 * time.Second, MaxRetries: 10}
+
+	assert.Equals(t, 10*time.Second, policy.backoff(0))
+	assert.Equals(t, 20*time.Second, policy.backoff(1))
+	assert.Equals(t, 80*time.Second, policy.backoff(3))
+	assert.Equals(t, maxRetryBackoff, policy.backoff(5))
+	assert.Equals(t, maxRetryBackoff, policy.backoff(100))
+	assert.Equals(t, maxRetryBackoff, RetryPolicy{Backoff: time.Hour}.backoff(0))
+}
+
+func newRetryReconcilerWithObjects(t *testing.T, objs ...client.Object) (client.Client, *Reconciler) {
+	t.Helper()
+	return newRetryReconcilerWithInterceptor(t, interceptor.Funcs{}, objs...)
+}
+
+func newRetryReconcilerWithInterceptor(t *testing.T, funcs interceptor.Funcs, objs ...client.Object) (client.Client, *Reconciler) {
+	t.Helper()
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithObjects(objs...).
+		WithStatusSubresource(&crdv2.MountpointS3PodAttachment{}).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldNodeName, func(obj client.Object) []string {
+			return []string{obj.(*crdv2.MountpointS3PodAttachment).Spec.NodeName}
+		}).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldPersistentVolumeName, func(obj client.Object) []string {
+			return []string{obj.(*crdv2.MountpointS3PodAttachment).Spec.PersistentVolumeName}
+		}).
+		WithInterceptorFuncs(funcs).
+		Build()
+
+	config := testPodConfig()
+	config.CSIDriverVersion = testCSIDriverVersion
+	return c, &Reconciler{
+		Client:               c,
+		mountpointPodConfig:  config,
+		mountpointPodCreator: mppod.NewCreator(config, logr.Discard()),
+		s3paExpectations:     newExpectations(),
+		retryPolicy:          DefaultRetryPolicy,
+	}
+}
+
+func newFailedMountpointPod(name string, failedAt time.Time) *corev1.Pod {
+	mpPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testPodConfig().Namespace,
+			UID:       types.UID(name + "-uid"),
+			Labels: map[string]string{
+				mppod.LabelCSIDriverVersion: testCSIDriverVersion,
+			},
+			Annotations: map[string]string{
+				mppod.AnnotationVolumeName: testPVName,
+				mppod.AnnotationVolumeId:   testVolumeID,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: testNodeName,
+		},
+	}
+	mpPod.CreationTimestamp = metav1.NewTime(failedAt.Add(-time.Minute))
+	mpPod.Status = corev1.PodStatus{
+		Phase:   corev1.PodFailed,
+		Message: "Pod was evicted",
+		Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(failedAt)},
+		},
+	}
+	return mpPod
+}
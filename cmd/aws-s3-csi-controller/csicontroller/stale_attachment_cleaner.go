@@ -96,6 +96,7 @@ func (cm *StaleAttachmentCleaner) RunCleanup(ctx context.Context) error {
 func (cm *StaleAttachmentCleaner) cleanupStaleWorkloads(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, existingPods map[string]*corev1.Pod) error {
 	log := logf.FromContext(ctx).WithValues("s3pa", s3pa.Name)
 	modified := false
+	removed := 0
 
 	now := time.Now().UTC()
 
@@ -112,6 +113,7 @@ func (cm *StaleAttachmentCleaner) cleanupStaleWorkloads(ctx context.Context, s3p
 				validAttachments = append(validAttachments, attachment)
 			} else {
 				modified = true
+				removed++
 				log.Info("Removing stale workload reference",
 					"workloadUID", attachment.WorkloadPodUID,
 					"mountpointPod", mpPodName,
@@ -131,10 +133,16 @@ func (cm *StaleAttachmentCleaner) cleanupStaleWorkloads(ctx context.Context, s3p
 
 	// Update the S3PodAttachment if modified
 	if modified {
+		var err error
 		if len(s3pa.Spec.MountpointS3PodAttachments) == 0 {
-			return cm.reconciler.deleteS3PodAttachment(ctx, s3pa)
+			err = cm.reconciler.deleteS3PodAttachment(ctx, s3pa)
+		} else {
+			err = cm.reconciler.updateS3PodAttachment(ctx, s3pa)
+		}
+		if err != nil {
+			return err
 		}
-		return cm.reconciler.Update(ctx, s3pa)
+		staleWorkloadsRemoved.Add(float64(removed))
 	}
 
 	return nil
@@ -172,6 +180,7 @@ func (cm *StaleAttachmentCleaner) cleanupStaleHeadroomPods(ctx context.Context,
 					"workloadPodUID", workloadPodUID)
 				continue
 			}
+			headroomPodOperations.WithLabelValues(operationDelete).Inc()
 		}
 	}
 
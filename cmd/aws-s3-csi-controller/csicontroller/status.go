@@ -0,0 +1,95 @@
+package csicontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// mountpointPodPendingMessage is the message of the `Ready` condition of newly spawned Mountpoint Pods.
+const mountpointPodPendingMessage = "Mountpoint Pod is spawned, waiting for the CSI Driver Node to mount the volume"
+
+// setMountpointPodPhase records `phase` of `mpPod` in the status of `s3pa`.
+//
+// Status updates are best-effort, a failure is only logged as the status is purely informational
+// and it'll be corrected with the next status update for the same Mountpoint Pod.
+func (r *Reconciler) setMountpointPodPhase(
+	ctx context.Context,
+	s3pa *crdv2.MountpointS3PodAttachment,
+	mpPod *corev1.Pod,
+	phase crdv2.MountpointS3PodPhase,
+	reason, message string,
+	log logr.Logger,
+) {
+	r.updateS3PodAttachmentStatus(ctx, s3pa, log, func(s3pa *crdv2.MountpointS3PodAttachment) {
+		s3pa.SetMountpointS3PodPhase(mpPod.Name, phase, mpPod.Labels[mppod.LabelMountpointVersion], reason, message)
+	})
+}
+
+// removeMountpointPodStatus removes the status of `mpPodName` from `s3pa` after it's removed from the spec.
+func (r *Reconciler) removeMountpointPodStatus(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, mpPodName string, log logr.Logger) {
+	r.updateS3PodAttachmentStatus(ctx, s3pa, log, func(s3pa *crdv2.MountpointS3PodAttachment) {
+		s3pa.RemoveMountpointS3PodStatus(mpPodName)
+	})
+}
+
+// setMountpointPodPhaseInS3PodAttachments records `phase` of `mpPod` in the status of all MountpointS3PodAttachments it serves.
+func (r *Reconciler) setMountpointPodPhaseInS3PodAttachments(
+	ctx context.Context,
+	mpPod *corev1.Pod,
+	phase crdv2.MountpointS3PodPhase,
+	reason, message string,
+	log logr.Logger,
+) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	err := r.List(ctx, s3paList, client.MatchingFields{
+		crdv2.FieldNodeName:             mpPod.Spec.NodeName,
+		crdv2.FieldPersistentVolumeName: mpPod.Annotations[mppod.AnnotationVolumeName],
+	})
+	if err != nil {
+		log.Error(err, "Failed to list MountpointS3PodAttachments to update status of Mountpoint Pod")
+		return
+	}
+
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if _, ok := s3pa.Spec.MountpointS3PodAttachments[mpPod.Name]; !ok {
+			continue
+		}
+		r.setMountpointPodPhase(ctx, s3pa, mpPod, phase, reason, message, log)
+	}
+}
+
+// updateS3PodAttachmentStatus updates status of `s3pa` using `mutate`.
+// `s3pa` is only replaced with the updated MountpointS3PodAttachment if the update succeeds,
+// so callers can keep using it for spec updates either way.
+func (r *Reconciler) updateS3PodAttachmentStatus(
+	ctx context.Context,
+	s3pa *crdv2.MountpointS3PodAttachment,
+	log logr.Logger,
+	mutate func(*crdv2.MountpointS3PodAttachment),
+) {
+	updated := s3pa.DeepCopy()
+	if err := crdv2.UpdateStatus(ctx, r.Client, updated, mutate); err != nil {
+		log.Error(err, "Failed to update MountpointS3PodAttachment status", "s3pa", s3pa.Name)
+		return
+	}
+	*s3pa = *updated
+}
+
+// mountpointPodFailureMessage returns a human-readable message describing why `mpPod` failed.
+func mountpointPodFailureMessage(mpPod *corev1.Pod) string {
+	switch {
+	case mpPod.Status.Message != "":
+		return "Mountpoint Pod failed: " + mpPod.Status.Message
+	case mpPod.Status.Reason != "":
+		return "Mountpoint Pod failed: " + mpPod.Status.Reason
+	default:
+		return "Mountpoint Pod failed"
+	}
+}
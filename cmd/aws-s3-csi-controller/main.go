@@ -9,6 +9,8 @@ package main
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,6 +21,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/cmd/aws-s3-csi-controller/csicontroller"
 	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
@@ -39,6 +43,11 @@ var mountpointImagePullPolicy = flag.String("mountpoint-image-pull-policy", os.G
 var mountpointContainerCommand = flag.String("mountpoint-container-command", "/bin/aws-s3-csi-mounter", "Entrypoint command of the Mountpoint Pods.")
 var mountpointPodLabels = flag.String("mountpoint-pod-labels", os.Getenv("MOUNTPOINT_POD_LABELS"), "Pod labels to apply to Mountpoint Pods (JSON format).")
 var mountpointHeadroomPodLabels = flag.String("mountpoint-headroom-pod-labels", os.Getenv("MOUNTPOINT_HEADROOM_POD_LABELS"), "Pod labels to apply to Headroom Pods (JSON format).")
+var metricsBindAddress = flag.String("metrics-bind-address", metricsserver.DefaultBindAddress, "Address to serve Prometheus metrics at, or \"0\" to disable serving metrics.")
+var mountpointPodRetryBackoff = flag.String("mountpoint-pod-retry-backoff", os.Getenv("MOUNTPOINT_POD_RETRY_BACKOFF"), "Time to wait before retrying a failed Mountpoint Pod, doubled with each successive failure (e.g., \"10s\").")
+var mountpointPodMaxRetries = flag.String("mountpoint-pod-max-retries", os.Getenv("MOUNTPOINT_POD_MAX_RETRIES"), "Number of successive retries after which a failed Mountpoint Pod is no longer retried.")
+var webhookPort = flag.String("webhook-port", os.Getenv("WEBHOOK_PORT"), "Port to serve the validating admission webhook for PersistentVolumes at, or empty to disable the webhook.")
+var webhookCertDir = flag.String("webhook-cert-dir", os.Getenv("WEBHOOK_CERT_DIR"), "Directory containing `tls.crt` and `tls.key` to serve the validating admission webhook with.")
 
 var (
 	scheme = runtime.NewScheme()
@@ -57,12 +66,29 @@ func main() {
 	log := logf.Log.WithName(csicontroller.Name)
 	conf := config.GetConfigOrDie()
 
+	var webhookServer webhook.Server
+	if *webhookPort != "" {
+		port, err := strconv.Atoi(*webhookPort)
+		if err != nil || port <= 0 {
+			log.Error(err, "Invalid webhook port", "port", *webhookPort)
+			os.Exit(1)
+		}
+		webhookServer = webhook.NewServer(webhook.Options{
+			Port:    port,
+			CertDir: *webhookCertDir,
+		})
+	}
+
 	mgr, err := manager.New(conf, manager.Options{
 		Scheme:                        scheme,
 		LeaderElection:                true,
 		LeaderElectionID:              "aws-s3-csi-controller",
 		LeaderElectionResourceLock:    "leases",
 		LeaderElectionReleaseOnCancel: true,
+		Metrics: metricsserver.Options{
+			BindAddress: *metricsBindAddress,
+		},
+		WebhookServer: webhookServer,
 	})
 	if err != nil {
 		log.Error(err, "Failed to create a new manager")
@@ -77,7 +103,7 @@ func main() {
 	podLabels := util.ParseLabels(*mountpointPodLabels, log)
 	headroomPodLabels := util.ParseLabels(*mountpointHeadroomPodLabels, log)
 
-	reconciler := csicontroller.NewReconciler(mgr.GetClient(), mppod.Config{
+	podConfig := mppod.Config{
 		Namespace:                   *mountpointNamespace,
 		MountpointVersion:           *mountpointVersion,
 		PriorityClassName:           *mountpointPriorityClassName,
@@ -93,13 +119,38 @@ func main() {
 		ClusterVariant:    cluster.DetectVariant(conf, log),
 		PodLabels:         podLabels,
 		HeadroomPodLabels: headroomPodLabels,
-	}, log)
+	}
+	reconciler := csicontroller.NewReconciler(mgr.GetClient(), podConfig, log)
+
+	retryPolicy := csicontroller.DefaultRetryPolicy
+	if *mountpointPodRetryBackoff != "" {
+		retryPolicy.Backoff, err = time.ParseDuration(*mountpointPodRetryBackoff)
+		if err != nil || retryPolicy.Backoff < 0 {
+			log.Error(err, "Invalid Mountpoint Pod retry backoff", "backoff", *mountpointPodRetryBackoff)
+			os.Exit(1)
+		}
+	}
+	if *mountpointPodMaxRetries != "" {
+		retryPolicy.MaxRetries, err = strconv.Atoi(*mountpointPodMaxRetries)
+		if err != nil || retryPolicy.MaxRetries < 0 {
+			log.Error(err, "Invalid Mountpoint Pod max retries", "maxRetries", *mountpointPodMaxRetries)
+			os.Exit(1)
+		}
+	}
+	reconciler.SetRetryPolicy(retryPolicy)
 
 	if err := reconciler.SetupWithManager(mgr); err != nil {
 		log.Error(err, "Failed to create controller")
 		os.Exit(1)
 	}
 
+	if webhookServer != nil {
+		if err := csicontroller.NewPersistentVolumeValidator(podConfig, log).SetupWebhookWithManager(mgr); err != nil {
+			log.Error(err, "Failed to create PersistentVolume validating webhook")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.Add(csicontroller.NewStaleAttachmentCleaner(reconciler)); err != nil {
 		log.Error(err, "Failed to add stale attachment cleaner to manager")
 		os.Exit(1)
@@ -1,44 +1,100 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net"
 	"regexp"
 	"syscall"
 	"time"
 
+	"golang.org/x/sys/unix"
 	"k8s.io/klog/v2"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/runner"
 )
 
 var validMountId = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
 
-// handleConnection receives mount options from a single connection and spawns a Mountpoint child process.
+// handleConnection receives mount options from a single connection, spawns a Mountpoint child process,
+// and replies the result to the sender once Mountpoint starts serving the filesystem or fails to do so.
+// Waiting for Mountpoint happens in the background, so it doesn't block receiving other mount requests.
 func handleConnection(conn *net.UnixConn, mountpointPath string, pm *ProcessManager, recvTimeout time.Duration) {
-	defer conn.Close()
-
 	var deadline time.Time
 	if recvTimeout > 0 {
 		deadline = time.Now().Add(recvTimeout)
 	}
 
-	options, err := mountoptions.RecvOnConn(conn, deadline)
+	req, err := mountoptions.RecvOnConn(conn, deadline)
 	if err != nil {
+		conn.Close()
 		klog.Errorf("Failed to receive mount options: %v", err)
 		return
 	}
+	options := req.Options
 
 	mountId := options.VolumeId
 	if mountId == "" || !validMountId.MatchString(mountId) {
 		syscall.Close(options.Fd)
 		klog.Errorf("Received mount request with invalid mountId: %q", mountId)
+		reply(req, mountId, mountoptions.NewReplyError(mountoptions.ReplyCodeInvalidOptions, "invalid mount id %q", mountId))
 		return
 	}
 
 	klog.Infof("Received mount request for mount %s, bucket %s", mountId, options.BucketName)
 
-	err = pm.Launch(mountId, mountpointPath, options) // ownership of options.Fd is transferred here
+	// Mountpoint's copy of the FUSE file descriptor is closed once it exits, keep a duplicate to wait until it's ready
+	readyFd, err := unix.Dup(options.Fd)
+	if err != nil {
+		syscall.Close(options.Fd)
+		klog.Errorf("Received mount request for mount %s with invalid FUSE file descriptor: %v", mountId, err)
+		reply(req, mountId, mountoptions.NewReplyError(mountoptions.ReplyCodeInvalidOptions, "invalid FUSE file descriptor %d: %v", options.Fd, err))
+		return
+	}
+
+	exited, err := pm.Launch(mountId, mountpointPath, options) // ownership of options.Fd is transferred here
 	if err != nil {
+		unix.Close(readyFd)
 		klog.Errorf("Failed to launch Mountpoint for mount %s: %v", mountId, err)
+		reply(req, mountId, err)
+		return
+	}
+
+	go func() {
+		defer unix.Close(readyFd)
+		reply(req, mountId, waitForMountpoint(readyFd, exited))
+	}()
+}
+
+// waitForMountpoint waits until Mountpoint reads the FUSE INIT request from FUSE file descriptor `fd`,
+// or returns an error if Mountpoint exits before that.
+func waitForMountpoint(fd int, exited <-chan error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan error, 1)
+	go func() {
+		ready <- runner.WaitForFUSEInit(ctx, fd, runner.DefaultFUSEInitTimeout)
+	}()
+
+	select {
+	case err := <-ready:
+		return err
+	case err := <-exited:
+		// Ensure `fd` is no longer used once this function returns
+		cancel()
+		<-ready
+		if err == nil {
+			err = errors.New("Mountpoint exited before it started serving the filesystem")
+		}
+		return err
+	}
+}
+
+// reply replies `err` to the sender of `req`, and logs if it fails.
+func reply(req *mountoptions.Request, mountId string, err error) {
+	if replyErr := req.Reply(err); replyErr != nil {
+		klog.Errorf("Failed to reply to mount request for mount %q: %v", mountId, replyErr)
 	}
 }
@@ -8,12 +8,14 @@
 // # Protocol
 //
 // Communication happens over a single Unix domain socket (mount.sock) in the shared comm directory.
-// Each mount request is a separate connection to this socket:
+// The mounter advertises the mount options protocol version it implements in mount.sock.version,
+// the driver uses the legacy framing if the file is missing. Each mount request is a separate connection to this socket:
 //
 //  1. The driver connects and sends a JSON-encoded [mountoptions.Options] message along with
 //     the FUSE file descriptor via SCM_RIGHTS (Unix domain socket ancillary data).
 //  2. The mounter receives the options, spawns a Mountpoint child process with the FUSE fd,
-//     and closes the connection.
+//     and replies once Mountpoint reads the FUSE INIT request, or with Mountpoint's error if it
+//     exits before that. Legacy requests (bare JSON without a reply) are accepted without replying.
 //  3. If the Mountpoint process exits with a non-zero code, its stderr is written to
 //     <comm-dir>/<mount-id>.error. Nothing is written on clean (zero) exit.
 //     The driver is responsible for removing this file during Unmount.
@@ -37,6 +39,8 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
 )
 
 var (
@@ -61,7 +65,7 @@ func main() {
 	// Remove stale socket file if it exists
 	os.Remove(sockPath)
 
-	listener, err := net.Listen("unix", sockPath)
+	listener, err := mountoptions.Listen(sockPath)
 	if err != nil {
 		klog.Fatalf("Failed to listen on %s: %v", sockPath, err)
 	}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -42,11 +43,12 @@ func NewProcessManager(commDir string, runner ProcessRunner) *ProcessManager {
 
 // Launch spawns a Mountpoint process for the given mount and waits for it asynchronously.
 // Takes ownership of options.Fd, caller must not close it after calling this function.
-// Returns an error if a process with the same mountId is already running.
-func (pm *ProcessManager) Launch(mountId string, mountpointPath string, options mountoptions.Options) error {
+// Returns an error if a process with the same mountId is already running. Otherwise, returns a channel
+// that receives the error of the process (nil on clean exit) once it exits.
+func (pm *ProcessManager) Launch(mountId string, mountpointPath string, options mountoptions.Options) (<-chan error, error) {
 	fuseDev := os.NewFile(uintptr(options.Fd), "/dev/fuse")
 	if fuseDev == nil {
-		return fmt.Errorf("invalid FUSE file descriptor %d", options.Fd)
+		return nil, fmt.Errorf("invalid FUSE file descriptor %d", options.Fd)
 	}
 
 	args := mountpoint.ParseArgs(options.Args)
@@ -73,14 +75,14 @@ func (pm *ProcessManager) Launch(mountId string, mountpointPath string, options
 	if _, exists := pm.processes[mountId]; exists {
 		pm.mu.Unlock()
 		fuseDev.Close()
-		return fmt.Errorf("mount %s already has a running process", mountId)
+		return nil, fmt.Errorf("mount %s already has a running process", mountId)
 	}
 
 	handle, err := pm.runner.Start(cmd)
 	if err != nil {
 		pm.mu.Unlock()
 		fuseDev.Close()
-		return fmt.Errorf("failed to start Mountpoint: %w", err)
+		return nil, fmt.Errorf("failed to start Mountpoint: %w", err)
 	}
 
 	// Child has its own copy of the FD (kernel dup'd it during fork/exec).
@@ -91,6 +93,7 @@ func (pm *ProcessManager) Launch(mountId string, mountpointPath string, options
 
 	klog.Infof("Launched Mountpoint for mount %s (pid %d)", mountId, handle.Pid())
 
+	exited := make(chan error, 1)
 	pm.wg.Add(1)
 	go func() {
 		defer pm.wg.Done()
@@ -106,12 +109,14 @@ func (pm *ProcessManager) Launch(mountId string, mountpointPath string, options
 				klog.Errorf("Failed to write error file for mount %s: %v", mountId, writeErr)
 			}
 			klog.Errorf("Mountpoint for mount %s exited with code %d", mountId, exitCode)
+			exited <- fmt.Errorf("Mountpoint failed: %s", strings.TrimSpace(string(stderr)))
 		} else {
 			klog.Infof("Mountpoint for mount %s exited cleanly", mountId)
+			exited <- nil
 		}
 	}()
 
-	return nil
+	return exited, nil
 }
 
 // Shutdown sends SIGTERM to all processes and waits for them to exit.
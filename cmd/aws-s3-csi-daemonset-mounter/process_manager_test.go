@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -121,13 +122,102 @@ func TestHandleConnection_PropagatesOptionsToRunner(t *testing.T) {
 	pm.Shutdown()
 }
 
+func TestHandleConnection_RepliesOnceMountpointIsReady(t *testing.T) {
+	commDir := t.TempDir()
+	fr := &fakeProcessRunner{}
+	pm := NewProcessManager(commDir, fr)
+
+	sockPath := filepath.Join(commDir, "test.sock")
+	listener, err := net.Listen("unix", sockPath)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	dev, devWriter := openFUSEDevice(t)
+	_, err = devWriter.Write([]byte("INIT"))
+	assert.NoError(t, err)
+
+	sendErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		sendErr <- mountoptions.Send(ctx, sockPath, mountoptions.Options{
+			Fd:         int(dev.Fd()),
+			BucketName: "bucket",
+			VolumeId:   "vol-1",
+		})
+	}()
+
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	handleConnection(conn.(*net.UnixConn), "/opt/mount-s3", pm, 5*time.Second)
+
+	select {
+	case err := <-sendErr:
+		t.Fatalf("expected no reply before Mountpoint reads the FUSE INIT request, got: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Emulate Mountpoint reading the FUSE INIT request
+	_, err = dev.Read(make([]byte, 4))
+	assert.NoError(t, err)
+	assert.NoError(t, <-sendErr)
+
+	fr.handles[0].Exit(0, "")
+	pm.Shutdown()
+}
+
+func TestHandleConnection_RepliesMountpointError(t *testing.T) {
+	commDir := t.TempDir()
+	fr := &fakeProcessRunner{}
+	pm := NewProcessManager(commDir, fr)
+
+	sockPath := filepath.Join(commDir, "test.sock")
+	listener, err := net.Listen("unix", sockPath)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	dev, devWriter := openFUSEDevice(t)
+	_, err = devWriter.Write([]byte("INIT"))
+	assert.NoError(t, err)
+
+	sendErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := mountoptions.Send(ctx, sockPath, mountoptions.Options{
+			Fd:         int(dev.Fd()),
+			BucketName: "bucket",
+			VolumeId:   "vol-1",
+		})
+		sendErr <- err
+	}()
+
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	handleConnection(conn.(*net.UnixConn), "/opt/mount-s3", pm, 5*time.Second)
+
+	fr.mu.Lock()
+	handle := fr.handles[0]
+	fr.mu.Unlock()
+	handle.Exit(1, "Failed to create S3 client\n")
+
+	err = <-sendErr
+	var replyErr *mountoptions.ReplyError
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("expected a reply error, got: %v", err)
+	}
+	assert.Equals(t, mountoptions.ReplyError{Code: mountoptions.ReplyCodeFailed, Message: "Mountpoint failed: Failed to create S3 client"}, *replyErr)
+
+	pm.Shutdown()
+}
+
 func TestProcessManager_Launch_HappyPath(t *testing.T) {
 	commDir := t.TempDir()
 	fr := &fakeProcessRunner{}
 	pm := NewProcessManager(commDir, fr)
 	dev := mountertest.OpenDevNull(t)
 
-	err := pm.Launch("mount-123", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err := pm.Launch("mount-123", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev.Fd()),
 		BucketName: "my-bucket",
 		Env:        []string{"AWS_REGION=us-east-1"},
@@ -166,7 +256,7 @@ func TestProcessManager_Launch_MultipleProcesses(t *testing.T) {
 
 	for i, id := range []string{"mount-a", "mount-b", "mount-c"} {
 		dev := mountertest.OpenDevNull(t)
-		err := pm.Launch(id, "/usr/bin/mount-s3", mountoptions.Options{
+		_, err := pm.Launch(id, "/usr/bin/mount-s3", mountoptions.Options{
 			Fd:         int(dev.Fd()),
 			BucketName: fmt.Sprintf("bucket-%d", i),
 		})
@@ -220,7 +310,7 @@ func TestProcessManager_Launch_DuplicateMountId_Rejected(t *testing.T) {
 	pm := NewProcessManager(commDir, fr)
 
 	dev1 := mountertest.OpenDevNull(t)
-	err := pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err := pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev1.Fd()),
 		BucketName: "bucket",
 	})
@@ -228,7 +318,7 @@ func TestProcessManager_Launch_DuplicateMountId_Rejected(t *testing.T) {
 
 	// Second launch with same mountId should fail
 	dev2 := mountertest.OpenDevNull(t)
-	err = pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err = pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev2.Fd()),
 		BucketName: "bucket",
 	})
@@ -246,7 +336,7 @@ func TestProcessManager_Launch_DuplicateMountId_Rejected(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 
 	dev3 := mountertest.OpenDevNull(t)
-	err = pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err = pm.Launch("same-mount", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev3.Fd()),
 		BucketName: "bucket",
 	})
@@ -262,7 +352,7 @@ func TestProcessManager_Shutdown_SendsSIGTERM(t *testing.T) {
 	pm := NewProcessManager(commDir, fr)
 
 	dev := mountertest.OpenDevNull(t)
-	err := pm.Launch("m1", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err := pm.Launch("m1", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev.Fd()),
 		BucketName: "b",
 	})
@@ -300,7 +390,7 @@ func TestHandleConnection_NoFdLeak(t *testing.T) {
 
 	const iterations = 5
 	for i := range iterations {
-		dev := mountertest.OpenDevNull(t)
+		dev, devWriter := openFUSEDevice(t)
 		sendDone := make(chan struct{})
 		volumeId := fmt.Sprintf("vol-%d", i)
 		if i == iterations-1 {
@@ -315,6 +405,7 @@ func TestHandleConnection_NoFdLeak(t *testing.T) {
 				VolumeId:   volumeId,
 			})
 			dev.Close()
+			devWriter.Close()
 			close(sendDone)
 		}()
 
@@ -336,6 +427,19 @@ func TestHandleConnection_NoFdLeak(t *testing.T) {
 	}
 }
 
+// openFUSEDevice emulates a FUSE device with a pipe, Mountpoint is considered to be serving the filesystem
+// until something (i.e., the FUSE INIT request) is written to the returned writer.
+func openFUSEDevice(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+	dev, devWriter, err := os.Pipe()
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		dev.Close()
+		devWriter.Close()
+	})
+	return dev, devWriter
+}
+
 func countOpenFds(t *testing.T) int {
 	t.Helper()
 	entries, err := os.ReadDir("/proc/self/fd")
@@ -369,30 +473,33 @@ func TestHandleConnection_MountIdValidation(t *testing.T) {
 	assert.NoError(t, err)
 	defer listener.Close()
 
-	dev := mountertest.OpenDevNull(t)
-	defer dev.Close()
+	dev, _ := openFUSEDevice(t)
 
-	sendMount := func(id string) {
-		sendDone := make(chan struct{})
+	sendMount := func(id string) error {
+		sendErr := make(chan error)
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			mountoptions.Send(ctx, sockPath, mountoptions.Options{
+			err := mountoptions.Send(ctx, sockPath, mountoptions.Options{
 				Fd:         int(dev.Fd()),
 				BucketName: "bucket",
 				VolumeId:   id,
 			})
-			close(sendDone)
+			sendErr <- err
 		}()
 
 		conn, err := listener.Accept()
 		assert.NoError(t, err)
 		handleConnection(conn.(*net.UnixConn), "/opt/mount-s3", pm, 5*time.Second)
-		<-sendDone
+		return <-sendErr
 	}
 
 	for _, id := range invalidIds {
-		sendMount(id)
+		err := sendMount(id)
+		var replyErr *mountoptions.ReplyError
+		if !errors.As(err, &replyErr) || replyErr.Code != mountoptions.ReplyCodeInvalidOptions {
+			t.Errorf("expected mount id %q to be rejected with %q, got: %v", id, mountoptions.ReplyCodeInvalidOptions, err)
+		}
 	}
 
 	fr.mu.Lock()
@@ -400,7 +507,7 @@ func TestHandleConnection_MountIdValidation(t *testing.T) {
 	fr.mu.Unlock()
 
 	for _, id := range validIds {
-		sendMount(id)
+		assert.NoError(t, sendMount(id))
 	}
 
 	fr.mu.Lock()
@@ -419,7 +526,7 @@ func TestProcessManager_Launch_ErrorExit_WritesErrorFile(t *testing.T) {
 	pm := NewProcessManager(commDir, fr)
 
 	dev := mountertest.OpenDevNull(t)
-	err := pm.Launch("mount-abc", "/usr/bin/mount-s3", mountoptions.Options{
+	_, err := pm.Launch("mount-abc", "/usr/bin/mount-s3", mountoptions.Options{
 		Fd:         int(dev.Fd()),
 		BucketName: "bucket",
 	})
@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/version"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 	utillog "github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/log"
 	"k8s.io/klog/v2"
 )
@@ -33,6 +36,9 @@ var unknownVersion = "UNKNOWN"
 
 const (
 	NodeIDEnvVar = "CSI_NODE_NAME"
+
+	modeNode       = "node"
+	modeController = "controller"
 )
 
 func main() {
@@ -41,7 +47,23 @@ func main() {
 		printVersion = flag.Bool("version", false, "Print the version and exit")
 		mpVersion    = flag.String("mp-version", os.Getenv("MOUNTPOINT_VERSION"), "mp version to report in service name")
 		nodeID       = flag.String("node-id", os.Getenv(NodeIDEnvVar), "node-id to report in NodeGetInfo RPC")
+		mode         = flag.String("mode", modeNode, "mode to run the driver in, either \"node\" or \"controller\" for dynamic provisioning")
+		mounterKind  = flag.String("mounter", driver.MounterKindPod, "mounter to use in node mode, either \"pod\" to spawn a Mountpoint Pod per volume or \"daemonset\" to use the mounter DaemonSet")
+
+		daemonSetCommDir        = flag.String("daemonset-comm-dir", filepath.Join(util.ContainerKubeletPath(), "plugins", "s3.csi.aws.com", "daemonset"), "communication directory of the mounter DaemonSet as seen by the driver")
+		daemonSetMounterCommDir = flag.String("daemonset-mounter-comm-dir", "/comm", "communication directory of the mounter DaemonSet as seen by the mounter DaemonSet")
+
+		metricsAddress = flag.String("metrics-address", "", "address to serve Prometheus metrics at in node mode (e.g., \":8080\"), metrics are not served if empty")
 	)
+	credentialPlugins := map[string]string{}
+	flag.Func("credential-plugin", "out-of-tree credential plugin to register in node mode as \"<name>=<unix socket path>\", can be specified multiple times", func(value string) error {
+		name, socketPath, ok := strings.Cut(value, "=")
+		if !ok || name == "" || socketPath == "" {
+			return fmt.Errorf("must be in \"<name>=<unix socket path>\" format, got %q", value)
+		}
+		credentialPlugins[name] = socketPath
+		return nil
+	})
 	utillog.InitKlog()
 	flag.Parse()
 
@@ -54,14 +76,28 @@ func main() {
 		os.Exit(0)
 	}
 
-	if mpVersion == nil {
-		mpVersion = &unknownVersion
-	}
-	if *nodeID == "" {
-		klog.Fatalln("node-id is required")
+	var drv *driver.Driver
+	var err error
+	switch *mode {
+	case modeNode:
+		if mpVersion == nil {
+			mpVersion = &unknownVersion
+		}
+		if *nodeID == "" {
+			klog.Fatalln("node-id is required")
+		}
+		drv, err = driver.NewDriver(*endpoint, *mpVersion, *nodeID, driver.NodeOptions{
+			MounterKind:             *mounterKind,
+			DaemonSetCommDir:        *daemonSetCommDir,
+			DaemonSetMounterCommDir: *daemonSetMounterCommDir,
+			MetricsAddress:          *metricsAddress,
+			CredentialPlugins:       credentialPlugins,
+		})
+	case modeController:
+		drv, err = driver.NewControllerDriver(*endpoint)
+	default:
+		klog.Fatalf("unknown mode %q, must be one of %q or %q", *mode, modeNode, modeController)
 	}
-
-	drv, err := driver.NewDriver(*endpoint, *mpVersion, *nodeID)
 	if err != nil {
 		klog.Fatalf("failed to create driver: %s", err)
 	}
@@ -1,11 +1,16 @@
 package csimounter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
+	"golang.org/x/sys/unix"
 	"k8s.io/klog/v2"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
@@ -32,6 +37,9 @@ type Options struct {
 	MountErrPath   string
 	MountOptions   mountoptions.Options
 	CmdRunner      runner.CmdRunner
+	// Acknowledge, if non-nil, is called once with nil when Mountpoint starts serving the filesystem,
+	// or with the error preventing Mountpoint to start with `MountOptions`.
+	Acknowledge func(err error)
 }
 
 // Run runs Mountpoint with given options until completion and returns its exit code and its error (if any).
@@ -39,18 +47,57 @@ func Run(options Options) (int, error) {
 	mountOptions := options.MountOptions
 	mountpointArgs := mountpoint.ParseArgs(mountOptions.Args)
 
+	var acknowledgeOnce sync.Once
+	acknowledge := func(err error) {
+		acknowledgeOnce.Do(func() {
+			if options.Acknowledge != nil {
+				options.Acknowledge(err)
+			}
+		})
+	}
+	// Ensure the sender is not left waiting for a reply on early returns
+	defer acknowledge(errors.New("Mountpoint exited before it started serving the filesystem"))
+
 	localCacheDir := filepath.Join("/", mppod.LocalCacheDirName)
 	_, localCacheEnabledViaMountOptions := mountpointArgs.Remove(mountpoint.ArgCache)
 	localCacheMounted := checkIfDirExists(localCacheDir)
 
 	if localCacheEnabledViaMountOptions && !localCacheMounted {
-		return 0, fmt.Errorf("local cache enabled via mount options but cache folder is not mounted at %q", localCacheDir)
+		err := mountoptions.NewReplyError(mountoptions.ReplyCodeInvalidOptions, "local cache enabled via mount options but cache folder is not mounted at %q", localCacheDir)
+		acknowledge(err)
+		return 0, err
 	}
 
 	if localCacheMounted {
 		mountpointArgs.Set(mountpoint.ArgCache, localCacheDir)
 	}
 
+	// Fail early with a reply to the sender if the received FUSE file descriptor is not usable.
+	// The duplicate is used to acknowledge once Mountpoint starts serving the filesystem, as Mountpoint's copy
+	// is closed once it exits.
+	readyFd, err := unix.Dup(mountOptions.Fd)
+	if err != nil {
+		err = mountoptions.NewReplyError(mountoptions.ReplyCodeInvalidOptions, "invalid FUSE file descriptor %d: %v", mountOptions.Fd, err)
+		acknowledge(err)
+		return 0, err
+	}
+	defer unix.Close(readyFd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	readyDone := make(chan struct{})
+	go func() {
+		defer close(readyDone)
+		// If the FUSE connection is aborted, Mountpoint exits and its error is acknowledged below
+		err := runner.WaitForFUSEInit(ctx, readyFd, runner.DefaultFUSEInitTimeout)
+		if err == nil || errors.Is(err, runner.ErrFUSEInitTimeout) {
+			acknowledge(err)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-readyDone
+	}()
+
 	exitCode, stdErr, err := runner.RunInForeground(runner.ForegroundOptions{
 		BinaryPath: options.MountpointPath,
 		BucketName: mountOptions.BucketName,
@@ -64,6 +111,7 @@ func Run(options Options) (int, error) {
 		if writeErr := os.WriteFile(options.MountErrPath, stdErr, mountErrorFileperm); writeErr != nil {
 			klog.Errorf("Failed to write mount error logs to %s: %v\n", options.MountErrPath, err)
 		}
+		acknowledge(fmt.Errorf("Mountpoint failed: %s", strings.TrimSpace(string(stdErr))))
 		return exitCode, err
 	}
 
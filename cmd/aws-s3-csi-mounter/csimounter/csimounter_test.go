@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 
@@ -132,6 +133,88 @@ func TestRunningMountpoint(t *testing.T) {
 		assert.Equals(t, cmpopts.AnyError, err)
 	})
 
+	t.Run("Acknowledges once Mountpoint reads the FUSE INIT request", func(t *testing.T) {
+		// Emulate the FUSE device with a pipe, where the kernel queues the FUSE INIT request while mounting
+		fuseINIT, dev, err := os.Pipe()
+		assert.NoError(t, err)
+		defer fuseINIT.Close()
+		defer dev.Close()
+		_, err = dev.Write([]byte("INIT"))
+		assert.NoError(t, err)
+
+		acks := make(chan error, 2)
+		runner := func(c *exec.Cmd) (int, error) {
+			select {
+			case err := <-acks:
+				t.Fatalf("expected no acknowledgement before reading FUSE INIT request, got: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			_, err := c.ExtraFiles[0].Read(make([]byte, 4))
+			assert.NoError(t, err)
+
+			select {
+			case err := <-acks:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected an acknowledgement after reading FUSE INIT request")
+			}
+			return 0, nil
+		}
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(fuseINIT.Fd()),
+				BucketName: "test-bucket",
+			},
+			CmdRunner:   runner,
+			Acknowledge: func(err error) { acks <- err },
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, restartExitCode, exitCode)
+		assert.Equals(t, 0, len(acks))
+	})
+
+	t.Run("Acknowledges with Mountpoint's error if it fails before serving the filesystem", func(t *testing.T) {
+		var acks []error
+		runner := func(c *exec.Cmd) (int, error) {
+			_, err := c.Stderr.Write([]byte("Failed to create S3 client\n"))
+			assert.NoError(t, err)
+			return restartExitCode, errors.New("exit status 1")
+		}
+
+		_, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountErrPath:   filepath.Join(t.TempDir(), "mount.err"),
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+			},
+			CmdRunner:   runner,
+			Acknowledge: func(err error) { acks = append(acks, err) },
+		})
+		assert.Equals(t, cmpopts.AnyError, err)
+		assert.Equals(t, 1, len(acks))
+		assert.Equals(t, "Mountpoint failed: Failed to create S3 client", acks[0].Error())
+	})
+
+	t.Run("Acknowledges with an error if Mountpoint cannot be started", func(t *testing.T) {
+		var acks []error
+		_, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountErrPath:   filepath.Join(t.TempDir(), "mount.err"),
+			MountOptions: mountoptions.Options{
+				Fd:         -1,
+				BucketName: "test-bucket",
+			},
+			Acknowledge: func(err error) { acks = append(acks, err) },
+		})
+		assert.Equals(t, cmpopts.AnyError, err)
+		assert.Equals(t, 1, len(acks))
+		assert.Equals(t, cmpopts.AnyError, acks[0])
+	})
+
 	t.Run("Writes `mount.err` file if Mountpoint fails", func(t *testing.T) {
 		basepath := t.TempDir()
 		mountErrPath := filepath.Join(basepath, "mount.err")
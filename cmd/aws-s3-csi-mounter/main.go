@@ -37,7 +37,7 @@ func main() {
 	flag.Parse()
 
 	mountpointBinFullPath := filepath.Join(*mountpointBinDir, mountpointBin)
-	mountOptions, err := recvMountOptions()
+	req, err := recvMountOptions()
 	if err != nil {
 		if csimounter.ShouldExitWithSuccessCode(mountExitPath) {
 			klog.Info("Failed to receive mount options and detected `mount.exit` file, exiting with zero code")
@@ -60,7 +60,13 @@ func main() {
 		MountpointPath: mountpointBinFullPath,
 		MountExitPath:  mountExitPath,
 		MountErrPath:   mountErrorPath,
-		MountOptions:   mountOptions,
+		MountOptions:   req.Options,
+		// Let the CSI Driver Node Pod know whether Mountpoint is started with the mount options it sent
+		Acknowledge: func(err error) {
+			if replyErr := req.Reply(err); replyErr != nil {
+				klog.Errorf("Failed to reply to mount options received from %s: %v", mountSockPath, replyErr)
+			}
+		},
 	})
 	if err != nil {
 		klog.Fatalf("Failed to run Mountpoint: %v\n", err)
@@ -69,16 +75,16 @@ func main() {
 	os.Exit(exitCode)
 }
 
-func recvMountOptions() (mountoptions.Options, error) {
+func recvMountOptions() (*mountoptions.Request, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), *mountSockRecvTimeout)
 	defer cancel()
 	klog.Infof("Trying to receive mount options from %s", mountSockPath)
-	options, err := mountoptions.Recv(ctx, mountSockPath)
+	req, err := mountoptions.Recv(ctx, mountSockPath)
 	if err != nil {
-		return mountoptions.Options{}, err
+		return nil, err
 	}
 	klog.Infof("Mount options has been received from %s", mountSockPath)
-	return options, nil
+	return req, nil
 }
 
 // setupSignalHandler captures and ignores SIGTERM signals to prevent default
@@ -1,6 +1,3 @@
-// Package v2 contains API Schema definitions for the s3.csi.aws.com v2 API group.
-// +kubebuilder:object:generate=true
-// +groupName=s3.csi.aws.com
 package v2
 
 import (
@@ -17,4 +14,12 @@ var (
 
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
+
+	// SchemeGroupVersion is an alias of [GroupVersion] used by the generated clientset, informers and listers.
+	SchemeGroupVersion = GroupVersion
 )
+
+// Resource takes an unqualified resource and returns a group-qualified [schema.GroupResource].
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
@@ -0,0 +1,92 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// A MountPolicyViolationError is returned when mount options of a volume violate a MountpointS3MountPolicy.
+type MountPolicyViolationError struct {
+	// Policy is the name of the violated MountpointS3MountPolicy.
+	Policy string
+	// Reason describes how the mount options violate the policy.
+	Reason string
+}
+
+func (e *MountPolicyViolationError) Error() string {
+	return fmt.Sprintf("mount options violate MountpointS3MountPolicy %q: %s", e.Policy, e.Reason)
+}
+
+// HasNamespaceSelector returns whether the policy only applies to some namespaces.
+func (p *MountpointS3MountPolicy) HasNamespaceSelector() bool {
+	return p.Spec.NamespaceSelector != nil
+}
+
+// HasPodSelector returns whether the policy only applies to some workload pods.
+func (p *MountpointS3MountPolicy) HasPodSelector() bool {
+	return p.Spec.PodSelector != nil
+}
+
+// Matches returns whether the policy applies to workload pods with `podLabels` in a namespace with `namespaceLabels`.
+// Labels of namespaces or pods are only considered if the policy has the corresponding selector.
+func (p *MountpointS3MountPolicy) Matches(namespaceLabels, podLabels map[string]string) (bool, error) {
+	matches := func(selector *metav1.LabelSelector, set map[string]string) (bool, error) {
+		if selector == nil {
+			return true, nil
+		}
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid selector in MountpointS3MountPolicy %q: %w", p.Name, err)
+		}
+		return s.Matches(labels.Set(set)), nil
+	}
+
+	if ok, err := matches(p.Spec.NamespaceSelector, namespaceLabels); !ok || err != nil {
+		return false, err
+	}
+	return matches(p.Spec.PodSelector, podLabels)
+}
+
+// ApplyDefaults sets default mount options of the policy to `args` unless they're already set.
+func (p *MountpointS3MountPolicy) ApplyDefaults(args *mountpoint.Args) {
+	defaults := mountpoint.ParseArgs(p.Spec.DefaultMountOptions)
+	for _, key := range defaults.Keys() {
+		value, _ := defaults.Value(key)
+		args.SetIfAbsent(key, value)
+	}
+}
+
+// CheckDenied returns a [MountPolicyViolationError] if `args` has any of the mount options denied by the policy.
+func (p *MountpointS3MountPolicy) CheckDenied(args *mountpoint.Args) error {
+	denied := mountpoint.ParseArgs(p.Spec.DeniedMountOptions)
+	var found []string
+	for _, key := range denied.Keys() {
+		if args.Has(key) {
+			found = append(found, key)
+		}
+	}
+	if len(found) > 0 {
+		return &MountPolicyViolationError{Policy: p.Name, Reason: fmt.Sprintf("denied mount options: %s", strings.Join(found, ", "))}
+	}
+	return nil
+}
+
+// CheckRequired returns a [MountPolicyViolationError] if `args` is missing any of the mount options required by the policy.
+func (p *MountpointS3MountPolicy) CheckRequired(args *mountpoint.Args) error {
+	required := mountpoint.ParseArgs(p.Spec.RequiredMountOptions)
+	var missing []string
+	for _, key := range required.Keys() {
+		if !args.Has(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MountPolicyViolationError{Policy: p.Name, Reason: fmt.Sprintf("missing required mount options: %s", strings.Join(missing, ", "))}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MountpointS3MountPolicySpec defines mount option defaults and restrictions for workloads matching the policy.
+type MountpointS3MountPolicySpec struct {
+	// Important: Run "make generate" to regenerate code after modifying this file
+
+	// Selects namespaces of workload pods the policy applies to. The policy applies to all namespaces if it's not set.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Selects workload pods the policy applies to by their labels. The policy applies to all pods if it's not set.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Mount options added to volumes unless they're already set, e.g. `aws-max-attempts 10` or `read-part-size=8388608`.
+	// +optional
+	DefaultMountOptions []string `json:"defaultMountOptions,omitempty"`
+
+	// Mount options volumes are not allowed to use, e.g. `allow-delete`. Only the option names are considered.
+	// +optional
+	DeniedMountOptions []string `json:"deniedMountOptions,omitempty"`
+
+	// Mount options volumes are required to use after defaults are applied, e.g. `read-only`. Only the option names are considered.
+	// +optional
+	RequiredMountOptions []string `json:"requiredMountOptions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=s3mp
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MountpointS3MountPolicy is the Schema for the mountpoints3mountpolicies API.
+type MountpointS3MountPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MountpointS3MountPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MountpointS3MountPolicyList contains a list of MountpointS3MountPolicy.
+type MountpointS3MountPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MountpointS3MountPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MountpointS3MountPolicy{}, &MountpointS3MountPolicyList{})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -16,14 +18,41 @@ func getIndexFields() map[string]func(*MountpointS3PodAttachment) string {
 		FieldPersistentVolumeName:             func(cr *MountpointS3PodAttachment) string { return cr.Spec.PersistentVolumeName },
 		FieldVolumeID:                         func(cr *MountpointS3PodAttachment) string { return cr.Spec.VolumeID },
 		FieldMountOptions:                     func(cr *MountpointS3PodAttachment) string { return cr.Spec.MountOptions },
+		FieldMountPolicies:                    func(cr *MountpointS3PodAttachment) string { return cr.Spec.MountPolicies },
 		FieldAuthenticationSource:             func(cr *MountpointS3PodAttachment) string { return cr.Spec.AuthenticationSource },
 		FieldWorkloadFSGroup:                  func(cr *MountpointS3PodAttachment) string { return cr.Spec.WorkloadFSGroup },
 		FieldWorkloadServiceAccountName:       func(cr *MountpointS3PodAttachment) string { return cr.Spec.WorkloadServiceAccountName },
 		FieldWorkloadNamespace:                func(cr *MountpointS3PodAttachment) string { return cr.Spec.WorkloadNamespace },
 		FieldWorkloadServiceAccountIAMRoleARN: func(cr *MountpointS3PodAttachment) string { return cr.Spec.WorkloadServiceAccountIAMRoleARN },
+		FieldAssumeRoleARN:                    func(cr *MountpointS3PodAttachment) string { return cr.Spec.AssumeRoleARN },
 	}
 }
 
+// Indexers returns indexers for the indexed fields of MountpointS3PodAttachments, keyed by field name,
+// to add to informers created with the generated informers package.
+func Indexers() cache.Indexers {
+	indexers := cache.Indexers{}
+	for field, extractor := range getIndexFields() {
+		indexers[field] = func(obj any) ([]string, error) {
+			s3pa, ok := obj.(*MountpointS3PodAttachment)
+			if !ok {
+				return nil, fmt.Errorf("expected a MountpointS3PodAttachment but got %T", obj)
+			}
+			return []string{extractor(s3pa)}, nil
+		}
+	}
+	return indexers
+}
+
+// IndexedFields returns the values of the indexed fields of `s3pa`, to match it against field selectors.
+func IndexedFields(s3pa *MountpointS3PodAttachment) fields.Set {
+	set := fields.Set{}
+	for field, extractor := range getIndexFields() {
+		set[field] = extractor(s3pa)
+	}
+	return set
+}
+
 // SetupManagerIndices sets up indices for a manager
 func SetupManagerIndices(mgr manager.Manager) error {
 	for field, extractor := range getIndexFields() {
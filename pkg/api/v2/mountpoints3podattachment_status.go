@@ -0,0 +1,138 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the `Ready` condition of Mountpoint S3 pods.
+const (
+	ReasonMountpointPodScheduled = "MountpointPodScheduled"
+	ReasonMountpointPodFailed    = "MountpointPodFailed"
+	ReasonMounted                = "Mounted"
+	ReasonMountFailed            = "MountFailed"
+	ReasonRetryLimitExceeded     = "RetryLimitExceeded"
+)
+
+// SetMountpointS3PodPhase records `phase` of Mountpoint S3 pod `mpPodName` along with its `Ready` condition.
+// `message` is recorded as the last mount error if the phase is [MountpointS3PodFailed],
+// and the last mount error and the retry count are cleared once the phase is [MountpointS3PodMounted].
+// `mountpointVersion` is only updated if it's non-empty.
+func (s3pa *MountpointS3PodAttachment) SetMountpointS3PodPhase(mpPodName string, phase MountpointS3PodPhase, mountpointVersion, reason, message string) {
+	if s3pa.Status.MountpointS3PodStatuses == nil {
+		s3pa.Status.MountpointS3PodStatuses = make(map[string]MountpointS3PodStatus)
+	}
+
+	mpPodStatus := s3pa.Status.MountpointS3PodStatuses[mpPodName]
+	mpPodStatus.Phase = phase
+	if mountpointVersion != "" {
+		mpPodStatus.MountpointVersion = mountpointVersion
+	}
+	switch phase {
+	case MountpointS3PodFailed:
+		mpPodStatus.LastMountError = message
+	case MountpointS3PodMounted:
+		mpPodStatus.LastMountError = ""
+		mpPodStatus.RetryCount = 0
+	}
+
+	ready := metav1.ConditionFalse
+	if phase == MountpointS3PodMounted {
+		ready = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&mpPodStatus.Conditions, metav1.Condition{
+		Type:    MountpointS3PodConditionReady,
+		Status:  ready,
+		Reason:  reason,
+		Message: message,
+	})
+
+	s3pa.Status.MountpointS3PodStatuses[mpPodName] = mpPodStatus
+	s3pa.updateReadySummary()
+}
+
+// SetMountpointS3PodRetryCount records that Mountpoint S3 pod `mpPodName` is the `retryCount`th retry of failed Mountpoint S3 pods.
+func (s3pa *MountpointS3PodAttachment) SetMountpointS3PodRetryCount(mpPodName string, retryCount int32) {
+	if s3pa.Status.MountpointS3PodStatuses == nil {
+		s3pa.Status.MountpointS3PodStatuses = make(map[string]MountpointS3PodStatus)
+	}
+
+	mpPodStatus := s3pa.Status.MountpointS3PodStatuses[mpPodName]
+	mpPodStatus.RetryCount = retryCount
+	s3pa.Status.MountpointS3PodStatuses[mpPodName] = mpPodStatus
+}
+
+// SetMountpointS3PodCredentialOwner records that credentials of workload pod `workloadPodUID` are provided to Mountpoint S3 pod `mpPodName`.
+func (s3pa *MountpointS3PodAttachment) SetMountpointS3PodCredentialOwner(mpPodName string, workloadPodUID string) {
+	if s3pa.Status.MountpointS3PodStatuses == nil {
+		s3pa.Status.MountpointS3PodStatuses = make(map[string]MountpointS3PodStatus)
+	}
+
+	mpPodStatus := s3pa.Status.MountpointS3PodStatuses[mpPodName]
+	mpPodStatus.CredentialOwnerPodUID = workloadPodUID
+	s3pa.Status.MountpointS3PodStatuses[mpPodName] = mpPodStatus
+}
+
+// MountpointS3PodCredentialOwner returns the UID of the workload pod whose credentials are provided to Mountpoint S3 pod `mpPodName`,
+// if that workload pod is still attached to it. Otherwise, it returns an empty string.
+func (s3pa *MountpointS3PodAttachment) MountpointS3PodCredentialOwner(mpPodName string) string {
+	owner := s3pa.Status.MountpointS3PodStatuses[mpPodName].CredentialOwnerPodUID
+	if owner == "" {
+		return ""
+	}
+	for _, attachment := range s3pa.Spec.MountpointS3PodAttachments[mpPodName] {
+		if attachment.WorkloadPodUID == owner {
+			return owner
+		}
+	}
+	return ""
+}
+
+// RemoveMountpointS3PodStatus removes the status of Mountpoint S3 pod `mpPodName`.
+func (s3pa *MountpointS3PodAttachment) RemoveMountpointS3PodStatus(mpPodName string) {
+	delete(s3pa.Status.MountpointS3PodStatuses, mpPodName)
+	if len(s3pa.Status.MountpointS3PodStatuses) == 0 {
+		s3pa.Status.MountpointS3PodStatuses = nil
+	}
+	s3pa.updateReadySummary()
+}
+
+// updateReadySummary updates `Status.Ready` with number of ready Mountpoint S3 pods out of all Mountpoint S3 pods in the spec.
+func (s3pa *MountpointS3PodAttachment) updateReadySummary() {
+	ready := 0
+	for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+		if meta.IsStatusConditionTrue(s3pa.Status.MountpointS3PodStatuses[mpPodName].Conditions, MountpointS3PodConditionReady) {
+			ready++
+		}
+	}
+	s3pa.Status.Ready = fmt.Sprintf("%d/%d", ready, len(s3pa.Spec.MountpointS3PodAttachments))
+}
+
+// UpdateStatus applies `mutate` to `s3pa` and updates its status subresource.
+// It's a no-op if `mutate` does not change the status.
+// On conflicts, it re-fetches the MountpointS3PodAttachment and retries, so `mutate` might be called multiple times.
+// `s3pa` is updated in-place with the latest state on success.
+func UpdateStatus(ctx context.Context, c client.Client, s3pa *MountpointS3PodAttachment, mutate func(*MountpointS3PodAttachment)) error {
+	firstAttempt := true
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if !firstAttempt {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(s3pa), s3pa); err != nil {
+				return err
+			}
+		}
+		firstAttempt = false
+
+		before := s3pa.Status.DeepCopy()
+		mutate(s3pa)
+		if equality.Semantic.DeepEqual(before, &s3pa.Status) {
+			return nil
+		}
+		return c.Status().Update(ctx, s3pa)
+	})
+}
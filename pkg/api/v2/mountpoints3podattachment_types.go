@@ -14,11 +14,13 @@ const (
 	FieldPersistentVolumeName             = "spec.persistentVolumeName"
 	FieldVolumeID                         = "spec.volumeID"
 	FieldMountOptions                     = "spec.mountOptions"
+	FieldMountPolicies                    = "spec.mountPolicies"
 	FieldAuthenticationSource             = "spec.authenticationSource"
 	FieldWorkloadFSGroup                  = "spec.workloadFSGroup"
 	FieldWorkloadServiceAccountName       = "spec.workloadServiceAccountName"
 	FieldWorkloadNamespace                = "spec.workloadNamespace"
 	FieldWorkloadServiceAccountIAMRoleARN = "spec.workloadServiceAccountIAMRoleARN"
+	FieldAssumeRoleARN                    = "spec.assumeRoleARN"
 )
 
 // MountpointS3PodAttachmentSpec defines the desired state of MountpointS3PodAttachment.
@@ -37,6 +39,9 @@ type MountpointS3PodAttachmentSpec struct {
 	// Comma separated mount options taken from volume.
 	MountOptions string `json:"mountOptions"`
 
+	// Comma separated names and generations of MountpointS3MountPolicies matching the workload pod. Exists only if any policy matches the workload pod.
+	MountPolicies string `json:"mountPolicies,omitempty"`
+
 	// Authentication source taken from volume attribute field `authenticationSource`.
 	AuthenticationSource string `json:"authenticationSource"`
 
@@ -46,12 +51,15 @@ type MountpointS3PodAttachmentSpec struct {
 	// Workload pod's service account name. Exists only if `authenticationSource: pod`.
 	WorkloadServiceAccountName string `json:"workloadServiceAccountName,omitempty"`
 
-	// Workload pod's namespace. Exists only if `authenticationSource: pod`.
+	// Workload pod's namespace. Exists only if `authenticationSource: pod` or `authenticationSource: secret`.
 	WorkloadNamespace string `json:"workloadNamespace,omitempty"`
 
 	// EKS IAM Role ARN from workload pod's service account annotation (IRSA). Exists only if `authenticationSource: pod` and service account has `eks.amazonaws.com/role-arn` annotation.
 	WorkloadServiceAccountIAMRoleARN string `json:"workloadServiceAccountIAMRoleARN,omitempty"`
 
+	// IAM Role ARN assumed with credentials of the authentication source, taken from volume attribute field `roleArn`. Exists only if `roleArn` is set.
+	AssumeRoleARN string `json:"assumeRoleARN,omitempty"`
+
 	// Maps each Mountpoint S3 pod name to its workload attachments
 	MountpointS3PodAttachments map[string][]WorkloadAttachment `json:"mountpointS3PodAttachments"`
 }
@@ -63,8 +71,70 @@ type WorkloadAttachment struct {
 
 	// AttachmentTime represents when the workload pod was attached to the Mountpoint S3 pod
 	AttachmentTime metav1.Time `json:"attachmentTime"`
+
+	// WorkloadVolumeName is the name of the volume in the workload pod's spec. Exists only for CSI ephemeral inline volumes.
+	WorkloadVolumeName string `json:"workloadVolumeName,omitempty"`
+}
+
+// MountpointS3PodPhase is the phase of a Mountpoint S3 pod serving a MountpointS3PodAttachment.
+// +kubebuilder:validation:Enum=Pending;Mounted;Failed
+type MountpointS3PodPhase string
+
+const (
+	// MountpointS3PodPending means the Mountpoint S3 pod is spawned, but the volume is not mounted on it yet.
+	MountpointS3PodPending MountpointS3PodPhase = "Pending"
+	// MountpointS3PodMounted means the volume is successfully mounted on the Mountpoint S3 pod.
+	MountpointS3PodMounted MountpointS3PodPhase = "Mounted"
+	// MountpointS3PodFailed means the volume failed to be mounted on the Mountpoint S3 pod, or the Mountpoint S3 pod failed.
+	MountpointS3PodFailed MountpointS3PodPhase = "Failed"
+)
+
+// MountpointS3PodConditionReady is the type of the condition indicating whether the Mountpoint S3 pod is serving the volume.
+const MountpointS3PodConditionReady = "Ready"
+
+// MountpointS3PodStatus defines the observed state of a Mountpoint S3 pod serving a MountpointS3PodAttachment.
+type MountpointS3PodStatus struct {
+	// Phase of the Mountpoint S3 pod.
+	Phase MountpointS3PodPhase `json:"phase"`
+
+	// Last error reported by Mountpoint while mounting the volume. Cleared once the volume is mounted.
+	// +optional
+	LastMountError string `json:"lastMountError,omitempty"`
+
+	// Version of Mountpoint running in the Mountpoint S3 pod.
+	// +optional
+	MountpointVersion string `json:"mountpointVersion,omitempty"`
+
+	// Number of failed Mountpoint S3 pods this Mountpoint S3 pod is retrying in succession.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// UID of the workload pod whose credentials are currently provided to the Mountpoint S3 pod.
+	// Other workloads sharing the Mountpoint S3 pod don't overwrite the credentials while this workload is attached,
+	// and the credentials are failed over to another attached workload once it detaches.
+	// +optional
+	CredentialOwnerPodUID string `json:"credentialOwnerPodUID,omitempty"`
+
+	// Conditions of the Mountpoint S3 pod, currently only `Ready`.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MountpointS3PodAttachmentStatus defines the observed state of MountpointS3PodAttachment.
+type MountpointS3PodAttachmentStatus struct {
+	// Number of ready Mountpoint S3 pods out of all Mountpoint S3 pods in the attachment, e.g. `1/2`.
+	// +optional
+	Ready string `json:"ready,omitempty"`
+
+	// Maps each Mountpoint S3 pod name to its observed status.
+	// +optional
+	MountpointS3PodStatuses map[string]MountpointS3PodStatus `json:"mountpointS3PodStatuses,omitempty"`
 }
 
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=s3pa
@@ -72,6 +142,7 @@ type WorkloadAttachment struct {
 // +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`,description="The node where the volume is mounted"
 // +kubebuilder:printcolumn:name="PV Name",type=string,JSONPath=`.spec.persistentVolumeName`,description="The persistent volume name"
 // +kubebuilder:printcolumn:name="Mount Options",type=string,JSONPath=`.spec.mountOptions`,description="Comma separated mount options"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.ready`,description="Number of ready Mountpoint S3 pods"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MountpointS3PodAttachment is the Schema for the mountpoints3podattachments API.
@@ -79,7 +150,8 @@ type MountpointS3PodAttachment struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MountpointS3PodAttachmentSpec `json:"spec,omitempty"`
+	Spec   MountpointS3PodAttachmentSpec   `json:"spec,omitempty"`
+	Status MountpointS3PodAttachmentStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -5,15 +5,130 @@
 package v2
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountPolicyViolationError) DeepCopyInto(out *MountPolicyViolationError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountPolicyViolationError.
+func (in *MountPolicyViolationError) DeepCopy() *MountPolicyViolationError {
+	if in == nil {
+		return nil
+	}
+	out := new(MountPolicyViolationError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3MountPolicy) DeepCopyInto(out *MountpointS3MountPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3MountPolicy.
+func (in *MountpointS3MountPolicy) DeepCopy() *MountpointS3MountPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3MountPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MountpointS3MountPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3MountPolicyList) DeepCopyInto(out *MountpointS3MountPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MountpointS3MountPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3MountPolicyList.
+func (in *MountpointS3MountPolicyList) DeepCopy() *MountpointS3MountPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3MountPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MountpointS3MountPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3MountPolicySpec) DeepCopyInto(out *MountpointS3MountPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultMountOptions != nil {
+		in, out := &in.DefaultMountOptions, &out.DefaultMountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedMountOptions != nil {
+		in, out := &in.DeniedMountOptions, &out.DeniedMountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredMountOptions != nil {
+		in, out := &in.RequiredMountOptions, &out.RequiredMountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3MountPolicySpec.
+func (in *MountpointS3MountPolicySpec) DeepCopy() *MountpointS3MountPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3MountPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MountpointS3PodAttachment) DeepCopyInto(out *MountpointS3PodAttachment) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3PodAttachment.
@@ -99,6 +214,50 @@ func (in *MountpointS3PodAttachmentSpec) DeepCopy() *MountpointS3PodAttachmentSp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3PodAttachmentStatus) DeepCopyInto(out *MountpointS3PodAttachmentStatus) {
+	*out = *in
+	if in.MountpointS3PodStatuses != nil {
+		in, out := &in.MountpointS3PodStatuses, &out.MountpointS3PodStatuses
+		*out = make(map[string]MountpointS3PodStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3PodAttachmentStatus.
+func (in *MountpointS3PodAttachmentStatus) DeepCopy() *MountpointS3PodAttachmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3PodAttachmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3PodStatus) DeepCopyInto(out *MountpointS3PodStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3PodStatus.
+func (in *MountpointS3PodStatus) DeepCopy() *MountpointS3PodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3PodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadAttachment) DeepCopyInto(out *WorkloadAttachment) {
 	*out = *in
@@ -27,12 +27,18 @@ import (
 
 func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
 	klog.V(4).Infof("CreateVolume: called with args %#v", req)
-	return nil, status.Error(codes.Unimplemented, "")
+	if d.Provisioner == nil {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+	return d.Provisioner.CreateVolume(ctx, req)
 }
 
 func (d *Driver) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
 	klog.V(4).Infof("DeleteVolume: called with args: %#v", req)
-	return nil, status.Error(codes.Unimplemented, "")
+	if d.Provisioner == nil {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+	return d.Provisioner.DeleteVolume(ctx, req)
 }
 
 func (d *Driver) ControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error) {
@@ -48,6 +54,9 @@ func (d *Driver) ControllerGetCapabilities(ctx context.Context, req *csi.Control
 	caps := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_UNKNOWN, // not required, but our testing framework expects some controller capabilities to be returned: https://github.com/kubernetes-csi/csi-test/blob/v2.0.1/pkg/sanity/controller.go#L71
 	}
+	if d.Provisioner != nil {
+		caps = append(caps, csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME)
+	}
 	var capsResponse []*csi.ControllerServiceCapability
 	for _, cap := range caps {
 		c := &csi.ControllerServiceCapability{
@@ -18,7 +18,9 @@ package driver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"os"
 	"slices"
@@ -26,6 +28,7 @@ import (
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsclientsetscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
@@ -40,16 +43,21 @@ import (
 	"k8s.io/klog/v2"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/client/clientset/versioned"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/client/informers/externalversions"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/provisioner"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/version"
 	mpmounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 )
 
 const (
@@ -79,12 +87,42 @@ type Driver struct {
 
 	NodeServer *node.S3NodeServer
 
+	// Provisioner is only set if the driver is running in controller mode.
+	Provisioner *provisioner.Provisioner
+
 	Clientset kubernetes.Interface
 
 	stopCh chan struct{}
 }
 
-func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error) {
+// A MounterKind represents the [mounter.Mounter] implementation used by the driver running in node mode.
+type MounterKind = string
+
+const (
+	// MounterKindPod mounts volumes on Mountpoint Pods, which are managed by the controller via MountpointS3PodAttachments.
+	MounterKindPod MounterKind = "pod"
+	// MounterKindDaemonSet mounts volumes on the mounter DaemonSet Pod running in the same node,
+	// without requiring the controller, the MountpointS3PodAttachment CRD or Mountpoint Pods.
+	MounterKindDaemonSet MounterKind = "daemonset"
+)
+
+// NodeOptions configures the driver running in node mode.
+type NodeOptions struct {
+	MounterKind MounterKind
+	// DaemonSetCommDir is the communication directory of the mounter DaemonSet as seen by the driver.
+	// Only used with [MounterKindDaemonSet].
+	DaemonSetCommDir string
+	// DaemonSetMounterCommDir is the communication directory of the mounter DaemonSet as seen by the mounter DaemonSet.
+	// Only used with [MounterKindDaemonSet].
+	DaemonSetMounterCommDir string
+	// MetricsAddress is the address to serve Prometheus metrics of the node at, metrics are not served if it's empty.
+	MetricsAddress string
+	// CredentialPlugins maps names of out-of-tree credential plugins to the Unix sockets they're listening on.
+	// Volumes can use them via `authenticationSource: <name>`.
+	CredentialPlugins map[string]string
+}
+
+func NewDriver(endpoint string, mpVersion string, nodeID string, opts NodeOptions) (*Driver, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return nil, fmt.Errorf("cannot create in-cluster config: %w", err)
@@ -105,26 +143,103 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 	installMethod := cluster.InstallationMethod()
 
 	version := version.GetVersion()
-	klog.Infof("Driver version: %v, Git commit: %v, build date: %v, nodeID: %v, mount-s3 version: %v, kubernetes version: %v, variant: %s, install: %v",
-		version.DriverVersion, version.GitCommit, version.BuildDate, nodeID, mpVersion, kubernetesVersion, variant.String(), installMethod)
+	klog.Infof("Driver version: %v, Git commit: %v, build date: %v, nodeID: %v, mount-s3 version: %v, kubernetes version: %v, variant: %s, install: %v, mounter: %v",
+		version.DriverVersion, version.GitCommit, version.BuildDate, nodeID, mpVersion, kubernetesVersion, variant.String(), installMethod, opts.MounterKind)
 	// `credentialprovider.RegionFromIMDSOnce` is a `sync.OnceValues` and it only makes request to IMDS once,
 	// this call is basically here to pre-warm the cache of IMDS call.
 	go func() {
 		_, _ = credentialprovider.RegionFromIMDSOnce()
 	}()
 
-	credProvider := credentialprovider.New(clientset.CoreV1(), credentialprovider.RegionFromIMDSOnce)
-
 	stopCh := make(chan struct{})
 
+	provider := credentialprovider.New(clientset.CoreV1(), credentialprovider.RegionFromIMDSOnce)
+	for _, name := range slices.Sorted(maps.Keys(opts.CredentialPlugins)) {
+		socketPath := opts.CredentialPlugins[name]
+		plugin, err := credentialprovider.NewGRPCPlugin(name, socketPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := provider.Register(name, plugin); err != nil {
+			return nil, err
+		}
+		klog.Infof("Registered credential plugin %q listening on %s", name, socketPath)
+	}
+
+	// Refresh driver-level service account tokens of mounts in the background and track expiry of all tokens,
+	// pod-level service account tokens are refreshed by kubelet republishing volumes
+	credProvider := credentialprovider.NewRefresher(provider)
+	go credProvider.Run(stopCh)
+
 	mpMounter := mpmounter.New()
+
+	if opts.MetricsAddress != "" {
+		if err := metrics.RegisterMountsCollector(mpMounter.ListMountpoints, mounter.SourceMountDir(util.ContainerKubeletPath())); err != nil {
+			return nil, fmt.Errorf("failed to register mounts collector: %w", err)
+		}
+		if err := metrics.RegisterCredentialsCollector(credProvider.Expiries); err != nil {
+			return nil, fmt.Errorf("failed to register credentials collector: %w", err)
+		}
+		go func() {
+			if err := metrics.Serve(opts.MetricsAddress, stopCh); err != nil {
+				klog.Fatalf("Failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
+	var nodeMounter mounter.Mounter
+	switch opts.MounterKind {
+	case MounterKindPod:
+		nodeMounter, err = newPodMounter(config, clientset, credProvider, mpMounter, stopCh, kubernetesVersion, nodeID, variant)
+		if err != nil {
+			return nil, err
+		}
+	case MounterKindDaemonSet:
+		nodeMounter, err = mounter.NewDaemonSetMounter(credProvider, clientset.CoreV1(), mountpointPodNamespace, mpMounter, opts.DaemonSetCommDir, opts.DaemonSetMounterCommDir,
+			nil, nil, kubernetesVersion, variant)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mounter for the mounter DaemonSet: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown mounter %q, must be one of %q or %q", opts.MounterKind, MounterKindPod, MounterKindDaemonSet)
+	}
+
+	nodeServer := node.NewS3NodeServer(nodeID, nodeMounter)
+
+	policyCache, err := setupMountPolicyCache(config, stopCh, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	nodeServer.MountPolicies = mountpolicy.New(policyCache)
+
+	return &Driver{
+		Endpoint:   endpoint,
+		NodeID:     nodeID,
+		NodeServer: nodeServer,
+		Clientset:  clientset,
+		stopCh:     stopCh,
+	}, nil
+}
+
+// newPodMounter creates a [mounter.PodMounter] alongside with the Pod watcher, MountpointS3PodAttachment cache and
+// [mounter.PodUnmounter] it relies on. It also starts recovering mounts lost while the CSI Driver Node Pod or the node was down.
+func newPodMounter(config *rest.Config, clientset *kubernetes.Clientset, credProvider credentialprovider.ProviderInterface,
+	mpMounter *mpmounter.Mounter, stopCh chan struct{}, kubernetesVersion, nodeID string, variant cluster.Variant) (*mounter.PodMounter, error) {
 	podWatcher := watcher.New(clientset, mountpointPodNamespace, nodeID, podWatcherResyncPeriod)
-	err = podWatcher.Start(stopCh)
+	err := podWatcher.Start(stopCh)
 	if err != nil {
-		klog.Fatalf("Failed to start Pod watcher: %v\n", err)
+		return nil, fmt.Errorf("failed to start Pod watcher: %w", err)
 	}
 
-	s3paCache := setupS3PodAttachmentCache(config, stopCh, nodeID, kubernetesVersion)
+	s3paCache, err := setupS3PodAttachmentCache(config, stopCh, nodeID, kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s3paClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for reporting MountpointS3PodAttachment status: %w", err)
+	}
 
 	unmounter := mounter.NewPodUnmounter(nodeID, mpMounter, podWatcher, credProvider)
 
@@ -132,20 +247,27 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 
 	go unmounter.StartPeriodicCleanup(stopCh)
 
-	podMounter, err := mounter.NewPodMounter(podWatcher, s3paCache, credProvider, mpMounter, nil, nil,
+	podMounter, err := mounter.NewPodMounter(podWatcher, s3paCache, s3paClient, credProvider, mpMounter, nil, nil,
 		kubernetesVersion, nodeID, variant)
 	if err != nil {
-		klog.Fatalln(err)
+		return nil, fmt.Errorf("failed to create mounter for Mountpoint Pods: %w", err)
 	}
 
-	nodeServer := node.NewS3NodeServer(nodeID, podMounter)
+	go podMounter.StartMountRecovery(stopCh)
+
+	return podMounter, nil
+}
+
+// NewControllerDriver creates a driver running in controller mode, which only serves
+// Identity and Controller services for dynamic provisioning of volumes.
+func NewControllerDriver(endpoint string) (*Driver, error) {
+	version := version.GetVersion()
+	klog.Infof("Driver version: %v, Git commit: %v, build date: %v, mode: controller",
+		version.DriverVersion, version.GitCommit, version.BuildDate)
 
 	return &Driver{
-		Endpoint:   endpoint,
-		NodeID:     nodeID,
-		NodeServer: nodeServer,
-		Clientset:  clientset,
-		stopCh:     stopCh,
+		Endpoint:    endpoint,
+		Provisioner: provisioner.New(provisioner.NewS3ObjectStore()),
 	}, nil
 }
 
@@ -183,15 +305,21 @@ func (d *Driver) Run() error {
 		}
 		return resp, err
 	}
+	interceptors := []grpc.UnaryServerInterceptor{logErr}
+	if d.NodeServer != nil {
+		interceptors = append(interceptors, metrics.UnaryServerInterceptor)
+	}
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(logErr),
+		grpc.ChainUnaryInterceptor(interceptors...),
 		grpc.MaxRecvMsgSize(grpcServerMaxReceiveMessageSize),
 	}
 	d.Srv = grpc.NewServer(opts...)
 
 	csi.RegisterIdentityServer(d.Srv, d)
 	csi.RegisterControllerServer(d.Srv, d)
-	csi.RegisterNodeServer(d.Srv, d.NodeServer)
+	if d.NodeServer != nil {
+		csi.RegisterNodeServer(d.Srv, d.NodeServer)
+	}
 
 	klog.Infof("Listening for connections on address: %#v", listener.Addr())
 
@@ -220,58 +348,73 @@ func kubernetesVersion(clientset *kubernetes.Clientset) (string, error) {
 	return version.String(), nil
 }
 
-// setupS3PodAttachmentCache sets up cache for MountpointS3PodAttachment custom resource
-func setupS3PodAttachmentCache(config *rest.Config, stopCh <-chan struct{}, nodeID, kubernetesVersion string) ctrlcache.Cache {
-	options := ctrlcache.Options{
-		Scheme:                      scheme,
-		SyncPeriod:                  &podWatcherResyncPeriod,
-		ReaderFailOnMissingInformer: true,
+// setupS3PodAttachmentCache sets up cache for MountpointS3PodAttachment custom resource using the generated informer,
+// and returns a reader listing them from the cache with [crdv2.Indexers].
+func setupS3PodAttachmentCache(config *rest.Config, stopCh <-chan struct{}, nodeID, kubernetesVersion string) (client.Reader, error) {
+	s3paClientset, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset for MountpointS3PodAttachment: %w", err)
 	}
 
 	isSelectFieldsSupported, err := checkIfMountpointS3PodAttachmentHasNodeNameSelectableFieldInCurrentVersion(context.TODO(), config)
 	if err != nil {
-		klog.Fatalf("Failed to check support for selectable fields in the cluster %v\n", err)
+		return nil, fmt.Errorf("failed to check support for selectable fields in the cluster: %w", err)
 	}
+
+	var informerOptions []externalversions.SharedInformerOption
 	if isSelectFieldsSupported {
 		klog.Info("Using `spec.nodeName` filter for caching MountpointS3PodAttachment as the cluster supports it")
-		options.ByObject = map[client.Object]ctrlcache.ByObject{
-			&crdv2.MountpointS3PodAttachment{}: {
-				Field: fields.OneTermEqualSelector("spec.nodeName", nodeID),
-			},
-		}
+		informerOptions = append(informerOptions, externalversions.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeID).String()
+		}))
 	} else {
 		klog.Info("Cluster doesn't support selectable fields, falling back to client-side filtering")
 		// TODO: We can potentially use label filter hash of nodeId for old clusters instead of field selector
-		options.ByObject = map[client.Object]ctrlcache.ByObject{
-			&crdv2.MountpointS3PodAttachment{}: {},
-		}
 	}
 
-	s3paCache, err := ctrlcache.New(config, options)
-	if err != nil {
-		klog.Fatalf("Failed to create cache: %v\n", err)
+	informerFactory := externalversions.NewSharedInformerFactoryWithOptions(s3paClientset, podWatcherResyncPeriod, informerOptions...)
+	s3podAttachmentInformer := informerFactory.S3().V2().MountpointS3PodAttachments().Informer()
+	if err := s3podAttachmentInformer.AddIndexers(crdv2.Indexers()); err != nil {
+		return nil, fmt.Errorf("failed to setup field indexers: %w", err)
 	}
 
-	if err := crdv2.SetupCacheIndices(s3paCache); err != nil {
-		klog.Fatalf("Failed to setup field indexers: %v", err)
+	informerFactory.Start(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, s3podAttachmentInformer.HasSynced) {
+		return nil, errors.New("failed to sync informer cache for MountpointS3PodAttachment")
 	}
 
-	s3podAttachmentInformer, err := s3paCache.GetInformer(context.Background(), &crdv2.MountpointS3PodAttachment{})
+	return mounter.NewS3PodAttachmentReader(s3podAttachmentInformer.GetIndexer()), nil
+}
+
+// setupMountPolicyCache sets up cache for MountpointS3MountPolicies and the namespaces and workload Pods they might select,
+// to avoid querying the API server on every volume publish. Only the workload Pods on this node are cached.
+// Informers are started lazily on first use, so nothing is cached unless policies are enforced.
+func setupMountPolicyCache(config *rest.Config, stopCh <-chan struct{}, nodeID string) (ctrlcache.Cache, error) {
+	policyCache, err := ctrlcache.New(config, ctrlcache.Options{
+		Scheme: scheme,
+		ByObject: map[client.Object]ctrlcache.ByObject{
+			&corev1.Pod{}: {
+				Field: fields.OneTermEqualSelector("spec.nodeName", nodeID),
+			},
+		},
+	})
 	if err != nil {
-		klog.Fatalf("Failed to create informer for MountpointS3PodAttachment: %v\n", err)
+		return nil, fmt.Errorf("cannot create cache for MountpointS3MountPolicies: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		if err := s3paCache.Start(signals.SetupSignalHandler()); err != nil {
-			klog.Fatalf("Failed to start cache: %v\n", err)
+		<-stopCh
+		cancel()
+	}()
+	go func() {
+		if err := policyCache.Start(ctx); err != nil {
+			klog.Errorf("Failed to start cache for MountpointS3MountPolicies: %v", err)
 		}
 	}()
 
-	if !cache.WaitForCacheSync(stopCh, s3podAttachmentInformer.HasSynced) {
-		klog.Fatalf("Failed to sync informer cache within the timeout: %v\n", err)
-	}
-
-	return s3paCache
+	return policyCache, nil
 }
 
 // checkIfMountpointS3PodAttachmentHasNodeNameSelectableFieldInCurrentVersion returns whether
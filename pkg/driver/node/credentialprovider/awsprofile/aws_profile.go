@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"unicode"
 
@@ -17,11 +18,25 @@ const (
 	awsProfileNameSuffix                = "s3-csi"
 	awsProfileConfigFilenameSuffix      = "s3-csi-config"
 	awsProfileCredentialsFilenameSuffix = "s3-csi-credentials"
+
+	awsAssumeRoleProfileNameSuffix       = "s3-csi-role"
+	awsAssumeRoleSourceProfileNameSuffix = "s3-csi-role-source"
+	awsAssumeRoleConfigFilenameSuffix    = "s3-csi-role-config"
+)
+
+// Supported values of [RoleSource.CredentialSource].
+const (
+	CredentialSourceEcsContainer        = "EcsContainer"
+	CredentialSourceEc2InstanceMetadata = "Ec2InstanceMetadata"
 )
 
 // ErrInvalidCredentials is returned when given AWS Credentials contains invalid characters.
 var ErrInvalidCredentials = errors.New("aws-profile: Invalid AWS Credentials")
 
+// credentialProcessArgPattern matches arguments allowed in `credential_process`. The command might be run via a shell,
+// so arguments are restricted to characters that don't need quoting, which is enough for paths and ARNs.
+var credentialProcessArgPattern = regexp.MustCompile(`^[\w/:.,=+@-]+$`)
+
 // Profile represents an AWS profile with it's credentials and config filenames.
 type Profile struct {
 	// Name is the AWS profile name
@@ -39,6 +54,45 @@ type Credentials struct {
 	SessionToken    string
 }
 
+// A CredentialProcess represents an external process sourcing AWS credentials via `credential_process`,
+// e.g., IAM Roles Anywhere credential helper.
+type CredentialProcess struct {
+	// Command is the path of the executable, followed by its arguments.
+	Command []string
+}
+
+// isValid checks if all arguments of the process can be safely used in `credential_process`.
+func (p *CredentialProcess) isValid() bool {
+	if len(p.Command) == 0 {
+		return false
+	}
+	for _, arg := range p.Command {
+		if !credentialProcessArgPattern.MatchString(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// A Role represents an IAM role to assume via an AWS Profile.
+type Role struct {
+	ARN string
+	// ExternalID and SessionName are optional.
+	ExternalID  string
+	SessionName string
+}
+
+// A RoleSource represents the credentials used to assume a [Role]. Only one of the sources should be set.
+type RoleSource struct {
+	// Profile is the name of an existing profile to source credentials from, e.g., created via [Create].
+	Profile string
+	// WebIdentityRoleARN and WebIdentityTokenFile configures sourcing credentials from STS Web Identity (IRSA).
+	WebIdentityRoleARN   string
+	WebIdentityTokenFile string
+	// CredentialSource is one of [CredentialSourceEcsContainer] or [CredentialSourceEc2InstanceMetadata].
+	CredentialSource string
+}
+
 // isValid checks if all credential fields contain only printable characters
 func (c *Credentials) isValid() bool {
 	return isValidCredential(c.AccessKeyID) &&
@@ -101,7 +155,90 @@ func Create(settings Settings, credentials Credentials) (Profile, error) {
 	}, nil
 }
 
-// Cleanup cleans up credentials and config files created via [Create].
+// CreateCredentialProcess creates an AWS Profile with credentials and config files sourcing credentials from `process`.
+// The `credential_process` setting is written into the credentials file, so the created profile can be used
+// as a source profile in [CreateAssumeRole] like profiles created via [Create].
+// Created credentials and config files can be clean up with [Cleanup].
+func CreateCredentialProcess(settings Settings, process CredentialProcess) (Profile, error) {
+	if !process.isValid() {
+		return Profile{}, ErrInvalidCredentials
+	}
+
+	name := settings.prefixed(awsProfileNameSuffix)
+
+	configFilename := settings.prefixed(awsProfileConfigFilenameSuffix)
+	configPath := settings.path(configFilename)
+	err := writeAWSProfileFile(configPath, configFileContents(name), settings.FilePerm)
+	if err != nil {
+		return Profile{}, fmt.Errorf("aws-profile: Failed to create config file %s: %v", configPath, err)
+	}
+
+	credentialsFilename := settings.prefixed(awsProfileCredentialsFilenameSuffix)
+	credentialsPath := settings.path(credentialsFilename)
+	credentials := fmt.Sprintf("[%s]\ncredential_process=%s\n", name, strings.Join(process.Command, " "))
+	err = writeAWSProfileFile(credentialsPath, credentials, settings.FilePerm)
+	if err != nil {
+		return Profile{}, fmt.Errorf("aws-profile: Failed to create credentials file %s: %v", credentialsPath, err)
+	}
+
+	return Profile{
+		Name:                name,
+		ConfigFilename:      configFilename,
+		CredentialsFilename: credentialsFilename,
+	}, nil
+}
+
+// CreateAssumeRole creates an AWS Profile assuming `role` with credentials from `source`.
+// Only a config file is created, and the returned profile's [Profile.CredentialsFilename] is empty.
+// If `source` is an existing profile, the credentials file of that profile should also be passed to the AWS SDK.
+// Created config file can be clean up with [Cleanup].
+func CreateAssumeRole(settings Settings, source RoleSource, role Role) (Profile, error) {
+	if !isValidCredential(role.ARN) || !isValidCredential(role.ExternalID) || !isValidCredential(role.SessionName) ||
+		!isValidCredential(source.Profile) || !isValidCredential(source.WebIdentityRoleARN) ||
+		!isValidCredential(source.WebIdentityTokenFile) || !isValidCredential(source.CredentialSource) {
+		return Profile{}, ErrInvalidCredentials
+	}
+
+	name := settings.prefixed(awsAssumeRoleProfileNameSuffix)
+
+	var b strings.Builder
+	var roleSource [2]string
+	switch {
+	case source.Profile != "":
+		roleSource = [2]string{"source_profile", source.Profile}
+	case source.WebIdentityRoleARN != "":
+		sourceName := settings.prefixed(awsAssumeRoleSourceProfileNameSuffix)
+		writeConfigProfile(&b, sourceName, [][2]string{
+			{"role_arn", source.WebIdentityRoleARN},
+			{"web_identity_token_file", source.WebIdentityTokenFile},
+		})
+		roleSource = [2]string{"source_profile", sourceName}
+	case source.CredentialSource != "":
+		roleSource = [2]string{"credential_source", source.CredentialSource}
+	default:
+		return Profile{}, errors.New("aws-profile: No source credentials to assume the role with")
+	}
+	writeConfigProfile(&b, name, [][2]string{
+		roleSource,
+		{"role_arn", role.ARN},
+		{"external_id", role.ExternalID},
+		{"role_session_name", role.SessionName},
+	})
+
+	configFilename := settings.prefixed(awsAssumeRoleConfigFilenameSuffix)
+	configPath := settings.path(configFilename)
+	err := writeAWSProfileFile(configPath, b.String(), settings.FilePerm)
+	if err != nil {
+		return Profile{}, fmt.Errorf("aws-profile: Failed to create config file %s: %v", configPath, err)
+	}
+
+	return Profile{
+		Name:           name,
+		ConfigFilename: configFilename,
+	}, nil
+}
+
+// Cleanup cleans up credentials and config files created via [Create], [CreateCredentialProcess] and [CreateAssumeRole].
 func Cleanup(settings Settings) error {
 	configPath := settings.prefixedPath(awsProfileConfigFilenameSuffix)
 	if err := os.Remove(configPath); err != nil {
@@ -117,6 +254,13 @@ func Cleanup(settings Settings) error {
 		}
 	}
 
+	assumeRoleConfigPath := settings.prefixedPath(awsAssumeRoleConfigFilenameSuffix)
+	if err := os.Remove(assumeRoleConfigPath); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("aws-profile: Failed to remove config file %s: %v", assumeRoleConfigPath, err)
+		}
+	}
+
 	return nil
 }
 
@@ -156,6 +300,20 @@ func configFileContents(profile string) string {
 	return fmt.Sprintf("[profile %s]\n", profile)
 }
 
+// writeConfigProfile writes a profile section with given settings into an AWS config file. Settings with empty values are omitted.
+func writeConfigProfile(b *strings.Builder, profile string, settings [][2]string) {
+	b.WriteString(configFileContents(profile))
+	for _, setting := range settings {
+		if setting[1] == "" {
+			continue
+		}
+		b.WriteString(setting[0])
+		b.WriteRune('=')
+		b.WriteString(setting[1])
+		b.WriteRune('\n')
+	}
+}
+
 // isValidCredential checks whether given credential file contains any non-printable characters.
 func isValidCredential(s string) bool {
 	return !strings.ContainsFunc(s, func(r rune) bool { return !unicode.IsPrint(r) })
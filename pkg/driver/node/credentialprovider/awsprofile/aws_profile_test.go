@@ -1,12 +1,15 @@
 package awsprofile_test
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/config"
+
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile/awsprofiletest"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
@@ -16,6 +19,9 @@ const testAccessKeyId = "test-access-key-id"
 const testSecretAccessKey = "test-secret-access-key"
 const testSessionToken = "test-session-token"
 const testFilePerm = fs.FileMode(0600)
+const testRoleARN = "arn:aws:iam::444455556666:role/data-lake"
+const testExternalID = "test-external-id"
+const testRoleSessionName = "test-session"
 
 func TestCreatingAWSProfile(t *testing.T) {
 	defaultSettings := awsprofile.Settings{
@@ -95,6 +101,125 @@ func TestCreatingAWSProfile(t *testing.T) {
 	})
 }
 
+func TestCreatingAssumeRoleAWSProfile(t *testing.T) {
+	settings := awsprofile.Settings{
+		Basepath: t.TempDir(),
+		Prefix:   "test-",
+		FilePerm: testFilePerm,
+	}
+	role := awsprofile.Role{
+		ARN:         testRoleARN,
+		ExternalID:  testExternalID,
+		SessionName: testRoleSessionName,
+	}
+
+	t.Run("source profile", func(t *testing.T) {
+		sourceProfile, err := awsprofile.Create(settings, awsprofile.Credentials{
+			AccessKeyID:     testAccessKeyId,
+			SecretAccessKey: testSecretAccessKey,
+		})
+		assert.NoError(t, err)
+
+		profile, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{Profile: sourceProfile.Name}, role)
+		assert.NoError(t, err)
+		assert.Equals(t, "test-s3-csi-role", profile.Name)
+		assert.Equals(t, "", profile.CredentialsFilename)
+
+		sharedConfig := loadSharedConfig(t, settings, profile, filepath.Join(settings.Basepath, sourceProfile.CredentialsFilename))
+		assertRole(t, sharedConfig)
+		assert.Equals(t, sourceProfile.Name, sharedConfig.SourceProfileName)
+		assert.Equals(t, testAccessKeyId, sharedConfig.Source.Credentials.AccessKeyID)
+		assert.Equals(t, testSecretAccessKey, sharedConfig.Source.Credentials.SecretAccessKey)
+	})
+
+	t.Run("web identity", func(t *testing.T) {
+		profile, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{
+			WebIdentityRoleARN:   "arn:aws:iam::111122223333:role/pod-a-role",
+			WebIdentityTokenFile: "/test-env/token",
+		}, role)
+		assert.NoError(t, err)
+
+		sharedConfig := loadSharedConfig(t, settings, profile)
+		assertRole(t, sharedConfig)
+		assert.Equals(t, "test-s3-csi-role-source", sharedConfig.SourceProfileName)
+		assert.Equals(t, "arn:aws:iam::111122223333:role/pod-a-role", sharedConfig.Source.RoleARN)
+		assert.Equals(t, "/test-env/token", sharedConfig.Source.WebIdentityTokenFile)
+	})
+
+	t.Run("credential source", func(t *testing.T) {
+		for _, source := range []string{awsprofile.CredentialSourceEcsContainer, awsprofile.CredentialSourceEc2InstanceMetadata} {
+			profile, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{CredentialSource: source}, role)
+			assert.NoError(t, err)
+
+			sharedConfig := loadSharedConfig(t, settings, profile)
+			assertRole(t, sharedConfig)
+			assert.Equals(t, source, sharedConfig.CredentialSource)
+		}
+	})
+
+	t.Run("optional role settings", func(t *testing.T) {
+		profile, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{
+			CredentialSource: awsprofile.CredentialSourceEc2InstanceMetadata,
+		}, awsprofile.Role{ARN: testRoleARN})
+		assert.NoError(t, err)
+
+		sharedConfig := loadSharedConfig(t, settings, profile)
+		assert.Equals(t, testRoleARN, sharedConfig.RoleARN)
+		assert.Equals(t, "", sharedConfig.ExternalID)
+		assert.Equals(t, "", sharedConfig.RoleSessionName)
+	})
+
+	t.Run("fail without a source", func(t *testing.T) {
+		_, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{}, role)
+		if err == nil {
+			t.Fatal("Expected an error without a source")
+		}
+	})
+
+	t.Run("fail if role contains non-printable characters", func(t *testing.T) {
+		_, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{
+			CredentialSource: awsprofile.CredentialSourceEc2InstanceMetadata,
+		}, awsprofile.Role{ARN: testRoleARN, ExternalID: testExternalID + "\ncredential_process=exit"})
+		assert.Equals(t, true, errors.Is(err, awsprofile.ErrInvalidCredentials))
+	})
+}
+
+func TestCreatingCredentialProcessAWSProfile(t *testing.T) {
+	settings := awsprofile.Settings{
+		Basepath: t.TempDir(),
+		Prefix:   "test-",
+		FilePerm: testFilePerm,
+	}
+
+	t.Run("create config and credentials files", func(t *testing.T) {
+		profile, err := awsprofile.CreateCredentialProcess(settings, awsprofile.CredentialProcess{
+			Command: []string{"/bin/aws_signing_helper", "credential-process", "--role-arn", testRoleARN},
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, "test-s3-csi", profile.Name)
+
+		credentialsFile := filepath.Join(settings.Basepath, profile.CredentialsFilename)
+		credentialsStat, err := os.Stat(credentialsFile)
+		assert.NoError(t, err)
+		assert.Equals(t, testFilePerm, credentialsStat.Mode())
+
+		sharedConfig := loadSharedConfig(t, settings, profile, credentialsFile)
+		assert.Equals(t, "/bin/aws_signing_helper credential-process --role-arn "+testRoleARN, sharedConfig.CredentialProcess)
+	})
+
+	t.Run("fail if arguments need quoting", func(t *testing.T) {
+		for _, command := range [][]string{
+			nil,
+			{"/bin/aws_signing_helper", "--role-arn", "role; exit"},
+			{"/bin/aws_signing_helper", "--role-arn", testRoleARN + "\ncredential_process=exit"},
+			{"/bin/aws_signing_helper", ""},
+		} {
+			_, err := awsprofile.CreateCredentialProcess(settings, awsprofile.CredentialProcess{Command: command})
+			assert.Equals(t, true, errors.Is(err, awsprofile.ErrInvalidCredentials))
+		}
+	})
+}
+
 func TestCleaningUpAWSProfile(t *testing.T) {
 	settings := awsprofile.Settings{
 		Basepath: t.TempDir(),
@@ -123,6 +248,19 @@ func TestCleaningUpAWSProfile(t *testing.T) {
 		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
 	})
 
+	t.Run("clean assume role config file", func(t *testing.T) {
+		profile, err := awsprofile.CreateAssumeRole(settings, awsprofile.RoleSource{
+			CredentialSource: awsprofile.CredentialSourceEc2InstanceMetadata,
+		}, awsprofile.Role{ARN: testRoleARN})
+		assert.NoError(t, err)
+
+		err = awsprofile.Cleanup(settings)
+		assert.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(settings.Basepath, profile.ConfigFilename))
+		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+	})
+
 	t.Run("cleaning non-existent config and credentials files should not be an error", func(t *testing.T) {
 		err := awsprofile.Cleanup(settings)
 		assert.NoError(t, err)
@@ -141,3 +279,25 @@ func assertCredentialsFromAWSProfile(t *testing.T, settings awsprofile.Settings,
 		sessionToken,
 	)
 }
+
+func loadSharedConfig(t *testing.T, settings awsprofile.Settings, profile awsprofile.Profile, credentialsFiles ...string) config.SharedConfig {
+	t.Helper()
+	configFile := filepath.Join(settings.Basepath, profile.ConfigFilename)
+	configStat, err := os.Stat(configFile)
+	assert.NoError(t, err)
+	assert.Equals(t, testFilePerm, configStat.Mode())
+
+	sharedConfig, err := config.LoadSharedConfigProfile(context.Background(), profile.Name, func(c *config.LoadSharedConfigOptions) {
+		c.ConfigFiles = []string{configFile}
+		c.CredentialsFiles = credentialsFiles
+	})
+	assert.NoError(t, err)
+	return sharedConfig
+}
+
+func assertRole(t *testing.T, sharedConfig config.SharedConfig) {
+	t.Helper()
+	assert.Equals(t, testRoleARN, sharedConfig.RoleARN)
+	assert.Equals(t, testExternalID, sharedConfig.ExternalID)
+	assert.Equals(t, testRoleSessionName, sharedConfig.RoleSessionName)
+}
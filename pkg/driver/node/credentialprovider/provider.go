@@ -1,22 +1,29 @@
 // Package credentialprovider provides utilities for obtaining AWS credentials to use.
-// Depending on the configuration, it either uses Pod-level or Driver-level credentials.
+// Depending on the configuration, it either uses Pod-level, Driver-level, or Secret credentials,
+// or credentials from a registered credential plugin.
 //
 //go:generate mockgen -source=provider.go -destination=./mocks/mock_provider.go -package=mock_credentialprovider
 package credentialprovider
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	k8sstrings "k8s.io/utils/strings"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 )
 
 // CredentialFilePerm is the default permissions to be used for credential files.
@@ -29,17 +36,23 @@ const CredentialFilePerm = fs.FileMode(0640)
 // Group access is needed as Mountpoint Pod is run as non-root user
 const CredentialDirPerm = fs.FileMode(0750)
 
-// An AuthenticationSource represents the source (i.e., driver-level or pod-level) where the credentials was obtained.
+// An AuthenticationSource represents the source (i.e., driver-level, pod-level, secret, or a credential plugin) where the credentials was obtained.
 type AuthenticationSource = string
 
 const (
 	// This is when users don't provide a `authenticationSource` option in their volume attributes.
 	// We're defaulting to `driver` in this case.
 	AuthenticationSourceUnspecified AuthenticationSource = ""
-	AuthenticationSourceDriver      AuthenticationSource = "driver"
-	AuthenticationSourcePod         AuthenticationSource = "pod"
+	AuthenticationSourceDriver      AuthenticationSource = volumecontext.AuthenticationSourceDriver
+	AuthenticationSourcePod         AuthenticationSource = volumecontext.AuthenticationSourcePod
+	AuthenticationSourceSecret      AuthenticationSource = volumecontext.AuthenticationSourceSecret
 )
 
+// IsCredentialPlugin returns whether `source` refers to a credential plugin rather than a built-in authentication source.
+func IsCredentialPlugin(source AuthenticationSource) bool {
+	return volumecontext.IsCredentialPluginName(source)
+}
+
 // MountKind represents the type of mount being used
 type MountKind string
 
@@ -49,12 +62,27 @@ const (
 	MountKindPod MountKind = "pod"
 	// MountKindSystemd indicates the mount is managed by systemd
 	MountKindSystemd MountKind = "systemd"
+	// MountKindDaemonSet indicates the mount is managed by DaemonSetMounter
+	MountKindDaemonSet MountKind = "daemonset"
 )
 
 // A Provider provides methods for accessing AWS credentials.
+// It delegates to the [Plugin] registered for the volume's authentication source.
 type Provider struct {
-	client         k8sv1.CoreV1Interface
-	regionFromIMDS func() (string, error)
+	client  k8sv1.CoreV1Interface
+	plugins map[AuthenticationSource]Plugin
+}
+
+// A Plugin provides AWS credentials from an authentication source.
+// Built-in plugins handle `driver`, `pod` and `secret` authentication sources, and additional plugins can be registered
+// with [Provider.Register], e.g., out-of-tree plugins served over gRPC via [NewGRPCPlugin].
+type Plugin interface {
+	// Provide writes credentials for given context into [ProvideContext.WritePath] if needed,
+	// and returns environment variables for Mountpoint to use them.
+	Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error)
+	// Cleanup removes any credentials previously written for given context.
+	// It's called for all registered plugins regardless of the authentication source used by the volume.
+	Cleanup(cleanupCtx CleanupContext) error
 }
 
 // ProviderInterface
@@ -90,14 +118,43 @@ type ProvideContext struct {
 
 	// The following values are provided from CSI volume context.
 	AuthenticationSource     AuthenticationSource
+	PodName                  string
 	PodNamespace             string
 	ServiceAccountTokens     string
 	ServiceAccountName       string
 	ServiceAccountEKSRoleARN string
 	// StsRegion is the `stsRegion` parameter passed via volume attribute.
 	StsRegion string
+	// StsEndpoint is the `stsEndpoint` parameter passed via volume attribute. StsUseFIPSEndpoint and StsUseDualStackEndpoint
+	// are the `useFipsEndpoint` and `useDualStackEndpoint` parameters, used to resolve the STS endpoint if StsEndpoint is empty.
+	StsEndpoint             string
+	StsUseFIPSEndpoint      bool
+	StsUseDualStackEndpoint bool
 	// BucketRegion is the `--region` parameter passed via mount options.
 	BucketRegion string
+	// EndpointURL is the `--endpoint-url` parameter passed via mount options or `endpointUrl` volume attribute.
+	EndpointURL string
+
+	// The following values are only used with `authenticationSource: secret`, and with pod-level IAM Roles Anywhere.
+	// Secrets is the contents of the Secret referenced by `nodePublishSecretRef`, passed via CSI secrets.
+	Secrets map[string]string
+	// SecretName and SecretNamespace are the `secretName` and `secretNamespace` parameters passed via volume attributes.
+	SecretName      string
+	SecretNamespace string
+	// InlineVolume is set for CSI ephemeral inline volumes.
+	InlineVolume bool
+	// RolesAnywhereTrustAnchorARN, RolesAnywhereProfileARN and RolesAnywhereRoleARN are the `rolesAnywhere*` parameters
+	// passed via volume attributes. If set, the Secret contains an X.509 certificate and private key for IAM Roles Anywhere.
+	RolesAnywhereTrustAnchorARN string
+	RolesAnywhereProfileARN     string
+	RolesAnywhereRoleARN        string
+
+	// AssumeRoleARN is the `roleArn` parameter passed via volume attributes. If set, the role is assumed
+	// with the credentials of the authentication source. AssumeRoleExternalID and AssumeRoleSessionName
+	// are the optional `roleExternalId` and `roleSessionName` parameters.
+	AssumeRoleARN         string
+	AssumeRoleExternalID  string
+	AssumeRoleSessionName string
 }
 
 // SetWriteAndEnvPath sets `WritePath` and `EnvPath` for `ctx`.
@@ -126,6 +183,11 @@ func (ctx *ProvideContext) SetAsPodMountpoint() {
 	ctx.MountKind = MountKindPod
 }
 
+// SetAsDaemonSetMountpoint marks this context as managed by daemonset mounter.
+func (ctx *ProvideContext) SetAsDaemonSetMountpoint() {
+	ctx.MountKind = MountKindDaemonSet
+}
+
 // IsSystemDMountpoint returns true if this context is managed by systemd mounter.
 func (ctx *ProvideContext) IsSystemDMountpoint() bool {
 	return ctx.MountKind == MountKindSystemd
@@ -136,6 +198,11 @@ func (ctx *ProvideContext) IsPodMountpoint() bool {
 	return ctx.MountKind == MountKindPod
 }
 
+// IsDaemonSetMountpoint returns true if this context is managed by daemonset mounter.
+func (ctx *ProvideContext) IsDaemonSetMountpoint() bool {
+	return ctx.MountKind == MountKindDaemonSet
+}
+
 // GetCredentialPodID returns the appropriate Pod ID for credential operations.
 // When MountpointPodID is not empty string it returns MountpointPodID (for pod mounter mounts),
 // otherwise returns workload Pod ID (for systemd mounts).
@@ -167,6 +234,11 @@ func (ctx *CleanupContext) SetAsPodMountpoint() {
 	ctx.MountKind = MountKindPod
 }
 
+// SetAsDaemonSetMountpoint marks this context as managed by daemonset mounter.
+func (ctx *CleanupContext) SetAsDaemonSetMountpoint() {
+	ctx.MountKind = MountKindDaemonSet
+}
+
 // IsSystemDMountpoint returns true if this context is managed by systemd mounter.
 func (ctx *CleanupContext) IsSystemDMountpoint() bool {
 	return ctx.MountKind == MountKindSystemd
@@ -177,29 +249,79 @@ func (ctx *CleanupContext) IsPodMountpoint() bool {
 	return ctx.MountKind == MountKindPod
 }
 
-// New creates a new [Provider] with given client.
+// IsDaemonSetMountpoint returns true if this context is managed by daemonset mounter.
+func (ctx *CleanupContext) IsDaemonSetMountpoint() bool {
+	return ctx.MountKind == MountKindDaemonSet
+}
+
+// New creates a new [Provider] with given client and built-in plugins for `driver`, `pod` and `secret` authentication sources.
 func New(client k8sv1.CoreV1Interface, regionFromIMDS func() (string, error)) *Provider {
-	return &Provider{client, regionFromIMDS}
+	return &Provider{
+		client: client,
+		plugins: map[AuthenticationSource]Plugin{
+			AuthenticationSourceDriver: &driverPlugin{},
+			AuthenticationSourcePod:    &podPlugin{client, regionFromIMDS},
+			AuthenticationSourceSecret: &secretPlugin{client},
+		},
+	}
+}
+
+// Register registers `plugin` to provide credentials for volumes with `authenticationSource: <name>`.
+// The name must be a valid credential plugin name (see [volumecontext.IsCredentialPluginName]) and must not be registered already.
+// Plugins must be registered before the [Provider] is used.
+func (c *Provider) Register(name AuthenticationSource, plugin Plugin) error {
+	if !volumecontext.IsCredentialPluginName(name) {
+		return fmt.Errorf("credentialprovider: invalid credential plugin name %q, must be a DNS subdomain with at least two labels, e.g., `vault.example.com`", name)
+	}
+	if _, ok := c.plugins[name]; ok {
+		return fmt.Errorf("credentialprovider: credential plugin %q is already registered", name)
+	}
+	c.plugins[name] = plugin
+	return nil
 }
 
 // Provide provides credentials for given context.
-// Depending on the configuration, it either returns driver-level or pod-level credentials.
+// Depending on the configuration, it either returns driver-level, pod-level, secret, or credential plugin credentials.
+// If [ProvideContext.AssumeRoleARN] is set, the returned credentials assume that role with these credentials.
 func (c *Provider) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, AuthenticationSource, error) {
 	if provideCtx.MountKind == MountKindUnspecified {
 		return nil, "", fmt.Errorf("MountKind must be specified on credential ProvideContext struct.")
 	}
 
-	authenticationSource := provideCtx.AuthenticationSource
-	switch authenticationSource {
-	case AuthenticationSourcePod:
-		env, err := c.provideFromPod(ctx, provideCtx)
-		return env, AuthenticationSourcePod, err
-	case AuthenticationSourceUnspecified, AuthenticationSourceDriver:
-		env, err := c.provideFromDriver(provideCtx)
-		return env, AuthenticationSourceDriver, err
-	default:
-		return nil, AuthenticationSourceUnspecified, fmt.Errorf("unknown `authenticationSource`: %s, only `driver` (default option if not specified) and `pod` supported", authenticationSource)
+	env, authenticationSource, err := c.provideFromAuthenticationSource(ctx, provideCtx)
+	if err != nil || provideCtx.AssumeRoleARN == "" {
+		return env, authenticationSource, err
 	}
+
+	env, err = c.provideAssumeRole(provideCtx, env)
+	return env, authenticationSource, err
+}
+
+// provideFromAuthenticationSource provides credentials from the plugin registered for the authentication source configured in given context.
+func (c *Provider) provideFromAuthenticationSource(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, AuthenticationSource, error) {
+	authenticationSource := cmp.Or(provideCtx.AuthenticationSource, AuthenticationSourceDriver)
+	plugin, ok := c.plugins[authenticationSource]
+	if !ok {
+		supported := "`pod` and `secret`"
+		if names := c.credentialPluginNames(); len(names) > 0 {
+			supported = fmt.Sprintf("`pod`, `secret` and credential plugins `%s`", strings.Join(names, "`, `"))
+		}
+		return nil, AuthenticationSourceUnspecified, status.Errorf(codes.InvalidArgument, "unknown `authenticationSource`: %s, only `driver` (default option if not specified), %s supported", authenticationSource, supported)
+	}
+
+	env, err := plugin.Provide(ctx, provideCtx)
+	return env, authenticationSource, err
+}
+
+// credentialPluginNames returns sorted names of registered plugins, excluding built-in ones.
+func (c *Provider) credentialPluginNames() []string {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(c.plugins)) {
+		if volumecontext.IsCredentialPluginName(name) {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // Cleanup cleans any previously created credential files for given context.
@@ -208,14 +330,17 @@ func (c *Provider) Cleanup(cleanupCtx CleanupContext) error {
 		return fmt.Errorf("MountKind must be specified on credential CleanupContext struct.")
 	}
 
-	errPod := c.cleanupFromPod(cleanupCtx)
-	errDriver := c.cleanupFromDriver(cleanupCtx)
-	return errors.Join(errPod, errDriver)
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(c.plugins)) {
+		errs = append(errs, c.plugins[name].Cleanup(cleanupCtx))
+	}
+	errs = append(errs, c.cleanupAssumeRole(cleanupCtx))
+	return errors.Join(errs...)
 }
 
 // cleanupToken removes a token file from the filesystem. If the file doesn't exist, it's not considered
-// an error. This helper is used by both cleanupFromPod and cleanupFromDriver.
-func (c *Provider) cleanupToken(basePath, tokenName string) error {
+// an error. This helper is used by both [podPlugin.Cleanup] and [driverPlugin.Cleanup].
+func cleanupToken(basePath, tokenName string) error {
 	tokenPath := filepath.Join(basePath, tokenName)
 	err := os.Remove(tokenPath)
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
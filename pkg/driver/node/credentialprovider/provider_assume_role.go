@@ -0,0 +1,80 @@
+package credentialprovider
+
+import (
+	"errors"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+const assumeRoleDocsPage = "https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CONFIGURATION.md#assuming-a-role-per-volume"
+
+// provideAssumeRole chains assuming [ProvideContext.AssumeRoleARN] onto credentials provided in `sourceEnv`
+// by the volume's authentication source.
+//
+// It creates an AWS Profile in [provideCtx.WritePath] sourcing its credentials from the same provider Mountpoint
+// would use with `sourceEnv`, and returns `sourceEnv` updated to use this profile instead.
+func (c *Provider) provideAssumeRole(provideCtx ProvideContext, sourceEnv envprovider.Environment) (envprovider.Environment, error) {
+	klog.V(4).Infof("credentialprovider: Assuming role %s with %s credentials", provideCtx.AssumeRoleARN, provideCtx.AuthenticationSource)
+
+	env := envprovider.Environment{}
+	env.Merge(sourceEnv)
+
+	var source awsprofile.RoleSource
+	switch {
+	case env[envprovider.EnvProfile] != "":
+		// Long-term credentials, the credentials file needs to be kept to resolve the source profile
+		source.Profile = env[envprovider.EnvProfile]
+	case env[envprovider.EnvConfigFile] != "" && provideCtx.AuthenticationSource != AuthenticationSourcePod:
+		// Driver-level profile provider in SystemD mounts, we cannot chain onto an arbitrary user-provided config file
+		return nil, status.Error(codes.InvalidArgument, "Assuming a role is not supported with driver-level `AWS_CONFIG_FILE`, see "+assumeRoleDocsPage)
+	case env[envprovider.EnvRoleARN] != "":
+		source.WebIdentityRoleARN = env[envprovider.EnvRoleARN]
+		source.WebIdentityTokenFile = env[envprovider.EnvWebIdentityTokenFile]
+		env.Delete(envprovider.EnvRoleARN)
+		env.Delete(envprovider.EnvWebIdentityTokenFile)
+	case env[envprovider.EnvContainerCredentialsFullURI] != "":
+		source.CredentialSource = awsprofile.CredentialSourceEcsContainer
+	default:
+		source.CredentialSource = awsprofile.CredentialSourceEc2InstanceMetadata
+	}
+
+	prefix := assumeRoleProfilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID)
+	awsProfile, err := awsprofile.CreateAssumeRole(awsprofile.Settings{
+		Basepath: provideCtx.WritePath,
+		Prefix:   prefix,
+		FilePerm: CredentialFilePerm,
+	}, source, awsprofile.Role{
+		ARN:         provideCtx.AssumeRoleARN,
+		ExternalID:  provideCtx.AssumeRoleExternalID,
+		SessionName: provideCtx.AssumeRoleSessionName,
+	})
+	if err != nil {
+		if errors.Is(err, awsprofile.ErrInvalidCredentials) {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid role to assume: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to write AWS profile to assume role: %v", err)
+	}
+
+	env.Set(envprovider.EnvProfile, awsProfile.Name)
+	env.Set(envprovider.EnvConfigFile, filepath.Join(provideCtx.EnvPath, awsProfile.ConfigFilename))
+	return env, nil
+}
+
+// cleanupAssumeRole removes the AWS Profile created via [Provider.provideAssumeRole].
+func (c *Provider) cleanupAssumeRole(cleanupCtx CleanupContext) error {
+	return awsprofile.Cleanup(awsprofile.Settings{
+		Basepath: cleanupCtx.WritePath,
+		Prefix:   assumeRoleProfilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID),
+	})
+}
+
+// assumeRoleProfilePrefix generates a prefix for AWS profile names used to assume a role for a volume.
+func assumeRoleProfilePrefix(podID, volumeID string) string {
+	return escapedVolumeIdentifier(podID, volumeID) + "-assume-role-"
+}
@@ -1,6 +1,7 @@
 package credentialprovider
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -20,8 +21,11 @@ const (
 	eksPodIdentityServiceAccountTokenName = "eks-pod-identity-token"
 )
 
-// provideFromDriver provides driver-level AWS credentials.
-func (c *Provider) provideFromDriver(provideCtx ProvideContext) (envprovider.Environment, error) {
+// A driverPlugin is the built-in [Plugin] for `authenticationSource: driver`, and it provides credentials of the CSI Driver Node Pod.
+type driverPlugin struct{}
+
+// Provide provides driver-level AWS credentials.
+func (p *driverPlugin) Provide(_ context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
 	klog.V(4).Infof("credentialprovider: Using driver identity and %s mount kind", provideCtx.MountKind)
 
 	env := envprovider.Environment{}
@@ -39,6 +43,16 @@ func (c *Provider) provideFromDriver(provideCtx ProvideContext) (envprovider.Env
 		}
 
 		env.Merge(longTermCredsEnv)
+	} else if rolesAnywhere, certificateFile, privateKeyFile, ok := rolesAnywhereConfigFromDriverEnv(); ok && !provideCtx.IsSystemDMountpoint() {
+		// IAM Roles Anywhere, the credential helper is only available in Mountpoint Pods
+		klog.V(4).Infof("Providing credentials from driver with IAM Roles Anywhere")
+		rolesAnywhereCredsEnv, err := provideRolesAnywhereCredentialsFromDriver(provideCtx, rolesAnywhere, certificateFile, privateKeyFile)
+		if err != nil {
+			klog.V(4).ErrorS(err, "credentialprovider: Failed to provide IAM Roles Anywhere credentials from driver")
+			return nil, err
+		}
+
+		env.Merge(rolesAnywhereCredsEnv)
 	} else {
 		// Profile provider
 		if provideCtx.IsSystemDMountpoint() {
@@ -70,7 +84,7 @@ func (c *Provider) provideFromDriver(provideCtx ProvideContext) (envprovider.Env
 	// Container credential provider (EKS Pod Identity)
 	containerAuthorizationTokenFile := os.Getenv(envprovider.EnvContainerAuthorizationTokenFile)
 	containerCredentialsFullURI := os.Getenv(envprovider.EnvContainerCredentialsFullURI)
-	if provideCtx.IsPodMountpoint() && containerAuthorizationTokenFile != "" && containerCredentialsFullURI != "" {
+	if (provideCtx.IsPodMountpoint() || provideCtx.IsDaemonSetMountpoint()) && containerAuthorizationTokenFile != "" && containerCredentialsFullURI != "" {
 		klog.V(4).Infof("Providing credentials from driver with Container credential provider (EKS Pod Identity)")
 		containerCredsEnv, err := provideContainerCredentialsFromDriver(provideCtx, containerAuthorizationTokenFile, containerCredentialsFullURI)
 		if err != nil {
@@ -83,28 +97,33 @@ func (c *Provider) provideFromDriver(provideCtx ProvideContext) (envprovider.Env
 	return env, nil
 }
 
-// cleanupFromDriver removes any credential files that were created for driver-level authentication via [Provider.provideFromDriver].
-func (c *Provider) cleanupFromDriver(cleanupCtx CleanupContext) error {
+// Cleanup removes any credential files that were created for driver-level authentication via [driverPlugin.Provide].
+func (p *driverPlugin) Cleanup(cleanupCtx CleanupContext) error {
 	prefix := driverLevelLongTermCredentialsProfilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID)
 	errLongTerm := awsprofile.Cleanup(awsprofile.Settings{
 		Basepath: cleanupCtx.WritePath,
 		Prefix:   prefix,
 	})
 
+	errRolesAnywhere := cleanupRolesAnywhereCredentials(cleanupCtx.WritePath, prefix)
+	if errRolesAnywhere != nil {
+		errRolesAnywhere = status.Errorf(codes.Internal, "Failed to cleanup driver-level IAM Roles Anywhere certificate: %v", errRolesAnywhere)
+	}
+
 	var errSTS, errEKS error
-	if cleanupCtx.IsPodMountpoint() {
-		errSTS = c.cleanupToken(cleanupCtx.WritePath, webIdentityServiceAccountTokenName)
+	if cleanupCtx.IsPodMountpoint() || cleanupCtx.IsDaemonSetMountpoint() {
+		errSTS = cleanupToken(cleanupCtx.WritePath, webIdentityServiceAccountTokenName)
 		if errSTS != nil {
 			errSTS = status.Errorf(codes.Internal, "Failed to cleanup driver-level service account STS token: %v", errSTS)
 		}
 
-		errEKS = c.cleanupToken(cleanupCtx.WritePath, eksPodIdentityServiceAccountTokenName)
+		errEKS = cleanupToken(cleanupCtx.WritePath, eksPodIdentityServiceAccountTokenName)
 		if errEKS != nil {
 			errEKS = status.Errorf(codes.Internal, "Failed to cleanup driver-level service account EKS Pod Identity token: %v", errEKS)
 		}
 	}
 
-	return errors.Join(errLongTerm, errSTS, errEKS)
+	return errors.Join(errLongTerm, errRolesAnywhere, errSTS, errEKS)
 }
 
 // provideStsWebIdentityCredentialsFromDriver provides credentials for STS Web Identity from the driver's service account.
@@ -143,19 +162,30 @@ func provideContainerCredentialsFromDriver(provideCtx ProvideContext, containerA
 // created a AWS Profile from these credentials in [provideCtx.WritePath].
 func provideLongTermCredentialsFromDriver(provideCtx ProvideContext, accessKeyID, secretAccessKey, sessionToken string) (envprovider.Environment, error) {
 	prefix := driverLevelLongTermCredentialsProfilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID)
-	awsProfile, err := awsprofile.Create(awsprofile.Settings{
-		Basepath: provideCtx.WritePath,
-		Prefix:   prefix,
-		FilePerm: CredentialFilePerm,
-	}, awsprofile.Credentials{
+	return provideLongTermCredentials(provideCtx, prefix, awsprofile.Credentials{
 		AccessKeyID:     accessKeyID,
 		SecretAccessKey: secretAccessKey,
 		SessionToken:    sessionToken,
 	})
+}
+
+// provideLongTermCredentials creates an AWS Profile with given `prefix` from `credentials` in [provideCtx.WritePath],
+// and returns environment variables for Mountpoint to use this profile.
+func provideLongTermCredentials(provideCtx ProvideContext, prefix string, credentials awsprofile.Credentials) (envprovider.Environment, error) {
+	awsProfile, err := awsprofile.Create(awsprofile.Settings{
+		Basepath: provideCtx.WritePath,
+		Prefix:   prefix,
+		FilePerm: CredentialFilePerm,
+	}, credentials)
 	if err != nil {
 		return nil, fmt.Errorf("credentialprovider: long-term: failed to create aws profile: %w", err)
 	}
 
+	return profileEnvironment(provideCtx, awsProfile), nil
+}
+
+// profileEnvironment returns environment variables for Mountpoint to use `awsProfile` created in [provideCtx.WritePath].
+func profileEnvironment(provideCtx ProvideContext, awsProfile awsprofile.Profile) envprovider.Environment {
 	profile := awsProfile.Name
 	configFile := filepath.Join(provideCtx.EnvPath, awsProfile.ConfigFilename)
 	credentialsFile := filepath.Join(provideCtx.EnvPath, awsProfile.CredentialsFilename)
@@ -164,7 +194,25 @@ func provideLongTermCredentialsFromDriver(provideCtx ProvideContext, accessKeyID
 		envprovider.EnvProfile:               profile,
 		envprovider.EnvConfigFile:            configFile,
 		envprovider.EnvSharedCredentialsFile: credentialsFile,
-	}, nil
+	}
+}
+
+// provideRolesAnywhereCredentialsFromDriver provides IAM Roles Anywhere credentials from the driver's configuration.
+// It basically copies the X.509 certificate and private key mounted to driver's Pod from a configured Kubernetes secret
+// to [provideCtx.WritePath], so they're rotated once the secret changes.
+func provideRolesAnywhereCredentialsFromDriver(provideCtx ProvideContext, config rolesAnywhereConfig, certificateFile, privateKeyFile string) (envprovider.Environment, error) {
+	prefix := driverLevelLongTermCredentialsProfilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID)
+
+	err := util.ReplaceFile(rolesAnywhereCertificatePath(provideCtx.WritePath, prefix), certificateFile, CredentialFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: roles-anywhere: failed to copy driver's certificate: %w", err)
+	}
+	err = util.ReplaceFile(rolesAnywherePrivateKeyPath(provideCtx.WritePath, prefix), privateKeyFile, CredentialFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: roles-anywhere: failed to copy driver's private key: %w", err)
+	}
+
+	return provideRolesAnywhereCredentials(provideCtx, prefix, config)
 }
 
 // driverLevelLongTermCredentialsProfilePrefix generates a prefix for AWS credential profile names
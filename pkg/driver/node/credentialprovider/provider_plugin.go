@@ -0,0 +1,133 @@
+package credentialprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/renameio"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	credentialpluginv1 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/credentialplugin/v1"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// credentialPluginFileNamePattern matches names of files returned by credential plugins, which must be path-safe.
+var credentialPluginFileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9._-]*$`)
+
+// credentialPluginEnvPrefix is the prefix of environment variables credential plugins are allowed to pass to Mountpoint.
+const credentialPluginEnvPrefix = "AWS_"
+
+// A grpcPlugin is a [Plugin] calling an out-of-tree credential plugin serving [credentialpluginv1.CredentialPluginServer].
+//
+// Files returned by the plugin are written into [ProvideContext.WritePath], and the environment variables
+// pointing to them are set with their paths in [ProvideContext.EnvPath].
+type grpcPlugin struct {
+	name   string
+	client credentialpluginv1.CredentialPluginClient
+}
+
+// NewGRPCPlugin creates a [Plugin] for the out-of-tree credential plugin `name` listening on Unix socket `socketPath`.
+// The connection is established lazily, so the plugin doesn't need to be running at this point.
+func NewGRPCPlugin(name, socketPath string) (Plugin, error) {
+	conn, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to create client for credential plugin %q: %w", name, err)
+	}
+	return &grpcPlugin{
+		name:   name,
+		client: credentialpluginv1.NewCredentialPluginClient(conn),
+	}, nil
+}
+
+// Provide provides credentials from the credential plugin.
+func (p *grpcPlugin) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	klog.V(4).Infof("credentialprovider: Using credential plugin %q and %s mount kind", p.name, provideCtx.MountKind)
+
+	response, err := p.client.Provide(ctx, &credentialpluginv1.ProvideRequest{
+		VolumeId:             provideCtx.VolumeID,
+		PodName:              provideCtx.PodName,
+		PodNamespace:         provideCtx.PodNamespace,
+		ServiceAccountName:   provideCtx.ServiceAccountName,
+		ServiceAccountTokens: provideCtx.ServiceAccountTokens,
+		Secrets:              provideCtx.Secrets,
+		BucketRegion:         provideCtx.BucketRegion,
+		EndpointUrl:          provideCtx.EndpointURL,
+	})
+	if err != nil {
+		// Keep the status code returned by the plugin, e.g., `InvalidArgument` for misconfigured volumes
+		st := status.Convert(err)
+		return nil, status.Errorf(st.Code(), "Failed to provide credentials from credential plugin %q: %s", p.name, st.Message())
+	}
+
+	env := envprovider.Environment{}
+	for key, value := range response.GetEnv() {
+		if !strings.HasPrefix(key, credentialPluginEnvPrefix) {
+			return nil, status.Errorf(codes.Internal, "Credential plugin %q returned environment variable %q, only %q prefixed variables are allowed", p.name, key, credentialPluginEnvPrefix)
+		}
+		env.Set(key, value)
+	}
+
+	prefix := credentialPluginFilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID, p.name)
+	names := make(map[string]bool)
+	for _, file := range response.GetFiles() {
+		name := file.GetName()
+		if !credentialPluginFileNamePattern.MatchString(name) || names[name] {
+			return nil, status.Errorf(codes.Internal, "Credential plugin %q returned invalid or duplicate file name %q", p.name, name)
+		}
+		names[name] = true
+
+		if file.GetEnv() != "" && !strings.HasPrefix(file.GetEnv(), credentialPluginEnvPrefix) {
+			return nil, status.Errorf(codes.Internal, "Credential plugin %q returned environment variable %q for file %q, only %q prefixed variables are allowed", p.name, file.GetEnv(), name, credentialPluginEnvPrefix)
+		}
+
+		if err := renameio.WriteFile(filepath.Join(provideCtx.WritePath, prefix+name), file.GetContents(), CredentialFilePerm); err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to write file %q from credential plugin %q: %v", name, p.name, err)
+		}
+		if file.GetEnv() != "" {
+			env.Set(file.GetEnv(), filepath.Join(provideCtx.EnvPath, prefix+name))
+		}
+	}
+
+	return env, nil
+}
+
+// Cleanup removes any files that were written for the credential plugin via [grpcPlugin.Provide].
+func (p *grpcPlugin) Cleanup(cleanupCtx CleanupContext) error {
+	entries, err := os.ReadDir(cleanupCtx.WritePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return status.Errorf(codes.Internal, "Failed to cleanup files from credential plugin %q: %v", p.name, err)
+	}
+
+	prefix := credentialPluginFilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID, p.name)
+	var errs []error
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cleanupCtx.WritePath, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return status.Errorf(codes.Internal, "Failed to cleanup files from credential plugin %q: %v", p.name, err)
+	}
+	return nil
+}
+
+// credentialPluginFilePrefix generates a prefix for names of files written for the credential plugin `name`.
+func credentialPluginFilePrefix(podID, volumeID, name string) string {
+	return escapedVolumeIdentifier(podID, volumeID) + "-plugin-" + name + "-"
+}
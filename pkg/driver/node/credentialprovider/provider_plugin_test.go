@@ -0,0 +1,218 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	credentialpluginv1 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/credentialplugin/v1"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testCredentialPluginName = "vault.example.com"
+const testCredentialPluginFilePrefix = testPodID + "-" + testVolumeID + "-plugin-" + testCredentialPluginName + "-"
+
+type fakePlugin struct {
+	env         envprovider.Environment
+	err         error
+	provideCtxs []credentialprovider.ProvideContext
+	cleanupCtxs []credentialprovider.CleanupContext
+}
+
+func (p *fakePlugin) Provide(_ context.Context, provideCtx credentialprovider.ProvideContext) (envprovider.Environment, error) {
+	p.provideCtxs = append(p.provideCtxs, provideCtx)
+	return p.env, p.err
+}
+
+func (p *fakePlugin) Cleanup(cleanupCtx credentialprovider.CleanupContext) error {
+	p.cleanupCtxs = append(p.cleanupCtxs, cleanupCtx)
+	return nil
+}
+
+type fakeCredentialPluginServer struct {
+	credentialpluginv1.UnimplementedCredentialPluginServer
+
+	response *credentialpluginv1.ProvideResponse
+	err      error
+	requests []*credentialpluginv1.ProvideRequest
+}
+
+func (s *fakeCredentialPluginServer) Provide(_ context.Context, req *credentialpluginv1.ProvideRequest) (*credentialpluginv1.ProvideResponse, error) {
+	s.requests = append(s.requests, req)
+	return s.response, s.err
+}
+
+func TestRegisteringCredentialPlugins(t *testing.T) {
+	t.Run("Register and provide", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		plugin := &fakePlugin{env: envprovider.Environment{"AWS_ACCESS_KEY_ID": testAccessKeyID}}
+		assert.NoError(t, provider.Register(testCredentialPluginName, plugin))
+
+		writePath := t.TempDir()
+		env, source, err := provider.Provide(context.Background(), provideCtx(t, writePath, testCredentialPluginName))
+		assert.NoError(t, err)
+		assert.Equals(t, testCredentialPluginName, source)
+		assert.Equals(t, envprovider.Environment{"AWS_ACCESS_KEY_ID": testAccessKeyID}, env)
+		assert.Equals(t, 1, len(plugin.provideCtxs))
+		assert.Equals(t, testVolumeID, plugin.provideCtxs[0].VolumeID)
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 1, len(plugin.cleanupCtxs))
+	})
+
+	t.Run("Propagates plugin errors", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		pluginErr := status.Error(codes.PermissionDenied, "denied")
+		assert.NoError(t, provider.Register(testCredentialPluginName, &fakePlugin{err: pluginErr}))
+
+		_, _, err := provider.Provide(context.Background(), provideCtx(t, t.TempDir(), testCredentialPluginName))
+		assert.Equals(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Unknown plugin", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		assert.NoError(t, provider.Register(testCredentialPluginName, &fakePlugin{}))
+
+		_, _, err := provider.Provide(context.Background(), provideCtx(t, t.TempDir(), "other.example.com"))
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		assert.Equals(t, "unknown `authenticationSource`: other.example.com, only `driver` (default option if not specified), `pod`, `secret` and credential plugins `vault.example.com` supported", status.Convert(err).Message())
+	})
+
+	t.Run("Invalid names", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		for _, name := range []string{"", "driver", "pod", "secret", "vault", "Vault.Example.com", "vault..example.com"} {
+			if err := provider.Register(name, &fakePlugin{}); err == nil {
+				t.Errorf("Expected error registering credential plugin %q, but got nil", name)
+			}
+		}
+	})
+
+	t.Run("Duplicate names", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		assert.NoError(t, provider.Register(testCredentialPluginName, &fakePlugin{}))
+		if err := provider.Register(testCredentialPluginName, &fakePlugin{}); err == nil {
+			t.Fatal("Expected error registering a duplicate credential plugin, but got nil")
+		}
+	})
+}
+
+func TestProvidingCredentialsFromGRPCPlugin(t *testing.T) {
+	t.Run("Materialises env and files", func(t *testing.T) {
+		server := &fakeCredentialPluginServer{
+			response: &credentialpluginv1.ProvideResponse{
+				Env: map[string]string{"AWS_ROLE_ARN": "arn:aws:iam::111122223333:role/test"},
+				Files: []*credentialpluginv1.File{
+					{Name: "token", Contents: []byte("test-web-identity-token"), Env: "AWS_WEB_IDENTITY_TOKEN_FILE"},
+					{Name: "extra.json", Contents: []byte("{}")},
+				},
+			},
+		}
+		provider := providerWithGRPCPlugin(t, server)
+
+		writePath := t.TempDir()
+		ctx := provideCtx(t, writePath, testCredentialPluginName)
+		ctx.PodName = "test-pod"
+		ctx.PodNamespace = "test-ns"
+		ctx.ServiceAccountName = "test-sa"
+		ctx.Secrets = map[string]string{"key": "value"}
+		ctx.BucketRegion = "eu-west-1"
+
+		env, source, err := provider.Provide(context.Background(), ctx)
+		assert.NoError(t, err)
+		assert.Equals(t, testCredentialPluginName, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_ROLE_ARN":                "arn:aws:iam::111122223333:role/test",
+			"AWS_WEB_IDENTITY_TOKEN_FILE": filepath.Join(testEnvPath, testCredentialPluginFilePrefix+"token"),
+		}, env)
+
+		assert.Equals(t, 1, len(server.requests))
+		req := server.requests[0]
+		assert.Equals(t, testVolumeID, req.GetVolumeId())
+		assert.Equals(t, "test-pod", req.GetPodName())
+		assert.Equals(t, "test-ns", req.GetPodNamespace())
+		assert.Equals(t, "test-sa", req.GetServiceAccountName())
+		assert.Equals(t, map[string]string{"key": "value"}, req.GetSecrets())
+		assert.Equals(t, "eu-west-1", req.GetBucketRegion())
+
+		token, err := os.ReadFile(filepath.Join(writePath, testCredentialPluginFilePrefix+"token"))
+		assert.NoError(t, err)
+		assert.Equals(t, "test-web-identity-token", string(token))
+		info, err := os.Stat(filepath.Join(writePath, testCredentialPluginFilePrefix+"extra.json"))
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.CredentialFilePerm, info.Mode().Perm())
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+		entries, err := os.ReadDir(writePath)
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(entries))
+	})
+
+	t.Run("Propagates plugin status codes", func(t *testing.T) {
+		provider := providerWithGRPCPlugin(t, &fakeCredentialPluginServer{
+			err: status.Error(codes.InvalidArgument, "missing role"),
+		})
+
+		_, _, err := provider.Provide(context.Background(), provideCtx(t, t.TempDir(), testCredentialPluginName))
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Rejects invalid responses", func(t *testing.T) {
+		for name, response := range map[string]*credentialpluginv1.ProvideResponse{
+			"non-AWS env":         {Env: map[string]string{"LD_PRELOAD": "/evil.so"}},
+			"non-AWS file env":    {Files: []*credentialpluginv1.File{{Name: "lib.so", Env: "LD_PRELOAD"}}},
+			"path traversal":      {Files: []*credentialpluginv1.File{{Name: "../token"}}},
+			"dot file name":       {Files: []*credentialpluginv1.File{{Name: ".."}}},
+			"duplicate file name": {Files: []*credentialpluginv1.File{{Name: "token"}, {Name: "token"}}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				provider := providerWithGRPCPlugin(t, &fakeCredentialPluginServer{response: response})
+
+				_, _, err := provider.Provide(context.Background(), provideCtx(t, t.TempDir(), testCredentialPluginName))
+				assert.Equals(t, codes.Internal, status.Code(err))
+			})
+		}
+	})
+}
+
+func providerWithGRPCPlugin(t *testing.T, server credentialpluginv1.CredentialPluginServer) *credentialprovider.Provider {
+	socketPath := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	credentialpluginv1.RegisterCredentialPluginServer(grpcServer, server)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			t.Errorf("Failed to serve credential plugin: %v", err)
+		}
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	plugin, err := credentialprovider.NewGRPCPlugin(testCredentialPluginName, socketPath)
+	assert.NoError(t, err)
+
+	provider := credentialprovider.New(nil, dummyRegionProvider)
+	assert.NoError(t, provider.Register(testCredentialPluginName, plugin))
+	return provider
+}
@@ -12,8 +12,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
 
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 )
 
@@ -31,8 +33,15 @@ type serviceAccountToken struct {
 	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
 }
 
-// provideFromPod provides pod-level AWS credentials.
-func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+// A podPlugin is the built-in [Plugin] for `authenticationSource: pod`, and it provides credentials of the workload Pod
+// using IRSA or EKS Pod Identity, or IAM Roles Anywhere with a Secret in the workload Pod's namespace.
+type podPlugin struct {
+	client         k8sv1.CoreV1Interface
+	regionFromIMDS func() (string, error)
+}
+
+// Provide provides pod-level AWS credentials.
+func (p *podPlugin) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
 	klog.V(4).Infof("credentialprovider: Using pod identity and %s mount kind", provideCtx.MountKind)
 
 	podID := provideCtx.GetCredentialPodID()
@@ -40,6 +49,10 @@ func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext
 		return nil, status.Error(codes.InvalidArgument, "Missing Pod info. Please make sure to enable `podInfoOnMountCompat`, see "+podLevelCredentialsDocsPage)
 	}
 
+	if provideCtx.RolesAnywhereTrustAnchorARN != "" {
+		return p.provideRolesAnywhereCredentials(ctx, provideCtx)
+	}
+
 	// 1. Parse ServiceAccountTokens map
 	tokensJson := provideCtx.ServiceAccountTokens
 	if tokensJson == "" {
@@ -59,7 +72,7 @@ func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext
 	}
 
 	eksToken := tokens[serviceAccountTokenAudiencePodIdentity]
-	if provideCtx.IsPodMountpoint() && eksToken == nil {
+	if (provideCtx.IsPodMountpoint() || provideCtx.IsDaemonSetMountpoint()) && eksToken == nil {
 		klog.Errorf("credentialprovider: `authenticationSource` configured to `pod` but no service account token for %s received. Please make sure to enable `podInfoOnMountCompat`, see "+podLevelCredentialsDocsPage, serviceAccountTokenAudiencePodIdentity)
 		return nil, status.Errorf(codes.InvalidArgument, "Missing service account token for %s", serviceAccountTokenAudiencePodIdentity)
 	}
@@ -82,7 +95,7 @@ func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext
 	}
 
 	// 3. Provide credentials with IRSA. If not configured, provide credentials with EKS Pod Identity instead.
-	irsaCredentialsEnvironment, irsaCredentialsEnvironmentError := c.createIRSACredentialsEnvironment(ctx, provideCtx)
+	irsaCredentialsEnvironment, irsaCredentialsEnvironmentError := p.createIRSACredentialsEnvironment(ctx, provideCtx)
 	if irsaCredentialsEnvironmentError == nil {
 		klog.V(4).Infof("Providing credentials from pod with STS Web Identity provider (IRSA)")
 
@@ -102,7 +115,7 @@ func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext
 		}
 
 		klog.V(4).Infof("Providing credentials from pod with Container credential provider (EKS Pod Identity)")
-		eksPodIdentityCredentialsEnvironment, eksPodIdentityCredentialsEnvironmentError := c.createEKSPodIdentityCredentialsEnvironment(provideCtx)
+		eksPodIdentityCredentialsEnvironment, eksPodIdentityCredentialsEnvironmentError := p.createEKSPodIdentityCredentialsEnvironment(provideCtx)
 
 		if eksPodIdentityCredentialsEnvironmentError == nil {
 			// Copy EKS Token file to WritePath
@@ -124,31 +137,77 @@ func (c *Provider) provideFromPod(ctx context.Context, provideCtx ProvideContext
 	return nil, irsaCredentialsEnvironmentError
 }
 
-// cleanupFromPod removes any credential files that were created for pod-level authentication via [Provider.provideFromPod].
-func (c *Provider) cleanupFromPod(cleanupCtx CleanupContext) error {
+// provideRolesAnywhereCredentials provides pod-level IAM Roles Anywhere credentials with the X.509 certificate and private key
+// from a Secret in the workload Pod's namespace. The Secret is looked up using `secretName` volume attribute, or for
+// CSI ephemeral inline volumes, it's referenced by `nodePublishSecretRef` which kubelet only resolves in the workload Pod's namespace.
+//
+// Credentials are written on each call, and the CSI Driver Node is called periodically for already published volumes
+// as `requiresRepublish` is set, so the certificate is rotated once the Secret changes.
+func (p *podPlugin) provideRolesAnywhereCredentials(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	if provideCtx.InlineVolume {
+		if len(provideCtx.Secrets) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "Pod-level IAM Roles Anywhere requires `nodePublishSecretRef` for CSI ephemeral inline volumes, see "+rolesAnywhereDocsPage)
+		}
+	} else if provideCtx.SecretName == "" {
+		return nil, status.Error(codes.InvalidArgument, "Pod-level IAM Roles Anywhere requires `secretName` volume attribute, see "+rolesAnywhereDocsPage)
+	}
+
+	// Never look up the Secret outside of the workload Pod's namespace
+	provideCtx.SecretNamespace = ""
+	data, err := (&secretPlugin{p.client}).secretData(ctx, provideCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := podLevelRolesAnywhereProfilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID)
+	env, err := provideRolesAnywhereCredentialsFromSecret(provideCtx, prefix, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not fallback to the node's instance profile if the certificate in the Secret is rejected
+	env.Set(envprovider.EnvEC2MetadataDisabled, "true")
+	return env, nil
+}
+
+// Cleanup removes any credential files that were created for pod-level authentication via [podPlugin.Provide].
+func (p *podPlugin) Cleanup(cleanupCtx CleanupContext) error {
 	tokenNameSTS := podLevelSTSWebIdentityServiceAccountTokenName(cleanupCtx.PodID, cleanupCtx.VolumeID)
-	errSTS := c.cleanupToken(cleanupCtx.WritePath, tokenNameSTS)
+	errSTS := cleanupToken(cleanupCtx.WritePath, tokenNameSTS)
 	if errSTS != nil {
 		errSTS = status.Errorf(codes.Internal, "Failed to cleanup service account STS token: %v", errSTS)
 	}
 
 	tokenNameEKS := podLevelEksPodIdentityServiceAccountTokenName(cleanupCtx.PodID, cleanupCtx.VolumeID)
-	errEKS := c.cleanupToken(cleanupCtx.WritePath, tokenNameEKS)
+	errEKS := cleanupToken(cleanupCtx.WritePath, tokenNameEKS)
 	if errEKS != nil {
 		errEKS = status.Errorf(codes.Internal, "Failed to cleanup service account EKS Pod Identity token: %v", errEKS)
 	}
 
-	return errors.Join(errSTS, errEKS)
+	prefix := podLevelRolesAnywhereProfilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID)
+	errRolesAnywhere := errors.Join(
+		awsprofile.Cleanup(awsprofile.Settings{
+			Basepath: cleanupCtx.WritePath,
+			Prefix:   prefix,
+		}),
+		cleanupRolesAnywhereCredentials(cleanupCtx.WritePath, prefix),
+	)
+	if errRolesAnywhere != nil {
+		errRolesAnywhere = status.Errorf(codes.Internal, "Failed to cleanup pod-level IAM Roles Anywhere credentials: %v", errRolesAnywhere)
+	}
+
+	return errors.Join(errSTS, errEKS, errRolesAnywhere)
 }
 
 var errMissingServiceAccountAnnotationForIRSA = errors.New("Missing role annotation on pod's service account")
 
 // findPodServiceAccountRole tries to provide associated AWS IAM role for service account specified in the volume context.
-func (c *Provider) findPodServiceAccountRole(ctx context.Context, provideCtx ProvideContext) (string, error) {
+func (p *podPlugin) findPodServiceAccountRole(ctx context.Context, provideCtx ProvideContext) (string, error) {
 	podNamespace := provideCtx.PodNamespace
 	podServiceAccount := provideCtx.ServiceAccountName
 
-	// In PodMounter we get IAM Role ARN from MountpointS3PodAttachment custom resource
+	// In PodMounter we get IAM Role ARN from MountpointS3PodAttachment custom resource,
+	// SystemD and DaemonSet mounts look it up from the service account below.
 	if provideCtx.IsPodMountpoint() {
 		if provideCtx.ServiceAccountEKSRoleARN != "" {
 			return provideCtx.ServiceAccountEKSRoleARN, nil
@@ -162,7 +221,7 @@ func (c *Provider) findPodServiceAccountRole(ctx context.Context, provideCtx Pro
 		return "", status.Error(codes.InvalidArgument, "Missing Pod info. Please make sure to enable `podInfoOnMountCompat`, see "+podLevelCredentialsDocsPage)
 	}
 
-	response, err := c.client.ServiceAccounts(podNamespace).Get(ctx, podServiceAccount, metav1.GetOptions{})
+	response, err := p.client.ServiceAccounts(podNamespace).Get(ctx, podServiceAccount, metav1.GetOptions{})
 	if err != nil {
 		return "", status.Errorf(codes.InvalidArgument, "Failed to get pod's service account %s/%s: %v", podNamespace, podServiceAccount, err)
 	}
@@ -183,6 +242,12 @@ func podLevelSTSWebIdentityServiceAccountTokenName(podID string, volumeID string
 	return id + ".token"
 }
 
+// podLevelRolesAnywhereProfilePrefix returns the prefix of the AWS Profile and certificate files for pod-level IAM Roles Anywhere.
+// It's distinct from [secretLongTermCredentialsProfilePrefix].
+func podLevelRolesAnywhereProfilePrefix(podID string, volumeID string) string {
+	return escapedVolumeIdentifier(podID, volumeID) + "-pod-roles-anywhere-"
+}
+
 // podLevelEksPodIdentityServiceAccountTokenName returns service account token name for Pod-level identity with EKS Pod Identity.
 // It escapes from slashes to make this token name path-safe.
 func podLevelEksPodIdentityServiceAccountTokenName(podID string, volumeID string) string {
@@ -191,13 +256,13 @@ func podLevelEksPodIdentityServiceAccountTokenName(podID string, volumeID string
 }
 
 // createIRSACredentialsEnvironment creates an environment with the environment variables needed for pod-level authentication with IRSA
-func (c *Provider) createIRSACredentialsEnvironment(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
-	roleARN, err := c.findPodServiceAccountRole(ctx, provideCtx)
+func (p *podPlugin) createIRSACredentialsEnvironment(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	roleARN, err := p.findPodServiceAccountRole(ctx, provideCtx)
 	if err != nil {
 		return nil, err
 	}
 
-	region, err := c.stsRegion(provideCtx)
+	region, err := p.stsRegion(provideCtx)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Failed to detect STS AWS Region, please explicitly set the AWS Region, see "+stsConfigDocsPage)
 	}
@@ -211,16 +276,23 @@ func (c *Provider) createIRSACredentialsEnvironment(ctx context.Context, provide
 	tokenName := podLevelSTSWebIdentityServiceAccountTokenName(podID, provideCtx.VolumeID)
 	tokenFile := filepath.Join(provideCtx.EnvPath, tokenName)
 
-	return envprovider.Environment{
+	stsEndpointEnv, err := stsEndpointEnvironment(region, provideCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	env := envprovider.Environment{
 		envprovider.EnvRoleARN:              roleARN,
 		envprovider.EnvWebIdentityTokenFile: tokenFile,
 		envprovider.EnvRegion:               region,
 		envprovider.EnvDefaultRegion:        defaultRegion,
-	}, nil
+	}
+	env.Merge(stsEndpointEnv)
+	return env, nil
 }
 
 // createEKSPodIdentityCredentialsEnvironment creates an environment with the environment variables needed for pod-level authentication with EKS Pod Identity
-func (c *Provider) createEKSPodIdentityCredentialsEnvironment(provideCtx ProvideContext) (envprovider.Environment, error) {
+func (p *podPlugin) createEKSPodIdentityCredentialsEnvironment(provideCtx ProvideContext) (envprovider.Environment, error) {
 	podID := provideCtx.GetCredentialPodID()
 	tokenName := podLevelEksPodIdentityServiceAccountTokenName(podID, provideCtx.VolumeID)
 	tokenFile := filepath.Join(provideCtx.EnvPath, tokenName)
@@ -0,0 +1,100 @@
+package credentialprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// RolesAnywhereSigningHelperPath is the path of the IAM Roles Anywhere credential helper in the CSI Driver image,
+// which is also used by Mountpoint Pods.
+const RolesAnywhereSigningHelperPath = "/bin/aws_signing_helper"
+
+// Environment variables configuring driver-level IAM Roles Anywhere credentials, set via Helm chart.
+const (
+	envRolesAnywhereTrustAnchorARN  = "ROLES_ANYWHERE_TRUST_ANCHOR_ARN"
+	envRolesAnywhereProfileARN      = "ROLES_ANYWHERE_PROFILE_ARN"
+	envRolesAnywhereRoleARN         = "ROLES_ANYWHERE_ROLE_ARN"
+	envRolesAnywhereCertificateFile = "ROLES_ANYWHERE_CERTIFICATE_FILE"
+	envRolesAnywherePrivateKeyFile  = "ROLES_ANYWHERE_PRIVATE_KEY_FILE"
+)
+
+const (
+	rolesAnywhereCertificateFilenameSuffix = "roles-anywhere.crt"
+	rolesAnywherePrivateKeyFilenameSuffix  = "roles-anywhere.key"
+)
+
+const rolesAnywhereDocsPage = "https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CONFIGURATION.md#iam-roles-anywhere"
+
+// A rolesAnywhereConfig contains the ARNs passed to IAM Roles Anywhere credential helper.
+type rolesAnywhereConfig struct {
+	TrustAnchorARN string
+	ProfileARN     string
+	RoleARN        string
+}
+
+// rolesAnywhereConfigFromDriverEnv returns driver-level IAM Roles Anywhere configuration and paths of
+// the X.509 certificate and private key in the CSI Driver Node Pod. It returns false if it's not configured.
+func rolesAnywhereConfigFromDriverEnv() (config rolesAnywhereConfig, certificateFile, privateKeyFile string, ok bool) {
+	config = rolesAnywhereConfig{
+		TrustAnchorARN: os.Getenv(envRolesAnywhereTrustAnchorARN),
+		ProfileARN:     os.Getenv(envRolesAnywhereProfileARN),
+		RoleARN:        os.Getenv(envRolesAnywhereRoleARN),
+	}
+	certificateFile = os.Getenv(envRolesAnywhereCertificateFile)
+	privateKeyFile = os.Getenv(envRolesAnywherePrivateKeyFile)
+	ok = config.TrustAnchorARN != "" && config.ProfileARN != "" && config.RoleARN != "" && certificateFile != "" && privateKeyFile != ""
+	return config, certificateFile, privateKeyFile, ok
+}
+
+// provideRolesAnywhereCredentials creates an AWS Profile with given `prefix` in [provideCtx.WritePath] sourcing credentials
+// from IAM Roles Anywhere credential helper via `credential_process`, and returns environment variables for Mountpoint to use this profile.
+// The X.509 certificate and private key must be written beforehand to [rolesAnywhereCertificatePath] and [rolesAnywherePrivateKeyPath].
+func provideRolesAnywhereCredentials(provideCtx ProvideContext, prefix string, config rolesAnywhereConfig) (envprovider.Environment, error) {
+	awsProfile, err := awsprofile.CreateCredentialProcess(awsprofile.Settings{
+		Basepath: provideCtx.WritePath,
+		Prefix:   prefix,
+		FilePerm: CredentialFilePerm,
+	}, awsprofile.CredentialProcess{
+		Command: []string{
+			RolesAnywhereSigningHelperPath, "credential-process",
+			"--certificate", rolesAnywhereCertificatePath(provideCtx.EnvPath, prefix),
+			"--private-key", rolesAnywherePrivateKeyPath(provideCtx.EnvPath, prefix),
+			"--trust-anchor-arn", config.TrustAnchorARN,
+			"--profile-arn", config.ProfileARN,
+			"--role-arn", config.RoleARN,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: roles-anywhere: failed to create aws profile: %w", err)
+	}
+
+	return profileEnvironment(provideCtx, awsProfile), nil
+}
+
+// cleanupRolesAnywhereCredentials removes the X.509 certificate and private key written for IAM Roles Anywhere with given `prefix`.
+// The AWS Profile is removed with [awsprofile.Cleanup] using the same prefix.
+func cleanupRolesAnywhereCredentials(writePath, prefix string) error {
+	var errs []error
+	for _, path := range []string{rolesAnywhereCertificatePath(writePath, prefix), rolesAnywherePrivateKeyPath(writePath, prefix)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rolesAnywhereCertificatePath returns path of the X.509 certificate for IAM Roles Anywhere with given `prefix` in `basepath`.
+func rolesAnywhereCertificatePath(basepath, prefix string) string {
+	return filepath.Join(basepath, prefix+rolesAnywhereCertificateFilenameSuffix)
+}
+
+// rolesAnywherePrivateKeyPath returns path of the private key for IAM Roles Anywhere with given `prefix` in `basepath`.
+func rolesAnywherePrivateKeyPath(basepath, prefix string) string {
+	return filepath.Join(basepath, prefix+rolesAnywherePrivateKeyFilenameSuffix)
+}
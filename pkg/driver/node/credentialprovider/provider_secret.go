@@ -0,0 +1,174 @@
+package credentialprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/renameio"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// Keys of long-term AWS credentials in the Secret.
+// These are the same keys used in the driver-level `aws-secret`.
+const (
+	secretKeyAccessKeyID     = "key_id"
+	secretKeySecretAccessKey = "access_key"
+	secretKeySessionToken    = "session_token"
+)
+
+const secretCredentialsDocsPage = "https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CONFIGURATION.md#secret-credentials"
+
+// A secretPlugin is the built-in [Plugin] for `authenticationSource: secret`, and it provides credentials from a Kubernetes Secret.
+type secretPlugin struct {
+	client k8sv1.CoreV1Interface
+}
+
+// Provide provides long-term AWS credentials, or IAM Roles Anywhere credentials if configured, from a Kubernetes Secret.
+// The Secret is either passed via CSI secrets (i.e., `nodePublishSecretRef`), or looked up using `secretName` and `secretNamespace` volume attributes.
+//
+// Credentials are written on each call, and the CSI Driver Node is called periodically for already published volumes
+// as `requiresRepublish` is set, so the credentials are rotated once the Secret changes.
+func (p *secretPlugin) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	klog.V(4).Infof("credentialprovider: Using secret credentials and %s mount kind", provideCtx.MountKind)
+
+	data, err := p.secretData(ctx, provideCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := secretLongTermCredentialsProfilePrefix(provideCtx.GetCredentialPodID(), provideCtx.VolumeID)
+	var env envprovider.Environment
+	if provideCtx.RolesAnywhereTrustAnchorARN != "" {
+		env, err = provideRolesAnywhereCredentialsFromSecret(provideCtx, prefix, data)
+	} else {
+		env, err = provideLongTermCredentialsFromSecret(provideCtx, prefix, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not fallback to the node's instance profile if the credentials in the Secret are rejected
+	env.Set(envprovider.EnvEC2MetadataDisabled, "true")
+	return env, nil
+}
+
+// provideLongTermCredentialsFromSecret creates an AWS Profile with given `prefix` from long-term AWS credentials in Secret `data`.
+func provideLongTermCredentialsFromSecret(provideCtx ProvideContext, prefix string, data map[string]string) (envprovider.Environment, error) {
+	credentials := awsprofile.Credentials{
+		AccessKeyID:     strings.TrimSpace(data[secretKeyAccessKeyID]),
+		SecretAccessKey: strings.TrimSpace(data[secretKeySecretAccessKey]),
+		SessionToken:    strings.TrimSpace(data[secretKeySessionToken]),
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Secret must contain %q and %q keys, see "+secretCredentialsDocsPage, secretKeyAccessKeyID, secretKeySecretAccessKey)
+	}
+
+	env, err := provideLongTermCredentials(provideCtx, prefix, credentials)
+	if err != nil {
+		if errors.Is(err, awsprofile.ErrInvalidCredentials) {
+			return nil, status.Errorf(codes.InvalidArgument, "Secret contains invalid AWS credentials: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to write credentials from secret: %v", err)
+	}
+	return env, nil
+}
+
+// provideRolesAnywhereCredentialsFromSecret writes the X.509 certificate and private key in Secret `data` to [provideCtx.WritePath],
+// and creates an AWS Profile with given `prefix` sourcing credentials from IAM Roles Anywhere.
+func provideRolesAnywhereCredentialsFromSecret(provideCtx ProvideContext, prefix string, data map[string]string) (envprovider.Environment, error) {
+	if provideCtx.IsSystemDMountpoint() {
+		return nil, status.Error(codes.InvalidArgument, "IAM Roles Anywhere is not supported with SystemD mounts, see "+rolesAnywhereDocsPage)
+	}
+
+	certificate, privateKey := data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey]
+	if certificate == "" || privateKey == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Secret must contain %q and %q keys to use IAM Roles Anywhere, see "+rolesAnywhereDocsPage, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	if err := renameio.WriteFile(rolesAnywhereCertificatePath(provideCtx.WritePath, prefix), []byte(certificate), CredentialFilePerm); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to write certificate from secret: %v", err)
+	}
+	if err := renameio.WriteFile(rolesAnywherePrivateKeyPath(provideCtx.WritePath, prefix), []byte(privateKey), CredentialFilePerm); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to write private key from secret: %v", err)
+	}
+
+	env, err := provideRolesAnywhereCredentials(provideCtx, prefix, rolesAnywhereConfig{
+		TrustAnchorARN: provideCtx.RolesAnywhereTrustAnchorARN,
+		ProfileARN:     provideCtx.RolesAnywhereProfileARN,
+		RoleARN:        provideCtx.RolesAnywhereRoleARN,
+	})
+	if err != nil {
+		if errors.Is(err, awsprofile.ErrInvalidCredentials) {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid IAM Roles Anywhere configuration: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to write IAM Roles Anywhere configuration: %v", err)
+	}
+	return env, nil
+}
+
+// secretData returns contents of the Secret to use for given context.
+func (p *secretPlugin) secretData(ctx context.Context, provideCtx ProvideContext) (map[string]string, error) {
+	if provideCtx.SecretName == "" {
+		if len(provideCtx.Secrets) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "`authenticationSource` configured to `secret` but no secret received. Please either set `nodePublishSecretRef` or `secretName` volume attribute, see "+secretCredentialsDocsPage)
+		}
+		return provideCtx.Secrets, nil
+	}
+
+	// Inline volumes are defined by workloads rather than cluster administrators,
+	// they should not be able to read Secrets using the CSI Driver Node's permissions.
+	if provideCtx.InlineVolume {
+		return nil, status.Error(codes.InvalidArgument, "CSI ephemeral inline volumes cannot use `secretName` volume attribute, use `nodePublishSecretRef` instead, see "+secretCredentialsDocsPage)
+	}
+
+	name := provideCtx.SecretName
+	namespace := provideCtx.SecretNamespace
+	if namespace == "" {
+		namespace = provideCtx.PodNamespace
+	}
+	if namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing Pod info. Please either set `secretNamespace` volume attribute or enable `podInfoOnMountCompat`, see "+secretCredentialsDocsPage)
+	}
+
+	secret, err := p.client.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Failed to get secret %s/%s: %v", namespace, name, err)
+	}
+
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	return data, nil
+}
+
+// Cleanup removes any credential files that were created for secret authentication via [secretPlugin.Provide].
+func (p *secretPlugin) Cleanup(cleanupCtx CleanupContext) error {
+	prefix := secretLongTermCredentialsProfilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID)
+	err := errors.Join(
+		awsprofile.Cleanup(awsprofile.Settings{
+			Basepath: cleanupCtx.WritePath,
+			Prefix:   prefix,
+		}),
+		cleanupRolesAnywhereCredentials(cleanupCtx.WritePath, prefix),
+	)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to cleanup credentials from secret: %v", err)
+	}
+	return nil
+}
+
+// secretLongTermCredentialsProfilePrefix generates a prefix for AWS credential profile names
+// when using secret authentication. It's distinct from [driverLevelLongTermCredentialsProfilePrefix].
+func secretLongTermCredentialsProfilePrefix(podID, volumeID string) string {
+	return escapedVolumeIdentifier(podID, volumeID) + "-secret-"
+}
@@ -1,6 +1,7 @@
 package credentialprovider_test
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -36,6 +39,18 @@ const testMountpointPodID = "6984d258-b0bc-4103-a104-3d7fc5440744"
 const testVolumeID = "test-vol"
 const testSystemDProfilePrefix = testPodID + "-" + testVolumeID + "-"
 const testPodMounterProfilePrefix = testMountpointPodID + "-" + testVolumeID + "-"
+const testPodMounterSecretProfilePrefix = testMountpointPodID + "-" + testVolumeID + "-secret-"
+const testPodMounterPodRolesAnywhereProfilePrefix = testMountpointPodID + "-" + testVolumeID + "-pod-roles-anywhere-"
+const testPodMounterAssumeRoleProfilePrefix = testMountpointPodID + "-" + testVolumeID + "-assume-role-"
+
+const testAssumeRoleARN = "arn:aws:iam::444455556666:role/data-lake"
+const testAssumeRoleExternalID = "test-external-id"
+const testAssumeRoleSessionName = "test-session"
+
+const testRolesAnywhereTrustAnchorARN = "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4"
+const testRolesAnywhereProfileARN = "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8"
+const testRolesAnywhereCertificate = "test-certificate"
+const testRolesAnywherePrivateKey = "test-private-key"
 
 const testRoleARN = "arn:aws:iam::111122223333:role/pod-a-role"
 const testWebIdentityToken = "test-web-identity-token"
@@ -540,6 +555,48 @@ func TestProvidingPodLevelCredentials(t *testing.T) {
 		assertWebIdentityTokenFile(t, filepath.Join(writePath, testPodMounterPodLevelServiceAccountToken))
 	})
 
+	t.Run("correct values for IRSA (DaemonSetMounter)", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(serviceAccount(testPodServiceAccount, testPodNamespace, map[string]string{
+			"eks.amazonaws.com/role-arn": testRoleARN,
+		}))
+		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := credentialprovider.ProvideContext{
+			AuthenticationSource: credentialprovider.AuthenticationSourcePod,
+			WritePath:            writePath,
+			EnvPath:              testEnvPath,
+			WorkloadPodID:        testPodID,
+			VolumeID:             testVolumeID,
+			PodNamespace:         testPodNamespace,
+			ServiceAccountName:   testPodServiceAccount,
+			ServiceAccountTokens: serviceAccountTokens(t, tokens{
+				serviceAccountTokenAudienceSTS: {
+					Token: testWebIdentityToken,
+				},
+				serviceAccountTokenAudienceEKS: {
+					Token: testContainerAuthorizationToken,
+				},
+			}),
+			MountKind: credentialprovider.MountKindDaemonSet,
+		}
+
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourcePod, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_ROLE_ARN":                testRoleARN,
+			"AWS_WEB_IDENTITY_TOKEN_FILE": filepath.Join(testEnvPath, testSystemDPodLevelServiceAccountToken),
+
+			// Disable EC2 credentials
+			"AWS_EC2_METADATA_DISABLED": "true",
+
+			"AWS_REGION":         testIMDSRegion,
+			"AWS_DEFAULT_REGION": testIMDSRegion,
+		}, env)
+		assertWebIdentityTokenFile(t, filepath.Join(writePath, testSystemDPodLevelServiceAccountToken))
+	})
+
 	t.Run("missing information", func(t *testing.T) {
 		clientset := fake.NewSimpleClientset(
 			serviceAccount(testPodServiceAccount, testPodNamespace, map[string]string{
@@ -697,6 +754,32 @@ func TestDetectingRegionToUseForPodLevelCredentials(t *testing.T) {
 		}, env)
 	})
 
+	t.Run("no region from imds for non-AWS endpoint", func(t *testing.T) {
+		provider := credentialprovider.New(clientset.CoreV1(), func() (string, error) {
+			t.Error("IMDS should not be queried for non-AWS endpoints")
+			return "us-east-2", nil
+		})
+
+		provideCtx := baseProvideCtx
+		provideCtx.EndpointURL = "http://minio.storage.svc:9000"
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		if err == nil {
+			t.Error("it should fail if there is not any region information")
+		}
+	})
+
+	t.Run("region from imds for AWS endpoint", func(t *testing.T) {
+		provider := credentialprovider.New(clientset.CoreV1(), func() (string, error) {
+			return "us-east-2", nil
+		})
+
+		provideCtx := baseProvideCtx
+		provideCtx.EndpointURL = "https://s3.us-east-2.amazonaws.com"
+		env, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, "us-east-2", env["AWS_REGION"])
+	})
+
 	t.Run("region from env", func(t *testing.T) {
 		t.Setenv("AWS_REGION", "eu-west-1")
 		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
@@ -831,6 +914,202 @@ func TestDetectingRegionToUseForPodLevelCredentials(t *testing.T) {
 	})
 }
 
+func TestResolvingSTSEndpointForPodLevelCredentials(t *testing.T) {
+	testutil.CleanRegionEnv(t)
+
+	clientset := fake.NewSimpleClientset(serviceAccount(testPodServiceAccount, testPodNamespace, map[string]string{
+		"eks.amazonaws.com/role-arn": testRoleARN,
+	}))
+	provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+	baseProvideCtx := credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourcePod,
+		WritePath:            t.TempDir(),
+		EnvPath:              testEnvPath,
+		WorkloadPodID:        testPodID,
+		VolumeID:             testVolumeID,
+		PodNamespace:         testPodNamespace,
+		ServiceAccountName:   testPodServiceAccount,
+		ServiceAccountTokens: serviceAccountTokens(t, tokens{
+			serviceAccountTokenAudienceSTS: {
+				Token: testWebIdentityToken,
+			},
+		}),
+		MountKind: credentialprovider.MountKindSystemd,
+	}
+
+	for _, test := range []struct {
+		name         string
+		region       string
+		useFIPS      bool
+		useDualStack bool
+		// expectedEnv is the expected STS endpoint environment, nil if the default endpoint should be used.
+		expectedEnv envprovider.Environment
+	}{
+		{name: "aws", region: "eu-west-1"},
+		{name: "aws with unknown region", region: "default"},
+		{
+			name:    "aws fips",
+			region:  "us-east-1",
+			useFIPS: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":  "https://sts-fips.us-east-1.amazonaws.com",
+				"AWS_USE_FIPS_ENDPOINT": "true",
+			},
+		},
+		{
+			name:         "aws dual-stack",
+			region:       "eu-west-1",
+			useDualStack: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":       "https://sts.eu-west-1.api.aws",
+				"AWS_USE_DUALSTACK_ENDPOINT": "true",
+			},
+		},
+		{
+			name:         "aws fips and dual-stack",
+			region:       "us-west-2",
+			useFIPS:      true,
+			useDualStack: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":       "https://sts-fips.us-west-2.api.aws",
+				"AWS_USE_FIPS_ENDPOINT":      "true",
+				"AWS_USE_DUALSTACK_ENDPOINT": "true",
+			},
+		},
+		{
+			name:   "aws-cn",
+			region: "cn-north-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.cn-north-1.amazonaws.com.cn",
+			},
+		},
+		{
+			name:         "aws-cn dual-stack",
+			region:       "cn-northwest-1",
+			useDualStack: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":       "https://sts.cn-northwest-1.api.amazonwebservices.com.cn",
+				"AWS_USE_DUALSTACK_ENDPOINT": "true",
+			},
+		},
+		{
+			name:   "aws-us-gov",
+			region: "us-gov-west-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.us-gov-west-1.amazonaws.com",
+			},
+		},
+		{
+			name:    "aws-us-gov fips",
+			region:  "us-gov-east-1",
+			useFIPS: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":  "https://sts.us-gov-east-1.amazonaws.com",
+				"AWS_USE_FIPS_ENDPOINT": "true",
+			},
+		},
+		{
+			name:         "aws-us-gov fips and dual-stack",
+			region:       "us-gov-west-1",
+			useFIPS:      true,
+			useDualStack: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":       "https://sts.us-gov-west-1.api.aws",
+				"AWS_USE_FIPS_ENDPOINT":      "true",
+				"AWS_USE_DUALSTACK_ENDPOINT": "true",
+			},
+		},
+		{
+			name:   "aws-iso",
+			region: "us-iso-east-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.us-iso-east-1.c2s.ic.gov",
+			},
+		},
+		{
+			name:    "aws-iso fips",
+			region:  "us-iso-west-1",
+			useFIPS: true,
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS":  "https://sts-fips.us-iso-west-1.c2s.ic.gov",
+				"AWS_USE_FIPS_ENDPOINT": "true",
+			},
+		},
+		{
+			name:   "aws-iso-b",
+			region: "us-isob-east-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.us-isob-east-1.sc2s.sgov.gov",
+			},
+		},
+		{
+			name:   "aws-iso-e",
+			region: "eu-isoe-west-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.eu-isoe-west-1.cloud.adc-e.uk",
+			},
+		},
+		{
+			name:   "aws-iso-f",
+			region: "us-isof-south-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.us-isof-south-1.csp.hci.ic.gov",
+			},
+		},
+		{
+			name:   "aws-eusc",
+			region: "eusc-de-east-1",
+			expectedEnv: envprovider.Environment{
+				"AWS_ENDPOINT_URL_STS": "https://sts.eusc-de-east-1.amazonaws.eu",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			provideCtx := baseProvideCtx
+			provideCtx.StsRegion = test.region
+			provideCtx.StsUseFIPSEndpoint = test.useFIPS
+			provideCtx.StsUseDualStackEndpoint = test.useDualStack
+
+			env, _, err := provider.Provide(context.Background(), provideCtx)
+			assert.NoError(t, err)
+
+			expectedEnv := envprovider.Environment{
+				"AWS_ROLE_ARN":                  testRoleARN,
+				"AWS_WEB_IDENTITY_TOKEN_FILE":   filepath.Join(testEnvPath, testSystemDPodLevelServiceAccountToken),
+				"UNSTABLE_MOUNTPOINT_CACHE_KEY": testPodNamespace + "/" + testPodServiceAccount,
+				"AWS_CONFIG_FILE":               "/test-env/disable-config",
+				"AWS_SHARED_CREDENTIALS_FILE":   "/test-env/disable-credentials",
+				"AWS_EC2_METADATA_DISABLED":     "true",
+				"AWS_REGION":                    test.region,
+				"AWS_DEFAULT_REGION":            test.region,
+			}
+			expectedEnv.Merge(test.expectedEnv)
+			assert.Equals(t, expectedEnv, env)
+		})
+	}
+
+	t.Run("endpoint from volume context", func(t *testing.T) {
+		provideCtx := baseProvideCtx
+		provideCtx.StsRegion = "us-gov-west-1"
+		provideCtx.StsEndpoint = "https://vpce-1a2b3c4d.sts.us-gov-west-1.vpce.amazonaws.com"
+
+		env, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, "https://vpce-1a2b3c4d.sts.us-gov-west-1.vpce.amazonaws.com", env["AWS_ENDPOINT_URL_STS"])
+		assert.Equals(t, "", env["AWS_USE_FIPS_ENDPOINT"])
+	})
+
+	t.Run("dual-stack in partition without dual-stack endpoints", func(t *testing.T) {
+		provideCtx := baseProvideCtx
+		provideCtx.StsRegion = "us-isob-east-1"
+		provideCtx.StsUseDualStackEndpoint = true
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
 func TestProvidingPodLevelCredentialsForDifferentPods(t *testing.T) {
 	testutil.CleanRegionEnv(t)
 
@@ -973,6 +1252,480 @@ func TestProvidingPodLevelCredentialsWithSlashInIDs(t *testing.T) {
 	})
 }
 
+func TestProvidingSecretCredentials(t *testing.T) {
+	testutil.CleanRegionEnv(t)
+
+	secretData := map[string]string{
+		"key_id":        testAccessKeyID,
+		"access_key":    testSecretAccessKey,
+		"session_token": testSessionToken,
+	}
+	secretEnv := envprovider.Environment{
+		"AWS_PROFILE":                 testPodMounterSecretProfilePrefix + "s3-csi",
+		"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterSecretProfilePrefix + "s3-csi-config",
+		"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterSecretProfilePrefix + "s3-csi-credentials",
+		"AWS_EC2_METADATA_DISABLED":   "true",
+	}
+
+	secretProvideCtx := func(writePath string) credentialprovider.ProvideContext {
+		return credentialprovider.ProvideContext{
+			AuthenticationSource: credentialprovider.AuthenticationSourceSecret,
+			WritePath:            writePath,
+			EnvPath:              testEnvPath,
+			WorkloadPodID:        testPodID,
+			MountpointPodID:      testMountpointPodID,
+			VolumeID:             testVolumeID,
+			MountKind:            credentialprovider.MountKindPod,
+			PodNamespace:         testPodNamespace,
+		}
+	}
+
+	t.Run("secret from nodePublishSecretRef", func(t *testing.T) {
+		// Driver-level credentials should be ignored
+		setEnvForLongTermCredentials(t)
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := secretProvideCtx(writePath)
+		provideCtx.Secrets = secretData
+
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourceSecret, source)
+		assert.Equals(t, secretEnv, env)
+		assertLongTermCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+	})
+
+	t.Run("secret from volume attributes", func(t *testing.T) {
+		for _, tc := range []struct {
+			name            string
+			secretNamespace string
+		}{
+			{name: "defaults to pod namespace", secretNamespace: ""},
+			{name: "explicit namespace", secretNamespace: "secret-ns"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				namespace := cmp.Or(tc.secretNamespace, testPodNamespace)
+				clientset := fake.NewSimpleClientset(secret("s3-credentials", namespace, secretData))
+				provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+				writePath := t.TempDir()
+				provideCtx := secretProvideCtx(writePath)
+				provideCtx.SecretName = "s3-credentials"
+				provideCtx.SecretNamespace = tc.secretNamespace
+
+				env, source, err := provider.Provide(context.Background(), provideCtx)
+				assert.NoError(t, err)
+				assert.Equals(t, credentialprovider.AuthenticationSourceSecret, source)
+				assert.Equals(t, secretEnv, env)
+				assertLongTermCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+			})
+		}
+	})
+
+	t.Run("rotates credentials once the secret changes", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(secret("s3-credentials", testPodNamespace, map[string]string{
+			"key_id":     "old-access-key-id",
+			"access_key": "old-secret-access-key",
+		}))
+		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := secretProvideCtx(writePath)
+		provideCtx.SecretName = "s3-credentials"
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+
+		_, err = clientset.CoreV1().Secrets(testPodNamespace).Update(context.Background(), secret("s3-credentials", testPodNamespace, secretData), metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		_, _, err = provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertLongTermCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+	})
+
+	t.Run("trims whitespace from secret values", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := secretProvideCtx(writePath)
+		provideCtx.Secrets = map[string]string{
+			"key_id":        testAccessKeyID + "\n",
+			"access_key":    testSecretAccessKey + "\n",
+			"session_token": testSessionToken + "\n",
+		}
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertLongTermCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+	})
+
+	t.Run("fails without a secret", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		_, _, err := provider.Provide(context.Background(), secretProvideCtx(t.TempDir()))
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("fails with missing keys", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		provideCtx := secretProvideCtx(t.TempDir())
+		provideCtx.Secrets = map[string]string{"key_id": testAccessKeyID}
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("fails if the secret does not exist", func(t *testing.T) {
+		provider := credentialprovider.New(fake.NewSimpleClientset().CoreV1(), dummyRegionProvider)
+
+		provideCtx := secretProvideCtx(t.TempDir())
+		provideCtx.SecretName = "s3-credentials"
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("inline volumes cannot look up secrets", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(secret("s3-credentials", testPodNamespace, secretData))
+		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+		provideCtx := secretProvideCtx(t.TempDir())
+		provideCtx.SecretName = "s3-credentials"
+		provideCtx.PodNamespace = testPodNamespace
+		provideCtx.InlineVolume = true
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestProvidingCredentialsAssumingRole(t *testing.T) {
+	provider := credentialprovider.New(nil, dummyRegionProvider)
+
+	assumeRoleProvideCtx := func(writePath, authSource string) credentialprovider.ProvideContext {
+		return credentialprovider.ProvideContext{
+			AuthenticationSource:  authSource,
+			WritePath:             writePath,
+			EnvPath:               testEnvPath,
+			WorkloadPodID:         testPodID,
+			MountpointPodID:       testMountpointPodID,
+			VolumeID:              testVolumeID,
+			MountKind:             credentialprovider.MountKindPod,
+			AssumeRoleARN:         testAssumeRoleARN,
+			AssumeRoleExternalID:  testAssumeRoleExternalID,
+			AssumeRoleSessionName: testAssumeRoleSessionName,
+		}
+	}
+	roleProfile := "[profile " + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role]\n"
+	roleSettings := "role_arn=" + testAssumeRoleARN + "\n" +
+		"external_id=" + testAssumeRoleExternalID + "\n" +
+		"role_session_name=" + testAssumeRoleSessionName + "\n"
+
+	t.Run("driver-level long-term credentials", func(t *testing.T) {
+		setEnvForLongTermCredentials(t)
+
+		writePath := t.TempDir()
+		env, source, err := provider.Provide(context.Background(), assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourceDriver, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+			"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterProfilePrefix + "s3-csi-credentials",
+		}, env)
+		assertLongTermCredentials(t, writePath, testPodMounterProfilePrefix)
+		assertAssumeRoleConfigFile(t, writePath, roleProfile+
+			"source_profile="+testPodMounterProfilePrefix+"s3-csi\n"+
+			roleSettings)
+	})
+
+	t.Run("driver-level sts web identity credentials", func(t *testing.T) {
+		setEnvForStsWebIdentityCredentials(t)
+
+		writePath := t.TempDir()
+		env, _, err := provider.Provide(context.Background(), assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":     testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE": "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+		}, env)
+		assertWebIdentityTokenFile(t, filepath.Join(writePath, testWebIdentityServiceAccountToken))
+		assertAssumeRoleConfigFile(t, writePath, "[profile "+testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-source]\n"+
+			"role_arn="+testRoleARN+"\n"+
+			"web_identity_token_file="+filepath.Join(testEnvPath, testWebIdentityServiceAccountToken)+"\n"+
+			roleProfile+
+			"source_profile="+testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-source\n"+
+			roleSettings)
+	})
+
+	t.Run("driver-level container credentials", func(t *testing.T) {
+		setEnvForContainerCredentials(t)
+
+		writePath := t.TempDir()
+		env, _, err := provider.Provide(context.Background(), assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE": filepath.Join(testEnvPath, testEKSPodIdentityServiceAccountToken),
+			"AWS_CONTAINER_CREDENTIALS_FULL_URI":     testContainerCredentialsFullURI,
+			"AWS_PROFILE":                            testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE":                        "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+		}, env)
+		assertAssumeRoleConfigFile(t, writePath, roleProfile+"credential_source=EcsContainer\n"+roleSettings)
+	})
+
+	t.Run("driver-level instance profile", func(t *testing.T) {
+		writePath := t.TempDir()
+		env, _, err := provider.Provide(context.Background(), assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":     testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE": "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+		}, env)
+		assertAssumeRoleConfigFile(t, writePath, roleProfile+"credential_source=Ec2InstanceMetadata\n"+roleSettings)
+	})
+
+	t.Run("pod-level sts web identity credentials", func(t *testing.T) {
+		testutil.CleanRegionEnv(t)
+
+		writePath := t.TempDir()
+		provideCtx := assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourcePod)
+		provideCtx.PodNamespace = testPodNamespace
+		provideCtx.ServiceAccountName = testPodServiceAccount
+		provideCtx.ServiceAccountEKSRoleARN = testRoleARN
+		provideCtx.ServiceAccountTokens = serviceAccountTokens(t, tokens{
+			serviceAccountTokenAudienceSTS: {Token: testWebIdentityToken},
+			serviceAccountTokenAudienceEKS: {Token: testContainerAuthorizationToken},
+		})
+
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourcePod, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":               testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE":           "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+			"AWS_EC2_METADATA_DISABLED": "true",
+			"AWS_REGION":                testIMDSRegion,
+			"AWS_DEFAULT_REGION":        testIMDSRegion,
+		}, env)
+		assertWebIdentityTokenFile(t, filepath.Join(writePath, testPodMounterPodLevelServiceAccountToken))
+		assertAssumeRoleConfigFile(t, writePath, "[profile "+testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-source]\n"+
+			"role_arn="+testRoleARN+"\n"+
+			"web_identity_token_file="+filepath.Join(testEnvPath, testPodMounterPodLevelServiceAccountToken)+"\n"+
+			roleProfile+
+			"source_profile="+testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-source\n"+
+			roleSettings)
+	})
+
+	t.Run("secret credentials", func(t *testing.T) {
+		writePath := t.TempDir()
+		provideCtx := assumeRoleProvideCtx(writePath, credentialprovider.AuthenticationSourceSecret)
+		provideCtx.Secrets = map[string]string{
+			"key_id":        testAccessKeyID,
+			"access_key":    testSecretAccessKey,
+			"session_token": testSessionToken,
+		}
+
+		env, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testPodMounterAssumeRoleProfilePrefix + "s3-csi-role",
+			"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterAssumeRoleProfilePrefix + "s3-csi-role-config",
+			"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterSecretProfilePrefix + "s3-csi-credentials",
+			"AWS_EC2_METADATA_DISABLED":   "true",
+		}, env)
+		assertAssumeRoleConfigFile(t, writePath, roleProfile+
+			"source_profile="+testPodMounterSecretProfilePrefix+"s3-csi\n"+
+			roleSettings)
+	})
+
+	t.Run("driver-level profile provider (SystemD)", func(t *testing.T) {
+		t.Setenv("AWS_CONFIG_FILE", "/root/.aws/config")
+		t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/root/.aws/credentials")
+
+		provideCtx := assumeRoleProvideCtx(t.TempDir(), credentialprovider.AuthenticationSourceDriver)
+		provideCtx.MountKind = credentialprovider.MountKindSystemd
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Expected InvalidArgument error, got %v", err)
+		}
+	})
+}
+
+func TestProvidingRolesAnywhereCredentials(t *testing.T) {
+	testutil.CleanRegionEnv(t)
+
+	rolesAnywhereProvideCtx := func(writePath, authSource string) credentialprovider.ProvideContext {
+		return credentialprovider.ProvideContext{
+			AuthenticationSource: authSource,
+			WritePath:            writePath,
+			EnvPath:              testEnvPath,
+			WorkloadPodID:        testPodID,
+			MountpointPodID:      testMountpointPodID,
+			VolumeID:             testVolumeID,
+			MountKind:            credentialprovider.MountKindPod,
+			PodNamespace:         testPodNamespace,
+		}
+	}
+
+	t.Run("driver-level credentials", func(t *testing.T) {
+		setEnvForRolesAnywhereCredentials(t)
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		writePath := t.TempDir()
+		env, source, err := provider.Provide(context.Background(), rolesAnywhereProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourceDriver, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testPodMounterProfilePrefix + "s3-csi",
+			"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterProfilePrefix + "s3-csi-config",
+			"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterProfilePrefix + "s3-csi-credentials",
+		}, env)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterProfilePrefix)
+	})
+
+	t.Run("driver-level long-term credentials take precedence", func(t *testing.T) {
+		setEnvForRolesAnywhereCredentials(t)
+		setEnvForLongTermCredentials(t)
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		writePath := t.TempDir()
+		_, _, err := provider.Provide(context.Background(), rolesAnywhereProvideCtx(writePath, credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assertLongTermCredentials(t, writePath, testPodMounterProfilePrefix)
+	})
+
+	t.Run("secret credentials", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(secret("s3-certificate", testPodNamespace, map[string]string{
+			"tls.crt": "old-certificate",
+			"tls.key": "old-private-key",
+		}))
+		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := rolesAnywhereProvideCtx(writePath, credentialprovider.AuthenticationSourceSecret)
+		provideCtx.SecretName = "s3-certificate"
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourceSecret, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testPodMounterSecretProfilePrefix + "s3-csi",
+			"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterSecretProfilePrefix + "s3-csi-config",
+			"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterSecretProfilePrefix + "s3-csi-credentials",
+			"AWS_EC2_METADATA_DISABLED":   "true",
+		}, env)
+
+		// Rotates the certificate once the secret changes
+		_, err = clientset.CoreV1().Secrets(testPodNamespace).Update(context.Background(), secret("s3-certificate", testPodNamespace, map[string]string{
+			"tls.crt": testRolesAnywhereCertificate,
+			"tls.key": testRolesAnywherePrivateKey,
+		}), metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		_, _, err = provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+	})
+
+	t.Run("secret without certificate", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		provideCtx := rolesAnywhereProvideCtx(t.TempDir(), credentialprovider.AuthenticationSourceSecret)
+		provideCtx.Secrets = map[string]string{"key_id": testAccessKeyID, "access_key": testSecretAccessKey}
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("secret credentials (SystemD)", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		provideCtx := rolesAnywhereProvideCtx(t.TempDir(), credentialprovider.AuthenticationSourceSecret)
+		provideCtx.MountKind = credentialprovider.MountKindSystemd
+		provideCtx.Secrets = map[string]string{"tls.crt": testRolesAnywhereCertificate, "tls.key": testRolesAnywherePrivateKey}
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("pod-level credentials", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			secret("s3-certificate", testPodNamespace, map[string]string{
+				"tls.crt": testRolesAnywhereCertificate,
+				"tls.key": testRolesAnywherePrivateKey,
+			}),
+			secret("s3-certificate", "other-ns", map[string]string{
+				"tls.crt": "other-certificate",
+				"tls.key": "other-private-key",
+			}),
+		)
+		provider := credentialprovider.New(clientset.CoreV1(), dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := rolesAnywhereProvideCtx(writePath, credentialprovider.AuthenticationSourcePod)
+		provideCtx.SecretName = "s3-certificate"
+		// The Secret is always looked up in the workload's namespace
+		provideCtx.SecretNamespace = "other-ns"
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourcePod, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testPodMounterPodRolesAnywhereProfilePrefix + "s3-csi",
+			"AWS_CONFIG_FILE":             "/test-env/" + testPodMounterPodRolesAnywhereProfilePrefix + "s3-csi-config",
+			"AWS_SHARED_CREDENTIALS_FILE": "/test-env/" + testPodMounterPodRolesAnywhereProfilePrefix + "s3-csi-credentials",
+			"AWS_EC2_METADATA_DISABLED":   "true",
+		}, env)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterPodRolesAnywhereProfilePrefix)
+	})
+
+	t.Run("pod-level credentials of inline volumes", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		writePath := t.TempDir()
+		provideCtx := rolesAnywhereProvideCtx(writePath, credentialprovider.AuthenticationSourcePod)
+		provideCtx.InlineVolume = true
+		provideCtx.Secrets = map[string]string{"tls.crt": testRolesAnywhereCertificate, "tls.key": testRolesAnywherePrivateKey}
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterPodRolesAnywhereProfilePrefix)
+	})
+
+	t.Run("pod-level credentials without secret name", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+
+		// `nodePublishSecretRef` of PersistentVolumes is not resolved in the workload's namespace
+		provideCtx := rolesAnywhereProvideCtx(t.TempDir(), credentialprovider.AuthenticationSourcePod)
+		provideCtx.Secrets = map[string]string{"tls.crt": testRolesAnywhereCertificate, "tls.key": testRolesAnywherePrivateKey}
+		provideCtx.RolesAnywhereTrustAnchorARN = testRolesAnywhereTrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = testRolesAnywhereProfileARN
+		provideCtx.RolesAnywhereRoleARN = testRoleARN
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
 func TestCleanup(t *testing.T) {
 	testutil.CleanRegionEnv(t)
 
@@ -1024,6 +1777,66 @@ func TestCleanup(t *testing.T) {
 		assert.Equals(t, fs.ErrNotExist, err)
 	})
 
+	t.Run("cleanup secret credentials", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		writePath := t.TempDir()
+		provideCtx := credentialprovider.ProvideContext{
+			AuthenticationSource: credentialprovider.AuthenticationSourceSecret,
+			WritePath:            writePath,
+			EnvPath:              testEnvPath,
+			WorkloadPodID:        testPodID,
+			MountpointPodID:      testMountpointPodID,
+			VolumeID:             testVolumeID,
+			MountKind:            credentialprovider.MountKindPod,
+			Secrets: map[string]string{
+				"key_id":        testAccessKeyID,
+				"access_key":    testSecretAccessKey,
+				"session_token": testSessionToken,
+			},
+		}
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertLongTermCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testMountpointPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(writePath, testPodMounterSecretProfilePrefix+"s3-csi-config"))
+		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+		_, err = os.Stat(filepath.Join(writePath, testPodMounterSecretProfilePrefix+"s3-csi-credentials"))
+		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("cleanup assume role profile", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		writePath := t.TempDir()
+		provideCtx := provideCtx(t, writePath, credentialprovider.AuthenticationSourceDriver)
+		provideCtx.MountpointPodID = testMountpointPodID
+		provideCtx.AssumeRoleARN = testAssumeRoleARN
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(writePath, testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-config"))
+		assert.NoError(t, err)
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testMountpointPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(writePath, testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-config"))
+		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+	})
+
 	t.Run("cleanup driver level sts web identity token (PodMounter)", func(t *testing.T) {
 		setEnvForStsWebIdentityCredentials(t)
 		provider := credentialprovider.New(nil, dummyRegionProvider)
@@ -1187,6 +2000,77 @@ func TestCleanup(t *testing.T) {
 		assert.Equals(t, fs.ErrNotExist, err)
 	})
 
+	t.Run("cleanup secret IAM Roles Anywhere credentials", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		writePath := t.TempDir()
+		provideCtx := credentialprovider.ProvideContext{
+			AuthenticationSource:        credentialprovider.AuthenticationSourceSecret,
+			WritePath:                   writePath,
+			EnvPath:                     testEnvPath,
+			WorkloadPodID:               testPodID,
+			MountpointPodID:             testMountpointPodID,
+			VolumeID:                    testVolumeID,
+			MountKind:                   credentialprovider.MountKindPod,
+			Secrets:                     map[string]string{"tls.crt": testRolesAnywhereCertificate, "tls.key": testRolesAnywherePrivateKey},
+			RolesAnywhereTrustAnchorARN: testRolesAnywhereTrustAnchorARN,
+			RolesAnywhereProfileARN:     testRolesAnywhereProfileARN,
+			RolesAnywhereRoleARN:        testRoleARN,
+		}
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterSecretProfilePrefix)
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testMountpointPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+
+		for _, filename := range []string{"s3-csi-config", "s3-csi-credentials", "roles-anywhere.crt", "roles-anywhere.key"} {
+			_, err = os.Stat(filepath.Join(writePath, testPodMounterSecretProfilePrefix+filename))
+			assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+		}
+	})
+
+	t.Run("cleanup pod-level IAM Roles Anywhere credentials", func(t *testing.T) {
+		provider := credentialprovider.New(nil, dummyRegionProvider)
+		writePath := t.TempDir()
+		provideCtx := credentialprovider.ProvideContext{
+			AuthenticationSource:        credentialprovider.AuthenticationSourcePod,
+			WritePath:                   writePath,
+			EnvPath:                     testEnvPath,
+			WorkloadPodID:               testPodID,
+			MountpointPodID:             testMountpointPodID,
+			VolumeID:                    testVolumeID,
+			MountKind:                   credentialprovider.MountKindPod,
+			InlineVolume:                true,
+			Secrets:                     map[string]string{"tls.crt": testRolesAnywhereCertificate, "tls.key": testRolesAnywherePrivateKey},
+			RolesAnywhereTrustAnchorARN: testRolesAnywhereTrustAnchorARN,
+			RolesAnywhereProfileARN:     testRolesAnywhereProfileARN,
+			RolesAnywhereRoleARN:        testRoleARN,
+		}
+
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertRolesAnywhereCredentials(t, writePath, testPodMounterPodRolesAnywhereProfilePrefix)
+
+		err = provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testMountpointPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+
+		for _, filename := range []string{"s3-csi-config", "s3-csi-credentials", "roles-anywhere.crt", "roles-anywhere.key"} {
+			_, err = os.Stat(filepath.Join(writePath, testPodMounterPodRolesAnywhereProfilePrefix+filename))
+			assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+		}
+	})
+
 	t.Run("cleanup with non-existent files", func(t *testing.T) {
 		writePath := t.TempDir()
 		provider := credentialprovider.New(nil, dummyRegionProvider)
@@ -1263,8 +2147,9 @@ func TestProvideValidations(t *testing.T) {
 			t.Fatal("Expected error when authentication source is unknown, but got nil")
 		}
 
-		expectedErrMsg := "unknown `authenticationSource`: unknown, only `driver` (default option if not specified) and `pod` supported"
-		if err.Error() != expectedErrMsg {
+		expectedErrMsg := "unknown `authenticationSource`: unknown, only `driver` (default option if not specified), `pod` and `secret` supported"
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		if status.Convert(err).Message() != expectedErrMsg {
 			t.Errorf("Expected error message %q, but got %q", expectedErrMsg, err.Error())
 		}
 	})
@@ -1337,6 +2222,54 @@ func assertLongTermCredentials(t *testing.T, basepath, prefix string) {
 	)
 }
 
+func assertAssumeRoleConfigFile(t *testing.T, basepath, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(filepath.Join(basepath, testPodMounterAssumeRoleProfilePrefix+"s3-csi-role-config"))
+	assert.NoError(t, err)
+	assert.Equals(t, want, string(got))
+}
+
+func setEnvForRolesAnywhereCredentials(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	certificatePath, privateKeyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	assert.NoError(t, os.WriteFile(certificatePath, []byte(testRolesAnywhereCertificate), 0600))
+	assert.NoError(t, os.WriteFile(privateKeyPath, []byte(testRolesAnywherePrivateKey), 0600))
+
+	t.Setenv("ROLES_ANYWHERE_TRUST_ANCHOR_ARN", testRolesAnywhereTrustAnchorARN)
+	t.Setenv("ROLES_ANYWHERE_PROFILE_ARN", testRolesAnywhereProfileARN)
+	t.Setenv("ROLES_ANYWHERE_ROLE_ARN", testRoleARN)
+	t.Setenv("ROLES_ANYWHERE_CERTIFICATE_FILE", certificatePath)
+	t.Setenv("ROLES_ANYWHERE_PRIVATE_KEY_FILE", privateKeyPath)
+}
+
+func assertRolesAnywhereCredentials(t *testing.T, basepath, prefix string) {
+	t.Helper()
+
+	for filename, want := range map[string]string{
+		prefix + "roles-anywhere.crt": testRolesAnywhereCertificate,
+		prefix + "roles-anywhere.key": testRolesAnywherePrivateKey,
+		prefix + "s3-csi-credentials": "[" + prefix + "s3-csi]\n" +
+			"credential_process=" + credentialprovider.RolesAnywhereSigningHelperPath + " credential-process" +
+			" --certificate " + filepath.Join(testEnvPath, prefix+"roles-anywhere.crt") +
+			" --private-key " + filepath.Join(testEnvPath, prefix+"roles-anywhere.key") +
+			" --trust-anchor-arn " + testRolesAnywhereTrustAnchorARN +
+			" --profile-arn " + testRolesAnywhereProfileARN +
+			" --role-arn " + testRoleARN + "\n",
+	} {
+		path := filepath.Join(basepath, filename)
+		got, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equals(t, want, string(got))
+
+		stat, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.CredentialFilePerm, stat.Mode())
+	}
+}
+
 func setEnvForStsWebIdentityCredentials(t *testing.T) {
 	t.Helper()
 
@@ -1384,6 +2317,17 @@ func serviceAccountTokens(t *testing.T, tokens tokens) string {
 	return string(buf)
 }
 
+func secret(name, namespace string, data map[string]string) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
 func serviceAccount(name, namespace string, annotations map[string]string) *v1.ServiceAccount {
 	return &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
 		Name:        name,
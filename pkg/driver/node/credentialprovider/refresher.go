@@ -0,0 +1,296 @@
+package credentialprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+const (
+	// refreshCheckInterval is the interval to check whether any tracked credentials are due for refresh.
+	refreshCheckInterval = 30 * time.Second
+	// refreshAtLifetimeRatio is the ratio of token's lifetime after which it's refreshed, same as kubelet does for projected tokens.
+	refreshAtLifetimeRatio = 0.8
+	// refreshBeforeExpiry is the duration before expiry to refresh tokens that has no issued at time.
+	refreshBeforeExpiry = 5 * time.Minute
+)
+
+// A Refresher is a [ProviderInterface] that refreshes service account tokens of provided credentials in the background
+// before they expire, independent of kubelet calling `NodePublishVolume` periodically.
+//
+// It tracks the [ProvideContext] of each successful [Refresher.Provide] call that provided expiring tokens until
+// credentials are cleaned up with [Refresher.Cleanup]. For driver-level credentials, the driver's own service account
+// tokens are copied again. Pod-level credentials are only tracked to report their expiry, their tokens are refreshed
+// by kubelet passing fresh `csi.storage.k8s.io/serviceAccount.tokens` while republishing volumes, as `requiresRepublish`
+// is set. The driver does not request service account tokens itself, as that would require permission to request
+// tokens for any service account in the cluster.
+type Refresher struct {
+	provider *Provider
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[refreshKey]*refreshEntry
+}
+
+// refreshKey identifies credentials of a mount, the same way [Provider.Cleanup] does.
+type refreshKey struct {
+	writePath string
+	podID     string
+	volumeID  string
+}
+
+type refreshEntry struct {
+	provideCtx ProvideContext
+	issuedAt   time.Time
+	expiresAt  time.Time
+}
+
+// refreshAt returns the time `e` needs to be refreshed.
+func (e *refreshEntry) refreshAt() time.Time {
+	if e.issuedAt.IsZero() || !e.issuedAt.Before(e.expiresAt) {
+		return e.expiresAt.Add(-refreshBeforeExpiry)
+	}
+	lifetime := e.expiresAt.Sub(e.issuedAt)
+	return e.issuedAt.Add(time.Duration(float64(lifetime) * refreshAtLifetimeRatio))
+}
+
+// A CredentialsExpiry represents the expiry of credentials provided for a mount.
+type CredentialsExpiry struct {
+	PodID    string
+	VolumeID string
+	// ExpiresIn is the time until the earliest expiring service account token provided for the mount.
+	ExpiresIn time.Duration
+}
+
+// NewRefresher creates a new [Refresher] that provides credentials using `provider`.
+func NewRefresher(provider *Provider) *Refresher {
+	return &Refresher{
+		provider: provider,
+		now:      time.Now,
+		entries:  make(map[refreshKey]*refreshEntry),
+	}
+}
+
+// Provide provides credentials using the underlying [Provider] and starts tracking them for refreshing if they expire.
+func (r *Refresher) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, AuthenticationSource, error) {
+	env, source, err := r.provider.Provide(ctx, provideCtx)
+	if err != nil {
+		return env, source, err
+	}
+
+	r.track(provideCtx, source)
+	return env, source, nil
+}
+
+// Cleanup cleans up credentials using the underlying [Provider] and stops tracking them.
+func (r *Refresher) Cleanup(cleanupCtx CleanupContext) error {
+	r.mu.Lock()
+	delete(r.entries, refreshKey{cleanupCtx.WritePath, cleanupCtx.PodID, cleanupCtx.VolumeID})
+	r.mu.Unlock()
+
+	return r.provider.Cleanup(cleanupCtx)
+}
+
+// Expiries returns the expiry of credentials of each tracked mount.
+func (r *Refresher) Expiries() []CredentialsExpiry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	expiries := make([]CredentialsExpiry, 0, len(r.entries))
+	for key, entry := range r.entries {
+		expiries = append(expiries, CredentialsExpiry{
+			PodID:     key.podID,
+			VolumeID:  key.volumeID,
+			ExpiresIn: entry.expiresAt.Sub(now),
+		})
+	}
+	return expiries
+}
+
+// Run refreshes tracked credentials periodically until `stopCh` is closed.
+func (r *Refresher) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(refreshCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			r.Refresh(context.Background())
+		}
+	}
+}
+
+// Refresh refreshes tracked credentials that are due for refresh.
+func (r *Refresher) Refresh(ctx context.Context) {
+	now := r.now()
+
+	r.mu.Lock()
+	var due []refreshKey
+	for key, entry := range r.entries {
+		if !now.Before(entry.refreshAt()) {
+			due = append(due, key)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, key := range due {
+		r.refresh(ctx, key)
+	}
+}
+
+// refresh refreshes the credentials tracked with `key`.
+func (r *Refresher) refresh(ctx context.Context, key refreshKey) {
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	provideCtx := entry.provideCtx
+	if _, err := os.Stat(provideCtx.WritePath); errors.Is(err, fs.ErrNotExist) {
+		// The mount is gone without cleaning up its credentials, e.g., the Mountpoint Pod is deleted
+		klog.V(4).Infof("credentialprovider: Credentials directory %q is gone, stopped refreshing credentials for volume %q", provideCtx.WritePath, provideCtx.VolumeID)
+		r.untrack(key, entry)
+		return
+	}
+
+	if provideCtx.AuthenticationSource == AuthenticationSourcePod {
+		// Pod-level tokens can only be refreshed by kubelet republishing the volume
+		klog.V(4).Infof("credentialprovider: Pod-level credentials for volume %q expire in %v, waiting for kubelet to republish the volume", provideCtx.VolumeID, entry.expiresAt.Sub(r.now()).Round(time.Second))
+		return
+	}
+
+	if _, _, err := r.Provide(ctx, provideCtx); err != nil {
+		klog.Errorf("credentialprovider: Failed to refresh credentials for volume %q: %v", provideCtx.VolumeID, err)
+		return
+	}
+
+	klog.V(4).Infof("credentialprovider: Refreshed credentials for volume %q", provideCtx.VolumeID)
+}
+
+// track starts tracking `provideCtx` if it provided expiring tokens, or stops tracking it otherwise.
+func (r *Refresher) track(provideCtx ProvideContext, source AuthenticationSource) {
+	key := refreshKey{provideCtx.WritePath, provideCtx.GetCredentialPodID(), provideCtx.VolumeID}
+	issuedAt, expiresAt, ok := providedTokensExpiry(provideCtx, source)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !ok {
+		delete(r.entries, key)
+		return
+	}
+
+	provideCtx.AuthenticationSource = source
+	r.entries[key] = &refreshEntry{
+		provideCtx: provideCtx,
+		issuedAt:   issuedAt,
+		expiresAt:  expiresAt,
+	}
+	klog.V(4).Infof("credentialprovider: Credentials for volume %q expire in %v", provideCtx.VolumeID, expiresAt.Sub(r.now()).Round(time.Second))
+}
+
+// untrack stops tracking `key` if it's still tracked with `entry`.
+func (r *Refresher) untrack(key refreshKey, entry *refreshEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries[key] == entry {
+		delete(r.entries, key)
+	}
+}
+
+// providedTokensExpiry returns the issued at and expiry time of the earliest expiring service account token
+// provided for `provideCtx`. It returns false if no expiring tokens were provided.
+func providedTokensExpiry(provideCtx ProvideContext, source AuthenticationSource) (time.Time, time.Time, bool) {
+	var tokens []serviceAccountToken
+	switch source {
+	case AuthenticationSourcePod:
+		if provideCtx.RolesAnywhereTrustAnchorARN != "" {
+			// Service account tokens are not used with pod-level IAM Roles Anywhere
+			return time.Time{}, time.Time{}, false
+		}
+		var podTokens map[string]*serviceAccountToken
+		if err := json.Unmarshal([]byte(provideCtx.ServiceAccountTokens), &podTokens); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		for _, token := range podTokens {
+			if token != nil {
+				tokens = append(tokens, *token)
+			}
+		}
+	case AuthenticationSourceDriver:
+		if provideCtx.IsSystemDMountpoint() {
+			// SystemD mounts use driver's service account token directly
+			return time.Time{}, time.Time{}, false
+		}
+		for _, env := range []string{envprovider.EnvWebIdentityTokenFile, envprovider.EnvContainerAuthorizationTokenFile} {
+			path := os.Getenv(env)
+			if path == "" {
+				continue
+			}
+			token, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			tokens = append(tokens, serviceAccountToken{Token: string(token)})
+		}
+	}
+
+	var issuedAt, expiresAt time.Time
+	for _, token := range tokens {
+		iat, exp := tokenClaims(token.Token)
+		if exp.IsZero() {
+			exp = token.ExpirationTimestamp
+		}
+		if exp.IsZero() {
+			continue
+		}
+		if expiresAt.IsZero() || exp.Before(expiresAt) {
+			issuedAt, expiresAt = iat, exp
+		}
+	}
+
+	return issuedAt, expiresAt, !expiresAt.IsZero()
+}
+
+// tokenClaims returns `iat` and `exp` claims of the JWT `token`, or zero times if they can't be parsed.
+func tokenClaims(token string) (time.Time, time.Time) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}, time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+
+	var claims struct {
+		IssuedAt  int64 `json:"iat"`
+		ExpiresAt int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == 0 {
+		return time.Time{}, time.Time{}
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != 0 {
+		issuedAt = time.Unix(claims.IssuedAt, 0)
+	}
+	return issuedAt, time.Unix(claims.ExpiresAt, 0)
+}
@@ -0,0 +1,160 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testPodName = "test-pod"
+
+func TestRefreshingPodLevelCredentials(t *testing.T) {
+	testutil.CleanRegionEnv(t)
+
+	expiringToken := jwt(t, time.Now().Add(-50*time.Minute), time.Now().Add(10*time.Minute))
+	freshToken := jwt(t, time.Now(), time.Now().Add(time.Hour))
+
+	refreshingProvideCtx := func(writePath string, token string) credentialprovider.ProvideContext {
+		return credentialprovider.ProvideContext{
+			AuthenticationSource:     credentialprovider.AuthenticationSourcePod,
+			WritePath:                writePath,
+			EnvPath:                  testEnvPath,
+			WorkloadPodID:            testPodID,
+			MountpointPodID:          testMountpointPodID,
+			VolumeID:                 testVolumeID,
+			PodName:                  testPodName,
+			PodNamespace:             testPodNamespace,
+			ServiceAccountName:       testPodServiceAccount,
+			ServiceAccountEKSRoleARN: testRoleARN,
+			ServiceAccountTokens: serviceAccountTokens(t, tokens{
+				serviceAccountTokenAudienceSTS: {Token: token},
+				serviceAccountTokenAudienceEKS: {Token: token},
+			}),
+			MountKind: credentialprovider.MountKindPod,
+		}
+	}
+
+	t.Run("tracks expiry of tokens until kubelet republishes the volume", func(t *testing.T) {
+		refresher := credentialprovider.NewRefresher(credentialprovider.New(nil, dummyRegionProvider))
+
+		writePath := t.TempDir()
+		_, _, err := refresher.Provide(context.Background(), refreshingProvideCtx(writePath, expiringToken))
+		assert.NoError(t, err)
+		assertExpiresWithin(t, refresher, 9*time.Minute, 10*time.Minute)
+
+		// The driver does not request service account tokens itself
+		refresher.Refresh(context.Background())
+		got, err := os.ReadFile(filepath.Join(writePath, testPodMounterPodLevelServiceAccountToken))
+		assert.NoError(t, err)
+		assert.Equals(t, expiringToken, string(got))
+
+		// Kubelet republishes the volume with fresh tokens
+		_, _, err = refresher.Provide(context.Background(), refreshingProvideCtx(writePath, freshToken))
+		assert.NoError(t, err)
+		got, err = os.ReadFile(filepath.Join(writePath, testPodMounterPodLevelServiceAccountToken))
+		assert.NoError(t, err)
+		assert.Equals(t, freshToken, string(got))
+		assertExpiresWithin(t, refresher, 59*time.Minute, time.Hour)
+	})
+
+	t.Run("stops tracking credentials once cleaned up", func(t *testing.T) {
+		refresher := credentialprovider.NewRefresher(credentialprovider.New(nil, dummyRegionProvider))
+
+		writePath := t.TempDir()
+		_, _, err := refresher.Provide(context.Background(), refreshingProvideCtx(writePath, expiringToken))
+		assert.NoError(t, err)
+		assert.Equals(t, 1, len(refresher.Expiries()))
+
+		err = refresher.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testMountpointPodID,
+			VolumeID:  testVolumeID,
+			MountKind: credentialprovider.MountKindPod,
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(refresher.Expiries()))
+	})
+
+	t.Run("stops tracking credentials once credentials directory is gone", func(t *testing.T) {
+		refresher := credentialprovider.NewRefresher(credentialprovider.New(nil, dummyRegionProvider))
+
+		writePath := filepath.Join(t.TempDir(), "credentials")
+		assert.NoError(t, os.Mkdir(writePath, credentialprovider.CredentialDirPerm))
+		_, _, err := refresher.Provide(context.Background(), refreshingProvideCtx(writePath, expiringToken))
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.RemoveAll(writePath))
+		refresher.Refresh(context.Background())
+
+		assert.Equals(t, 0, len(refresher.Expiries()))
+	})
+}
+
+func TestRefreshingDriverLevelCredentials(t *testing.T) {
+	testutil.CleanRegionEnv(t)
+
+	t.Run("copies driver's service account token again before it expires", func(t *testing.T) {
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		expiringToken := jwt(t, time.Now().Add(-50*time.Minute), time.Now().Add(10*time.Minute))
+		assert.NoError(t, os.WriteFile(tokenPath, []byte(expiringToken), 0600))
+		t.Setenv("AWS_ROLE_ARN", testRoleARN)
+		t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenPath)
+
+		refresher := credentialprovider.NewRefresher(credentialprovider.New(nil, dummyRegionProvider))
+
+		writePath := t.TempDir()
+		provideCtx := provideCtx(t, writePath, credentialprovider.AuthenticationSourceDriver)
+		_, _, err := refresher.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertExpiresWithin(t, refresher, 9*time.Minute, 10*time.Minute)
+
+		// Kubelet rotates driver's service account token
+		freshToken := jwt(t, time.Now(), time.Now().Add(time.Hour))
+		assert.NoError(t, os.WriteFile(tokenPath, []byte(freshToken), 0600))
+
+		refresher.Refresh(context.Background())
+
+		got, err := os.ReadFile(filepath.Join(writePath, testWebIdentityServiceAccountToken))
+		assert.NoError(t, err)
+		assert.Equals(t, freshToken, string(got))
+		assertExpiresWithin(t, refresher, 59*time.Minute, time.Hour)
+	})
+
+	t.Run("does not track long-term credentials", func(t *testing.T) {
+		setEnvForLongTermCredentials(t)
+		refresher := credentialprovider.NewRefresher(credentialprovider.New(nil, dummyRegionProvider))
+
+		_, _, err := refresher.Provide(context.Background(), provideCtx(t, t.TempDir(), credentialprovider.AuthenticationSourceDriver))
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(refresher.Expiries()))
+	})
+}
+
+// jwt returns an unsigned JWT with given `iat` and `exp` claims.
+func jwt(t *testing.T, issuedAt, expiresAt time.Time) string {
+	t.Helper()
+
+	claims, err := json.Marshal(map[string]int64{"iat": issuedAt.Unix(), "exp": expiresAt.Unix()})
+	assert.NoError(t, err)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString(claims) + ".signature"
+}
+
+func assertExpiresWithin(t *testing.T, refresher *credentialprovider.Refresher, min, max time.Duration) {
+	t.Helper()
+
+	expiries := refresher.Expiries()
+	assert.Equals(t, 1, len(expiries))
+	if expiries[0].ExpiresIn < min || expiries[0].ExpiresIn > max {
+		t.Fatalf("Expected credentials to expire in between %v and %v, but got %v", min, max, expiries[0].ExpiresIn)
+	}
+}
@@ -0,0 +1,129 @@
+package credentialprovider
+
+import (
+	"regexp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+const stsEndpointDocsPage = "https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CONFIGURATION.md#configuring-the-sts-endpoint"
+
+// A partition is a group of AWS regions sharing the same DNS suffixes for their endpoints.
+type partition struct {
+	name          string
+	regionPattern *regexp.Regexp
+	dnsSuffix     string
+	// dualStackDNSSuffix is empty if the partition doesn't support dual-stack endpoints.
+	dualStackDNSSuffix string
+}
+
+// awsPartition is the commercial partition, it's also used for regions not matching any known partition.
+var awsPartition = partition{
+	name:               "aws",
+	regionPattern:      regexp.MustCompile(`^(us|eu|ap|sa|ca|me|af|il|mx)-\w+-\d+$`),
+	dnsSuffix:          "amazonaws.com",
+	dualStackDNSSuffix: "api.aws",
+}
+
+// partitions is the list of known partitions other than [awsPartition].
+var partitions = []partition{
+	{
+		name:               "aws-cn",
+		regionPattern:      regexp.MustCompile(`^cn-\w+-\d+$`),
+		dnsSuffix:          "amazonaws.com.cn",
+		dualStackDNSSuffix: "api.amazonwebservices.com.cn",
+	},
+	{
+		name:               "aws-us-gov",
+		regionPattern:      regexp.MustCompile(`^us-gov-\w+-\d+$`),
+		dnsSuffix:          "amazonaws.com",
+		dualStackDNSSuffix: "api.aws",
+	},
+	{
+		name:          "aws-iso",
+		regionPattern: regexp.MustCompile(`^us-iso-\w+-\d+$`),
+		dnsSuffix:     "c2s.ic.gov",
+	},
+	{
+		name:          "aws-iso-b",
+		regionPattern: regexp.MustCompile(`^us-isob-\w+-\d+$`),
+		dnsSuffix:     "sc2s.sgov.gov",
+	},
+	{
+		name:          "aws-iso-e",
+		regionPattern: regexp.MustCompile(`^eu-isoe-\w+-\d+$`),
+		dnsSuffix:     "cloud.adc-e.uk",
+	},
+	{
+		name:          "aws-iso-f",
+		regionPattern: regexp.MustCompile(`^us-isof-\w+-\d+$`),
+		dnsSuffix:     "csp.hci.ic.gov",
+	},
+	{
+		name:          "aws-eusc",
+		regionPattern: regexp.MustCompile(`^eusc-(de)-\w+-\d+$`),
+		dnsSuffix:     "amazonaws.eu",
+	},
+}
+
+// partitionOf returns the partition of `region`, or [awsPartition] if `region` doesn't match any known partition.
+func partitionOf(region string) partition {
+	for _, p := range partitions {
+		if p.regionPattern.MatchString(region) {
+			return p
+		}
+	}
+	return awsPartition
+}
+
+// stsEndpointEnvironment returns the environment variables configuring the STS endpoint Mountpoint uses
+// to obtain pod-level credentials in the STS region `region`.
+//
+// It uses the following (in-order):
+//  1. `stsEndpoint` passed via volume context as-is
+//  2. The STS endpoint in the partition of `region`, using FIPS and/or dual-stack endpoints if
+//     `useFipsEndpoint` and/or `useDualStackEndpoint` are passed via volume context
+//
+// It returns an empty environment for the commercial partition without FIPS and dual-stack endpoints,
+// as Mountpoint resolves the same STS endpoint by default.
+func stsEndpointEnvironment(region string, provideCtx ProvideContext) (envprovider.Environment, error) {
+	env := envprovider.Environment{}
+	if provideCtx.StsEndpoint != "" {
+		klog.V(5).Infof("credentialprovider: pod-level: Using STS endpoint %s from volume context", provideCtx.StsEndpoint)
+		env.Set(envprovider.EnvEndpointURLSTS, provideCtx.StsEndpoint)
+		return env, nil
+	}
+
+	useFIPS, useDualStack := provideCtx.StsUseFIPSEndpoint, provideCtx.StsUseDualStackEndpoint
+	p := partitionOf(region)
+	if p.name == awsPartition.name && !useFIPS && !useDualStack {
+		return env, nil
+	}
+
+	dnsSuffix := p.dnsSuffix
+	if useDualStack {
+		if p.dualStackDNSSuffix == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "Dual-stack STS endpoints are not supported in partition %s of region %s, see %s", p.name, region, stsEndpointDocsPage)
+		}
+		dnsSuffix = p.dualStackDNSSuffix
+		env.Set(envprovider.EnvUseDualStackEndpoint, "true")
+	}
+
+	service := "sts"
+	if useFIPS {
+		// Regional STS endpoints in AWS GovCloud (US) are already FIPS-compliant and there are no `sts-fips` endpoints
+		if p.name != "aws-us-gov" {
+			service = "sts-fips"
+		}
+		env.Set(envprovider.EnvUseFIPSEndpoint, "true")
+	}
+
+	endpoint := "https://" + service + "." + region + "." + dnsSuffix
+	klog.V(5).Infof("credentialprovider: pod-level: Resolved STS endpoint %s in partition %s", endpoint, p.name)
+	env.Set(envprovider.EnvEndpointURLSTS, endpoint)
+	return env, nil
+}
@@ -13,6 +13,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 )
 
 var errUnknownRegion = errors.New("credentialprovider: pod-level: unknown region")
@@ -23,10 +24,10 @@ var errUnknownRegion = errors.New("credentialprovider: pod-level: unknown region
 //  1. `stsRegion` passed via volume context
 //  2. Region set for S3 bucket via mount options
 //  3. `AWS_REGION` or `AWS_DEFAULT_REGION` env variables
-//  4. Calling IMDS to detect region
+//  4. Calling IMDS to detect region, unless the volume uses a non-AWS (e.g., S3-compatible) endpoint
 //
 // It returns an error if all of them fails.
-func (p *Provider) stsRegion(provideCtx ProvideContext) (string, error) {
+func (p *podPlugin) stsRegion(provideCtx ProvideContext) (string, error) {
 	region := provideCtx.StsRegion
 	if region != "" {
 		klog.V(5).Infof("credentialprovider: pod-level: Detected STS region %s from volume context", region)
@@ -51,6 +52,12 @@ func (p *Provider) stsRegion(provideCtx ProvideContext) (string, error) {
 		return region, nil
 	}
 
+	// The region of an S3-compatible endpoint has nothing to do with the region of the instance
+	if !volumecontext.IsAWSEndpoint(provideCtx.EndpointURL) {
+		klog.V(5).Infof("credentialprovider: pod-level: Skipping STS region detection from IMDS for non-AWS endpoint %s", provideCtx.EndpointURL)
+		return "", errUnknownRegion
+	}
+
 	// We're ignoring the error here, makes a call to IMDS only once and logs the error in case of error
 	region, _ = p.regionFromIMDS()
 	if region != "" {
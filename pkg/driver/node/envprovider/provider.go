@@ -13,6 +13,9 @@ const (
 	EnvRegion                          = "AWS_REGION"
 	EnvDefaultRegion                   = "AWS_DEFAULT_REGION"
 	EnvSTSRegionalEndpoints            = "AWS_STS_REGIONAL_ENDPOINTS"
+	EnvEndpointURLSTS                  = "AWS_ENDPOINT_URL_STS"
+	EnvUseFIPSEndpoint                 = "AWS_USE_FIPS_ENDPOINT"
+	EnvUseDualStackEndpoint            = "AWS_USE_DUALSTACK_ENDPOINT"
 	EnvMaxAttempts                     = "AWS_MAX_ATTEMPTS"
 	EnvProfile                         = "AWS_PROFILE"
 	EnvConfigFile                      = "AWS_CONFIG_FILE"
@@ -30,7 +33,8 @@ const (
 	EnvNoProxy                         = "NO_PROXY"
 )
 
-const mountpointEnvPrefix = "mountpointEnv."
+// MountpointEnvPrefix is the prefix of volume attributes configuring environment variables of Mountpoint.
+const MountpointEnvPrefix = "mountpointEnv."
 
 // Key represents an environment variable name.
 type Key = string
@@ -77,10 +81,12 @@ func Default() Environment {
 	return environment
 }
 
+// ParseUserEnvFromVolumeContext returns environment variables configured via [MountpointEnvPrefix] prefixed volume attributes in `volumeCtx`.
+// It returns an error if any of them is not allowed to be configured by users.
 func ParseUserEnvFromVolumeContext(volumeCtx map[string]string) (Environment, error) {
 	env := Environment{}
 	for key, value := range volumeCtx {
-		envName, ok := strings.CutPrefix(key, mountpointEnvPrefix)
+		envName, ok := strings.CutPrefix(key, MountpointEnvPrefix)
 		if !ok {
 			continue
 		}
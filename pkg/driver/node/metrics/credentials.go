@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+)
+
+var credentialsExpiryDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "credentials_expiry_seconds"),
+	"Time until the service account tokens provided for a mount expire in seconds, partitioned by workload Pod UID and volume ID. Negative if already expired.",
+	[]string{"pod_uid", "volume_id"}, nil,
+)
+
+// A CredentialsExpiriesFunc returns the expiry of credentials of each mount tracked for refreshing.
+type CredentialsExpiriesFunc func() []credentialprovider.CredentialsExpiry
+
+// credentialsCollector is a [prometheus.Collector] reporting the expiry of credentials provided for mounts.
+// Only mounts with expiring credentials (i.e., service account tokens) are reported.
+type credentialsCollector struct {
+	expiries CredentialsExpiriesFunc
+}
+
+// RegisterCredentialsCollector registers a collector reporting the expiry of credentials listed with `expiries`,
+// e.g., [credentialprovider.Refresher.Expiries].
+func RegisterCredentialsCollector(expiries CredentialsExpiriesFunc) error {
+	return Registry.Register(&credentialsCollector{expiries: expiries})
+}
+
+func (c *credentialsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- credentialsExpiryDesc
+}
+
+func (c *credentialsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, expiry := range c.expiries() {
+		ch <- prometheus.MustNewConstMetric(credentialsExpiryDesc, prometheus.GaugeValue, expiry.ExpiresIn.Seconds(), expiry.PodID, expiry.VolumeID)
+	}
+}
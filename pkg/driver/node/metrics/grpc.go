@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor is a [grpc.UnaryServerInterceptor] that records the count and latency of handled RPCs.
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	// `info.FullMethod` is in the form of "/csi.v1.Node/NodePublishVolume", only use the method name
+	method := path.Base(info.FullMethod)
+	rpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
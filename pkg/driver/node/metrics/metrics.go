@@ -0,0 +1,111 @@
+// Package metrics provides Prometheus metrics emitted by the CSI Driver Node.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "s3_csi_node"
+
+// Registry is the Prometheus registry containing all metrics of the CSI Driver Node.
+// It also contains Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+// A MountPhase represents a phase of mounting a volume with Mountpoint Pods.
+type MountPhase = string
+
+const (
+	// MountPhaseWaitForAttachment is the phase of waiting for the MountpointS3PodAttachment of the volume.
+	MountPhaseWaitForAttachment MountPhase = "wait_for_attachment"
+	// MountPhaseWaitForMountpointPod is the phase of waiting for the Mountpoint Pod to be running.
+	MountPhaseWaitForMountpointPod MountPhase = "wait_for_mountpoint_pod"
+	// MountPhaseProvideCredentials is the phase of providing AWS credentials to the Mountpoint Pod.
+	MountPhaseProvideCredentials MountPhase = "provide_credentials"
+	// MountPhaseMountSyscall is the phase of performing `mount` syscall to obtain the FUSE file descriptor.
+	MountPhaseMountSyscall MountPhase = "mount_syscall"
+	// MountPhaseSendOptions is the phase of sending mount options to the Mountpoint Pod.
+	MountPhaseSendOptions MountPhase = "send_options"
+	// MountPhaseWaitForMount is the phase of waiting for Mountpoint to start serving the mount.
+	MountPhaseWaitForMount MountPhase = "wait_for_mount"
+)
+
+// Results of cleanup operations.
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "Total number of CSI RPCs handled, partitioned by method and gRPC status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of CSI RPCs in seconds, partitioned by method.",
+		Buckets:   durationBuckets,
+	}, []string{"method"})
+
+	mountPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mount_phase_duration_seconds",
+		Help:      "Duration of phases of mounting a volume with Mountpoint Pods in seconds, partitioned by phase.",
+		Buckets:   durationBuckets,
+	}, []string{"phase"})
+
+	mountpointPodCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mountpoint_pod_cleanups_total",
+		Help:      "Total number of Mountpoint Pod cleanups (i.e., unmounting and removing credentials), partitioned by result.",
+	}, []string{"result"})
+
+	danglingMountCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dangling_mount_cleanups_total",
+		Help:      "Total number of cleanups of Mountpoint mounts without a corresponding Mountpoint Pod, partitioned by result.",
+	}, []string{"result"})
+)
+
+// durationBuckets are the histogram buckets for durations, ranging from 5ms to ~40s.
+// Mount operations are bounded by Mountpoint Pod scheduling and readiness, which can take tens of seconds.
+var durationBuckets = prometheus.ExponentialBuckets(0.005, 2, 14)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests,
+		rpcDuration,
+		mountPhaseDuration,
+		mountpointPodCleanups,
+		danglingMountCleanups,
+	)
+}
+
+// ObserveMountPhase records the duration of `phase` started at `start`.
+func ObserveMountPhase(phase MountPhase, start time.Time) {
+	mountPhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+// RecordMountpointPodCleanup records a Mountpoint Pod cleanup with given result.
+func RecordMountpointPodCleanup(err error) {
+	mountpointPodCleanups.WithLabelValues(resultOf(err)).Inc()
+}
+
+// RecordDanglingMountCleanup records a dangling Mountpoint mount cleanup with given result.
+func RecordDanglingMountCleanup(err error) {
+	danglingMountCleanups.WithLabelValues(resultOf(err)).Inc()
+}
+
+func resultOf(err error) string {
+	if err != nil {
+		return resultFailure
+	}
+	return resultSuccess
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	rpcRequests.Reset()
+	rpcDuration.Reset()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodePublishVolume"}
+	succeed := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	fail := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.InvalidArgument, "invalid")
+	}
+
+	resp, err := UnaryServerInterceptor(context.Background(), nil, info, succeed)
+	assert.NoError(t, err)
+	assert.Equals(t, "ok", resp)
+	_, err = UnaryServerInterceptor(context.Background(), nil, info, fail)
+	assert.Equals(t, codes.InvalidArgument, status.Code(err))
+
+	assert.Equals(t, float64(1), testutil.ToFloat64(rpcRequests.WithLabelValues("NodePublishVolume", "OK")))
+	assert.Equals(t, float64(1), testutil.ToFloat64(rpcRequests.WithLabelValues("NodePublishVolume", "InvalidArgument")))
+	assert.Equals(t, 1, testutil.CollectAndCount(rpcDuration))
+}
+
+func TestCleanupCounters(t *testing.T) {
+	mountpointPodCleanups.Reset()
+	danglingMountCleanups.Reset()
+
+	RecordMountpointPodCleanup(nil)
+	RecordMountpointPodCleanup(nil)
+	RecordMountpointPodCleanup(errors.New("failed"))
+	RecordDanglingMountCleanup(errors.New("failed"))
+
+	assert.Equals(t, float64(2), testutil.ToFloat64(mountpointPodCleanups.WithLabelValues(resultSuccess)))
+	assert.Equals(t, float64(1), testutil.ToFloat64(mountpointPodCleanups.WithLabelValues(resultFailure)))
+	assert.Equals(t, float64(1), testutil.ToFloat64(danglingMountCleanups.WithLabelValues(resultFailure)))
+}
+
+func TestMountsCollector(t *testing.T) {
+	sourceMountDir := "/var/lib/kubelet/plugins/s3.csi.aws.com/mnt"
+
+	t.Run("reports source and bind mounts", func(t *testing.T) {
+		collector := &mountsCollector{
+			listMountpoints: func() ([]string, error) {
+				return []string{
+					sourceMountDir + "/mp-pod-1",
+					sourceMountDir + "/mp-pod-2",
+					"/var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/s3-pv/mount",
+					"/var/lib/kubelet/plugins/s3.csi.aws.com/mnt-other/mp-pod-3",
+				}, nil
+			},
+			sourceMountDir: sourceMountDir,
+		}
+
+		expected := `
+# HELP s3_csi_node_active_mounts Number of active Mountpoint mounts on the node, partitioned by kind ("source" or "bind").
+# TYPE s3_csi_node_active_mounts gauge
+s3_csi_node_active_mounts{kind="bind"} 2
+s3_csi_node_active_mounts{kind="source"} 2
+`
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	})
+
+	t.Run("reports an error if listing mounts fails", func(t *testing.T) {
+		collector := &mountsCollector{
+			listMountpoints: func() ([]string, error) { return nil, errors.New("failed to read mountinfo") },
+			sourceMountDir:  sourceMountDir,
+		}
+
+		registry := prometheus.NewPedanticRegistry()
+		assert.NoError(t, registry.Register(collector))
+		_, err := registry.Gather()
+		if err == nil {
+			t.Fatalf("Expected an error from gathering metrics, but got nil")
+		}
+	})
+}
+
+func TestCredentialsCollector(t *testing.T) {
+	collector := &credentialsCollector{
+		expiries: func() []credentialprovider.CredentialsExpiry {
+			return []credentialprovider.CredentialsExpiry{
+				{PodID: "pod-1", VolumeID: "s3-pv-1", ExpiresIn: 30 * time.Minute},
+				{PodID: "pod-2", VolumeID: "s3-pv-2", ExpiresIn: -10 * time.Second},
+			}
+		},
+	}
+
+	expected := `
+# HELP s3_csi_node_credentials_expiry_seconds Time until the service account tokens provided for a mount expire in seconds, partitioned by workload Pod UID and volume ID. Negative if already expired.
+# TYPE s3_csi_node_credentials_expiry_seconds gauge
+s3_csi_node_credentials_expiry_seconds{pod_uid="pod-1",volume_id="s3-pv-1"} 1800
+s3_csi_node_credentials_expiry_seconds{pod_uid="pod-2",volume_id="s3-pv-2"} -10
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+)
+
+// Kinds of Mountpoint mounts.
+const (
+	// mountKindSource is a Mountpoint mount at the source mount directory, served by a Mountpoint Pod or the mounter DaemonSet.
+	mountKindSource = "source"
+	// mountKindBind is a Mountpoint mount anywhere else, i.e., bind mounts of sources at workloads' target paths.
+	mountKindBind = "bind"
+)
+
+var activeMountsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_mounts"),
+	"Number of active Mountpoint mounts on the node, partitioned by kind (\"source\" or \"bind\").",
+	[]string{"kind"}, nil,
+)
+
+// A ListMountpointsFunc returns paths of all Mountpoint mounts on the node.
+type ListMountpointsFunc func() ([]string, error)
+
+// mountsCollector is a [prometheus.Collector] reporting active Mountpoint mounts.
+// It lists mounts on each scrape rather than tracking them, so it also reports mounts created before the driver started.
+type mountsCollector struct {
+	listMountpoints ListMountpointsFunc
+	sourceMountDir  string
+}
+
+// RegisterMountsCollector registers a collector reporting active Mountpoint mounts listed with `listMountpoints`.
+// Mounts under `sourceMountDir` are reported as source mounts, and all others as bind mounts.
+func RegisterMountsCollector(listMountpoints ListMountpointsFunc, sourceMountDir string) error {
+	return Registry.Register(&mountsCollector{listMountpoints: listMountpoints, sourceMountDir: sourceMountDir})
+}
+
+func (c *mountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeMountsDesc
+}
+
+func (c *mountsCollector) Collect(ch chan<- prometheus.Metric) {
+	mountpoints, err := c.listMountpoints()
+	if err != nil {
+		klog.Errorf("metrics: Failed to list Mountpoint mounts: %v", err)
+		ch <- prometheus.NewInvalidMetric(activeMountsDesc, err)
+		return
+	}
+
+	var sources, binds int
+	for _, mountpoint := range mountpoints {
+		if isUnder(mountpoint, c.sourceMountDir) {
+			sources++
+		} else {
+			binds++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(activeMountsDesc, prometheus.GaugeValue, float64(sources), mountKindSource)
+	ch <- prometheus.MustNewConstMetric(activeMountsDesc, prometheus.GaugeValue, float64(binds), mountKindBind)
+}
+
+// isUnder returns whether `path` is located under `dir`.
+func isUnder(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../")
+}
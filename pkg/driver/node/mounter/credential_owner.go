@@ -0,0 +1,100 @@
+package mounter
+
+import (
+	"sync"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+)
+
+// A credentialWorkload is a workload mounting a volume served by a Mountpoint Pod,
+// along with everything needed to provide its credentials to the Mountpoint Pod.
+type credentialWorkload struct {
+	mpPodName                string
+	mpPodUID                 string
+	podPath                  string
+	serviceAccountEKSRoleARN string
+	s3pa                     *crdv2.MountpointS3PodAttachment
+	credentialCtx            credentialprovider.ProvideContext
+	// seq orders workloads by their last publish, so failing over prefers workloads with the most recent tokens.
+	seq uint64
+}
+
+// credentialOwners tracks workloads sharing Mountpoint Pods on this node, and which of them owns
+// the credentials provided to each Mountpoint Pod.
+//
+// The credentials directory of a Mountpoint Pod is shared by all workloads using it, so only the owner
+// refreshes the credentials, and once the owner detaches, another attached workload takes over.
+// The owner is also recorded in the status of MountpointS3PodAttachments to survive restarts of the CSI Driver Node.
+type credentialOwners struct {
+	mu  sync.Mutex
+	seq uint64
+	// targets maps target paths to workloads mounted at them.
+	targets map[string]*credentialWorkload
+	// owners maps Mountpoint Pod names to target paths of the workloads owning their credentials.
+	owners map[string]string
+}
+
+func newCredentialOwners() *credentialOwners {
+	return &credentialOwners{
+		targets: make(map[string]*credentialWorkload),
+		owners:  make(map[string]string),
+	}
+}
+
+// attach records `workload` mounted at `target`, and makes it the owner of the credentials of its Mountpoint Pod if `owner` is true.
+func (o *credentialOwners) attach(target string, workload *credentialWorkload, owner bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	workload.seq = o.seq
+	o.targets[target] = workload
+	if owner {
+		o.owners[workload.mpPodName] = target
+	}
+}
+
+// mountpointPodName returns the name of the Mountpoint Pod serving `target`, if known.
+func (o *credentialOwners) mountpointPodName(target string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	workload, ok := o.targets[target]
+	if !ok {
+		return "", false
+	}
+	return workload.mpPodName, true
+}
+
+// detach forgets the workload mounted at `target`. If it was owning the credentials of its Mountpoint Pod,
+// the ownership is transferred to the most recently published workload still attached to the same Mountpoint Pod,
+// and that workload is returned.
+func (o *credentialOwners) detach(target string) (*credentialWorkload, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	workload, ok := o.targets[target]
+	if !ok {
+		return nil, false
+	}
+	delete(o.targets, target)
+
+	if o.owners[workload.mpPodName] != target {
+		return nil, false
+	}
+	delete(o.owners, workload.mpPodName)
+
+	var nextTarget string
+	var next *credentialWorkload
+	for t, w := range o.targets {
+		if w.mpPodName == workload.mpPodName && (next == nil || w.seq > next.seq) {
+			nextTarget, next = t, w
+		}
+	}
+	if next == nil {
+		return nil, false
+	}
+	o.owners[workload.mpPodName] = nextTarget
+	return next, true
+}
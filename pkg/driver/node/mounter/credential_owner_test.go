@@ -0,0 +1,59 @@
+package mounter
+
+import (
+	"testing"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCredentialOwners(t *testing.T) {
+	workload := func(mpPodName, workloadPodID string) *credentialWorkload {
+		return &credentialWorkload{
+			mpPodName:     mpPodName,
+			credentialCtx: credentialprovider.ProvideContext{WorkloadPodID: workloadPodID},
+		}
+	}
+
+	t.Run("Fails over to the most recently attached workload of the same Mountpoint Pod", func(t *testing.T) {
+		owners := newCredentialOwners()
+		owners.attach("target-1", workload("mp-1", "workload-1"), true)
+		owners.attach("target-2", workload("mp-1", "workload-2"), false)
+		owners.attach("target-3", workload("mp-1", "workload-3"), false)
+		owners.attach("target-4", workload("mp-2", "workload-4"), true)
+
+		next, ok := owners.detach("target-1")
+		assert.Equals(t, true, ok)
+		assert.Equals(t, "workload-3", next.credentialCtx.WorkloadPodID)
+
+		// Detaching a workload not owning the credentials doesn't fail over
+		_, ok = owners.detach("target-2")
+		assert.Equals(t, false, ok)
+
+		// Nothing left to fail over to
+		_, ok = owners.detach("target-3")
+		assert.Equals(t, false, ok)
+		_, ok = owners.detach("target-4")
+		assert.Equals(t, false, ok)
+	})
+
+	t.Run("Republishing a workload makes it the most recent", func(t *testing.T) {
+		owners := newCredentialOwners()
+		owners.attach("target-1", workload("mp-1", "workload-1"), true)
+		owners.attach("target-2", workload("mp-1", "workload-2"), false)
+		owners.attach("target-3", workload("mp-1", "workload-3"), false)
+		owners.attach("target-2", workload("mp-1", "workload-2"), false)
+
+		next, ok := owners.detach("target-1")
+		assert.Equals(t, true, ok)
+		assert.Equals(t, "workload-2", next.credentialCtx.WorkloadPodID)
+	})
+
+	t.Run("Unknown targets", func(t *testing.T) {
+		owners := newCredentialOwners()
+		_, ok := owners.mountpointPodName("target-1")
+		assert.Equals(t, false, ok)
+		_, ok = owners.detach("target-1")
+		assert.Equals(t, false, ok)
+	})
+}
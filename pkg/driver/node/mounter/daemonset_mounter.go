@@ -0,0 +1,525 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/renameio"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	mpmounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// These need to be in sync with `cmd/aws-s3-csi-daemonset-mounter`.
+const (
+	daemonSetMountSockName = "mount.sock"
+	daemonSetErrorFileExt  = ".error"
+)
+
+const caBundleFileExt = ".crt"
+
+const (
+	daemonSetCredentialsDir   = "credentials"
+	daemonSetCABundlesDir     = "ca-bundles"
+	daemonSetMountWaitTimeout = 30 * time.Second
+)
+
+// A DaemonSetMounter is a [Mounter] that mounts Mountpoint on the mounter DaemonSet Pod running in the same node.
+//
+// Unlike [PodMounter], it does not require the controller, MountpointS3PodAttachment custom resources or
+// Mountpoint Pods. The mounter DaemonSet Pod spawns a Mountpoint process for each mount,
+// and the communication happens via a directory shared between the CSI Driver Node Pod and the mounter DaemonSet Pod.
+type DaemonSetMounter struct {
+	mount             *mpmounter.Mounter
+	credProvider      credentialprovider.ProviderInterface
+	client            k8sv1.CoreV1Interface
+	caBundleNamespace string
+	kubeletPath       string
+	commDir           string
+	mounterCommDir    string
+	mountSyscall      mountSyscall
+	bindMountSyscall  bindMountSyscall
+	kubernetesVersion string
+	variant           cluster.Variant
+}
+
+// NewDaemonSetMounter creates a new [DaemonSetMounter].
+//
+// `commDir` is the communication directory of the mounter DaemonSet as seen by the CSI Driver Node Pod,
+// and `mounterCommDir` is the same directory as seen by the mounter DaemonSet Pod.
+// Custom CA bundles of volumes are read from ConfigMaps and Secrets in `caBundleNamespace` using `client`.
+func NewDaemonSetMounter(
+	credProvider credentialprovider.ProviderInterface,
+	client k8sv1.CoreV1Interface,
+	caBundleNamespace string,
+	mount *mpmounter.Mounter,
+	commDir string,
+	mounterCommDir string,
+	mountSyscall mountSyscall,
+	bindMountSyscall bindMountSyscall,
+	kubernetesVersion string,
+	variant cluster.Variant,
+) (*DaemonSetMounter, error) {
+	if commDir == "" || mounterCommDir == "" {
+		return nil, errors.New("communication directories of the mounter DaemonSet must be specified")
+	}
+
+	return &DaemonSetMounter{
+		mount:             mount,
+		credProvider:      credProvider,
+		client:            client,
+		caBundleNamespace: caBundleNamespace,
+		kubeletPath:       util.ContainerKubeletPath(),
+		commDir:           commDir,
+		mounterCommDir:    mounterCommDir,
+		mountSyscall:      mountSyscall,
+		bindMountSyscall:  bindMountSyscall,
+		kubernetesVersion: kubernetesVersion,
+		variant:           variant,
+	}, nil
+}
+
+// Mount mounts the given `bucketName` at the `target` path using provided credential context and Mountpoint arguments.
+//
+// At high level, this method will:
+//  1. Write credentials to the mount's credentials directory in the shared communication directory
+//  2. Obtain a FUSE file descriptor
+//  3. Call `mount` syscall with `source` and obtained FUSE file descriptor
+//  4. Send mount options (including FUSE file descriptor) to the mounter DaemonSet Pod
+//  5. Wait until Mountpoint successfully mounts at `source`
+//  6. Bind mounts from `source` to `target`
+//
+// Each `target` gets its own `source` and Mountpoint process, as there is no controller to decide on sharing,
+// and `fsGroup` (if non-empty) is applied to that Mountpoint process as its `--gid`.
+// If Mountpoint is already mounted at `target`, it will return early after step 1 to ensure credentials are up-to-date.
+// If Mountpoint is already mounted at `source`, it will skip steps 2-5 and only perform bind mount to `target`.
+func (dm *DaemonSetMounter) Mount(ctx context.Context, bucketName string, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string, userEnv envprovider.Environment) error {
+	mountID, err := dm.mountID(target)
+	if err != nil {
+		return fmt.Errorf("Failed to extract mount id from %q: %w", target, err)
+	}
+
+	isTargetMountPoint, err := dm.IsMountPoint(target)
+	if err != nil {
+		err = dm.verifyOrSetupMountTarget(target, err)
+		if err != nil {
+			return fmt.Errorf("Failed to verify target path can be used as a mount point %q: %w", target, err)
+		}
+	}
+
+	source := dm.sourcePath(mountID)
+	isSourceMountPoint, err := dm.IsMountPoint(source)
+	if err != nil {
+		err = dm.verifyOrSetupMountTarget(source, err)
+		if err != nil {
+			return fmt.Errorf("Failed to verify source path can be used as a mount point %q: %w", source, err)
+		}
+	}
+
+	// Note that this part happens before `isMountPoint` check, as we want to update credentials even though
+	// there is an existing mount point at `target`.
+	credEnv, authenticationSource, err := dm.provideCredentials(ctx, mountID, credentialCtx)
+	if err != nil {
+		klog.Errorf("Failed to provide credentials for %q: %v", source, err)
+		return fmt.Errorf("Failed to provide credentials for %q: %w", source, err)
+	}
+
+	if !isSourceMountPoint {
+		if fsGroup != "" {
+			// Each workload gets its own Mountpoint process, so the workload's `fsGroup` can be applied to it directly.
+			// These are no-ops if the arguments are already set by the caller or by the customer via PV mountOptions.
+			args.SetIfAbsent(mountpoint.ArgGid, fsGroup)
+			args.SetIfAbsent(mountpoint.ArgAllowOther, mountpoint.ArgNoValue)
+		}
+
+		err = dm.mountS3AtSource(ctx, source, mountID, bucketName, credEnv, userEnv, authenticationSource, args)
+		if err != nil {
+			return fmt.Errorf("Failed to mount at source %q: %w. %s", source, err, dm.helpMessageForGettingMountpointLogs())
+		}
+	}
+
+	if isTargetMountPoint {
+		klog.V(4).Infof("Target path %q is already mounted. Only refreshed credentials.", target)
+		return nil
+	}
+
+	err = dm.bindMountSyscallWithDefault(source, target)
+	if err != nil {
+		klog.Errorf("Failed to bind mount %q to target %q: %v", source, target, err)
+		return fmt.Errorf("Failed to bind mount %q to target %q: %w", source, target, err)
+	}
+
+	klog.V(4).Infof("Created bind mount to target %s from mount %s at %s", target, mountID, source)
+
+	return nil
+}
+
+// mountS3AtSource mounts an S3 bucket at the specified source path using the mounter DaemonSet Pod.
+// If any step fails, it ensures cleanup by unmounting the source path.
+func (dm *DaemonSetMounter) mountS3AtSource(ctx context.Context, source, mountID, bucketName string,
+	credEnv envprovider.Environment, userEnv envprovider.Environment, authenticationSource credentialprovider.AuthenticationSource,
+	args mountpoint.Args) error {
+
+	// Build environment with precedence (highest wins): credEnv > Default() > userEnv
+	env := envprovider.Environment{}
+	env.Merge(userEnv)
+	env.Merge(envprovider.Default())
+	env.Merge(credEnv)
+
+	// Move `--aws-max-attempts` to env if provided
+	if maxAttempts, ok := args.Remove(mountpoint.ArgAWSMaxAttempts); ok {
+		env.Set(envprovider.EnvMaxAttempts, maxAttempts)
+	}
+
+	args.Set(mountpoint.ArgUserAgentPrefix, UserAgent(authenticationSource, dm.kubernetesVersion, dm.variant))
+
+	klog.V(4).Infof("Mounting %s for mount %s", source, mountID)
+
+	fuseDeviceFD, err := dm.mountSyscallWithDefault(source, args)
+	if err != nil {
+		klog.Errorf("Failed to mount %s: %v", source, err)
+		return fmt.Errorf("Failed to mount %s: %w", source, err)
+	}
+
+	// Remove the read-only argument from the list as mount-s3 does not support it when using FUSE
+	// file descriptor (we already pass MS_RDONLY flag during mount syscall)
+	args.Remove(mountpoint.ArgReadOnly)
+
+	// This will set to false in the success condition. This is set to `true` by default to
+	// ensure we don't leave `source` mounted if Mountpoint is not started to serve requests for it.
+	unmount := true
+	defer func() {
+		if unmount {
+			if err := dm.mount.Unmount(source); err != nil {
+				klog.V(4).ErrorS(err, "Failed to unmount mounted source %s\n", source)
+			} else {
+				klog.V(4).Infof("Source %s unmounted successfully\n", source)
+			}
+		}
+	}()
+
+	// The mounter DaemonSet Pod gets its own fd referencing the same underlying file description,
+	// in both success and failure cases we need to close the fd in this process.
+	defer func() {
+		if err := mpmounter.CloseFD(fuseDeviceFD); err != nil {
+			klog.V(4).Infof("Mount: Failed to close /dev/fuse file descriptor %d: %v\n", fuseDeviceFD, err)
+		}
+	}()
+
+	// Remove old mount error file if exists
+	errorPath := dm.errorFilePath(mountID)
+	_ = os.Remove(errorPath)
+
+	sockPath := filepath.Join(dm.commDir, daemonSetMountSockName)
+	klog.V(4).Infof("Sending mount options for mount %s to the mounter DaemonSet on %s", mountID, sockPath)
+
+	mountOptions := mountoptions.Options{
+		Fd:         fuseDeviceFD,
+		BucketName: bucketName,
+		Args:       args.SortedList(),
+		Env:        env.List(),
+		VolumeId:   mountID,
+	}
+
+	protocolVersion, err := mountoptions.AdvertisedProtocolVersion(ctx, sockPath)
+	if err != nil {
+		klog.Errorf("Failed to get mount options protocol version of the mounter DaemonSet for mount %s: %v", mountID, err)
+		return fmt.Errorf("Failed to get mount options protocol version of the mounter DaemonSet for mount %s: %w", mountID, err)
+	}
+
+	if protocolVersion == 0 {
+		// Mounter DaemonSets predating the mount options protocol can't parse framed messages and never reply,
+		// poll for the outcome instead
+		if err := mountoptions.SendLegacy(ctx, sockPath, mountOptions); err != nil {
+			klog.Errorf("Failed to send mount options for mount %s to the mounter DaemonSet: %v", mountID, err)
+			return fmt.Errorf("Failed to send mount options for mount %s to the mounter DaemonSet: %w", mountID, err)
+		}
+
+		if err := dm.waitForMount(ctx, source, mountID, errorPath); err != nil {
+			klog.Errorf("Failed to wait for Mountpoint to be ready for mount %s: %v", mountID, err)
+			return fmt.Errorf("Failed to wait for Mountpoint to be ready for mount %s: %w", mountID, err)
+		}
+	} else {
+		// The mounter DaemonSet replies once Mountpoint starts serving the mount, or fails to do so
+		if err := mountoptions.Send(ctx, sockPath, mountOptions); err != nil {
+			klog.Errorf("Failed to send mount options for mount %s to the mounter DaemonSet: %v", mountID, err)
+			return fmt.Errorf("Failed to send mount options for mount %s to the mounter DaemonSet: %w", mountID, err)
+		}
+	}
+
+	// Mountpoint successfully started, so don't unmount the filesystem
+	unmount = false
+	return nil
+}
+
+// waitForMount waits until Mountpoint is successfully mounted at `source`, it's only used with mounter DaemonSets
+// predating replies to mount options. It returns an error if Mountpoint fails to mount, i.e., the mounter DaemonSet
+// writes an error file for the mount.
+func (dm *DaemonSetMounter) waitForMount(ctx context.Context, source, mountID, errorPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, daemonSetMountWaitTimeout)
+	defer cancel()
+
+	klog.V(4).Infof("Waiting until Mountpoint mounts on %s for mount %s", source, mountID)
+
+	return wait.PollUntilContextCancel(ctx, 500*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+		if res, err := os.ReadFile(errorPath); err == nil {
+			return false, fmt.Errorf("Mountpoint failed: %s", strings.TrimSpace(string(res)))
+		}
+		return dm.IsMountPoint(source)
+	})
+}
+
+// Unmount unmounts the bind mount point at `target`, and unmounts its `source` which terminates the Mountpoint process.
+// It also cleans up the credentials, the CA bundle and the error file of the mount.
+func (dm *DaemonSetMounter) Unmount(ctx context.Context, target string, credentialCtx credentialprovider.CleanupContext) error {
+	mountID, err := dm.mountID(target)
+	if err != nil {
+		return fmt.Errorf("Failed to extract mount id from %q: %w", target, err)
+	}
+
+	err = dm.mount.Unmount(target)
+	if err != nil {
+		klog.Errorf("Failed to unmount %q: %v", target, err)
+		return fmt.Errorf("Failed to unmount %q: %w", target, err)
+	}
+
+	source := dm.sourcePath(mountID)
+	isSourceMountPoint, err := dm.IsMountPoint(source)
+	if isSourceMountPoint || dm.mount.IsMountpointCorrupted(err) {
+		if err := dm.mount.Unmount(source); err != nil {
+			klog.Errorf("Failed to unmount source %q: %v", source, err)
+			return fmt.Errorf("Failed to unmount source %q: %w", source, err)
+		}
+	}
+	if err := os.Remove(source); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		klog.V(4).Infof("Failed to remove source directory %q: %v", source, err)
+	}
+
+	if err := os.Remove(dm.errorFilePath(mountID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		klog.V(4).Infof("Failed to remove error file of mount %s: %v", mountID, err)
+	}
+
+	credentialsDir := dm.credentialsDir(mountID)
+	credentialCtx.SetAsDaemonSetMountpoint()
+	credentialCtx.WritePath = credentialsDir
+	if err := dm.credProvider.Cleanup(credentialCtx); err != nil {
+		klog.Errorf("Unmount: Failed to clean up credentials for %s: %v", target, err)
+	}
+	if err := os.RemoveAll(credentialsDir); err != nil {
+		klog.Errorf("Unmount: Failed to remove credentials directory %s: %v", credentialsDir, err)
+	}
+
+	if err := os.Remove(dm.caBundlePath(mountID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		klog.V(4).Infof("Failed to remove CA bundle of mount %s: %v", mountID, err)
+	}
+
+	return nil
+}
+
+// IsMountPoint returns whether given `target` is a `mount-s3` mount.
+func (dm *DaemonSetMounter) IsMountPoint(target string) (bool, error) {
+	return dm.mount.CheckMountpoint(target)
+}
+
+// VolumeStats returns the health of the volume mounted at `target`.
+//
+// The volume is reported as abnormal if `target` or its `source` mount is corrupted,
+// or if the Mountpoint process reported an error. Volumes mounted with [DaemonSetMounter]
+// do not have a local cache volume, so no usage is reported.
+func (dm *DaemonSetMounter) VolumeStats(ctx context.Context, target string) (*VolumeStats, error) {
+	isTargetMountPoint, err := dm.IsMountPoint(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrVolumeNotMounted
+		}
+		if dm.mount.IsMountpointCorrupted(err) {
+			return abnormalVolumeStats("Target %q is corrupted: %v", target, err), nil
+		}
+		return nil, err
+	}
+	if !isTargetMountPoint {
+		return nil, ErrVolumeNotMounted
+	}
+
+	mountID, err := dm.mountID(target)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to extract mount id from %q: %w", target, err)
+	}
+
+	if mountErr, err := os.ReadFile(dm.errorFilePath(mountID)); err == nil {
+		return abnormalVolumeStats("Mountpoint failed: %s. %s", strings.TrimSpace(string(mountErr)), dm.helpMessageForGettingMountpointLogs()), nil
+	}
+
+	source := dm.sourcePath(mountID)
+	if _, err := dm.IsMountPoint(source); err != nil && dm.mount.IsMountpointCorrupted(err) {
+		return abnormalVolumeStats("Mountpoint mount %q is corrupted: %v. %s", source, err, dm.helpMessageForGettingMountpointLogs()), nil
+	}
+
+	return &VolumeStats{}, nil
+}
+
+// provideCredentials provides credentials for the mount `mountID`.
+func (dm *DaemonSetMounter) provideCredentials(ctx context.Context, mountID string,
+	credentialCtx credentialprovider.ProvideContext) (envprovider.Environment, credentialprovider.AuthenticationSource, error) {
+	credentialsDir := dm.credentialsDir(mountID)
+	err := os.MkdirAll(credentialsDir, credentialprovider.CredentialDirPerm)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to create credentials directory: %w", err)
+	}
+
+	credentialCtx.SetAsDaemonSetMountpoint()
+	credentialCtx.SetWriteAndEnvPath(credentialsDir, filepath.Join(dm.mounterCommDir, daemonSetCredentialsDir, mountID))
+
+	return dm.credProvider.Provide(ctx, credentialCtx)
+}
+
+// WriteCABundle reads the CA bundle referenced by `source` and writes it to the communication directory for the mount at `target`.
+// It returns the path of the CA bundle as seen by the mounter DaemonSet Pod.
+func (dm *DaemonSetMounter) WriteCABundle(ctx context.Context, target string, source *volumecontext.CABundleSource) (string, error) {
+	mountID, err := dm.mountID(target)
+	if err != nil {
+		return "", fmt.Errorf("Failed to extract mount id from %q: %w", target, err)
+	}
+
+	caBundle, err := dm.readCABundle(ctx, source)
+	if err != nil {
+		return "", err
+	}
+
+	caBundlePath := dm.caBundlePath(mountID)
+	if err := os.MkdirAll(filepath.Dir(caBundlePath), credentialprovider.CredentialDirPerm); err != nil {
+		return "", fmt.Errorf("Failed to create CA bundles directory: %w", err)
+	}
+	if err := renameio.WriteFile(caBundlePath, caBundle, credentialprovider.CredentialFilePerm); err != nil {
+		return "", fmt.Errorf("Failed to write CA bundle of mount %s: %w", mountID, err)
+	}
+
+	return filepath.Join(dm.mounterCommDir, daemonSetCABundlesDir, mountID+caBundleFileExt), nil
+}
+
+// readCABundle reads the CA bundle referenced by `source`.
+func (dm *DaemonSetMounter) readCABundle(ctx context.Context, source *volumecontext.CABundleSource) ([]byte, error) {
+	switch source.Kind {
+	case volumecontext.CABundleKindConfigMap:
+		configMap, err := dm.client.ConfigMaps(dm.caBundleNamespace).Get(ctx, source.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get CA bundle ConfigMap %s/%s: %w", dm.caBundleNamespace, source.Name, err)
+		}
+		if data, ok := configMap.Data[source.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := configMap.BinaryData[source.Key]; ok {
+			return data, nil
+		}
+	case volumecontext.CABundleKindSecret:
+		secret, err := dm.client.Secrets(dm.caBundleNamespace).Get(ctx, source.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get CA bundle Secret %s/%s: %w", dm.caBundleNamespace, source.Name, err)
+		}
+		if data, ok := secret.Data[source.Key]; ok {
+			return data, nil
+		}
+	default:
+		return nil, fmt.Errorf("Unknown CA bundle source kind %q", source.Kind)
+	}
+
+	return nil, fmt.Errorf("Key %q not found in CA bundle %s %s/%s", source.Key, source.Kind, dm.caBundleNamespace, source.Name)
+}
+
+// verifyOrSetupMountTarget checks target path for existence and corrupted mount error.
+// If the target dir does not exists it tries to create it.
+// If the target dir is corrupted it tries to unmount it to have a clean mount.
+func (dm *DaemonSetMounter) verifyOrSetupMountTarget(target string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		klog.V(5).Infof("Target path does not exists %s, trying to create", target)
+		if err := os.MkdirAll(target, targetDirPerm); err != nil {
+			return fmt.Errorf("Failed to create target directory: %w", err)
+		}
+
+		return nil
+	} else if dm.mount.IsMountpointCorrupted(err) {
+		klog.V(4).Infof("Target path %q is a corrupted mount. Trying to unmount", target)
+		if unmountErr := dm.mount.Unmount(target); unmountErr != nil {
+			return fmt.Errorf("Failed to unmount target path %q: %w, original failure of stat: %v", target, unmountErr, err)
+		}
+
+		return nil
+	}
+
+	// Some other error that we cannot recover from, just propagate it.
+	return err
+}
+
+// mountID returns the mount id for `target`, which is "<workload pod UID>-<volume name>".
+// This is used to identify the Mountpoint process in the mounter DaemonSet.
+func (dm *DaemonSetMounter) mountID(target string) (string, error) {
+	tp, err := targetpath.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	return tp.PodID + "-" + tp.VolumeID, nil
+}
+
+// sourcePath returns the path where Mountpoint will be mounted for `mountID`.
+func (dm *DaemonSetMounter) sourcePath(mountID string) string {
+	return filepath.Join(SourceMountDir(dm.kubeletPath), mountID)
+}
+
+// errorFilePath returns the path of the error file the mounter DaemonSet writes if Mountpoint fails for `mountID`.
+func (dm *DaemonSetMounter) errorFilePath(mountID string) string {
+	return filepath.Join(dm.commDir, mountID+daemonSetErrorFileExt)
+}
+
+// credentialsDir returns the credentials directory of `mountID` as seen by the CSI Driver Node Pod.
+func (dm *DaemonSetMounter) credentialsDir(mountID string) string {
+	return filepath.Join(dm.commDir, daemonSetCredentialsDir, mountID)
+}
+
+// caBundlePath returns the path of the CA bundle of `mountID` as seen by the CSI Driver Node Pod.
+func (dm *DaemonSetMounter) caBundlePath(mountID string) string {
+	return filepath.Join(dm.commDir, daemonSetCABundlesDir, mountID+caBundleFileExt)
+}
+
+// mountSyscallWithDefault delegates to `mountSyscall` if set, or fallbacks to platform-native `mpmounter.Mount`.
+func (dm *DaemonSetMounter) mountSyscallWithDefault(target string, args mountpoint.Args) (int, error) {
+	if dm.mountSyscall != nil {
+		return dm.mountSyscall(target, args)
+	}
+
+	opts := mpmounter.MountOptions{
+		ReadOnly:   args.Has(mountpoint.ArgReadOnly),
+		AllowOther: args.Has(mountpoint.ArgAllowOther) || args.Has(mountpoint.ArgAllowRoot),
+	}
+	return dm.mount.Mount(target, opts)
+}
+
+// bindMountSyscallWithDefault delegates to `bindMountSyscall` if set, or fallbacks to platform-native `mpmounter.BindMount`.
+func (dm *DaemonSetMounter) bindMountSyscallWithDefault(source, target string) error {
+	if dm.bindMountSyscall != nil {
+		return dm.bindMountSyscall(source, target)
+	}
+
+	return dm.mount.BindMount(source, target)
+}
+
+// helpMessageForGettingMountpointLogs returns a help message to troubleshoot Mountpoint failures.
+func (dm *DaemonSetMounter) helpMessageForGettingMountpointLogs() string {
+	return "You can see Mountpoint logs by running `kubectl logs` on the mounter DaemonSet Pod running in the same node."
+}
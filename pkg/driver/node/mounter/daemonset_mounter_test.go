@@ -0,0 +1,481 @@
+package mounter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/mount-utils"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	mock_credentialprovider "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/mocks"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter/mountertest"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	mpmounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testMounterCommDir = "/comm"
+
+type daemonSetTestCtx struct {
+	t   *testing.T
+	ctx context.Context
+
+	dsMounter *mounter.DaemonSetMounter
+
+	mount            *mount.FakeMounter
+	client           *fake.Clientset
+	mockCredProvider *mock_credentialprovider.MockProviderInterface
+	mountSyscall     func(target string, args mountpoint.Args) (fd int, err error)
+
+	bucketName string
+	commDir    string
+	sourcePath string
+	targetPath string
+	podUID     string
+	volumeID   string
+	mountID    string
+}
+
+func setupDaemonSet(t *testing.T) *daemonSetTestCtx {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	mockCtl := gomock.NewController(t)
+	mockCredProvider := mock_credentialprovider.NewMockProviderInterface(mockCtl)
+
+	kubeletPath := t.TempDir()
+	// Eval symlinks on `kubeletPath` as `mount.NewFakeMounter` also does that and we rely on
+	// `mount.List()` to compare mount points and they need to be the same.
+	parentDir, err := filepath.EvalSymlinks(filepath.Dir(kubeletPath))
+	assert.NoError(t, err)
+	kubeletPath = filepath.Join(parentDir, filepath.Base(kubeletPath))
+	t.Setenv("CONTAINER_KUBELET_PATH", kubeletPath)
+
+	// Chdir to `kubeletPath` so `mountoptions.{Recv, Send}` can use relative paths to Unix sockets
+	// to overcome `bind: invalid argument`.
+	t.Chdir(kubeletPath)
+
+	podUID := uuid.New().String()
+	pvName := "s3-csi-driver-pv"
+	mountID := podUID + "-" + pvName
+	commDir := filepath.Join(kubeletPath, "plugins", "s3.csi.aws.com", "daemonset")
+	err = os.MkdirAll(commDir, 0750)
+	assert.NoError(t, err)
+
+	targetPath := filepath.Join(
+		kubeletPath,
+		fmt.Sprintf("pods/%s/volumes/kubernetes.io~csi/%s/mount", podUID, pvName),
+	)
+	// Same behaviour as Kubernetes, see https://github.com/kubernetes/kubernetes/blob/8f8c94a04d00e59d286fe4387197bc62c6a4f374/pkg/volume/csi/csi_mounter.go#L211-L215
+	err = os.MkdirAll(filepath.Dir(targetPath), 0750)
+	assert.NoError(t, err)
+
+	fakeMounter := mount.NewFakeMounter(nil)
+	client := fake.NewClientset()
+	devNull := mountertest.OpenDevNull(t)
+
+	testCtx := &daemonSetTestCtx{
+		t:                t,
+		ctx:              ctx,
+		mount:            fakeMounter,
+		client:           client,
+		mockCredProvider: mockCredProvider,
+		bucketName:       "test-bucket",
+		commDir:          commDir,
+		sourcePath:       filepath.Join(mounter.SourceMountDir(kubeletPath), mountID),
+		targetPath:       targetPath,
+		podUID:           podUID,
+		volumeID:         "s3-csi-driver-volume",
+		mountID:          mountID,
+	}
+
+	mountSyscall := func(target string, args mountpoint.Args) (fd int, err error) {
+		if testCtx.mountSyscall != nil {
+			return testCtx.mountSyscall(target, args)
+		}
+
+		fakeMounter.Mount("mountpoint-s3", target, "fuse", nil)
+		// `DaemonSetMounter.Mount` closes the file descriptor, duplicate it to not close the file descriptor
+		// owned by `devNull` twice, as the same number might be re-used for another file in the meantime.
+		return syscall.Dup(int(devNull.Fd()))
+	}
+
+	mountBindSyscall := func(source, target string) (err error) {
+		fakeMounter.Mount(source, target, "fuse", []string{"bind"})
+		return nil
+	}
+
+	dsMounter, err := mounter.NewDaemonSetMounter(mockCredProvider, client.CoreV1(), mountpointPodNamespace,
+		mpmounter.NewWithMount(fakeMounter), commDir, testMounterCommDir,
+		mountSyscall, mountBindSyscall, testK8sVersion, cluster.DefaultKubernetes)
+	assert.NoError(t, err)
+
+	testCtx.dsMounter = dsMounter
+
+	return testCtx
+}
+
+// receiveMountOptions will receive mount options sent to the mounter DaemonSet.
+// This operation will block in place, and ideally should be called from a separate goroutine.
+func (testCtx *daemonSetTestCtx) receiveMountOptions() mountoptions.Options {
+	testCtx.t.Helper()
+	req := testCtx.recv()
+	assert.NoError(testCtx.t, req.Reply(nil))
+	return req.Options
+}
+
+// recv receives a mount request sent to the mounter DaemonSet, listening the same way as the mounter DaemonSet.
+func (testCtx *daemonSetTestCtx) recv() *mountoptions.Request {
+	testCtx.t.Helper()
+	l, err := mountoptions.Listen(filepath.Join(testCtx.commDir, "mount.sock"))
+	assert.NoError(testCtx.t, err)
+	defer l.Close()
+	assert.NoError(testCtx.t, l.(*net.UnixListener).SetDeadline(time.Now().Add(10*time.Second)))
+
+	conn, err := l.Accept()
+	assert.NoError(testCtx.t, err)
+	req, err := mountoptions.RecvOnConn(conn.(*net.UnixConn), time.Now().Add(10*time.Second))
+	assert.NoError(testCtx.t, err)
+	return req
+}
+
+func (testCtx *daemonSetTestCtx) mountVolume() {
+	testCtx.t.Helper()
+	testCtx.mockCredProvider.EXPECT().
+		Provide(testCtx.ctx, gomock.Any()).
+		Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+	// Wait for `receiveMountOptions` to return, as closing the Unix socket removes it relative to
+	// the current working directory, which would be changed once the test finishes.
+	received := make(chan struct{})
+	go func() {
+		testCtx.receiveMountOptions()
+		close(received)
+	}()
+
+	err := testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+		VolumeID:      testCtx.volumeID,
+		WorkloadPodID: testCtx.podUID,
+	}, mountpoint.ParseArgs(nil), "", envprovider.Environment{})
+	assert.NoError(testCtx.t, err)
+	<-received
+}
+
+func TestDaemonSetMounter(t *testing.T) {
+	t.Run("Mounting", func(t *testing.T) {
+		t.Run("Correctly passes mount options", func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+
+			devNull := mountertest.OpenDevNull(t)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				testCtx.mount.Mount("mountpoint-s3", target, "fuse", nil)
+
+				// Since `DaemonSetMounter.Mount` closes the file descriptor once it passes it to Mountpoint,
+				// we should duplicate our file descriptor to ensure underlying file description won't
+				// closed once the file descriptor passed to `DaemonSetMounter.Mount` closed.
+				fd, err = syscall.Dup(int(devNull.Fd()))
+				assert.NoError(t, err)
+
+				return fd, nil
+			}
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, provideCtx credentialprovider.ProvideContext) (envprovider.Environment, credentialprovider.AuthenticationSource, error) {
+					assert.Equals(t, true, provideCtx.IsDaemonSetMountpoint())
+					assert.Equals(t, filepath.Join(testCtx.commDir, "credentials", testCtx.mountID), provideCtx.WritePath)
+					assert.Equals(t, filepath.Join(testMounterCommDir, "credentials", testCtx.mountID), provideCtx.EnvPath)
+					return envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil
+				})
+
+			mountRes := make(chan error)
+			go func() {
+				mountRes <- testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					WorkloadPodID:        testCtx.podUID,
+				}, mountpoint.ParseArgs([]string{mountpoint.ArgReadOnly}), "", envprovider.Environment{
+					envprovider.EnvHTTPSProxy: "proxy:3128",
+				})
+			}()
+
+			got := testCtx.receiveMountOptions()
+
+			err := <-mountRes
+			assert.NoError(t, err)
+
+			gotFile := os.NewFile(uintptr(got.Fd), "fd")
+			mountertest.AssertSameFile(t, devNull, gotFile)
+			// Reset fd as they might be different in different ends.
+			got.Fd = 0
+
+			env := envprovider.Default()
+			env.Set(envprovider.EnvHTTPSProxy, "proxy:3128")
+
+			assert.Equals(t, mountoptions.Options{
+				BucketName: testCtx.bucketName,
+				Args: []string{
+					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion, cluster.DefaultKubernetes),
+				},
+				Env:      env.List(),
+				VolumeId: testCtx.mountID,
+			}, got)
+
+			ok, err := testCtx.dsMounter.IsMountPoint(testCtx.sourcePath)
+			assert.NoError(t, err)
+			assert.Equals(t, true, ok)
+			ok, err = testCtx.dsMounter.IsMountPoint(testCtx.targetPath)
+			assert.NoError(t, err)
+			assert.Equals(t, true, ok)
+		})
+
+		t.Run("Applies fsGroup of the workload", func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				testCtx.mount.Mount("mountpoint-s3", target, "fuse", nil)
+				return syscall.Dup(int(mountertest.OpenDevNull(t).Fd()))
+			}
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			mountRes := make(chan error)
+			go func() {
+				mountRes <- testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					VolumeID:      testCtx.volumeID,
+					WorkloadPodID: testCtx.podUID,
+				}, mountpoint.ParseArgs(nil), "1000", envprovider.Environment{})
+			}()
+
+			got := testCtx.receiveMountOptions()
+			assert.NoError(t, <-mountRes)
+
+			args := mountpoint.ParseArgs(got.Args)
+			gid, _ := args.Value(mountpoint.ArgGid)
+			assert.Equals(t, "1000", gid)
+			assert.Equals(t, true, args.Has(mountpoint.ArgAllowOther))
+		})
+
+		t.Run("Only refreshes credentials if target is already mounted", func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+			testCtx.mountVolume()
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				t.Fatal("Mount syscall should not be called for already mounted target")
+				return 0, nil
+			}
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			err := testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID:      testCtx.volumeID,
+				WorkloadPodID: testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "", envprovider.Environment{})
+			assert.NoError(t, err)
+		})
+
+		t.Run("Uses the legacy framing for mounter DaemonSets predating the mount options protocol", func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			// Legacy mounter DaemonSets listen without advertising a version
+			legacy := make(chan bool, 1)
+			go func() {
+				req, err := mountoptions.Recv(testCtx.ctx, filepath.Join(testCtx.commDir, "mount.sock"))
+				assert.NoError(t, err)
+				syscall.Close(req.Options.Fd)
+				legacy <- req.Legacy()
+				assert.NoError(t, req.Reply(nil))
+			}()
+
+			err := testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID:      testCtx.volumeID,
+				WorkloadPodID: testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "", envprovider.Environment{})
+			assert.NoError(t, err)
+			assert.Equals(t, true, <-legacy)
+		})
+
+		t.Run("Unmounts source if Mountpoint fails to start", func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				// Register a non-Mountpoint mount to simulate Mountpoint is not ready to serve the mount yet
+				testCtx.mount.Mount("fuse", target, "fuse", nil)
+				return syscall.Dup(int(mountertest.OpenDevNull(t).Fd()))
+			}
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			go func() {
+				// Emulate that Mountpoint failed to mount
+				req := testCtx.recv()
+				assert.NoError(t, req.Reply(errors.New("Mountpoint failed: access denied")))
+			}()
+
+			err := testCtx.dsMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID:      testCtx.volumeID,
+				WorkloadPodID: testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "", envprovider.Environment{})
+			if err == nil || !strings.Contains(err.Error(), "access denied") {
+				t.Fatalf("Expected Mountpoint error to be propagated, got %v", err)
+			}
+
+			mounts, err := testCtx.mount.List()
+			assert.NoError(t, err)
+			assert.Equals(t, 0, len(mounts))
+		})
+	})
+
+	t.Run("Unmounting", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+		testCtx.mountVolume()
+
+		errorPath := filepath.Join(testCtx.commDir, testCtx.mountID+".error")
+		err := os.WriteFile(errorPath, []byte("exited"), 0600)
+		assert.NoError(t, err)
+
+		caBundlePath := filepath.Join(testCtx.commDir, "ca-bundles", testCtx.mountID+".crt")
+		assert.NoError(t, os.MkdirAll(filepath.Dir(caBundlePath), 0750))
+		assert.NoError(t, os.WriteFile(caBundlePath, []byte("ca"), 0600))
+
+		testCtx.mockCredProvider.EXPECT().
+			Cleanup(gomock.Any()).
+			DoAndReturn(func(cleanupCtx credentialprovider.CleanupContext) error {
+				assert.Equals(t, true, cleanupCtx.IsDaemonSetMountpoint())
+				assert.Equals(t, filepath.Join(testCtx.commDir, "credentials", testCtx.mountID), cleanupCtx.WritePath)
+				return nil
+			})
+
+		err = testCtx.dsMounter.Unmount(testCtx.ctx, testCtx.targetPath, credentialprovider.CleanupContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		})
+		assert.NoError(t, err)
+
+		mounts, err := testCtx.mount.List()
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(mounts))
+		assert.FileNotExists(t, errorPath)
+		assert.FileNotExists(t, filepath.Join(testCtx.commDir, "credentials", testCtx.mountID))
+		assert.FileNotExists(t, caBundlePath)
+	})
+}
+
+func TestDaemonSetMounterWritingCABundles(t *testing.T) {
+	const caBundle = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+	for name, test := range map[string]struct {
+		object runtime.Object
+		source *volumecontext.CABundleSource
+	}{
+		"ConfigMap": {
+			object: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: mountpointPodNamespace},
+				Data:       map[string]string{"ca.crt": caBundle},
+			},
+			source: &volumecontext.CABundleSource{Kind: volumecontext.CABundleKindConfigMap, Name: "corp-ca", Key: "ca.crt"},
+		},
+		"Secret": {
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: mountpointPodNamespace},
+				Data:       map[string][]byte{"bundle.pem": []byte(caBundle)},
+			},
+			source: &volumecontext.CABundleSource{Kind: volumecontext.CABundleKindSecret, Name: "corp-ca", Key: "bundle.pem"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testCtx := setupDaemonSet(t)
+			assert.NoError(t, testCtx.client.Tracker().Add(test.object))
+
+			path, err := testCtx.dsMounter.WriteCABundle(testCtx.ctx, testCtx.targetPath, test.source)
+			assert.NoError(t, err)
+			assert.Equals(t, filepath.Join(testMounterCommDir, "ca-bundles", testCtx.mountID+".crt"), path)
+
+			got, err := os.ReadFile(filepath.Join(testCtx.commDir, "ca-bundles", testCtx.mountID+".crt"))
+			assert.NoError(t, err)
+			assert.Equals(t, caBundle, string(got))
+		})
+	}
+
+	t.Run("Fails if the key does not exist", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+		assert.NoError(t, testCtx.client.Tracker().Add(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: mountpointPodNamespace},
+			Data:       map[string]string{"other.crt": caBundle},
+		}))
+
+		_, err := testCtx.dsMounter.WriteCABundle(testCtx.ctx, testCtx.targetPath,
+			&volumecontext.CABundleSource{Kind: volumecontext.CABundleKindConfigMap, Name: "corp-ca", Key: "ca.crt"})
+		if err == nil {
+			t.Fatal("Expected an error for missing key")
+		}
+	})
+
+	t.Run("Fails if the object does not exist", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+
+		_, err := testCtx.dsMounter.WriteCABundle(testCtx.ctx, testCtx.targetPath,
+			&volumecontext.CABundleSource{Kind: volumecontext.CABundleKindSecret, Name: "corp-ca", Key: "ca.crt"})
+		if err == nil {
+			t.Fatal("Expected an error for missing Secret")
+		}
+	})
+}
+
+func TestDaemonSetMounterVolumeStats(t *testing.T) {
+	t.Run("Healthy volume", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+		testCtx.mountVolume()
+
+		stats, err := testCtx.dsMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, &mounter.VolumeStats{}, stats)
+	})
+
+	t.Run("Returns not mounted error if target is not mounted", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+
+		_, err := testCtx.dsMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.Equals(t, mounter.ErrVolumeNotMounted, err)
+	})
+
+	t.Run("Abnormal if Mountpoint reported an error", func(t *testing.T) {
+		testCtx := setupDaemonSet(t)
+		testCtx.mountVolume()
+
+		err := os.WriteFile(filepath.Join(testCtx.commDir, testCtx.mountID+".error"), []byte("exited with code 1"), 0600)
+		assert.NoError(t, err)
+
+		stats, err := testCtx.dsMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, true, stats.Abnormal)
+		if !strings.Contains(stats.Message, "exited with code 1") {
+			t.Fatalf("Expected message to contain Mountpoint error, got %q", stats.Message)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 )
 
 type FakeMounter struct{}
@@ -22,3 +23,7 @@ func (m *FakeMounter) Unmount(ctx context.Context, target string, credentialCtx
 func (m *FakeMounter) IsMountPoint(target string) (bool, error) {
 	return false, nil
 }
+
+func (m *FakeMounter) VolumeStats(ctx context.Context, target string) (*VolumeStats, error) {
+	return &VolumeStats{CacheUsage: &util.FilesystemUsage{}}, nil
+}
@@ -10,6 +10,8 @@ import (
 
 	credentialprovider "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	envprovider "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	mounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	volumecontext "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	mountpoint "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -79,3 +81,56 @@ func (mr *MockMounterMockRecorder) Unmount(ctx, target, credentialCtx interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmount", reflect.TypeOf((*MockMounter)(nil).Unmount), ctx, target, credentialCtx)
 }
+
+// VolumeStats mocks base method.
+func (m *MockMounter) VolumeStats(ctx context.Context, target string) (*mounter.VolumeStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VolumeStats", ctx, target)
+	ret0, _ := ret[0].(*mounter.VolumeStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VolumeStats indicates an expected call of VolumeStats.
+func (mr *MockMounterMockRecorder) VolumeStats(ctx, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeStats", reflect.TypeOf((*MockMounter)(nil).VolumeStats), ctx, target)
+}
+
+// MockCABundleWriter is a mock of CABundleWriter interface.
+type MockCABundleWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCABundleWriterMockRecorder
+}
+
+// MockCABundleWriterMockRecorder is the mock recorder for MockCABundleWriter.
+type MockCABundleWriterMockRecorder struct {
+	mock *MockCABundleWriter
+}
+
+// NewMockCABundleWriter creates a new mock instance.
+func NewMockCABundleWriter(ctrl *gomock.Controller) *MockCABundleWriter {
+	mock := &MockCABundleWriter{ctrl: ctrl}
+	mock.recorder = &MockCABundleWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCABundleWriter) EXPECT() *MockCABundleWriterMockRecorder {
+	return m.recorder
+}
+
+// WriteCABundle mocks base method.
+func (m *MockCABundleWriter) WriteCABundle(ctx context.Context, target string, source *volumecontext.CABundleSource) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteCABundle", ctx, target, source)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteCABundle indicates an expected call of WriteCABundle.
+func (mr *MockCABundleWriterMockRecorder) WriteCABundle(ctx, target, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteCABundle", reflect.TypeOf((*MockCABundleWriter)(nil).WriteCABundle), ctx, target, source)
+}
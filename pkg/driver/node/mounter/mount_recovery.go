@@ -0,0 +1,330 @@
+package mounter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	// mountRecoveryInterval is the interval to retry recovering mounts that couldn't be recovered yet,
+	// e.g. because their Mountpoint Pods are not running yet after a node reboot.
+	mountRecoveryInterval = 10 * time.Second
+	// mountRecoveryTimeout is the duration to keep retrying to recover mounts after the startup.
+	mountRecoveryTimeout = 5 * time.Minute
+)
+
+// mountOptionsFilePerm is the permission of recorded mount options, they're only readable by the CSI Driver Node Pod
+// as they might contain sensitive environment variables.
+const mountOptionsFilePerm = fs.FileMode(0600)
+
+// mountOptionsDirPerm is the permission of the directory of recorded mount options.
+const mountOptionsDirPerm = fs.FileMode(0700)
+
+// sourceMountOptions are the options of a source mount, they're recorded once Mountpoint successfully starts
+// to re-establish the source mount with [PodMounter.RecoverMounts] if it's lost.
+type sourceMountOptions struct {
+	BucketName string   `json:"bucketName"`
+	Args       []string `json:"args"`
+	// Env doesn't contain credentials, as the credential files they refer to are lost alongside the source mount
+	// (e.g., after a node reboot) or might be expired, the credentials are provided again using `CredentialContext` instead.
+	Env envprovider.Environment `json:"env"`
+	// CredentialContext is the context credentials are provided with. Service account tokens and Secrets passed
+	// by kubelet are not recorded.
+	CredentialContext credentialprovider.ProvideContext `json:"credentialContext"`
+}
+
+// A recoverableTarget is a target path published by this node that's served by a Mountpoint Pod.
+type recoverableTarget struct {
+	path string
+	s3pa *crdv2.MountpointS3PodAttachment
+}
+
+// StartMountRecovery recovers mounts lost while the CSI Driver Node Pod or the node was down, see [PodMounter.RecoverMounts].
+// Mounts that couldn't be recovered (e.g., their Mountpoint Pods are not running yet after a node reboot)
+// are retried for a while.
+// stopCh: Channel to signal stopping of the recovery routine
+func (pm *PodMounter) StartMountRecovery(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithTimeout(wait.ContextForChannel(stopCh), mountRecoveryTimeout)
+	defer cancel()
+
+	var lastErr error
+	err := wait.PollUntilContextCancel(ctx, mountRecoveryInterval, true, func(ctx context.Context) (bool, error) {
+		lastErr = pm.RecoverMounts(ctx)
+		if lastErr != nil {
+			klog.V(4).Infof("Failed to recover some mounts, will retry: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		klog.Errorf("Failed to recover mounts: %v", lastErr)
+	}
+}
+
+// RecoverMounts re-establishes mounts that kubelet considers published, but have no live Mountpoint behind them.
+// This happens if a Mountpoint Pod restarts while the CSI Driver Node Pod is down, or after a node reboot.
+//
+// It finds target paths of workloads under kubelet's pods directory and matches them against MountpointS3PodAttachments
+// to find their Mountpoint Pods. For each Mountpoint Pod:
+//  1. If its source mount is broken or missing, credentials are provided again, and a new FUSE file descriptor
+//     is sent to the Mountpoint Pod alongside the mount options recorded when it was last mounted
+//  2. Bind mounts to the target paths that are broken or missing are refreshed
+//
+// Source mounts without recorded mount options, or with credentials that can only be obtained from kubelet
+// (i.e., service account tokens and Secrets passed via `nodePublishSecretRef`) are skipped,
+// and they're re-established once kubelet publishes the volume again.
+func (pm *PodMounter) RecoverMounts(ctx context.Context) error {
+	targets, err := pm.findRecoverableTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for mpPodName, mpPodTargets := range targets {
+		if err := pm.recoverMountpointPod(ctx, mpPodName, mpPodTargets); err != nil {
+			errs = append(errs, fmt.Errorf("failed to recover mounts of Mountpoint Pod %q: %w", mpPodName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findRecoverableTargets returns target paths under kubelet's pods directory by the Mountpoint Pods serving them.
+func (pm *PodMounter) findRecoverableTargets(ctx context.Context) (map[string][]recoverableTarget, error) {
+	paths, err := filepath.Glob(filepath.Join(pm.kubeletPath, "pods", "*", "volumes", "kubernetes.io~csi", "*", "mount"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find target paths: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	err = pm.s3paCache.List(ctx, s3paList, client.MatchingFields{crdv2.FieldNodeName: pm.nodeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MountpointS3PodAttachments: %w", err)
+	}
+
+	targets := make(map[string][]recoverableTarget)
+	for _, path := range paths {
+		tp, err := targetpath.Parse(path)
+		if err != nil {
+			continue
+		}
+
+	s3paLoop:
+		for i := range s3paList.Items {
+			s3pa := &s3paList.Items[i]
+			for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+				for _, attachment := range attachments {
+					if attachment.WorkloadPodUID == tp.PodID && isAttachmentForVolume(s3pa, attachment, tp.VolumeID) {
+						targets[mpPodName] = append(targets[mpPodName], recoverableTarget{path: path, s3pa: s3pa})
+						break s3paLoop
+					}
+				}
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// recoverMountpointPod re-establishes the source mount of `mpPodName` if it's broken, and refreshes bind mounts to `targets`.
+func (pm *PodMounter) recoverMountpointPod(ctx context.Context, mpPodName string, targets []recoverableTarget) error {
+	mpPod, err := pm.podWatcher.Get(mpPodName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The workloads are being moved to another Mountpoint Pod, kubelet will publish them again
+			klog.V(4).Infof("Mountpoint Pod %q is not found, skipping recovery of its mounts", mpPodName)
+			return nil
+		}
+		return err
+	}
+
+	unlockMountpointPod := lockMountpointPod(mpPod.Name)
+	defer unlockMountpointPod()
+
+	source := filepath.Join(SourceMountDir(pm.kubeletPath), mpPod.Name)
+	isSourceMountPoint, err := pm.IsMountPoint(source)
+	if err != nil {
+		err = pm.verifyOrSetupMountTarget(source, err)
+		if err != nil {
+			return fmt.Errorf("failed to verify source path can be used as a mount point %q: %w", source, err)
+		}
+	}
+
+	if !isSourceMountPoint {
+		options, err := readRecordedMountOptions(pm.kubeletPath, mpPod.Name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				klog.Infof("No mount options recorded for %q, it will be mounted once kubelet publishes its volumes again", source)
+				return nil
+			}
+			return err
+		}
+
+		if requiresCredentialsFromKubelet(options.CredentialContext) {
+			klog.Infof("Credentials of %q can only be provided by kubelet, it will be mounted once kubelet publishes its volumes again", source)
+			return nil
+		}
+
+		if err := pm.remountAtSource(ctx, source, mpPod, targets[0].s3pa, options); err != nil {
+			return err
+		}
+		klog.Infof("Recovered Mountpoint mount at %q served by Mountpoint Pod %q", source, mpPod.Name)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := pm.refreshBindMount(source, target.path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// remountAtSource provides credentials to `mpPod` again, mounts at `source` and sends the new FUSE file descriptor
+// with recorded `options` to `mpPod`. The outcome is reported to the status of `s3pa` once `mpPod` is ready to receive mount options.
+func (pm *PodMounter) remountAtSource(ctx context.Context, source string, mpPod *corev1.Pod, s3pa *crdv2.MountpointS3PodAttachment,
+	options sourceMountOptions) error {
+	runningPod, podPath, err := pm.waitForMountpointPod(ctx, mpPod.Name)
+	if err != nil {
+		return fmt.Errorf("failed to wait for Mountpoint Pod %q to be ready: %w", mpPod.Name, err)
+	}
+
+	// `aws-s3-csi-mounter` only listens on its mount socket while waiting for mount options, i.e. after a restart.
+	// Otherwise Mountpoint is still serving a FUSE connection that's lost, and it needs to be restarted first.
+	exists, err := fileExists(mppod.PathOnHost(podPath, mppod.KnownPathMountSock))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("Mountpoint Pod %q is not waiting for mount options", mpPod.Name)
+	}
+
+	credEnv, _, err := pm.provideCredentials(ctx, podPath, string(runningPod.UID), s3pa.Spec.WorkloadServiceAccountIAMRoleARN, options.CredentialContext)
+	if err != nil {
+		err = fmt.Errorf("failed to provide credentials for %q: %w", source, err)
+	} else {
+		err = pm.mountAtSource(ctx, source, runningPod, podPath, options, credEnv)
+	}
+	pm.reportMountStatus(ctx, s3pa, runningPod, err)
+	return err
+}
+
+// requiresCredentialsFromKubelet returns whether credentials of `credentialCtx` can only be provided with
+// service account tokens or Secrets passed by kubelet while publishing a volume, which are not recorded.
+func requiresCredentialsFromKubelet(credentialCtx credentialprovider.ProvideContext) bool {
+	switch credentialCtx.AuthenticationSource {
+	case credentialprovider.AuthenticationSourcePod:
+		return true
+	case credentialprovider.AuthenticationSourceSecret:
+		return credentialCtx.SecretName == ""
+	default:
+		return false
+	}
+}
+
+// refreshBindMount bind mounts `source` to `target` unless `target` is already a healthy mount.
+func (pm *PodMounter) refreshBindMount(source, target string) error {
+	isTargetMountPoint, err := pm.IsMountPoint(target)
+	if err != nil {
+		err = pm.verifyOrSetupMountTarget(target, err)
+		if err != nil {
+			return fmt.Errorf("failed to verify target path can be used as a mount point %q: %w", target, err)
+		}
+	}
+	if isTargetMountPoint {
+		return nil
+	}
+
+	err = pm.bindMountSyscallWithDefault(source, target)
+	if err != nil {
+		return fmt.Errorf("failed to bind mount %q to target %q: %w", source, target, err)
+	}
+
+	klog.Infof("Recovered bind mount to target %q from %q", target, source)
+	return nil
+}
+
+// recordedMountOptionsPath returns the path of recorded mount options of source mount `sourceName`.
+func recordedMountOptionsPath(kubeletPath, sourceName string) string {
+	return filepath.Join(MountOptionsDir(kubeletPath), sourceName+".json")
+}
+
+// recordMountOptions records `options` of source mount `sourceName` to be used while recovering it.
+// Service account tokens and Secrets in the credential context of `options` are not recorded.
+func recordMountOptions(kubeletPath, sourceName string, options sourceMountOptions) error {
+	options.CredentialContext.ServiceAccountTokens = ""
+	options.CredentialContext.Secrets = nil
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(MountOptionsDir(kubeletPath), mountOptionsDirPerm); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first and rename to make the update atomic
+	path := recordedMountOptionsPath(kubeletPath, sourceName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, mountOptionsFilePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// readRecordedMountOptions returns recorded mount options of source mount `sourceName`.
+func readRecordedMountOptions(kubeletPath, sourceName string) (sourceMountOptions, error) {
+	var options sourceMountOptions
+	data, err := os.ReadFile(recordedMountOptionsPath(kubeletPath, sourceName))
+	if err != nil {
+		return options, err
+	}
+
+	if err := json.Unmarshal(data, &options); err != nil {
+		return options, fmt.Errorf("failed to parse recorded mount options of %q: %w", sourceName, err)
+	}
+	return options, nil
+}
+
+// removeRecordedMountOptions removes recorded mount options of source mount `sourceName` if exists.
+func removeRecordedMountOptions(kubeletPath, sourceName string) error {
+	err := os.Remove(recordedMountOptionsPath(kubeletPath, sourceName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fileExists returns whether a file exists at `path`.
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
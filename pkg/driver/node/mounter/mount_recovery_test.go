@@ -0,0 +1,190 @@
+package mounter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/mount-utils"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestRecoveringMounts(t *testing.T) {
+	t.Run("Re-establishes lost source and bind mounts with recorded mount options and fresh credentials", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mpPod, gotOptions := mountForRecovery(testCtx, credentialprovider.AuthenticationSourceDriver)
+
+		// Emulate a node reboot, all mounts are gone and the Mountpoint Pod is waiting for mount options again
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.targetPath))
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.sourcePath))
+		assertMounted(t, testCtx, testCtx.sourcePath, false)
+
+		received := make(chan mountoptions.Options)
+		go func() {
+			received <- mpPod.receiveMountOptions(testCtx.ctx)
+		}()
+		mpPod.waitForFile(mppod.KnownPathMountSock)
+
+		testCtx.mockCredProvider.EXPECT().
+			Provide(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, provideCtx credentialprovider.ProvideContext) (envprovider.Environment, credentialprovider.AuthenticationSource, error) {
+				assert.Equals(t, testCtx.volumeID, provideCtx.VolumeID)
+				assert.Equals(t, testCtx.podUID, provideCtx.WorkloadPodID)
+				assert.Equals(t, string(mpPod.pod.UID), provideCtx.MountpointPodID)
+				return envprovider.Environment{envprovider.EnvSharedCredentialsFile: "/credentials/recovered"}, credentialprovider.AuthenticationSourceDriver, nil
+			})
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		recoveredOptions := <-received
+		recoveredOptions.Fd = 0
+		assert.Equals(t, gotOptions.Args, recoveredOptions.Args)
+		assert.Equals(t, true, slices.Contains(gotOptions.Env, envprovider.EnvSharedCredentialsFile+"=/credentials/provided"))
+		assert.Equals(t, true, slices.Contains(recoveredOptions.Env, envprovider.EnvSharedCredentialsFile+"=/credentials/recovered"))
+		assertMounted(t, testCtx, testCtx.sourcePath, true)
+		assertMounted(t, testCtx, testCtx.targetPath, true)
+		assert.Equals(t, crdv2.MountpointS3PodMounted, getMountpointPodStatus(testCtx).Phase)
+	})
+
+	t.Run("Refreshes lost bind mounts of a healthy source mount", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mountForRecovery(testCtx, credentialprovider.AuthenticationSourceDriver)
+
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.targetPath))
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		assertMounted(t, testCtx, testCtx.targetPath, true)
+		assert.Equals(t, 2, len(testCtx.mount.MountPoints))
+	})
+
+	t.Run("Does nothing if mounts are healthy", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mountForRecovery(testCtx, credentialprovider.AuthenticationSourceDriver)
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		assert.Equals(t, 2, len(testCtx.mount.MountPoints))
+	})
+
+	t.Run("Skips source mounts without recorded mount options", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mountForRecovery(testCtx, credentialprovider.AuthenticationSourceDriver)
+
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.targetPath))
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.sourcePath))
+		assert.NoError(t, os.RemoveAll(mounter.MountOptionsDir(testCtx.kubeletPath)))
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		assert.Equals(t, 0, len(testCtx.mount.MountPoints))
+	})
+
+	t.Run("Skips source mounts with credentials only kubelet can provide", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mountForRecovery(testCtx, credentialprovider.AuthenticationSourcePod)
+
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.targetPath))
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.sourcePath))
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		assert.Equals(t, 0, len(testCtx.mount.MountPoints))
+	})
+
+	t.Run("Skips target paths without MountpointS3PodAttachments", func(t *testing.T) {
+		testCtx := setupForRecovery(t)
+		mountForRecovery(testCtx, credentialprovider.AuthenticationSourceDriver)
+
+		assert.NoError(t, testCtx.mount.Unmount(testCtx.targetPath))
+		testCtx.s3paCache.TestItems = nil
+
+		assert.NoError(t, testCtx.podMounter.RecoverMounts(testCtx.ctx))
+
+		assertMounted(t, testCtx, testCtx.targetPath, false)
+	})
+}
+
+// setupForRecovery sets up a test context where bind mounts are reported as Mountpoint mounts, as they're in Linux.
+func setupForRecovery(t *testing.T) *testCtx {
+	testCtx := setup(t)
+	testCtx.mountBindSyscall = func(source, target string) error {
+		return testCtx.mount.Mount("mountpoint-s3", target, "fuse", []string{"bind"})
+	}
+	return testCtx
+}
+
+// mountForRecovery mounts the test volume via a Mountpoint Pod using `authenticationSource`,
+// and returns the Mountpoint Pod and the mount options it received.
+func mountForRecovery(testCtx *testCtx, authenticationSource credentialprovider.AuthenticationSource) (*mountpointPod, mountoptions.Options) {
+	t := testCtx.t
+	t.Helper()
+
+	testCtx.mockCredProvider.EXPECT().
+		Provide(testCtx.ctx, gomock.Any()).
+		Return(envprovider.Environment{envprovider.EnvSharedCredentialsFile: "/credentials/provided"}, authenticationSource, nil)
+
+	mpPod := createMountpointPod(testCtx)
+	mpPod.run()
+
+	received := make(chan mountoptions.Options)
+	go func() {
+		received <- mpPod.receiveMountOptions(testCtx.ctx)
+	}()
+
+	err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+		AuthenticationSource: authenticationSource,
+		VolumeID:             testCtx.volumeID,
+		WorkloadPodID:        testCtx.podUID,
+		ServiceAccountTokens: `{"sts.amazonaws.com":{"token":"service-account-token"}}`,
+	}, mountpoint.ParseArgs([]string{mountpoint.ArgReadOnly}), testCtx.fsGroup, envprovider.Environment{})
+	assert.NoError(t, err)
+
+	options := <-received
+	options.Fd = 0
+
+	info, err := os.Stat(filepath.Join(mounter.MountOptionsDir(testCtx.kubeletPath), testCtx.mpPodName+".json"))
+	assert.NoError(t, err)
+	assert.Equals(t, os.FileMode(0600), info.Mode().Perm())
+
+	recorded, err := os.ReadFile(filepath.Join(mounter.MountOptionsDir(testCtx.kubeletPath), testCtx.mpPodName+".json"))
+	assert.NoError(t, err)
+	assert.Equals(t, false, strings.Contains(string(recorded), "service-account-token"))
+	assert.Equals(t, false, strings.Contains(string(recorded), "/credentials/provided"))
+
+	return mpPod, options
+}
+
+// assertMounted asserts whether there is a mount at `path`.
+func assertMounted(t *testing.T, testCtx *testCtx, path string, mounted bool) {
+	t.Helper()
+	mountPoints, err := testCtx.mount.List()
+	assert.NoError(t, err)
+	assert.Equals(t, mounted, slices.ContainsFunc(mountPoints, func(mp mount.MountPoint) bool { return mp.Path == path }))
+}
+
+// waitForFile waits until `path` is created in Mountpoint Pod's communication directory.
+func (mp *mountpointPod) waitForFile(path string) {
+	t := mp.testCtx.t
+	t.Helper()
+
+	err := wait.PollUntilContextCancel(mp.testCtx.ctx, 5*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+		_, err := os.Stat(mppod.PathOnHost(mp.podPath, path))
+		return err == nil, nil
+	})
+	assert.NoError(t, err)
+}
@@ -3,11 +3,14 @@ package mounter
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 )
 
 // Mounter is an interface for mount operations
@@ -15,9 +18,35 @@ type Mounter interface {
 	Mount(ctx context.Context, bucketName string, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string, userEnv envprovider.Environment) error
 	Unmount(ctx context.Context, target string, credentialCtx credentialprovider.CleanupContext) error
 	IsMountPoint(target string) (bool, error)
+	VolumeStats(ctx context.Context, target string) (*VolumeStats, error)
+}
+
+// A CABundleWriter is a [Mounter] that provides custom CA bundles to Mountpoint itself for each mount,
+// as opposed to [PodMounter] whose Mountpoint Pods have them projected by the controller at [mppod.CABundlePath].
+type CABundleWriter interface {
+	// WriteCABundle writes the CA bundle referenced by `source` for the mount at `target`,
+	// and returns the path Mountpoint should use as `--ca-bundle`.
+	WriteCABundle(ctx context.Context, target string, source *volumecontext.CABundleSource) (string, error)
+}
+
+// ErrVolumeNotMounted is returned from [Mounter.VolumeStats] if there is no volume mounted at the given target.
+var ErrVolumeNotMounted = errors.New("mounter: volume is not mounted")
+
+// VolumeStats represents the health and the local cache usage of a mounted volume.
+type VolumeStats struct {
+	// Abnormal is whether the volume is unhealthy, and Message describes why.
+	Abnormal bool
+	Message  string
+	// CacheUsage is the usage of the volume's local cache, or nil if the volume has no local cache.
+	CacheUsage *util.FilesystemUsage
 }
 
 // Internal S3 CSI Driver directory for source mount points
 func SourceMountDir(kubeletPath string) string {
 	return filepath.Join(kubeletPath, "plugins", "s3.csi.aws.com", "mnt")
 }
+
+// Internal S3 CSI Driver directory for mount options of source mount points, see [PodMounter.RecoverMounts]
+func MountOptionsDir(kubeletPath string) string {
+	return filepath.Join(kubeletPath, "plugins", "s3.csi.aws.com", "mount-options")
+}
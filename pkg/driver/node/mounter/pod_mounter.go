@@ -12,13 +12,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
 	mpmounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
@@ -51,7 +51,8 @@ type bindMountSyscall func(source, target string) (err error)
 // A PodMounter is a [Mounter] that mounts Mountpoint on pre-created Kubernetes Pod running in the same node.
 type PodMounter struct {
 	podWatcher        *watcher.Watcher
-	s3paCache         cache.Cache
+	s3paCache         client.Reader
+	s3paClient        client.Client
 	mount             *mpmounter.Mounter
 	kubeletPath       string
 	mountSyscall      mountSyscall
@@ -60,12 +61,15 @@ type PodMounter struct {
 	variant           cluster.Variant
 	credProvider      credentialprovider.ProviderInterface
 	nodeID            string
+	credentialOwners  *credentialOwners
 }
 
 // NewPodMounter creates a new [PodMounter] with given Kubernetes client.
+// If `s3paClient` is non-nil, mount outcomes are reported to the status of MountpointS3PodAttachments.
 func NewPodMounter(
 	podWatcher *watcher.Watcher,
-	s3paCache cache.Cache,
+	s3paCache client.Reader,
+	s3paClient client.Client,
 	credProvider credentialprovider.ProviderInterface,
 	mount *mpmounter.Mounter,
 	mountSyscall mountSyscall,
@@ -77,6 +81,7 @@ func NewPodMounter(
 	return &PodMounter{
 		podWatcher:        podWatcher,
 		s3paCache:         s3paCache,
+		s3paClient:        s3paClient,
 		credProvider:      credProvider,
 		mount:             mount,
 		kubeletPath:       util.ContainerKubeletPath(),
@@ -85,6 +90,7 @@ func NewPodMounter(
 		kubernetesVersion: kubernetesVersion,
 		variant:           variant,
 		nodeID:            nodeID,
+		credentialOwners:  newCredentialOwners(),
 	}, nil
 }
 
@@ -96,11 +102,14 @@ func NewPodMounter(
 //  3. Write credentials to Mountpoint Pod's credentials directory
 //  4. Obtain a FUSE file descriptor
 //  5. Call `mount` syscall with `source` and obtained FUSE file descriptor
-//  6. Send mount options (including FUSE file descriptor) to Mountpoint Pod
+//  6. Send mount options (including FUSE file descriptor) to Mountpoint Pod, and wait for its acknowledgement
 //  7. Wait until Mountpoint successfully mounts at `source`
 //  8. Bind mounts from `source` to `target`
 //
+// The outcome of mounting at `source` is reported to the status of the MountpointS3PodAttachment.
 // If Mountpoint is already mounted at `target`, it will return early at step 3 to ensure credentials are up-to-date.
+// If Mountpoint is already mounted at `source` and another attached workload owns the credentials of the Mountpoint Pod,
+// step 3 is skipped so workloads sharing the Mountpoint Pod don't overwrite each other's credentials.
 // If Mountpoint is already mounted at `source`, it will skip steps 4-7 and only perform bind mount to `target`.
 func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string, userEnv envprovider.Environment) error {
 	volumeName, err := pm.volumeNameFromTargetPath(target)
@@ -131,13 +140,17 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 		return nil
 	}
 
+	phaseStart := time.Now()
 	s3PodAttachment, mpPodName, err := pm.getS3PodAttachmentWithRetry(ctx, volumeName, credentialCtx, fsGroup)
+	metrics.ObserveMountPhase(metrics.MountPhaseWaitForAttachment, phaseStart)
 	if err != nil {
 		klog.Errorf("Failed to find corresponding MountpointS3PodAttachment custom resource for %q: %v. %s", target, err, pm.helpMessageForGettingControllerLogs())
 		return fmt.Errorf("Failed to find corresponding MountpointS3PodAttachment custom resource: %w. %s", err, pm.helpMessageForGettingControllerLogs())
 	}
 
+	phaseStart = time.Now()
 	pod, podPath, err := pm.waitForMountpointPod(ctx, mpPodName)
+	metrics.ObserveMountPhase(metrics.MountPhaseWaitForMountpointPod, phaseStart)
 	if err != nil {
 		klog.Errorf("Failed to wait for Mountpoint Pod %q to be ready for %q: %v. %s", mpPodName, target, err, pm.helpMessageForGettingMountpointPodStatus(err, mpPodName))
 		return fmt.Errorf("Failed to wait for Mountpoint Pod %q to be ready: %w. %s", mpPodName, err, pm.helpMessageForGettingMountpointPodStatus(err, mpPodName))
@@ -154,33 +167,68 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 		}
 	}
 
+	workload := &credentialWorkload{
+		mpPodName:                pod.Name,
+		mpPodUID:                 string(pod.UID),
+		podPath:                  podPath,
+		serviceAccountEKSRoleARN: s3PodAttachment.Spec.WorkloadServiceAccountIAMRoleARN,
+		s3pa:                     s3PodAttachment.DeepCopy(),
+		credentialCtx:            credentialCtx,
+	}
+
+	// The credentials directory is shared by all workloads using the Mountpoint Pod, so once the volume is mounted,
+	// only the workload owning the credentials refreshes them while it's attached.
+	credentialOwner := s3PodAttachment.MountpointS3PodCredentialOwner(pod.Name)
+	if isSourceMountPoint && credentialOwner != "" && credentialOwner != credentialCtx.WorkloadPodID {
+		klog.V(4).Infof("Credentials of Mountpoint Pod %s are owned by workload %s, not providing credentials for %q", pod.Name, credentialOwner, target)
+		pm.credentialOwners.attach(target, workload, false)
+		pm.reportMountStatus(ctx, s3PodAttachment, pod, nil)
+		return pm.bindMountIfNeeded(source, target, pod, isTargetMountPoint)
+	}
+
 	// Note that this part happens before `isMountPoint` check, as we want to update credentials even though
 	// there is an existing mount point at `target`.
+	phaseStart = time.Now()
 	credEnv, authenticationSource, err := pm.provideCredentials(ctx, podPath, string(pod.UID), s3PodAttachment.Spec.WorkloadServiceAccountIAMRoleARN, credentialCtx)
+	metrics.ObserveMountPhase(metrics.MountPhaseProvideCredentials, phaseStart)
 	if err != nil {
 		klog.Errorf("Failed to provide credentials for %q: %v. %s", source, err, pm.helpMessageForGettingMountpointLogs(pod))
+		if !isSourceMountPoint {
+			pm.reportMountStatus(ctx, s3PodAttachment, pod, err)
+		}
 		return fmt.Errorf("Failed to provide credentials for %q: %w. %s", source, err, pm.helpMessageForGettingMountpointLogs(pod))
 	}
+	pm.credentialOwners.attach(target, workload, true)
+	if credentialOwner != credentialCtx.WorkloadPodID {
+		pm.reportCredentialOwner(ctx, s3PodAttachment, pod.Name, credentialCtx.WorkloadPodID)
+	}
 
 	if !isSourceMountPoint {
-		err = pm.mountS3AtSource(ctx, source, pod, podPath, bucketName, credEnv, userEnv, authenticationSource, args)
+		err = pm.mountS3AtSource(ctx, source, pod, podPath, bucketName, credentialCtx, credEnv, userEnv, authenticationSource, args)
 		if err != nil {
+			pm.reportMountStatus(ctx, s3PodAttachment, pod, err)
 			return fmt.Errorf("Failed to mount at source %q: %w. %s", source, err, pm.helpMessageForGettingMountpointLogs(pod))
 		}
 	}
+	pm.reportMountStatus(ctx, s3PodAttachment, pod, nil)
+
+	return pm.bindMountIfNeeded(source, target, pod, isTargetMountPoint)
+}
 
+// bindMountIfNeeded bind mounts `source` served by `mpPod` to `target`, unless `target` is already mounted.
+func (pm *PodMounter) bindMountIfNeeded(source, target string, mpPod *corev1.Pod, isTargetMountPoint bool) error {
 	if isTargetMountPoint {
 		klog.V(4).Infof("Target path %q is already mounted. Only refreshed credentials.", target)
 		return nil
 	}
 
-	err = pm.bindMountSyscallWithDefault(source, target)
+	err := pm.bindMountSyscallWithDefault(source, target)
 	if err != nil {
 		klog.Errorf("Failed to bind mount %q to target %q: %v", source, target, err)
 		return fmt.Errorf("Failed to bind mount %q to target %q: %w", source, target, err)
 	}
 
-	klog.V(4).Infof("Created bind mount to target %s from Mountpoint Pod %s at %s", target, pod.Name, source)
+	klog.V(4).Infof("Created bind mount to target %s from Mountpoint Pod %s at %s", target, mpPod.Name, source)
 
 	return nil
 }
@@ -193,6 +241,7 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 //   - mpPod: Mountpoint Pod that will serve this mount point
 //   - podPath: Base path for Pod-specific files
 //   - bucketName: Name of the S3 bucket to mount
+//   - credentialCtx: Context the credentials are provided with, recorded to provide them again while recovering the mount
 //   - credEnv: Environment variables related to AWS credentials
 //   - userEnv: Environment variables provided by user
 //   - authenticationSource: Authentication source from PV volume attribute
@@ -209,14 +258,14 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 //
 // If any step fails, it ensures cleanup by unmounting the source path.
 func (pm *PodMounter) mountS3AtSource(ctx context.Context, source string, mpPod *corev1.Pod, podPath string,
-	bucketName string, credEnv envprovider.Environment, userEnv envprovider.Environment, authenticationSource credentialprovider.AuthenticationSource,
-	args mountpoint.Args) error {
+	bucketName string, credentialCtx credentialprovider.ProvideContext, credEnv envprovider.Environment, userEnv envprovider.Environment,
+	authenticationSource credentialprovider.AuthenticationSource, args mountpoint.Args) error {
 
 	// Build environment with precedence (highest wins): credEnv > Default() > userEnv
+	// `credEnv` is merged in `mountAtSource` as it's not recorded
 	env := envprovider.Environment{}
 	env.Merge(userEnv)
 	env.Merge(envprovider.Default())
-	env.Merge(credEnv)
 
 	// Move `--aws-max-attempts` to env if provided
 	if maxAttempts, ok := args.Remove(mountpoint.ArgAWSMaxAttempts); ok {
@@ -225,12 +274,34 @@ func (pm *PodMounter) mountS3AtSource(ctx context.Context, source string, mpPod
 
 	args.Set(mountpoint.ArgUserAgentPrefix, UserAgent(authenticationSource, pm.kubernetesVersion, pm.variant))
 
+	return pm.mountAtSource(ctx, source, mpPod, podPath, sourceMountOptions{
+		BucketName:        bucketName,
+		Args:              args.SortedList(),
+		Env:               env,
+		CredentialContext: credentialCtx,
+	}, credEnv)
+}
+
+// mountAtSource obtains a FUSE file descriptor by mounting at `source`, and starts Mountpoint in `mpPod` with it
+// using `options` and credentials in `credEnv`. The mount options are recorded once Mountpoint successfully starts,
+// so the mount can be re-established with the same options by [PodMounter.RecoverMounts] if it's lost.
+//
+// If any step fails, it ensures cleanup by unmounting the source path.
+func (pm *PodMounter) mountAtSource(ctx context.Context, source string, mpPod *corev1.Pod, podPath string,
+	options sourceMountOptions, credEnv envprovider.Environment) error {
 	podMountSockPath := mppod.PathOnHost(podPath, mppod.KnownPathMountSock)
 	podMountErrorPath := mppod.PathOnHost(podPath, mppod.KnownPathMountError)
 
+	args := mountpoint.ParseArgs(options.Args)
+	env := envprovider.Environment{}
+	env.Merge(options.Env)
+	env.Merge(credEnv)
+
 	klog.V(4).Infof("Mounting %s for %s", source, mpPod.Name)
 
+	phaseStart := time.Now()
 	fuseDeviceFD, err := pm.mountSyscallWithDefault(source, args)
+	metrics.ObserveMountPhase(metrics.MountPhaseMountSyscall, phaseStart)
 	if err != nil {
 		klog.Errorf("Failed to mount %s: %v", source, err)
 		return fmt.Errorf("Failed to mount %s: %w", source, err)
@@ -265,29 +336,53 @@ func (pm *PodMounter) mountS3AtSource(ctx context.Context, source string, mpPod
 
 	klog.V(4).Infof("Sending mount options to Mountpoint Pod %s on %s", mpPod.Name, podMountSockPath)
 
-	err = mountoptions.Send(ctx, podMountSockPath, mountoptions.Options{
+	mountOptions := mountoptions.Options{
 		Fd:         fuseDeviceFD,
-		BucketName: bucketName,
+		BucketName: options.BucketName,
 		Args:       args.SortedList(),
 		Env:        env.List(),
-	})
-	if err != nil {
-		klog.Errorf("Failed to send mount option to Mountpoint Pod %s for %s: %v. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
-		return fmt.Errorf("Failed to send mount options to Mountpoint Pod %s for %s: %w. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
 	}
 
-	err = pm.waitForMount(ctx, source, mpPod.Name, podMountErrorPath)
-	if err != nil {
-		klog.Errorf("Failed to wait for Mountpoint Pod %s to be ready for %s: %v. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
-		return fmt.Errorf("Failed to wait for Mountpoint Pod %s to be ready for %s: %w. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+	if mppod.MountOptionsProtocolVersionOf(mpPod) == 0 {
+		// Mountpoint Pods predating the mount options protocol can't parse framed messages and never reply,
+		// poll for the outcome instead
+		phaseStart = time.Now()
+		err = mountoptions.SendLegacy(ctx, podMountSockPath, mountOptions)
+		metrics.ObserveMountPhase(metrics.MountPhaseSendOptions, phaseStart)
+		if err != nil {
+			klog.Errorf("Failed to send mount option to Mountpoint Pod %s for %s: %v. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+			return fmt.Errorf("Failed to send mount options to Mountpoint Pod %s for %s: %w. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+		}
+
+		phaseStart = time.Now()
+		err = pm.waitForMount(ctx, source, mpPod.Name, podMountErrorPath)
+		metrics.ObserveMountPhase(metrics.MountPhaseWaitForMount, phaseStart)
+		if err != nil {
+			klog.Errorf("Failed to wait for Mountpoint Pod %s to be ready for %s: %v. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+			return fmt.Errorf("Failed to wait for Mountpoint Pod %s to be ready for %s: %w. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+		}
+	} else {
+		// The Mountpoint Pod replies once Mountpoint starts serving the mount, or fails to do so
+		phaseStart = time.Now()
+		err = mountoptions.Send(ctx, podMountSockPath, mountOptions)
+		metrics.ObserveMountPhase(metrics.MountPhaseSendOptions, phaseStart)
+		if err != nil {
+			klog.Errorf("Failed to send mount option to Mountpoint Pod %s for %s: %v. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+			return fmt.Errorf("Failed to send mount options to Mountpoint Pod %s for %s: %w. %s", mpPod.Name, source, err, pm.helpMessageForGettingMountpointLogs(mpPod))
+		}
 	}
 
 	// Mountpoint successfully started, so don't unmount the filesystem
 	unmount = false
+
+	if err := recordMountOptions(pm.kubeletPath, filepath.Base(source), options); err != nil {
+		klog.Warningf("Failed to record mount options of %s, it won't be recovered if the mount is lost: %v", source, err)
+	}
 	return nil
 }
 
 // Unmount unmounts only the bind mount point at `target`.
+// If the workload being unmounted owns the credentials of the Mountpoint Pod, they're failed over to another attached workload.
 // Unmounting of source mount and credential cleanup for PodMounter is done separately in PodUnmounter
 // For systemd mounts it will unmount systemd mount and also remove credentials.
 func (pm *PodMounter) Unmount(ctx context.Context, target string, credentialCtx credentialprovider.CleanupContext) error {
@@ -299,6 +394,8 @@ func (pm *PodMounter) Unmount(ctx context.Context, target string, credentialCtx
 		return fmt.Errorf("Failed to unmount %q: %w", target, err)
 	}
 
+	pm.failOverCredentials(ctx, target)
+
 	if isSystemDMountpoint {
 		klog.Infof("Target %q was SystemD Mountpoint. Will cleanup credentials.", target)
 		credentialCtx.SetAsSystemDMountpoint()
@@ -360,8 +457,75 @@ func (pm *PodMounter) waitForMountpointPod(ctx context.Context, podName string)
 	return pod, pm.podPath(string(pod.UID)), nil
 }
 
-// waitForMount waits until Mountpoint is successfully mounted at `target`.
-// It returns an error if Mountpoint fails to mount.
+// reportMountStatus reports the outcome of mounting at `source` of `mpPod` to the status of `s3pa`.
+// If `mountErr` is nil, `mpPod` is reported as mounted, otherwise as failed with `mountErr`.
+//
+// Reporting is best-effort, a failure is only logged as the status is purely informational.
+func (pm *PodMounter) reportMountStatus(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, mpPod *corev1.Pod, mountErr error) {
+	if pm.s3paClient == nil {
+		return
+	}
+
+	phase, reason, message := crdv2.MountpointS3PodMounted, crdv2.ReasonMounted, "Volume is mounted"
+	if mountErr != nil {
+		phase, reason, message = crdv2.MountpointS3PodFailed, crdv2.ReasonMountFailed, mountErr.Error()
+	}
+
+	// `s3pa` is owned by the cache, so it must not be modified
+	s3pa = s3pa.DeepCopy()
+	err := crdv2.UpdateStatus(ctx, pm.s3paClient, s3pa, func(s3pa *crdv2.MountpointS3PodAttachment) {
+		s3pa.SetMountpointS3PodPhase(mpPod.Name, phase, mpPod.Labels[mppod.LabelMountpointVersion], reason, message)
+	})
+	if err != nil {
+		klog.Warningf("Failed to report status of Mountpoint Pod %s to MountpointS3PodAttachment %s: %v", mpPod.Name, s3pa.Name, err)
+	}
+}
+
+// reportCredentialOwner records `workloadPodUID` as the owner of the credentials provided to `mpPodName` to the status of `s3pa`.
+//
+// Reporting is best-effort, a failure is only logged as the ownership is also tracked in memory.
+func (pm *PodMounter) reportCredentialOwner(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, mpPodName, workloadPodUID string) {
+	if pm.s3paClient == nil {
+		return
+	}
+
+	// `s3pa` is owned by the cache, so it must not be modified
+	s3pa = s3pa.DeepCopy()
+	err := crdv2.UpdateStatus(ctx, pm.s3paClient, s3pa, func(s3pa *crdv2.MountpointS3PodAttachment) {
+		s3pa.SetMountpointS3PodCredentialOwner(mpPodName, workloadPodUID)
+	})
+	if err != nil {
+		klog.Warningf("Failed to report credential owner of Mountpoint Pod %s to MountpointS3PodAttachment %s: %v", mpPodName, s3pa.Name, err)
+	}
+}
+
+// failOverCredentials fails the credentials of the Mountpoint Pod serving `target` over to another attached workload
+// if the workload being unmounted from `target` owns them, so the credentials don't expire with the detached workload.
+func (pm *PodMounter) failOverCredentials(ctx context.Context, target string) {
+	mpPodName, ok := pm.credentialOwners.mountpointPodName(target)
+	if !ok {
+		return
+	}
+
+	unlockMountpointPod := lockMountpointPod(mpPodName)
+	defer unlockMountpointPod()
+
+	next, ok := pm.credentialOwners.detach(target)
+	if !ok {
+		return
+	}
+
+	klog.Infof("Workload owning credentials of Mountpoint Pod %s is unmounted from %q, failing over to workload %s", mpPodName, target, next.credentialCtx.WorkloadPodID)
+	_, _, err := pm.provideCredentials(ctx, next.podPath, next.mpPodUID, next.serviceAccountEKSRoleARN, next.credentialCtx)
+	if err != nil {
+		// The credentials will be refreshed once kubelet republishes the volume for the new owner
+		klog.Errorf("Failed to fail over credentials of Mountpoint Pod %s to workload %s: %v", mpPodName, next.credentialCtx.WorkloadPodID, err)
+	}
+	pm.reportCredentialOwner(ctx, next.s3pa, mpPodName, next.credentialCtx.WorkloadPodID)
+}
+
+// waitForMount waits until Mountpoint is successfully mounted at `target`, it's only used with Mountpoint Pods
+// predating replies to mount options. It returns an error if Mountpoint fails to mount.
 func (pm *PodMounter) waitForMount(parentCtx context.Context, target, podName, podMountErrorPath string) error {
 	ctx, cancel := context.WithCancel(parentCtx)
 	// Cancel at the end to ensure we cancel polling from goroutines.
@@ -545,17 +709,30 @@ func (pm *PodMounter) getS3PodAttachmentWithRetry(ctx context.Context, volumeNam
 	// Instead, we rely on matching the workload pod UID in the final filtering step below.
 	fieldFilters := client.MatchingFields{
 		crdv2.FieldNodeName:             pm.nodeID,
-		crdv2.FieldPersistentVolumeName: volumeName,
-		crdv2.FieldVolumeID:             credentialCtx.VolumeID,
 		crdv2.FieldWorkloadFSGroup:      fsGroup,
 		crdv2.FieldAuthenticationSource: credentialCtx.AuthenticationSource,
+		crdv2.FieldAssumeRoleARN:        credentialCtx.AssumeRoleARN,
 	}
-	if credentialCtx.AuthenticationSource == credentialprovider.AuthenticationSourcePod {
+	if !credentialCtx.InlineVolume {
+		// The PersistentVolume name and volume ID of CSI ephemeral inline volumes are synthesized by the controller
+		// and not known to us, their volume is matched in the final filtering step below instead.
+		fieldFilters[crdv2.FieldPersistentVolumeName] = volumeName
+		fieldFilters[crdv2.FieldVolumeID] = credentialCtx.VolumeID
+	}
+	switch credentialCtx.AuthenticationSource {
+	case credentialprovider.AuthenticationSourcePod:
 		fieldFilters[crdv2.FieldWorkloadNamespace] = credentialCtx.PodNamespace
 		fieldFilters[crdv2.FieldWorkloadServiceAccountName] = credentialCtx.ServiceAccountName
 		// Note that we intentionally do not include `FieldWorkloadServiceAccountIAMRoleARN` to list filters because
 		// CSI Driver Node does not know which role ARN to use (if any).
 		// Role ARN is determined by reconciler and passed to node via MountpointS3PodAttachment.
+	case credentialprovider.AuthenticationSourceSecret:
+		fieldFilters[crdv2.FieldWorkloadNamespace] = credentialCtx.PodNamespace
+	default:
+		if credentialprovider.IsCredentialPlugin(credentialCtx.AuthenticationSource) {
+			fieldFilters[crdv2.FieldWorkloadNamespace] = credentialCtx.PodNamespace
+			fieldFilters[crdv2.FieldWorkloadServiceAccountName] = credentialCtx.ServiceAccountName
+		}
 	}
 
 	for {
@@ -574,7 +751,7 @@ func (pm *PodMounter) getS3PodAttachmentWithRetry(ctx context.Context, volumeNam
 		for _, s3pa := range s3paList.Items {
 			for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
 				for _, attachment := range attachments {
-					if attachment.WorkloadPodUID == credentialCtx.WorkloadPodID {
+					if attachment.WorkloadPodUID == credentialCtx.WorkloadPodID && isAttachmentForVolume(&s3pa, attachment, volumeName) {
 						return &s3pa, mpPodName, nil
 					}
 				}
@@ -589,6 +766,16 @@ func (pm *PodMounter) getS3PodAttachmentWithRetry(ctx context.Context, volumeNam
 	}
 }
 
+// isAttachmentForVolume returns whether `attachment` in `s3pa` is for the volume `volumeName` extracted from the target path.
+// For CSI ephemeral inline volumes, the target path contains the name of the volume in the workload pod's spec
+// instead of a PersistentVolume name.
+func isAttachmentForVolume(s3pa *crdv2.MountpointS3PodAttachment, attachment crdv2.WorkloadAttachment, volumeName string) bool {
+	if attachment.WorkloadVolumeName != "" {
+		return attachment.WorkloadVolumeName == volumeName
+	}
+	return s3pa.Spec.PersistentVolumeName == volumeName
+}
+
 func hostPluginDirWithDefault() string {
 	hostPluginDir := os.Getenv("HOST_PLUGIN_DIR")
 	if hostPluginDir == "" {
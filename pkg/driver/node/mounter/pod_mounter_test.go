@@ -2,10 +2,12 @@ package mounter_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"syscall"
@@ -15,10 +17,14 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/mount-utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
@@ -49,6 +55,7 @@ type testCtx struct {
 	mount            *mount.FakeMounter
 	mockCredProvider *mock_credentialprovider.MockProviderInterface
 	s3paCache        *mounter.FakeCache
+	s3paClient       client.Client
 	mountSyscall     func(target string, args mountpoint.Args) (fd int, err error)
 	mountBindSyscall func(source, target string) (err error)
 
@@ -128,6 +135,7 @@ func setup(t *testing.T) *testCtx {
 	}
 
 	testCrd := crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-s3pa"},
 		Spec: crdv2.MountpointS3PodAttachmentSpec{
 			NodeName:             testCtx.nodeName,
 			PersistentVolumeName: testCtx.pvName,
@@ -138,6 +146,14 @@ func setup(t *testing.T) *testCtx {
 			},
 		},
 	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, crdv2.AddToScheme(scheme))
+	testCtx.s3paClient = ctrlfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&testCrd).
+		WithStatusSubresource(&crdv2.MountpointS3PodAttachment{}).
+		Build()
+	assert.NoError(t, testCtx.s3paClient.Get(ctx, types.NamespacedName{Name: testCrd.Name}, &testCrd))
 	testCtx.s3paCache.TestItems = []crdv2.MountpointS3PodAttachment{testCrd}
 
 	mountSyscall := func(target string, args mountpoint.Args) (fd int, err error) {
@@ -166,7 +182,7 @@ func setup(t *testing.T) *testCtx {
 	err = podWatcher.Start(stopCh)
 	assert.NoError(t, err)
 
-	podMounter, err := mounter.NewPodMounter(podWatcher, s3paCache, mockCredProvider, mpmounter.NewWithMount(fakeMounter), mountSyscall,
+	podMounter, err := mounter.NewPodMounter(podWatcher, s3paCache, testCtx.s3paClient, mockCredProvider, mpmounter.NewWithMount(fakeMounter), mountSyscall,
 		mountBindSyscall, testK8sVersion, nodeName, cluster.DefaultKubernetes)
 	assert.NoError(t, err)
 
@@ -240,6 +256,10 @@ func TestPodMounter(t *testing.T) {
 				},
 				Env: env.List(),
 			}, got)
+
+			status := getMountpointPodStatus(testCtx)
+			assert.Equals(t, crdv2.MountpointS3PodMounted, status.Phase)
+			assert.Equals(t, "", status.LastMountError)
 		})
 
 		t.Run("Waits for Mountpoint Pod", func(t *testing.T) {
@@ -265,6 +285,76 @@ func TestPodMounter(t *testing.T) {
 			assert.NoError(t, err)
 		})
 
+		t.Run("Uses the legacy framing for Mountpoint Pods predating the mount options protocol", func(t *testing.T) {
+			testCtx := setup(t)
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			legacy := make(chan bool, 1)
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				delete(mpPod.pod.Annotations, mppod.AnnotationMountOptionsProtocolVersion)
+				var err error
+				mpPod.pod, err = testCtx.client.CoreV1().Pods(mountpointPodNamespace).Update(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+				assert.NoError(t, err)
+				mpPod.run()
+
+				mountSock := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountSock)
+				req, err := mountoptions.Recv(testCtx.ctx, mountSock)
+				assert.NoError(t, err)
+				syscall.Close(req.Options.Fd)
+				legacy <- req.Legacy()
+				assert.NoError(t, req.Reply(nil))
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID:      testCtx.volumeID,
+				WorkloadPodID: testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), testCtx.fsGroup, envprovider.Environment{})
+			assert.NoError(t, err)
+			assert.Equals(t, true, <-legacy)
+		})
+
+		t.Run("Finds the Mountpoint Pod of an inline volume by its name in the workload's spec", func(t *testing.T) {
+			testCtx := setup(t)
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+			// For CSI ephemeral inline volumes, the target path contains the name of the volume in the workload's spec
+			inlineS3PA := func(mpPodName, workloadVolumeName string) crdv2.MountpointS3PodAttachment {
+				return crdv2.MountpointS3PodAttachment{
+					Spec: crdv2.MountpointS3PodAttachmentSpec{
+						NodeName:             testCtx.nodeName,
+						PersistentVolumeName: "inline-" + mpPodName,
+						VolumeID:             "inline-" + mpPodName,
+						WorkloadFSGroup:      testCtx.fsGroup,
+						MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+							mpPodName: {{WorkloadPodUID: testCtx.podUID, WorkloadVolumeName: workloadVolumeName}},
+						},
+					},
+				}
+			}
+			testCtx.s3paCache.TestItems = []crdv2.MountpointS3PodAttachment{
+				inlineS3PA("other-mppod", "other-volume"),
+				inlineS3PA(testCtx.mpPodName, testCtx.pvName),
+			}
+
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.run()
+				mpPod.receiveMountOptions(testCtx.ctx)
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID:      "csi-kubelet-generated-volume-id",
+				WorkloadPodID: testCtx.podUID,
+				InlineVolume:  true,
+			}, mountpoint.ParseArgs(nil), testCtx.fsGroup, envprovider.Environment{})
+			assert.NoError(t, err)
+		})
+
 		t.Run("Creates credential directory with group access", func(t *testing.T) {
 			testCtx := setup(t)
 			testCtx.mockCredProvider.EXPECT().
@@ -421,6 +511,84 @@ func TestPodMounter(t *testing.T) {
 			assert.Equals(t, int32(2), bindMountCount.Load())
 		})
 
+		t.Run("Keeps credentials of the owning workload and fails over once it's unmounted", func(t *testing.T) {
+			testCtx := setup(t)
+			firstPodUID, firstTargetPath := testCtx.podUID, testCtx.targetPath
+
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, provideCtx credentialprovider.ProvideContext) (envprovider.Environment, credentialprovider.AuthenticationSource, error) {
+					assert.Equals(t, firstPodUID, provideCtx.WorkloadPodID)
+					return envprovider.Environment{}, credentialprovider.AuthenticationSourcePod, nil
+				})
+
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.run()
+				mpPod.receiveMountOptions(testCtx.ctx)
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, firstTargetPath, credentialprovider.ProvideContext{
+				AuthenticationSource: credentialprovider.AuthenticationSourcePod,
+				VolumeID:             testCtx.volumeID,
+				WorkloadPodID:        firstPodUID,
+			}, mountpoint.ParseArgs(nil), testCtx.fsGroup, envprovider.Environment{})
+			assert.NoError(t, err)
+			assert.Equals(t, firstPodUID, getMountpointPodStatus(testCtx).CredentialOwnerPodUID)
+
+			// Second Pod shares the Mountpoint Pod while the first one owns the credentials
+			secondPodUID := uuid.New().String()
+			secondTargetPath := filepath.Join(
+				testCtx.kubeletPath,
+				fmt.Sprintf("pods/%s/volumes/kubernetes.io~csi/%s/mount", secondPodUID, testCtx.pvName),
+			)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(secondTargetPath), 0750))
+
+			s3pa := &crdv2.MountpointS3PodAttachment{}
+			assert.NoError(t, testCtx.s3paClient.Get(testCtx.ctx, client.ObjectKey{Name: "test-s3pa"}, s3pa))
+			s3pa.Spec.MountpointS3PodAttachments[testCtx.mpPodName] = append(s3pa.Spec.MountpointS3PodAttachments[testCtx.mpPodName],
+				crdv2.WorkloadAttachment{WorkloadPodUID: secondPodUID})
+			assert.NoError(t, testCtx.s3paClient.Update(testCtx.ctx, s3pa))
+			testCtx.s3paCache.TestItems = []crdv2.MountpointS3PodAttachment{*s3pa}
+
+			err = testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, secondTargetPath, credentialprovider.ProvideContext{
+				AuthenticationSource: credentialprovider.AuthenticationSourcePod,
+				VolumeID:             testCtx.volumeID,
+				WorkloadPodID:        secondPodUID,
+				ServiceAccountTokens: "second-pod-tokens",
+			}, mountpoint.ParseArgs(nil), testCtx.fsGroup, envprovider.Environment{})
+			assert.NoError(t, err)
+			assert.Equals(t, firstPodUID, getMountpointPodStatus(testCtx).CredentialOwnerPodUID)
+
+			ok, err := testCtx.podMounter.IsMountPoint(secondTargetPath)
+			assert.NoError(t, err)
+			assert.Equals(t, true, ok)
+
+			// Unmounting the first Pod fails the credentials over to the second one
+			testCtx.mockCredProvider.EXPECT().
+				Provide(testCtx.ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, provideCtx credentialprovider.ProvideContext) (envprovider.Environment, credentialprovider.AuthenticationSource, error) {
+					assert.Equals(t, secondPodUID, provideCtx.WorkloadPodID)
+					assert.Equals(t, "second-pod-tokens", provideCtx.ServiceAccountTokens)
+					assert.Equals(t, testCtx.mpPodUID, provideCtx.MountpointPodID)
+					return envprovider.Environment{}, credentialprovider.AuthenticationSourcePod, nil
+				})
+
+			err = testCtx.podMounter.Unmount(testCtx.ctx, firstTargetPath, credentialprovider.CleanupContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    firstPodUID,
+			})
+			assert.NoError(t, err)
+			assert.Equals(t, secondPodUID, getMountpointPodStatus(testCtx).CredentialOwnerPodUID)
+
+			// Unmounting the last Pod has nothing to fail over to
+			err = testCtx.podMounter.Unmount(testCtx.ctx, secondTargetPath, credentialprovider.CleanupContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    secondPodUID,
+			})
+			assert.NoError(t, err)
+		})
+
 		t.Run("Updates credentials for existing SystemD mounts", func(t *testing.T) {
 			testCtx := setup(t)
 			t.Setenv("SUPPORT_LEGACY_SYSTEMD_MOUNTS", "true")
@@ -512,12 +680,8 @@ func TestPodMounter(t *testing.T) {
 			go func() {
 				mpPod := createMountpointPod(testCtx)
 				mpPod.run()
-				mpPod.receiveMountOptions(testCtx.ctx)
-
 				// Emulate that Mountpoint failed to mount
-				mountErrorPath := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountError)
-				err := os.WriteFile(mountErrorPath, []byte("mount failed"), 0777)
-				assert.NoError(t, err)
+				mpPod.failMount(testCtx.ctx, errors.New("mount failed"))
 			}()
 
 			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
@@ -528,6 +692,12 @@ func TestPodMounter(t *testing.T) {
 				t.Errorf("mount shouldn't succeeded if Mountpoint fails to start")
 			}
 
+			status := getMountpointPodStatus(testCtx)
+			assert.Equals(t, crdv2.MountpointS3PodFailed, status.Phase)
+			if !strings.Contains(status.LastMountError, "mount failed") {
+				t.Errorf("Expected last mount error to contain the mount error, but got: %s", status.LastMountError)
+			}
+
 			ok, err := testCtx.mount.IsMountPoint(testCtx.sourcePath)
 			assert.NoError(t, err)
 			if ok {
@@ -555,12 +725,8 @@ func TestPodMounter(t *testing.T) {
 
 			go func() {
 				mpPod.run()
-				mpPod.receiveMountOptions(testCtx.ctx)
-
 				// Emulate that Mountpoint failed to mount
-				mountErrorPath := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountError)
-				err := os.WriteFile(mountErrorPath, []byte("mount failed"), 0777)
-				assert.NoError(t, err)
+				mpPod.failMount(testCtx.ctx, errors.New("mount failed"))
 			}()
 
 			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
@@ -683,6 +849,120 @@ func TestPodMounter(t *testing.T) {
 	})
 }
 
+func TestPodMounterVolumeStats(t *testing.T) {
+	mountVolume := func(testCtx *testCtx, configurePod func(pod *corev1.Pod)) *mountpointPod {
+		testCtx.t.Helper()
+		testCtx.mockCredProvider.EXPECT().
+			Provide(testCtx.ctx, gomock.Any()).
+			Return(envprovider.Environment{}, credentialprovider.AuthenticationSourceDriver, nil)
+
+		mpPod := createMountpointPod(testCtx)
+		if configurePod != nil {
+			configurePod(mpPod.pod)
+			var err error
+			mpPod.pod, err = testCtx.client.CoreV1().Pods(mountpointPodNamespace).Update(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+			assert.NoError(testCtx.t, err)
+		}
+		go func() {
+			mpPod.run()
+			mpPod.receiveMountOptions(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+			VolumeID:      testCtx.volumeID,
+			WorkloadPodID: testCtx.podUID,
+		}, mountpoint.ParseArgs(nil), testCtx.fsGroup, envprovider.Environment{})
+		assert.NoError(testCtx.t, err)
+		return mpPod
+	}
+
+	t.Run("Healthy volume without local cache", func(t *testing.T) {
+		testCtx := setup(t)
+		mountVolume(testCtx, nil)
+
+		stats, err := testCtx.podMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, &mounter.VolumeStats{}, stats)
+	})
+
+	t.Run("Returns not mounted error if target is not mounted", func(t *testing.T) {
+		testCtx := setup(t)
+
+		_, err := testCtx.podMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.Equals(t, mounter.ErrVolumeNotMounted, err)
+	})
+
+	t.Run("Abnormal if Mountpoint reported a mount error", func(t *testing.T) {
+		testCtx := setup(t)
+		mpPod := mountVolume(testCtx, nil)
+
+		err := os.WriteFile(mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountError), []byte("access denied"), 0600)
+		assert.NoError(t, err)
+
+		stats, err := testCtx.podMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, true, stats.Abnormal)
+		if !strings.Contains(stats.Message, "access denied") {
+			t.Errorf("Expected message to contain the mount error, but got: %s", stats.Message)
+		}
+	})
+
+	t.Run("Abnormal if Mountpoint Pod is not running", func(t *testing.T) {
+		testCtx := setup(t)
+		mpPod := mountVolume(testCtx, nil)
+
+		mpPod.pod.Status.Phase = corev1.PodFailed
+		_, err := testCtx.client.CoreV1().Pods(mountpointPodNamespace).UpdateStatus(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		// Pod watcher observes the update asynchronously
+		var stats *mounter.VolumeStats
+		for range 50 {
+			stats, err = testCtx.podMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+			assert.NoError(t, err)
+			if stats.Abnormal {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equals(t, true, stats.Abnormal)
+		if !strings.Contains(stats.Message, "is not running") {
+			t.Errorf("Expected message to mention Mountpoint Pod is not running, but got: %s", stats.Message)
+		}
+	})
+
+	t.Run("Reports usage of disk-backed emptyDir local cache", func(t *testing.T) {
+		testCtx := setup(t)
+		sizeLimit := resource.MustParse("1Mi")
+		mpPod := mountVolume(testCtx, func(pod *corev1.Pod) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name: mppod.LocalCacheDirName,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+				},
+			})
+		})
+
+		cachePath := filepath.Join(mpPod.podPath, "volumes", "kubernetes.io~empty-dir", mppod.LocalCacheDirName)
+		assert.NoError(t, os.MkdirAll(filepath.Join(cachePath, "blocks"), 0750))
+		assert.NoError(t, os.WriteFile(filepath.Join(cachePath, "blocks", "block-1"), make([]byte, 64*1024), 0600))
+
+		stats, err := testCtx.podMounter.VolumeStats(testCtx.ctx, testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, false, stats.Abnormal)
+		if stats.CacheUsage == nil {
+			t.Fatal("Expected cache usage to be reported")
+		}
+		assert.Equals(t, sizeLimit.Value(), stats.CacheUsage.TotalBytes)
+		// The cache directory, `blocks` directory and `block-1` file
+		assert.Equals(t, int64(3), stats.CacheUsage.UsedInodes)
+		if stats.CacheUsage.UsedBytes < 64*1024 {
+			t.Errorf("Expected used bytes to be at least %d, but got %d", 64*1024, stats.CacheUsage.UsedBytes)
+		}
+		assert.Equals(t, stats.CacheUsage.TotalBytes-stats.CacheUsage.UsedBytes, stats.CacheUsage.AvailableBytes)
+	})
+}
+
 type mountpointPod struct {
 	testCtx *testCtx
 	pod     *corev1.Pod
@@ -697,6 +977,9 @@ func createMountpointPod(testCtx *testCtx) *mountpointPod {
 		ObjectMeta: metav1.ObjectMeta{
 			UID:  types.UID(testCtx.mpPodUID),
 			Name: testCtx.mpPodName,
+			Annotations: map[string]string{
+				mppod.AnnotationMountOptionsProtocolVersion: strconv.FormatUint(uint64(mountoptions.ProtocolVersion), 10),
+			},
 		},
 	}
 	pod, err := testCtx.client.CoreV1().Pods(mountpointPodNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
@@ -723,7 +1006,26 @@ func (mp *mountpointPod) run() {
 func (mp *mountpointPod) receiveMountOptions(ctx context.Context) mountoptions.Options {
 	mp.testCtx.t.Helper()
 	mountSock := mppod.PathOnHost(mp.podPath, mppod.KnownPathMountSock)
-	options, err := mountoptions.Recv(ctx, mountSock)
+	req, err := mountoptions.Recv(ctx, mountSock)
 	assert.NoError(mp.testCtx.t, err)
-	return options
+	assert.NoError(mp.testCtx.t, req.Reply(nil))
+	return req.Options
+}
+
+// failMount receives mount options and replies with `mountErr` as if Mountpoint failed to start serving the mount.
+func (mp *mountpointPod) failMount(ctx context.Context, mountErr error) mountoptions.Options {
+	mp.testCtx.t.Helper()
+	mountSock := mppod.PathOnHost(mp.podPath, mppod.KnownPathMountSock)
+	req, err := mountoptions.Recv(ctx, mountSock)
+	assert.NoError(mp.testCtx.t, err)
+	assert.NoError(mp.testCtx.t, req.Reply(mountErr))
+	return req.Options
+}
+
+// getMountpointPodStatus returns the status of the test Mountpoint Pod reported to the MountpointS3PodAttachment.
+func getMountpointPodStatus(testCtx *testCtx) crdv2.MountpointS3PodStatus {
+	testCtx.t.Helper()
+	s3pa := &crdv2.MountpointS3PodAttachment{}
+	assert.NoError(testCtx.t, testCtx.s3paClient.Get(testCtx.ctx, client.ObjectKey{Name: "test-s3pa"}, s3pa))
+	return s3pa.Status.MountpointS3PodStatuses[testCtx.mpPodName]
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
 	mpmounter "github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
@@ -110,7 +111,9 @@ func (u *PodUnmounter) CleanupDanglingMounts() error {
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				klog.Infof("Found a dangling Mountpoint mount %q, cleaning up", mpPodName)
-				if _, err := u.unmountAndRemoveMountpointSource(source); err != nil {
+				_, err := u.unmountAndRemoveMountpointSource(source)
+				metrics.RecordDanglingMountCleanup(err)
+				if err != nil {
 					klog.Errorf("Failed to unmount and remove Mountpoint %q: %v", source, err)
 				} else {
 					klog.Infof("Successfully cleaned dangling Mountpoint mount %q", mpPodName)
@@ -141,6 +144,7 @@ func (u *PodUnmounter) unmountMountpointPodIfNeeded(mpPod *corev1.Pod) {
 }
 
 // cleanUnmount performs a clean unmount for `mpPod`.
+// The result is recorded in metrics, unless the cleanup is skipped or postponed as `mpPod` is still in use.
 func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
 	klog.V(5).Infof("Starting unmount procedure for Mountpoint Pod %q", mpPod.Name)
 
@@ -151,6 +155,7 @@ func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
 	if err := u.writeExitFile(podPath); err != nil {
 		if !errors.Is(err, fs.ErrNotExist) {
 			klog.Errorf("Failed to write exit file for Mountpoint Pod %q: %v", mpPod.Name, err)
+			metrics.RecordMountpointPodCleanup(err)
 		}
 		return
 	}
@@ -162,15 +167,18 @@ func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
 			klog.Infof("Mountpoint Pod %q is still in use, will retry later", mpPod.Name)
 		} else {
 			klog.Errorf("Failed to unmount and remove Mountpoint Pod %q: %v", mpPod.Name, err)
+			metrics.RecordMountpointPodCleanup(err)
 		}
 		return
 	}
 
 	if err := u.cleanupCredentials(mpPod); err != nil {
 		klog.Errorf("Failed to cleanup credentials of Mountpoint Pod %q: %v", mpPod.Name, err)
+		metrics.RecordMountpointPodCleanup(err)
 		return
 	}
 
+	metrics.RecordMountpointPodCleanup(nil)
 	if wasMountpoint {
 		klog.Infof("Mountpoint Pod %q successfully unmounted", mpPod.Name)
 	}
@@ -212,6 +220,10 @@ func (u *PodUnmounter) unmountAndRemoveMountpointSource(source string) (bool, er
 		return isMountpoint, fmt.Errorf("failed to remove source directory of orphan Mountpoint %q: %w", source, err)
 	}
 
+	if err := removeRecordedMountOptions(u.kubeletPath, filepath.Base(source)); err != nil {
+		klog.Warningf("Failed to remove recorded mount options of %q: %v", source, err)
+	}
+
 	return isMountpoint, nil
 }
 
@@ -360,6 +360,9 @@ func TestCleanupDanglingMounts(t *testing.T) {
 				assert.NoError(t, err)
 
 				fakeMounter.Mount("mountpoint-s3", sourcePath, "fuse", []string{})
+
+				assert.NoError(t, os.MkdirAll(mounter.MountOptionsDir(kubeletPath), 0700))
+				assert.NoError(t, os.WriteFile(filepath.Join(mounter.MountOptionsDir(kubeletPath), pod.Name+".json"), []byte("{}"), 0600))
 			}
 
 			podWatcher, _ := setupPodWatcher(t, tt.pods...)
@@ -384,6 +387,13 @@ func TestCleanupDanglingMounts(t *testing.T) {
 
 			unmountCalls := countUnmountCalls(fakeMounter)
 			assert.Equals(t, tt.expectedCalls, unmountCalls)
+
+			// Recorded mount options are removed alongside their source mounts
+			for _, pod := range tt.pods {
+				_, sourceErr := os.Stat(filepath.Join(sourceMountDir, pod.Name))
+				_, optionsErr := os.Stat(filepath.Join(mounter.MountOptionsDir(kubeletPath), pod.Name+".json"))
+				assert.Equals(t, os.IsNotExist(sourceErr), os.IsNotExist(optionsErr))
+			}
 		})
 	}
 }
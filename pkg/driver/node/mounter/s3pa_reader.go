@@ -0,0 +1,89 @@
+package mounter
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+)
+
+// An s3paReader is a [client.Reader] reading MountpointS3PodAttachments from the indexer of an informer
+// created with the generated informers package. The indexer must contain [crdv2.Indexers].
+type s3paReader struct {
+	indexer cache.Indexer
+}
+
+var _ client.Reader = &s3paReader{}
+
+// NewS3PodAttachmentReader returns a [client.Reader] reading MountpointS3PodAttachments from `indexer`,
+// which must contain [crdv2.Indexers] to list them with [client.MatchingFields].
+func NewS3PodAttachmentReader(indexer cache.Indexer) client.Reader {
+	return &s3paReader{indexer: indexer}
+}
+
+// Get gets the MountpointS3PodAttachment with given `key`.
+func (r *s3paReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	s3pa, ok := obj.(*crdv2.MountpointS3PodAttachment)
+	if !ok {
+		return fmt.Errorf("expected a MountpointS3PodAttachment but got %T", obj)
+	}
+
+	item, exists, err := r.indexer.GetByKey(cache.NewObjectName(key.Namespace, key.Name).String())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apierrors.NewNotFound(crdv2.GroupVersion.WithResource("mountpoints3podattachments").GroupResource(), key.Name)
+	}
+
+	item.(*crdv2.MountpointS3PodAttachment).DeepCopyInto(s3pa)
+	return nil
+}
+
+// List lists MountpointS3PodAttachments matching given label and field selectors.
+// Field selectors can only use indexed fields and equality, and the first one is looked up using its index.
+func (r *s3paReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	s3paList, ok := list.(*crdv2.MountpointS3PodAttachmentList)
+	if !ok {
+		return fmt.Errorf("expected a MountpointS3PodAttachmentList but got %T", list)
+	}
+
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+
+	var items []any
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		requirements := listOpts.FieldSelector.Requirements()
+		for _, requirement := range requirements {
+			if requirement.Operator != selection.Equals && requirement.Operator != selection.DoubleEquals {
+				return fmt.Errorf("unsupported field selector %q, only equality is supported", listOpts.FieldSelector)
+			}
+		}
+
+		var err error
+		items, err = r.indexer.ByIndex(requirements[0].Field, requirements[0].Value)
+		if err != nil {
+			return fmt.Errorf("failed to list MountpointS3PodAttachments by %q: %w", requirements[0].Field, err)
+		}
+	} else {
+		items = r.indexer.List()
+	}
+
+	s3paList.Items = make([]crdv2.MountpointS3PodAttachment, 0, len(items))
+	for _, item := range items {
+		s3pa := item.(*crdv2.MountpointS3PodAttachment)
+		if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(s3pa.Labels)) {
+			continue
+		}
+		if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Matches(crdv2.IndexedFields(s3pa)) {
+			continue
+		}
+		s3paList.Items = append(s3paList.Items, *s3pa.DeepCopy())
+	}
+	return nil
+}
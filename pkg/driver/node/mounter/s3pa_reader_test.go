@@ -0,0 +1,104 @@
+package mounter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/fake"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/client/informers/externalversions"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestS3PodAttachmentReader(t *testing.T) {
+	newS3PA := func(name, nodeName, volumeID string) *crdv2.MountpointS3PodAttachment {
+		return &crdv2.MountpointS3PodAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: crdv2.MountpointS3PodAttachmentSpec{
+				NodeName:             nodeName,
+				PersistentVolumeName: volumeID,
+				VolumeID:             volumeID,
+			},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newS3PA("s3pa-1", "node-1", "vol-1"),
+		newS3PA("s3pa-2", "node-1", "vol-2"),
+		newS3PA("s3pa-3", "node-2", "vol-1"),
+	)
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+
+	informerFactory := externalversions.NewSharedInformerFactory(clientset, 0)
+	informer := informerFactory.S3().V2().MountpointS3PodAttachments().Informer()
+	assert.NoError(t, informer.AddIndexers(crdv2.Indexers()))
+	informerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("Failed to sync informer cache")
+	}
+
+	reader := mounter.NewS3PodAttachmentReader(informer.GetIndexer())
+	ctx := context.Background()
+
+	namesOf := func(list *crdv2.MountpointS3PodAttachmentList) []string {
+		var names []string
+		for _, s3pa := range list.Items {
+			names = append(names, s3pa.Name)
+		}
+		return names
+	}
+
+	t.Run("lists by a single field", func(t *testing.T) {
+		list := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, reader.List(ctx, list, client.MatchingFields{crdv2.FieldNodeName: "node-1"}))
+		assert.Equals(t, 2, len(list.Items))
+	})
+
+	t.Run("lists by multiple fields", func(t *testing.T) {
+		list := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, reader.List(ctx, list, client.MatchingFields{
+			crdv2.FieldNodeName: "node-1",
+			crdv2.FieldVolumeID: "vol-1",
+		}))
+		assert.Equals(t, []string{"s3pa-1"}, namesOf(list))
+	})
+
+	t.Run("lists everything without selectors", func(t *testing.T) {
+		list := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, reader.List(ctx, list))
+		assert.Equals(t, 3, len(list.Items))
+	})
+
+	t.Run("gets by name", func(t *testing.T) {
+		s3pa := &crdv2.MountpointS3PodAttachment{}
+		assert.NoError(t, reader.Get(ctx, client.ObjectKey{Name: "s3pa-3"}, s3pa))
+		assert.Equals(t, "node-2", s3pa.Spec.NodeName)
+
+		err := reader.Get(ctx, client.ObjectKey{Name: "s3pa-4"}, s3pa)
+		if !apierrors.IsNotFound(err) {
+			t.Fatalf("Expected a not found error, but got %v", err)
+		}
+	})
+
+	t.Run("reflects updates from the clientset", func(t *testing.T) {
+		_, err := clientset.S3V2().MountpointS3PodAttachments().Create(ctx, newS3PA("s3pa-4", "node-2", "vol-2"), metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+			list := &crdv2.MountpointS3PodAttachmentList{}
+			err := reader.List(ctx, list, client.MatchingFields{crdv2.FieldNodeName: "node-2"})
+			return len(list.Items) == 2, err
+		})
+		assert.NoError(t, err)
+	})
+}
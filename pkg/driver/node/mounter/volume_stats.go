@@ -0,0 +1,161 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// VolumeStats returns the health and the local cache usage of the volume mounted at `target`.
+//
+// The volume is reported as abnormal if:
+//   - `target` or the Mountpoint Pod's `source` mount is corrupted, i.e., Mountpoint process is died
+//   - Mountpoint Pod serving the volume is not `Running`
+//   - Mountpoint Pod reported a mount error via `mount.err` file
+//
+// It returns [ErrVolumeNotMounted] if there is no volume mounted at `target`.
+func (pm *PodMounter) VolumeStats(ctx context.Context, target string) (*VolumeStats, error) {
+	isTargetMountPoint, err := pm.IsMountPoint(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrVolumeNotMounted
+		}
+		if pm.mount.IsMountpointCorrupted(err) {
+			return abnormalVolumeStats("Target %q is corrupted: %v", target, err), nil
+		}
+		return nil, err
+	}
+	if !isTargetMountPoint {
+		return nil, ErrVolumeNotMounted
+	}
+
+	if pm.IsSystemDMountpoint(target) {
+		// SystemD mounts do not have a Mountpoint Pod or a local cache to check
+		return &VolumeStats{}, nil
+	}
+
+	tp, err := targetpath.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse target path %q: %w", target, err)
+	}
+
+	mpPodName, err := pm.findAttachedMountpointPod(ctx, tp.VolumeID, tp.PodID)
+	if err != nil {
+		return abnormalVolumeStats("Failed to find Mountpoint Pod for the volume: %v", err), nil
+	}
+
+	source := filepath.Join(SourceMountDir(pm.kubeletPath), mpPodName)
+	if _, err := pm.IsMountPoint(source); err != nil && pm.mount.IsMountpointCorrupted(err) {
+		return abnormalVolumeStats("Mountpoint mount %q is corrupted: %v", source, err), nil
+	}
+
+	pod, err := pm.podWatcher.Get(mpPodName)
+	if err != nil {
+		return abnormalVolumeStats("Failed to get Mountpoint Pod %q: %v", mpPodName, err), nil
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return abnormalVolumeStats("Mountpoint Pod %q is not running, it is %q. %s", mpPodName, pod.Status.Phase, pm.helpMessageForGettingMountpointPodStatus(nil, mpPodName)), nil
+	}
+
+	podPath := pm.podPath(string(pod.UID))
+	if mountErr, err := os.ReadFile(mppod.PathOnHost(podPath, mppod.KnownPathMountError)); err == nil {
+		return abnormalVolumeStats("Mountpoint Pod %q failed: %s. %s", mpPodName, strings.TrimSpace(string(mountErr)), pm.helpMessageForGettingMountpointLogs(pod)), nil
+	}
+
+	stats := &VolumeStats{}
+	cacheUsage, err := pm.localCacheUsage(pod, podPath)
+	if err != nil {
+		// Failing to get cache usage does not make the volume unhealthy, just don't report it
+		klog.V(4).Infof("Failed to get local cache usage of Mountpoint Pod %q: %v", mpPodName, err)
+	} else {
+		stats.CacheUsage = cacheUsage
+	}
+
+	return stats, nil
+}
+
+// findAttachedMountpointPod returns the name of Mountpoint Pod that serves volume `volumeName` to the workload `workloadPodUID`.
+func (pm *PodMounter) findAttachedMountpointPod(ctx context.Context, volumeName, workloadPodUID string) (string, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	err := pm.s3paCache.List(ctx, s3paList, client.MatchingFields{
+		crdv2.FieldNodeName: pm.nodeID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list MountpointS3PodAttachments: %w", err)
+	}
+
+	for _, s3pa := range s3paList.Items {
+		for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			for _, attachment := range attachments {
+				if attachment.WorkloadPodUID == workloadPodUID && isAttachmentForVolume(&s3pa, attachment, volumeName) {
+					return mpPodName, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no MountpointS3PodAttachment found for volume %q and workload %q", volumeName, workloadPodUID)
+}
+
+// localCacheUsage returns usage of the local cache volume of Mountpoint Pod `pod`, or nil if there is no local cache configured.
+func (pm *PodMounter) localCacheUsage(pod *corev1.Pod, podPath string) (*util.FilesystemUsage, error) {
+	var cacheVolume *corev1.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == mppod.LocalCacheDirName {
+			cacheVolume = &pod.Spec.Volumes[i]
+			break
+		}
+	}
+	if cacheVolume == nil {
+		return nil, nil
+	}
+
+	var usage util.FilesystemUsage
+	var err error
+	switch {
+	case cacheVolume.EmptyDir != nil:
+		cachePath := filepath.Join(podPath, "volumes", "kubernetes.io~empty-dir", mppod.LocalCacheDirName)
+		emptyDir := cacheVolume.EmptyDir
+		if emptyDir.Medium != corev1.StorageMediumMemory && emptyDir.SizeLimit != nil {
+			// Disk-backed `emptyDir` shares the node's root filesystem, `statfs` would report usage of the whole node.
+			usage, err = util.DirectoryUsage(cachePath, emptyDir.SizeLimit.Value())
+		} else {
+			usage, err = util.StatFilesystem(cachePath)
+		}
+	case cacheVolume.Ephemeral != nil:
+		// Ephemeral volumes are mounted by their CSI driver to a path containing the name of their PersistentVolume.
+		var matches []string
+		matches, err = filepath.Glob(filepath.Join(podPath, "volumes", "kubernetes.io~csi", "*", "mount"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) != 1 {
+			return nil, fmt.Errorf("expected exactly one ephemeral cache volume, found %d", len(matches))
+		}
+		usage, err = util.StatFilesystem(matches[0])
+	default:
+		return nil, fmt.Errorf("unsupported local-cache volume source")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// abnormalVolumeStats returns [VolumeStats] for an abnormal volume with the given message.
+func abnormalVolumeStats(format string, a ...any) *VolumeStats {
+	return &VolumeStats{Abnormal: true, Message: fmt.Sprintf(format, a...)}
+}
@@ -17,31 +17,34 @@ limitations under the License.
 package node
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"maps"
 	"os"
-	"strconv"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
-	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 )
 
 var (
 	nodeCaps = []csi.NodeServiceCapability_RPC_Type{
 		csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
 	}
 )
 
@@ -54,6 +57,9 @@ var (
 			Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
 		},
 	}
+
+	// kubelet always uses `SINGLE_NODE_WRITER` access mode for CSI ephemeral inline volumes.
+	ephemeralVolumeCap = csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER
 )
 
 const (
@@ -65,6 +71,8 @@ const (
 type S3NodeServer struct {
 	NodeID  string
 	Mounter mounter.Mounter
+	// MountPolicies enforces MountpointS3MountPolicies on mount options of volumes, they're not enforced if it's nil.
+	MountPolicies *mountpolicy.Enforcer
 }
 
 func NewS3NodeServer(nodeID string, mounter mounter.Mounter) *S3NodeServer {
@@ -87,10 +95,14 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
 	}
 
-	volumeCtx := req.GetVolumeContext()
+	// Volumes might have been created with unknown volume attributes before they were rejected, so they're ignored here.
+	volumeAttrs, err := volumecontext.ParseLenient(req.GetVolumeContext())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid volume attributes: %v", err)
+	}
 
-	bucket, ok := volumeCtx[volumecontext.BucketName]
-	if !ok {
+	bucket := volumeAttrs.BucketName
+	if bucket == "" {
 		return nil, status.Error(codes.InvalidArgument, "Bucket name not provided")
 	}
 
@@ -112,10 +124,24 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 		return nil, status.Error(codes.InvalidArgument, "Volume capability not provided")
 	}
 
-	if !ns.isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
+	if !ns.isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) &&
+		!(volumeAttrs.Ephemeral && volCap.GetAccessMode().GetMode() == ephemeralVolumeCap) {
 		return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
 	}
 
+	// Inline volumes are declared by anyone who can create Pods, unlike PersistentVolumes which are usually managed by cluster administrators.
+	// Allowing them to use the driver-level credentials would grant every Pod in the cluster access to whatever the driver's IAM role can access.
+	if volumeAttrs.Ephemeral && cmp.Or(volumeAttrs.AuthenticationSource, credentialprovider.AuthenticationSourceDriver) == credentialprovider.AuthenticationSourceDriver {
+		return nil, status.Errorf(codes.InvalidArgument, "CSI ephemeral inline volumes cannot use driver-level credentials, use `authenticationSource: %s` instead", credentialprovider.AuthenticationSourcePod)
+	}
+
+	// Looking up Secrets by `secretName` uses the CSI Driver Node's permissions, which would let anyone who can create Pods read
+	// Secrets they can't access themselves. Secrets of inline volumes are passed via `nodePublishSecretRef` instead,
+	// which kubelet only resolves in the Pod's namespace, the same way as for Secret volumes.
+	if volumeAttrs.Ephemeral && volumeAttrs.SecretName != "" {
+		return nil, status.Error(codes.InvalidArgument, "CSI ephemeral inline volumes cannot use `secretName` volume attribute, use `nodePublishSecretRef` instead")
+	}
+
 	mountpointArgs := []string{}
 	if req.GetReadonly() || volCap.GetAccessMode().GetMode() == csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY {
 		mountpointArgs = append(mountpointArgs, mountpoint.ArgReadOnly)
@@ -128,13 +154,29 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 
 	args := mountpoint.ParseArgs(mountpointArgs)
 
-	if args.Has(mountpoint.ArgFsTab) {
-		return nil, status.Error(codes.InvalidArgument, "Running mount-s3 with mount flag -o is not supported in CSI Driver.")
+	if err := volumecontext.ValidateMountOptions(&args, volumeAttrs); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	// `--ca-bundle` is not supported in CSI Driver yet.
-	if args.Has(mountpoint.ArgCABundle) {
-		return nil, status.Errorf(codes.InvalidArgument, "Running mount-s3 with %s is not supported in CSI Driver.", mountpoint.ArgCABundle)
+	// `prefix` is set by the provisioner for dynamically provisioned volumes.
+	if volumeAttrs.Prefix != "" {
+		args.Set(mountpoint.ArgPrefix, volumeAttrs.Prefix)
+	}
+
+	volumecontext.ApplyEndpoint(&args, volumeAttrs)
+
+	if ns.MountPolicies != nil {
+		err := ns.MountPolicies.Apply(ctx, mountpolicy.Workload{
+			Namespace: volumeAttrs.PodNamespace,
+			Name:      volumeAttrs.PodName,
+		}, &args)
+		var violation *crdv2.MountPolicyViolationError
+		if errors.As(err, &violation) {
+			return nil, status.Errorf(codes.InvalidArgument, "Mount options violate MountpointS3MountPolicy %q: %s", violation.Policy, violation.Reason)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not apply MountpointS3MountPolicies: %v", err)
+		}
 	}
 
 	fsGroup := ""
@@ -155,58 +197,25 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 		args.SetIfAbsent(mountpoint.ArgAllowRoot, mountpoint.ArgNoValue)
 	}
 
-	// If cacheEmptyDirSizeLimit is set with cache=emptyDir, validate that an explicit --max-cache-size (in MiB) doesn't exceed it.
-	if emptyDirSizeLimit := volumeCtx[volumecontext.CacheEmptyDirSizeLimit]; emptyDirSizeLimit != "" && volumeCtx[volumecontext.Cache] == volumecontext.CacheTypeEmptyDir {
-		quantity, err := resource.ParseQuantity(emptyDirSizeLimit)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid %s %q: %v", volumecontext.CacheEmptyDirSizeLimit, emptyDirSizeLimit, err)
-		}
-		emptyDirSizeLimitMiB := quantity.Value() / (1024 * 1024)
-
-		// Safety factor to account for Mountpoint overshooting its cache target by around 1-2%.
-		const safetyFactor = 0.95
-		safeMaxCacheSizeMiB := int64(float64(quantity.Value()) * safetyFactor / (1024 * 1024))
-
-		if maxCacheSize, ok := args.Value(mountpoint.ArgMaxCacheSize); ok {
-			maxCacheSizeMiB, err := strconv.ParseInt(maxCacheSize, 10, 64)
-			if err != nil {
-				return nil, status.Errorf(codes.InvalidArgument, "Invalid %s %q: %v", mountpoint.ArgMaxCacheSize, maxCacheSize, err)
-			}
-			if maxCacheSizeMiB > emptyDirSizeLimitMiB {
-				return nil, status.Errorf(codes.InvalidArgument,
-					"%s (%d MiB) exceeds %s (%s = %d MiB). Reduce %s or increase %s.",
-					mountpoint.ArgMaxCacheSize, maxCacheSizeMiB,
-					volumecontext.CacheEmptyDirSizeLimit, emptyDirSizeLimit, emptyDirSizeLimitMiB,
-					mountpoint.ArgMaxCacheSize, volumecontext.CacheEmptyDirSizeLimit)
-			}
-			// For disk-backed (default) medium, remove user's explicit --max-cache-size if it exceeds
-			// the safe threshold, allowing the safe default to be injected below via SetIfAbsent.
-			if volumeCtx[volumecontext.CacheEmptyDirMedium] == string(corev1.StorageMediumDefault) && maxCacheSizeMiB > safeMaxCacheSizeMiB {
-				args.Remove(mountpoint.ArgMaxCacheSize)
-			}
-		}
+	// If cacheEmptyDirSizeLimit is set with cache=emptyDir, validate that an explicit --max-cache-size (in MiB) doesn't exceed it,
+	// and cap --max-cache-size for disk-backed emptyDir caches.
+	if err := volumecontext.ApplyCacheEmptyDirSizeLimit(&args, volumeAttrs); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
-		// For disk-backed (default) medium, statvfs on the cache directory reports the node's root filesystem
-		// stats rather than the emptyDir's sizeLimit, so Mountpoint cannot self-limit correctly.
-		// Inject --max-cache-size at 95% of the limit to ensure Mountpoint evicts before Kubernetes does.
-		// The 5% margin accounts for Mountpoint overshooting its target by around 1-2%.
-		// Memory medium has an isolated filesystems with accurate size reporting, so Mountpoint
-		// can self-limit without this injection.
-		if volumeCtx[volumecontext.CacheEmptyDirMedium] == string(corev1.StorageMediumDefault) {
-			args.SetIfAbsent(mountpoint.ArgMaxCacheSize, strconv.FormatInt(safeMaxCacheSizeMiB, 10))
+	if volumeAttrs.CABundle != nil {
+		caBundlePath, err := ns.caBundlePath(ctx, targetContainer, volumeAttrs.CABundle)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not provide CA bundle for %q: %v", targetContainer, err)
 		}
+		args.Set(mountpoint.ArgCABundle, caBundlePath)
 	}
 
 	klog.V(4).Infof("NodePublishVolume: mounting %s at %s with options %v", bucket, targetContainer, args.SortedList())
 
-	credentialCtx := credentialProvideContextFromPublishRequest(req, args)
+	credentialCtx := credentialProvideContextFromPublishRequest(req, volumeAttrs, args)
 
-	userEnv, err := envprovider.ParseUserEnvFromVolumeContext(req.GetVolumeContext())
-	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "Could not parse user environment: %v", err)
-	}
-
-	if err := ns.Mounter.Mount(ctx, bucket, targetContainer, credentialCtx, args, fsGroup, userEnv); err != nil {
+	if err := ns.Mounter.Mount(ctx, bucket, targetContainer, credentialCtx, args, fsGroup, volumeAttrs.UserEnv); err != nil {
 		os.Remove(targetContainer)
 		return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", bucket, targetContainer, err)
 	}
@@ -215,6 +224,16 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
+// caBundlePath returns the path Mountpoint should read the custom CA bundle referenced by `source` from for the mount at `target`.
+// Mountpoint Pods have the CA bundle projected by the controller at [mppod.CABundlePath],
+// and mounters implementing [mounter.CABundleWriter] write it for each mount themselves.
+func (ns *S3NodeServer) caBundlePath(ctx context.Context, target string, source *volumecontext.CABundleSource) (string, error) {
+	if writer, ok := ns.Mounter.(mounter.CABundleWriter); ok {
+		return writer.WriteCABundle(ctx, target, source)
+	}
+	return mppod.CABundlePath, nil
+}
+
 func (ns *S3NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (*csi.NodeUnpublishVolumeResponse, error) {
 	klog.V(4).Infof("NodeUnpublishVolume: called with args %+v", req)
 
@@ -260,7 +279,61 @@ func (ns *S3NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUn
 }
 
 func (ns *S3NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).Infof("NodeGetVolumeStats: called with args %+v", req)
+
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	volumePath := req.GetVolumePath()
+	if len(volumePath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume path not provided")
+	}
+
+	// Any path outside of kubelet's path cannot be a volume published by us.
+	targetContainer, err := util.KubeletHostPathToContainerPath(volumePath)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "Volume %q is not found at %q: %v", volumeID, volumePath, err)
+	}
+
+	stats, err := ns.Mounter.VolumeStats(ctx, targetContainer)
+	if err != nil {
+		if errors.Is(err, mounter.ErrVolumeNotMounted) {
+			return nil, status.Errorf(codes.NotFound, "Volume %q is not mounted at %q", volumeID, volumePath)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get stats of %q: %v", volumePath, err)
+	}
+
+	resp := &csi.NodeGetVolumeStatsResponse{
+		VolumeCondition: &csi.VolumeCondition{
+			Abnormal: stats.Abnormal,
+			Message:  stats.Message,
+		},
+	}
+	if stats.Abnormal {
+		klog.Warningf("NodeGetVolumeStats: volume %q at %q is abnormal: %s", volumeID, volumePath, stats.Message)
+	}
+
+	// Usage of S3 volumes is not known, so only report usage of the local cache if configured
+	if cache := stats.CacheUsage; cache != nil {
+		resp.Usage = []*csi.VolumeUsage{
+			{
+				Unit:      csi.VolumeUsage_BYTES,
+				Total:     cache.TotalBytes,
+				Used:      cache.UsedBytes,
+				Available: cache.AvailableBytes,
+			},
+			{
+				Unit:      csi.VolumeUsage_INODES,
+				Total:     cache.TotalInodes,
+				Used:      cache.UsedInodes,
+				Available: cache.AvailableInodes,
+			},
+		}
+	}
+
+	return resp, nil
 }
 
 func (ns *S3NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
@@ -310,42 +383,72 @@ func (ns *S3NodeServer) isValidVolumeCapabilities(volCaps []*csi.VolumeCapabilit
 	return foundAll
 }
 
-func credentialProvideContextFromPublishRequest(req *csi.NodePublishVolumeRequest, args mountpoint.Args) credentialprovider.ProvideContext {
-	volumeCtx := req.GetVolumeContext()
-
-	podID := volumeCtx[volumecontext.CSIPodUID]
+func credentialProvideContextFromPublishRequest(req *csi.NodePublishVolumeRequest, volumeAttrs volumecontext.VolumeAttributes, args mountpoint.Args) credentialprovider.ProvideContext {
+	podID := volumeAttrs.PodUID
 	if podID == "" {
 		podID, _ = podIDFromTargetPath(req.GetTargetPath())
 	}
 
-	authSource := credentialprovider.AuthenticationSourceDriver
-	if volumeCtx[volumecontext.AuthenticationSource] != credentialprovider.AuthenticationSourceUnspecified {
-		authSource = volumeCtx[volumecontext.AuthenticationSource]
-	}
-
 	bucketRegion, _ := args.Value(mountpoint.ArgRegion)
+	endpointURL, _ := args.Value(mountpoint.ArgEndpointURL)
+
+	provideCtx := credentialprovider.ProvideContext{
+		WorkloadPodID:           podID,
+		VolumeID:                req.GetVolumeId(),
+		AuthenticationSource:    volumeAttrs.AuthenticationSource,
+		PodName:                 volumeAttrs.PodName,
+		PodNamespace:            volumeAttrs.PodNamespace,
+		ServiceAccountTokens:    serviceAccountTokensFromRequest(req, volumeAttrs),
+		ServiceAccountName:      volumeAttrs.ServiceAccountName,
+		StsRegion:               volumeAttrs.STSRegion,
+		StsEndpoint:             volumeAttrs.STSEndpoint,
+		StsUseFIPSEndpoint:      volumeAttrs.UseFIPSEndpoint,
+		StsUseDualStackEndpoint: volumeAttrs.UseDualStackEndpoint,
+		BucketRegion:            bucketRegion,
+		EndpointURL:             endpointURL,
+		InlineVolume:            volumeAttrs.Ephemeral,
+	}
+
+	if credentialprovider.IsCredentialPlugin(volumeAttrs.AuthenticationSource) {
+		// Credential plugins might use the Secret referenced by `nodePublishSecretRef`, if any
+		provideCtx.Secrets = req.GetSecrets()
+	}
+
+	if volumeAttrs.AuthenticationSource == credentialprovider.AuthenticationSourceSecret {
+		provideCtx.Secrets = req.GetSecrets()
+		provideCtx.SecretName = volumeAttrs.SecretName
+		provideCtx.SecretNamespace = volumeAttrs.SecretNamespace
+	}
+
+	if rolesAnywhere := volumeAttrs.RolesAnywhere; rolesAnywhere != nil {
+		if volumeAttrs.AuthenticationSource == credentialprovider.AuthenticationSourcePod {
+			// The Secret is always looked up in the workload's namespace with pod-level IAM Roles Anywhere
+			provideCtx.Secrets = req.GetSecrets()
+			provideCtx.SecretName = volumeAttrs.SecretName
+		}
+		provideCtx.RolesAnywhereTrustAnchorARN = rolesAnywhere.TrustAnchorARN
+		provideCtx.RolesAnywhereProfileARN = rolesAnywhere.ProfileARN
+		provideCtx.RolesAnywhereRoleARN = rolesAnywhere.RoleARN
+	}
 
-	return credentialprovider.ProvideContext{
-		WorkloadPodID:        podID,
-		VolumeID:             req.GetVolumeId(),
-		AuthenticationSource: authSource,
-		PodNamespace:         volumeCtx[volumecontext.CSIPodNamespace],
-		ServiceAccountTokens: serviceAccountTokensFromRequest(req),
-		ServiceAccountName:   volumeCtx[volumecontext.CSIServiceAccountName],
-		StsRegion:            volumeCtx[volumecontext.STSRegion],
-		BucketRegion:         bucketRegion,
+	if role := volumeAttrs.AssumeRole; role != nil {
+		provideCtx.AssumeRoleARN = role.RoleARN
+		provideCtx.AssumeRoleExternalID = role.ExternalID
+		provideCtx.AssumeRoleSessionName = role.SessionName
 	}
+
+	return provideCtx
 }
 
 // serviceAccountTokensFromRequest checks secrets first, then volume context.
 // In Kubernetes v1.35+, tokens can be delivered via the secrets field (KEP-5538).
 // We don't set serviceAccountTokenInSecrets in our CSIDriver spec yet, but this
 // fallback ensures we're ready when we do.
-func serviceAccountTokensFromRequest(req *csi.NodePublishVolumeRequest) string {
+func serviceAccountTokensFromRequest(req *csi.NodePublishVolumeRequest, volumeAttrs volumecontext.VolumeAttributes) string {
 	if tokens, ok := req.GetSecrets()[volumecontext.CSIServiceAccountTokens]; ok {
 		return tokens
 	}
-	return req.GetVolumeContext()[volumecontext.CSIServiceAccountTokens]
+	return volumeAttrs.ServiceAccountTokens
 }
 
 func credentialCleanupContextFromUnpublishRequest(req *csi.NodeUnpublishVolumeRequest) credentialprovider.CleanupContext {
@@ -9,8 +9,17 @@ import (
 
 	csi "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
-
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
@@ -18,6 +27,8 @@ import (
 	mock_driver "github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/mounter/mocks"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
 
@@ -127,6 +138,128 @@ func TestNodePublishVolume(t *testing.T) {
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: single node writer access mode for ephemeral inline volume",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                   bucketName,
+						"authenticationSource":         "pod",
+						"csi.storage.k8s.io/ephemeral": "true",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Any(),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: driver-level credentials for ephemeral inline volume",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				for _, authenticationSource := range []string{"", "driver"} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId: volumeId,
+						VolumeCapability: &csi.VolumeCapability{
+							AccessType: &csi.VolumeCapability_Mount{
+								Mount: &csi.VolumeCapability_MountVolume{},
+							},
+							AccessMode: &csi.VolumeCapability_AccessMode{
+								Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+							},
+						},
+						TargetPath: targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":                   bucketName,
+							"authenticationSource":         authenticationSource,
+							"csi.storage.k8s.io/ephemeral": "true",
+						},
+					}
+
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					assert.Equals(t, codes.InvalidArgument, status.Code(err))
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: secret lookup for ephemeral inline volume",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"authenticationSource":             "secret",
+						"secretName":                       "s3-credentials",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.name":      "test-pod",
+						"csi.storage.k8s.io/pod.namespace": "test-ns",
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				assert.Equals(t, codes.InvalidArgument, status.Code(err))
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: single node writer access mode for persistent volume",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+						},
+					},
+					TargetPath:    targetPath,
+					VolumeContext: map[string]string{"bucketName": bucketName},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("NodePublishVolume is expected to fail")
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 		{
 			name: "success: mount with mount options and read only",
 			testFunc: func(t *testing.T) {
@@ -225,6 +358,275 @@ func TestNodePublishVolume(t *testing.T) {
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: custom CA bundle is passed with its path inside Mountpoint Pod",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":        bucketName,
+						"caBundleConfigMap": "corp-ca",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Any(),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root", "--ca-bundle=/ca-bundle/ca-bundle.crt"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: S3-compatible endpoint attributes are translated to mount options",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":      bucketName,
+						"endpointUrl":     "http://minio.storage.svc:9000",
+						"addressingStyle": "path",
+						"region":          "default",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+						BucketRegion:         "default",
+						EndpointURL:          "http://minio.storage.svc:9000",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{
+						"--allow-root",
+						"--endpoint-url=http://minio.storage.svc:9000",
+						"--force-path-style",
+						"--region=default",
+					})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: role to assume",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":      bucketName,
+						"roleArn":         "arn:aws:iam::111122223333:role/data-lake",
+						"roleExternalId":  "team-a-external-id",
+						"roleSessionName": "team-a",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:              volumeId,
+						AuthenticationSource:  credentialprovider.AuthenticationSourceDriver,
+						AssumeRoleARN:         "arn:aws:iam::111122223333:role/data-lake",
+						AssumeRoleExternalID:  "team-a-external-id",
+						AssumeRoleSessionName: "team-a",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: IAM Roles Anywhere with secret",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                  bucketName,
+						"authenticationSource":        "secret",
+						"secretName":                  "s3-certificate",
+						"rolesAnywhereTrustAnchorArn": "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+						"rolesAnywhereProfileArn":     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+						"rolesAnywhereRoleArn":        "arn:aws:iam::111122223333:role/data-lake",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:                    volumeId,
+						AuthenticationSource:        credentialprovider.AuthenticationSourceSecret,
+						SecretName:                  "s3-certificate",
+						RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+						RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+						RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: pod-level IAM Roles Anywhere",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"authenticationSource":             "pod",
+						"secretName":                       "s3-certificate",
+						"csi.storage.k8s.io/pod.namespace": "test-ns",
+						"rolesAnywhereTrustAnchorArn":      "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+						"rolesAnywhereProfileArn":          "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+						"rolesAnywhereRoleArn":             "arn:aws:iam::111122223333:role/data-lake",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:                    volumeId,
+						AuthenticationSource:        credentialprovider.AuthenticationSourcePod,
+						PodNamespace:                "test-ns",
+						SecretName:                  "s3-certificate",
+						RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+						RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+						RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: both caBundleConfigMap and caBundleSecret are set",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":        bucketName,
+						"caBundleConfigMap": "corp-ca",
+						"caBundleSecret":    "corp-ca",
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("NodePublishVolume should fail when both caBundleConfigMap and caBundleSecret are set")
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 		{
 			name: "success: foreground option is removed",
 			testFunc: func(t *testing.T) {
@@ -325,6 +727,53 @@ func TestNodePublishVolume(t *testing.T) {
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: passes secrets for secret authentication source",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				secrets := map[string]string{
+					"key_id":     "test-access-key-id",
+					"access_key": "test-secret-access-key",
+				}
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"authenticationSource":             "secret",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.name":      "test-pod",
+						"csi.storage.k8s.io/pod.namespace": "test-ns",
+					},
+					Secrets: secrets,
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(ctx),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						AuthenticationSource: credentialprovider.AuthenticationSourceSecret,
+						PodName:              "test-pod",
+						PodNamespace:         "test-ns",
+						Secrets:              secrets,
+						InlineVolume:         true,
+					}),
+					gomock.Any(),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 		{
 			name: "success: falls back to volume context for SA tokens when secrets field is empty",
 			testFunc: func(t *testing.T) {
@@ -444,6 +893,74 @@ func TestNodePublishVolume(t *testing.T) {
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: provisioned volume with prefix",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						volumecontext.BucketName: bucketName,
+						volumecontext.Prefix:     "tenants/ns/pvc/",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Any(),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--prefix=tenants/ns/pvc/", "--allow-root"})),
+					gomock.Eq(""),
+					gomock.Eq(envprovider.Environment{}),
+				)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: prefix mount option conflicts with provisioned volume prefix",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{
+								MountFlags: []string{"prefix other/"},
+							},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						volumecontext.BucketName: bucketName,
+						volumecontext.Prefix:     "tenants/ns/pvc/",
+					},
+				}
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err == nil {
+					t.Fatal("NodePublishVolume is success")
+				}
+
+				expectedErrMsg := "conflicts with the volume prefix"
+				if !strings.Contains(err.Error(), expectedErrMsg) {
+					t.Errorf("Expected error message %q, but got %q", expectedErrMsg, err.Error())
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 		{
 			name: "fail: config unallowed environment",
 			testFunc: func(t *testing.T) {
@@ -749,14 +1266,14 @@ func TestNodePublishVolumeMaxCacheSizeInjection(t *testing.T) {
 			expectedArgs: []string{"--allow-root"},
 		},
 		{
-			name: "does not inject when medium is HugePages (has isolated filesystem)",
+			name: "returns error for unsupported medium, as Mountpoint Pods cannot use it",
 			volumeCtx: map[string]string{
 				volumecontext.BucketName:             bucketName,
 				volumecontext.Cache:                  volumecontext.CacheTypeEmptyDir,
 				volumecontext.CacheEmptyDirSizeLimit: "50Mi",
 				volumecontext.CacheEmptyDirMedium:    string(corev1.StorageMediumHugePages),
 			},
-			expectedArgs: []string{"--allow-root"},
+			expectError: true,
 		},
 		{
 			name: "explicit max-cache-size below size limit takes precedence over auto-injected value",
@@ -823,9 +1340,11 @@ func TestNodePublishVolumeMaxCacheSizeInjection(t *testing.T) {
 		{
 			name: "does not inject when cache is not emptyDir",
 			volumeCtx: map[string]string{
-				volumecontext.BucketName:             bucketName,
-				volumecontext.Cache:                  volumecontext.CacheTypeEphemeral,
-				volumecontext.CacheEmptyDirSizeLimit: "50Mi",
+				volumecontext.BucketName:                           bucketName,
+				volumecontext.Cache:                                volumecontext.CacheTypeEphemeral,
+				volumecontext.CacheEphemeralStorageClassName:       "gp3",
+				volumecontext.CacheEphemeralStorageResourceRequest: "1Gi",
+				volumecontext.CacheEmptyDirSizeLimit:               "50Mi",
 			},
 			expectedArgs: []string{"--allow-root"},
 		},
@@ -898,6 +1417,82 @@ func TestNodePublishVolumeMaxCacheSizeInjection(t *testing.T) {
 	}
 }
 
+func TestNodePublishVolumeWithMountPolicies(t *testing.T) {
+	var (
+		volumeId   = "test-volume-id"
+		bucketName = "test-bucket-name"
+		targetPath = "/var/lib/kubelet/target/path"
+		volumeCtx  = map[string]string{
+			volumecontext.BucketName:      bucketName,
+			volumecontext.CSIPodNamespace: "team-a",
+			volumecontext.CSIPodName:      "workload",
+		}
+	)
+
+	newRequest := func(mountFlags ...string) *csi.NodePublishVolumeRequest {
+		return &csi.NodePublishVolumeRequest{
+			VolumeId: volumeId,
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{MountFlags: mountFlags},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				},
+			},
+			TargetPath:    targetPath,
+			VolumeContext: volumeCtx,
+		}
+	}
+
+	policy := &crdv2.MountpointS3MountPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a-policy"},
+		Spec: crdv2.MountpointS3MountPolicySpec{
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			DefaultMountOptions: []string{"aws-max-attempts 10"},
+			DeniedMountOptions:  []string{"allow-delete"},
+		},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}
+
+	t.Run("applies default mount options", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		nodeTestEnv.server.MountPolicies = newMountPolicyEnforcer(policy, namespace)
+		ctx := context.Background()
+
+		nodeTestEnv.mockMounter.EXPECT().Mount(
+			gomock.Eq(ctx),
+			gomock.Eq(bucketName),
+			gomock.Eq(targetPath),
+			gomock.Any(),
+			gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root", "--aws-max-attempts=10", "--read-part-size=1024"})),
+			gomock.Eq(""),
+			gomock.Eq(envprovider.Environment{}),
+		).Return(nil)
+
+		_, err := nodeTestEnv.server.NodePublishVolume(ctx, newRequest("read-part-size=1024"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects denied mount options with the policy name", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		nodeTestEnv.server.MountPolicies = newMountPolicyEnforcer(policy, namespace)
+
+		_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), newRequest("--allow-delete"))
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		if !strings.Contains(err.Error(), `MountpointS3MountPolicy "team-a-policy"`) {
+			t.Fatalf("Expected error to contain the policy name, got %v", err)
+		}
+	})
+}
+
+func newMountPolicyEnforcer(objs ...client.Object) *mountpolicy.Enforcer {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(crdv2.AddToScheme(scheme))
+	return mountpolicy.New(ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+}
+
 func TestNodeUnpublishVolume(t *testing.T) {
 	var (
 		volumeId   = "test-volume-id"
@@ -999,6 +1594,93 @@ func TestNodeUnpublishVolume(t *testing.T) {
 	}
 }
 
+func TestNodeGetVolumeStats(t *testing.T) {
+	var (
+		volumeId   = "test-volume-id"
+		targetPath = "/var/lib/kubelet/target/path"
+	)
+
+	t.Run("success: healthy volume with local cache", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		ctx := context.Background()
+
+		nodeTestEnv.mockMounter.EXPECT().VolumeStats(gomock.Eq(ctx), gomock.Eq(targetPath)).Return(&mounter.VolumeStats{
+			CacheUsage: &util.FilesystemUsage{
+				TotalBytes:      1024,
+				UsedBytes:       256,
+				AvailableBytes:  768,
+				TotalInodes:     100,
+				UsedInodes:      10,
+				AvailableInodes: 90,
+			},
+		}, nil)
+		resp, err := nodeTestEnv.server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+			VolumeId:   volumeId,
+			VolumePath: targetPath,
+		})
+		assert.NoError(t, err)
+
+		assert.Equals(t, &csi.VolumeCondition{Abnormal: false}, resp.GetVolumeCondition())
+		assert.Equals(t, []*csi.VolumeUsage{
+			{Unit: csi.VolumeUsage_BYTES, Total: 1024, Used: 256, Available: 768},
+			{Unit: csi.VolumeUsage_INODES, Total: 100, Used: 10, Available: 90},
+		}, resp.GetUsage())
+
+		nodeTestEnv.mockCtl.Finish()
+	})
+
+	t.Run("success: abnormal volume", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		ctx := context.Background()
+
+		nodeTestEnv.mockMounter.EXPECT().VolumeStats(gomock.Eq(ctx), gomock.Eq(targetPath)).Return(&mounter.VolumeStats{
+			Abnormal: true,
+			Message:  "Mountpoint Pod is not running",
+		}, nil)
+		resp, err := nodeTestEnv.server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+			VolumeId:   volumeId,
+			VolumePath: targetPath,
+		})
+		assert.NoError(t, err)
+
+		assert.Equals(t, &csi.VolumeCondition{Abnormal: true, Message: "Mountpoint Pod is not running"}, resp.GetVolumeCondition())
+		assert.Equals(t, 0, len(resp.GetUsage()))
+
+		nodeTestEnv.mockCtl.Finish()
+	})
+
+	t.Run("failure: volume is not mounted", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		ctx := context.Background()
+
+		nodeTestEnv.mockMounter.EXPECT().VolumeStats(gomock.Eq(ctx), gomock.Eq(targetPath)).Return(nil, mounter.ErrVolumeNotMounted)
+		_, err := nodeTestEnv.server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+			VolumeId:   volumeId,
+			VolumePath: targetPath,
+		})
+		assert.Equals(t, codes.NotFound, status.Code(err))
+
+		nodeTestEnv.mockCtl.Finish()
+	})
+
+	t.Run("failure: volume path outside of kubelet path", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		_, err := nodeTestEnv.server.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+			VolumeId:   volumeId,
+			VolumePath: "some/path",
+		})
+		assert.Equals(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("failure: missing arguments", func(t *testing.T) {
+		nodeTestEnv := initNodeServerTestEnv(t)
+		_, err := nodeTestEnv.server.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{VolumePath: targetPath})
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		_, err = nodeTestEnv.server.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{VolumeId: volumeId})
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
 func TestNodeGetCapabilitiesForPodMounter(t *testing.T) {
 	nodeTestEnv := initNodeServerTestEnv(t)
 	ctx := context.Background()
@@ -1017,6 +1699,20 @@ func TestNodeGetCapabilitiesForPodMounter(t *testing.T) {
 				},
 			},
 		},
+		{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+				},
+			},
+		},
+		{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+				},
+			},
+		},
 	}, resp.GetCapabilities())
 
 	nodeTestEnv.mockCtl.Finish()
@@ -1037,3 +1733,7 @@ func (d *dummyMounter) Unmount(ctx context.Context, target string, cleanupCtx cr
 func (d *dummyMounter) IsMountPoint(target string) (bool, error) {
 	return true, nil
 }
+
+func (d *dummyMounter) VolumeStats(ctx context.Context, target string) (*mounter.VolumeStats, error) {
+	return &mounter.VolumeStats{}, nil
+}
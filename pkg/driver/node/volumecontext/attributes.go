@@ -0,0 +1,574 @@
+package volumecontext
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// Supported values of `authenticationSource`.
+const (
+	AuthenticationSourceDriver = "driver"
+	AuthenticationSourcePod    = "pod"
+	AuthenticationSourceSecret = "secret"
+)
+
+// knownKeys is the list of volume attributes the CSI Driver understands.
+var knownKeys = []string{
+	BucketName,
+	AuthenticationSource,
+	STSRegion,
+	STSEndpoint,
+	UseFIPSEndpoint,
+	UseDualStackEndpoint,
+	Prefix,
+	SecretName,
+	SecretNamespace,
+	RoleARN,
+	RoleExternalID,
+	RoleSessionName,
+	RolesAnywhereTrustAnchorARN,
+	RolesAnywhereProfileARN,
+	RolesAnywhereRoleARN,
+	EndpointURL,
+	AddressingStyle,
+	Region,
+	Cache,
+	CacheEmptyDirSizeLimit,
+	CacheEmptyDirMedium,
+	CacheEphemeralStorageClassName,
+	CacheEphemeralStorageResourceRequest,
+	MountpointPodServiceAccountName,
+	MountpointContainerResourcesRequestsCpu,
+	MountpointContainerResourcesRequestsMemory,
+	MountpointContainerResourcesLimitsCpu,
+	MountpointContainerResourcesLimitsMemory,
+	CABundleConfigMap,
+	CABundleSecret,
+	CABundleKey,
+}
+
+// knownKeyPrefixes is the list of prefixes of volume attributes that are either set by Kubernetes
+// (e.g., `csi.storage.k8s.io/pod.name` or `storage.kubernetes.io/csiProvisionerIdentity`) or configure the user environment.
+var knownKeyPrefixes = []string{
+	"csi.storage.k8s.io/",
+	"storage.kubernetes.io/",
+	envprovider.MountpointEnvPrefix,
+}
+
+// awsEndpointSuffixes is the list of domain suffixes of AWS endpoints, including China, ISO and European Sovereign Cloud
+// regions and dual-stack endpoints.
+var awsEndpointSuffixes = []string{
+	".amazonaws.com",
+	".amazonaws.com.cn",
+	".api.aws",
+	".api.amazonwebservices.com.cn",
+	".c2s.ic.gov",
+	".sc2s.sgov.gov",
+	".cloud.adc-e.uk",
+	".csp.hci.ic.gov",
+	".amazonaws.eu",
+}
+
+// Patterns of values accepted by STS `AssumeRole` for role ARNs, external IDs and role session names.
+var (
+	roleARNPattern         = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]{1,512}$`)
+	roleExternalIDPattern  = regexp.MustCompile(`^[\w+=,.@:/-]+$`)
+	roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+)
+
+// Patterns of IAM Roles Anywhere trust anchor and profile ARNs.
+var (
+	rolesAnywhereTrustAnchorARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:rolesanywhere:[a-z0-9-]+:\d{12}:trust-anchor/[\w-]+$`)
+	rolesAnywhereProfileARNPattern     = regexp.MustCompile(`^arn:aws[a-z-]*:rolesanywhere:[a-z0-9-]+:\d{12}:profile/[\w-]+$`)
+)
+
+// credentialPluginNamePattern matches names of credential plugins, which are DNS subdomains with at least two labels,
+// e.g., `vault.example.com`, so they never conflict with built-in authentication sources.
+var credentialPluginNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// regionPattern matches valid region names. S3-compatible services might use non-AWS region names, e.g., `default`.
+var regionPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// A CacheConfig represents local-cache configuration of a volume.
+type CacheConfig struct {
+	// Type is either [CacheTypeEmptyDir] or [CacheTypeEphemeral].
+	Type string
+
+	// EmptyDirSizeLimit is the size limit of the `emptyDir` cache, or nil if there is no limit.
+	EmptyDirSizeLimit *resource.Quantity
+	// EmptyDirMedium is the storage medium of the `emptyDir` cache, either disk-backed (default) or memory-backed.
+	EmptyDirMedium corev1.StorageMedium
+
+	// EphemeralStorageClassName is the storage class of the `ephemeral` cache volume.
+	EphemeralStorageClassName string
+	// EphemeralStorageResourceRequest is the requested size of the `ephemeral` cache volume.
+	EphemeralStorageResourceRequest resource.Quantity
+}
+
+// IsDiskBackedEmptyDir returns whether the cache is an `emptyDir` on the node's disk.
+func (c *CacheConfig) IsDiskBackedEmptyDir() bool {
+	return c.Type == CacheTypeEmptyDir && c.EmptyDirMedium == corev1.StorageMediumDefault
+}
+
+// Supported kinds of [CABundleSource].
+const (
+	CABundleKindConfigMap = "ConfigMap"
+	CABundleKindSecret    = "Secret"
+)
+
+// DefaultCABundleKey is the key the CA bundle is read from if `caBundleKey` is not specified.
+const DefaultCABundleKey = "ca.crt"
+
+// A CABundleSource references a PEM-encoded CA bundle stored in a ConfigMap or a Secret.
+// The referenced object lives in the Mountpoint Pod namespace.
+type CABundleSource struct {
+	// Kind is either [CABundleKindConfigMap] or [CABundleKindSecret].
+	Kind string
+	Name string
+	Key  string
+}
+
+// An AssumeRoleConfig represents an IAM role to assume with the credentials of the volume's authentication source,
+// e.g., to access a bucket in another account.
+type AssumeRoleConfig struct {
+	RoleARN string
+	// ExternalID and SessionName are empty if not specified.
+	ExternalID  string
+	SessionName string
+}
+
+// A RolesAnywhereConfig represents IAM Roles Anywhere configuration used to obtain credentials with
+// the X.509 certificate and private key from the volume's Secret.
+type RolesAnywhereConfig struct {
+	TrustAnchorARN string
+	ProfileARN     string
+	RoleARN        string
+}
+
+// VolumeAttributes represents parsed and validated volume attributes of a volume.
+type VolumeAttributes struct {
+	BucketName string
+	Prefix     string
+	// AuthenticationSource is one of [AuthenticationSourceDriver], [AuthenticationSourcePod], [AuthenticationSourceSecret],
+	// or a credential plugin name (see [IsCredentialPluginName]). It's [AuthenticationSourceDriver] if not specified.
+	AuthenticationSource string
+	STSRegion            string
+	// STSEndpoint is the URL of the STS endpoint to use for pod-level credentials. It's empty if the endpoint should be
+	// resolved from the STS region, optionally using FIPS (UseFIPSEndpoint) or dual-stack (UseDualStackEndpoint) endpoints.
+	STSEndpoint          string
+	UseFIPSEndpoint      bool
+	UseDualStackEndpoint bool
+	SecretName           string
+	SecretNamespace      string
+
+	// RolesAnywhere is the IAM Roles Anywhere configuration, or nil if IAM Roles Anywhere is not used.
+	// It's only set with [AuthenticationSourceSecret], or [AuthenticationSourcePod] in which case the Secret
+	// is always looked up in the workload's namespace.
+	RolesAnywhere *RolesAnywhereConfig
+
+	// AssumeRole is the role to assume with the credentials of the authentication source, or nil if no role should be assumed.
+	AssumeRole *AssumeRoleConfig
+
+	// EndpointURL is the URL of the S3 endpoint, e.g., of an S3-compatible endpoint. It's empty if the default AWS endpoint should be used.
+	EndpointURL string
+	// AddressingStyle is either [AddressingStyleVirtual] or [AddressingStylePath]. It's empty if not specified.
+	AddressingStyle string
+	// Region is the region of the bucket. It's empty if Mountpoint should detect the region.
+	Region string
+
+	// Cache is the local-cache configuration of the volume, or nil if cache is not configured via volume attributes.
+	Cache *CacheConfig
+
+	MountpointPodServiceAccountName string
+	// MountpointContainerResources contains resource requests and limits of the Mountpoint container, only set ones are populated.
+	MountpointContainerResources corev1.ResourceRequirements
+
+	// CABundle is the source of the custom CA bundle Mountpoint should use, or nil if the default trust store should be used.
+	CABundle *CABundleSource
+
+	// UserEnv contains environment variables configured via `mountpointEnv.` prefixed volume attributes.
+	UserEnv envprovider.Environment
+
+	// Following attributes are set by Kubernetes while publishing volumes, as `podInfoOnMount` and `tokenRequests` are enabled.
+	PodName              string
+	PodNamespace         string
+	PodUID               string
+	ServiceAccountName   string
+	ServiceAccountTokens string
+	Ephemeral            bool
+}
+
+// Parse parses and validates volume attributes in `volumeCtx`.
+// It returns all validation errors at once, and it rejects unknown volume attributes.
+// The returned [VolumeAttributes] is populated on a best-effort basis if there are validation errors.
+func Parse(volumeCtx map[string]string) (VolumeAttributes, error) {
+	return parse(volumeCtx, true)
+}
+
+// ParseLenient is like [Parse] but ignores unknown volume attributes.
+// It's used for existing volumes, which might have been created with unknown volume attributes before they were rejected.
+func ParseLenient(volumeCtx map[string]string) (VolumeAttributes, error) {
+	return parse(volumeCtx, false)
+}
+
+func parse(volumeCtx map[string]string, rejectUnknownKeys bool) (VolumeAttributes, error) {
+	var errs []error
+	var err error
+
+	attrs := VolumeAttributes{
+		BucketName:                      volumeCtx[BucketName],
+		Prefix:                          volumeCtx[Prefix],
+		AuthenticationSource:            volumeCtx[AuthenticationSource],
+		STSRegion:                       volumeCtx[STSRegion],
+		STSEndpoint:                     volumeCtx[STSEndpoint],
+		SecretName:                      volumeCtx[SecretName],
+		SecretNamespace:                 volumeCtx[SecretNamespace],
+		EndpointURL:                     volumeCtx[EndpointURL],
+		AddressingStyle:                 volumeCtx[AddressingStyle],
+		Region:                          volumeCtx[Region],
+		MountpointPodServiceAccountName: volumeCtx[MountpointPodServiceAccountName],
+		PodName:                         volumeCtx[CSIPodName],
+		PodNamespace:                    volumeCtx[CSIPodNamespace],
+		PodUID:                          volumeCtx[CSIPodUID],
+		ServiceAccountName:              volumeCtx[CSIServiceAccountName],
+		ServiceAccountTokens:            volumeCtx[CSIServiceAccountTokens],
+		Ephemeral:                       volumeCtx[CSIEphemeral] == "true",
+	}
+
+	switch attrs.AuthenticationSource {
+	case "":
+		attrs.AuthenticationSource = AuthenticationSourceDriver
+	case AuthenticationSourceDriver, AuthenticationSourcePod, AuthenticationSourceSecret:
+	default:
+		// Credential plugins are registered in the CSI Driver Node, and unknown plugins are rejected while mounting
+		if !IsCredentialPluginName(attrs.AuthenticationSource) {
+			errs = append(errs, fmt.Errorf("unknown `%s`: %s, only `%s` (default option if not specified), `%s`, `%s` and credential plugin names (e.g., `vault.example.com`) supported",
+				AuthenticationSource, attrs.AuthenticationSource, AuthenticationSourceDriver, AuthenticationSourcePod, AuthenticationSourceSecret))
+		}
+	}
+
+	attrs.RolesAnywhere, err = parseRolesAnywhere(volumeCtx, attrs.AuthenticationSource)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	attrs.AssumeRole, err = parseAssumeRole(volumeCtx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	attrs.UseFIPSEndpoint, err = parseBool(volumeCtx, UseFIPSEndpoint)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	attrs.UseDualStackEndpoint, err = parseBool(volumeCtx, UseDualStackEndpoint)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, validateEndpoint(attrs)...)
+	errs = append(errs, validateSTSEndpoint(attrs)...)
+
+	cache, err := parseCache(volumeCtx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	attrs.Cache = cache
+
+	attrs.MountpointContainerResources.Requests = parseResourceList(volumeCtx, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    MountpointContainerResourcesRequestsCpu,
+		corev1.ResourceMemory: MountpointContainerResourcesRequestsMemory,
+	}, &errs)
+	attrs.MountpointContainerResources.Limits = parseResourceList(volumeCtx, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    MountpointContainerResourcesLimitsCpu,
+		corev1.ResourceMemory: MountpointContainerResourcesLimitsMemory,
+	}, &errs)
+
+	attrs.CABundle, err = parseCABundle(volumeCtx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	attrs.UserEnv, err = envprovider.ParseUserEnvFromVolumeContext(volumeCtx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if rejectUnknownKeys {
+		var unknownKeys []string
+		for key := range volumeCtx {
+			if !isKnownKey(key) {
+				unknownKeys = append(unknownKeys, key)
+			}
+		}
+		if len(unknownKeys) > 0 {
+			slices.Sort(unknownKeys)
+			errs = append(errs, fmt.Errorf("unknown volume attributes: %s", strings.Join(unknownKeys, ", ")))
+		}
+	}
+
+	return attrs, errors.Join(errs...)
+}
+
+// IsCredentialPluginName returns whether `name` is a valid name for a credential plugin,
+// which can be used as `authenticationSource` once it's registered in the CSI Driver Node.
+func IsCredentialPluginName(name string) bool {
+	return len(name) <= 253 && credentialPluginNamePattern.MatchString(name)
+}
+
+// parseRolesAnywhere parses IAM Roles Anywhere configuration in `volumeCtx`.
+func parseRolesAnywhere(volumeCtx map[string]string, authenticationSource string) (*RolesAnywhereConfig, error) {
+	rolesAnywhere := &RolesAnywhereConfig{
+		TrustAnchorARN: volumeCtx[RolesAnywhereTrustAnchorARN],
+		ProfileARN:     volumeCtx[RolesAnywhereProfileARN],
+		RoleARN:        volumeCtx[RolesAnywhereRoleARN],
+	}
+	if *rolesAnywhere == (RolesAnywhereConfig{}) {
+		return nil, nil
+	}
+
+	if authenticationSource != AuthenticationSourceSecret && authenticationSource != AuthenticationSourcePod {
+		return nil, fmt.Errorf("IAM Roles Anywhere can only be configured with `%s: %s` or `%s: %s`",
+			AuthenticationSource, AuthenticationSourceSecret, AuthenticationSource, AuthenticationSourcePod)
+	}
+
+	var errs []error
+	// With pod-level IAM Roles Anywhere, each workload uses the certificate from the Secret in its own namespace
+	if authenticationSource == AuthenticationSourcePod && volumeCtx[SecretNamespace] != "" {
+		errs = append(errs, fmt.Errorf("%q cannot be specified with pod-level IAM Roles Anywhere, the Secret is looked up in the workload's namespace", SecretNamespace))
+	}
+	if !rolesAnywhereTrustAnchorARNPattern.MatchString(rolesAnywhere.TrustAnchorARN) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be an IAM Roles Anywhere trust anchor ARN", RolesAnywhereTrustAnchorARN, rolesAnywhere.TrustAnchorARN))
+	}
+	if !rolesAnywhereProfileARNPattern.MatchString(rolesAnywhere.ProfileARN) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be an IAM Roles Anywhere profile ARN", RolesAnywhereProfileARN, rolesAnywhere.ProfileARN))
+	}
+	if !roleARNPattern.MatchString(rolesAnywhere.RoleARN) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be an IAM role ARN", RolesAnywhereRoleARN, rolesAnywhere.RoleARN))
+	}
+	return rolesAnywhere, errors.Join(errs...)
+}
+
+// parseAssumeRole parses the role to assume in `volumeCtx`.
+func parseAssumeRole(volumeCtx map[string]string) (*AssumeRoleConfig, error) {
+	role := &AssumeRoleConfig{
+		RoleARN:     volumeCtx[RoleARN],
+		ExternalID:  volumeCtx[RoleExternalID],
+		SessionName: volumeCtx[RoleSessionName],
+	}
+	if role.RoleARN == "" {
+		if role.ExternalID != "" || role.SessionName != "" {
+			return nil, fmt.Errorf("%q and %q can only be specified with %q", RoleExternalID, RoleSessionName, RoleARN)
+		}
+		return nil, nil
+	}
+
+	var errs []error
+	if !roleARNPattern.MatchString(role.RoleARN) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be an IAM role ARN", RoleARN, role.RoleARN))
+	}
+	if role.ExternalID != "" && (len(role.ExternalID) < 2 || len(role.ExternalID) > 1224 || !roleExternalIDPattern.MatchString(role.ExternalID)) {
+		errs = append(errs, fmt.Errorf("invalid %q: must be 2-1224 characters consisting of letters, digits and +=,.@:/-", RoleExternalID))
+	}
+	if role.SessionName != "" && !roleSessionNamePattern.MatchString(role.SessionName) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be 2-64 characters consisting of letters, digits and +=,.@-", RoleSessionName, role.SessionName))
+	}
+	return role, errors.Join(errs...)
+}
+
+// validateEndpoint validates S3 endpoint configuration in `attrs`.
+func validateEndpoint(attrs VolumeAttributes) []error {
+	var errs []error
+
+	if attrs.EndpointURL != "" {
+		endpoint, err := url.Parse(attrs.EndpointURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %q: %w", EndpointURL, err))
+		} else if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid %q: %q, must be an absolute URL with \"http\" or \"https\" scheme", EndpointURL, attrs.EndpointURL))
+		}
+	}
+
+	switch attrs.AddressingStyle {
+	case "", AddressingStyleVirtual, AddressingStylePath:
+	default:
+		errs = append(errs, fmt.Errorf("unknown value for %q: %q, only %q and %q supported", AddressingStyle, attrs.AddressingStyle, AddressingStyleVirtual, AddressingStylePath))
+	}
+
+	if attrs.Region != "" && !regionPattern.MatchString(attrs.Region) {
+		errs = append(errs, fmt.Errorf("invalid %q: %q", Region, attrs.Region))
+	}
+
+	return errs
+}
+
+// validateSTSEndpoint validates STS endpoint configuration in `attrs`.
+func validateSTSEndpoint(attrs VolumeAttributes) []error {
+	if attrs.STSEndpoint == "" {
+		return nil
+	}
+
+	var errs []error
+	endpoint, err := url.Parse(attrs.STSEndpoint)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid %q: %w", STSEndpoint, err))
+	} else if endpoint.Scheme != "https" || endpoint.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid %q: %q, must be an absolute URL with \"https\" scheme", STSEndpoint, attrs.STSEndpoint))
+	}
+
+	if attrs.UseFIPSEndpoint || attrs.UseDualStackEndpoint {
+		errs = append(errs, fmt.Errorf("%q and %q cannot be specified with %q", UseFIPSEndpoint, UseDualStackEndpoint, STSEndpoint))
+	}
+	return errs
+}
+
+// parseBool parses the boolean volume attribute `key` in `volumeCtx`. It returns false if the attribute is not specified.
+func parseBool(volumeCtx map[string]string, key string) (bool, error) {
+	switch value := volumeCtx[key]; value {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %q: %q, only \"true\" and \"false\" supported", key, value)
+	}
+}
+
+// IsAWSEndpoint returns whether `endpointURL` is an AWS endpoint. It returns true for an empty `endpointURL`,
+// as the default AWS endpoint is used in that case.
+func IsAWSEndpoint(endpointURL string) bool {
+	if endpointURL == "" {
+		return true
+	}
+	endpoint, err := url.Parse(endpointURL)
+	if err != nil {
+		return false
+	}
+	host := endpoint.Hostname()
+	return slices.ContainsFunc(awsEndpointSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(host, suffix)
+	})
+}
+
+// parseCache parses local-cache configuration in `volumeCtx`.
+func parseCache(volumeCtx map[string]string) (*CacheConfig, error) {
+	cacheType := volumeCtx[Cache]
+	switch cacheType {
+	case "":
+		return nil, nil
+	case CacheTypeEmptyDir, CacheTypeEphemeral:
+	default:
+		return nil, fmt.Errorf("unsupported local-cache type: %q, only %q and %q are supported", cacheType, CacheTypeEmptyDir, CacheTypeEphemeral)
+	}
+
+	cache := &CacheConfig{Type: cacheType}
+	var errs []error
+
+	if cacheType == CacheTypeEmptyDir {
+		if sizeLimit := volumeCtx[CacheEmptyDirSizeLimit]; sizeLimit != "" {
+			quantity, err := resource.ParseQuantity(sizeLimit)
+			if err != nil {
+				errs = append(errs, failedToParseQuantityError(err, CacheEmptyDirSizeLimit, sizeLimit))
+			} else {
+				cache.EmptyDirSizeLimit = &quantity
+			}
+		}
+
+		switch medium := volumeCtx[CacheEmptyDirMedium]; medium {
+		case string(corev1.StorageMediumDefault), string(corev1.StorageMediumMemory):
+			cache.EmptyDirMedium = corev1.StorageMedium(medium)
+		default:
+			errs = append(errs, fmt.Errorf("unknown value for %q: %q. Only %q supported", CacheEmptyDirMedium, medium, corev1.StorageMediumMemory))
+		}
+	}
+
+	if cacheType == CacheTypeEphemeral {
+		cache.EphemeralStorageClassName = volumeCtx[CacheEphemeralStorageClassName]
+		if cache.EphemeralStorageClassName == "" {
+			errs = append(errs, fmt.Errorf("%q must be provided with %q cache type", CacheEphemeralStorageClassName, CacheTypeEphemeral))
+		}
+
+		storageResourceRequest := volumeCtx[CacheEphemeralStorageResourceRequest]
+		if storageResourceRequest == "" {
+			errs = append(errs, fmt.Errorf("%q must be provided with %q cache type", CacheEphemeralStorageResourceRequest, CacheTypeEphemeral))
+		} else if quantity, err := resource.ParseQuantity(storageResourceRequest); err != nil {
+			errs = append(errs, failedToParseQuantityError(err, CacheEphemeralStorageResourceRequest, storageResourceRequest))
+		} else {
+			cache.EphemeralStorageResourceRequest = quantity
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return cache, fmt.Errorf("failed to configure %q local-cache: %w", cacheType, err)
+	}
+	return cache, nil
+}
+
+// parseCABundle parses the custom CA bundle source in `volumeCtx`.
+func parseCABundle(volumeCtx map[string]string) (*CABundleSource, error) {
+	configMap, secret, key := volumeCtx[CABundleConfigMap], volumeCtx[CABundleSecret], volumeCtx[CABundleKey]
+	if key == "" {
+		key = DefaultCABundleKey
+	}
+
+	switch {
+	case configMap != "" && secret != "":
+		return nil, fmt.Errorf("only one of %q and %q can be specified", CABundleConfigMap, CABundleSecret)
+	case configMap != "":
+		return &CABundleSource{Kind: CABundleKindConfigMap, Name: configMap, Key: key}, nil
+	case secret != "":
+		return &CABundleSource{Kind: CABundleKindSecret, Name: secret, Key: key}, nil
+	case volumeCtx[CABundleKey] != "":
+		return nil, fmt.Errorf("%q must be provided with either %q or %q", CABundleKey, CABundleConfigMap, CABundleSecret)
+	}
+	return nil, nil
+}
+
+// parseResourceList parses quantities of resources in `keys` from `volumeCtx`.
+// It returns nil if none of the resources are set, and appends parsing errors to `errs`.
+func parseResourceList(volumeCtx map[string]string, keys map[corev1.ResourceName]string, errs *[]error) corev1.ResourceList {
+	var list corev1.ResourceList
+	for _, name := range slices.Sorted(maps.Keys(keys)) {
+		key := keys[name]
+		value := volumeCtx[key]
+		if value == "" {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			*errs = append(*errs, failedToParseQuantityError(err, key, value))
+			continue
+		}
+		if list == nil {
+			list = make(corev1.ResourceList)
+		}
+		list[name] = quantity
+	}
+	return list
+}
+
+// isKnownKey returns whether `key` is a volume attribute the CSI Driver understands.
+func isKnownKey(key string) bool {
+	if slices.Contains(knownKeys, key) {
+		return true
+	}
+	return slices.ContainsFunc(knownKeyPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// failedToParseQuantityError creates an error if provided quantity is not parsable.
+func failedToParseQuantityError(err error, field, value string) error {
+	return fmt.Errorf("failed to parse quantity %q for %q: %w", value, field, err)
+}
@@ -0,0 +1,352 @@
+package volumecontext_test
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParsingVolumeAttributes(t *testing.T) {
+	t.Run("Parses all volume attributes", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.BucketName:                               "test-bucket",
+			volumecontext.Prefix:                                   "team-a/",
+			volumecontext.AuthenticationSource:                     volumecontext.AuthenticationSourceSecret,
+			volumecontext.STSRegion:                                "eu-west-1",
+			volumecontext.SecretName:                               "aws-secret",
+			volumecontext.SecretNamespace:                          "team-a",
+			volumecontext.Cache:                                    volumecontext.CacheTypeEmptyDir,
+			volumecontext.CacheEmptyDirSizeLimit:                   "1Gi",
+			volumecontext.CacheEmptyDirMedium:                      string(corev1.StorageMediumMemory),
+			volumecontext.MountpointPodServiceAccountName:          "mp-sa",
+			volumecontext.MountpointContainerResourcesRequestsCpu:  "100m",
+			volumecontext.MountpointContainerResourcesLimitsMemory: "1Gi",
+			volumecontext.CSIPodName:                               "workload",
+			volumecontext.CSIPodNamespace:                          "team-a",
+			volumecontext.CSIPodUID:                                "pod-uid",
+			volumecontext.CSIServiceAccountName:                    "workload-sa",
+			volumecontext.CSIEphemeral:                             "true",
+			"mountpointEnv.HTTPS_PROXY":                            "proxy:3128",
+			"storage.kubernetes.io/csiProvisionerIdentity":         "1700000000000-8081-s3.csi.aws.com",
+		})
+		assert.NoError(t, err)
+
+		sizeLimit := resource.MustParse("1Gi")
+		assert.Equals(t, volumecontext.VolumeAttributes{
+			BucketName:           "test-bucket",
+			Prefix:               "team-a/",
+			AuthenticationSource: volumecontext.AuthenticationSourceSecret,
+			STSRegion:            "eu-west-1",
+			SecretName:           "aws-secret",
+			SecretNamespace:      "team-a",
+			Cache: &volumecontext.CacheConfig{
+				Type:              volumecontext.CacheTypeEmptyDir,
+				EmptyDirSizeLimit: &sizeLimit,
+				EmptyDirMedium:    corev1.StorageMediumMemory,
+			},
+			MountpointPodServiceAccountName: "mp-sa",
+			MountpointContainerResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			UserEnv:            envprovider.Environment{"HTTPS_PROXY": "proxy:3128"},
+			PodName:            "workload",
+			PodNamespace:       "team-a",
+			PodUID:             "pod-uid",
+			ServiceAccountName: "workload-sa",
+			Ephemeral:          true,
+		}, attrs)
+	})
+
+	t.Run("Defaults authentication source to driver", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{volumecontext.BucketName: "test-bucket"})
+		assert.NoError(t, err)
+		assert.Equals(t, volumecontext.AuthenticationSourceDriver, attrs.AuthenticationSource)
+		assert.Equals(t, (*volumecontext.CacheConfig)(nil), attrs.Cache)
+	})
+
+	t.Run("Parses ephemeral cache", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.Cache:                                volumecontext.CacheTypeEphemeral,
+			volumecontext.CacheEphemeralStorageClassName:       "gp3",
+			volumecontext.CacheEphemeralStorageResourceRequest: "10Gi",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.CacheConfig{
+			Type:                            volumecontext.CacheTypeEphemeral,
+			EphemeralStorageClassName:       "gp3",
+			EphemeralStorageResourceRequest: resource.MustParse("10Gi"),
+		}, attrs.Cache)
+	})
+
+	t.Run("Parses CA bundle sources", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{volumecontext.CABundleConfigMap: "corp-ca"})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.CABundleSource{
+			Kind: volumecontext.CABundleKindConfigMap,
+			Name: "corp-ca",
+			Key:  volumecontext.DefaultCABundleKey,
+		}, attrs.CABundle)
+
+		attrs, err = volumecontext.Parse(map[string]string{
+			volumecontext.CABundleSecret: "corp-ca",
+			volumecontext.CABundleKey:    "bundle.pem",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.CABundleSource{
+			Kind: volumecontext.CABundleKindSecret,
+			Name: "corp-ca",
+			Key:  "bundle.pem",
+		}, attrs.CABundle)
+	})
+
+	t.Run("Rejects ambiguous CA bundle sources", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.CABundleConfigMap: "corp-ca",
+			volumecontext.CABundleSecret:    "corp-ca",
+		})
+		assertErrorContains(t, err, `only one of "caBundleConfigMap" and "caBundleSecret" can be specified`)
+
+		_, err = volumecontext.Parse(map[string]string{volumecontext.CABundleKey: "bundle.pem"})
+		assertErrorContains(t, err, `"caBundleKey" must be provided with either "caBundleConfigMap" or "caBundleSecret"`)
+	})
+
+	t.Run("Parses role to assume", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.RoleARN:         "arn:aws:iam::111122223333:role/data-lake",
+			volumecontext.RoleExternalID:  "team-a-external-id",
+			volumecontext.RoleSessionName: "team-a",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.AssumeRoleConfig{
+			RoleARN:     "arn:aws:iam::111122223333:role/data-lake",
+			ExternalID:  "team-a-external-id",
+			SessionName: "team-a",
+		}, attrs.AssumeRole)
+
+		attrs, err = volumecontext.Parse(map[string]string{volumecontext.RoleARN: "arn:aws-cn:iam::111122223333:role/path/data-lake"})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.AssumeRoleConfig{RoleARN: "arn:aws-cn:iam::111122223333:role/path/data-lake"}, attrs.AssumeRole)
+	})
+
+	t.Run("Rejects invalid role to assume", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.RoleARN:         "arn:aws:iam::111122223333:user/data-lake",
+			volumecontext.RoleExternalID:  "x",
+			volumecontext.RoleSessionName: "team a",
+		})
+		assertErrorContains(t, err,
+			`invalid "roleArn": "arn:aws:iam::111122223333:user/data-lake", must be an IAM role ARN`,
+			`invalid "roleExternalId"`,
+			`invalid "roleSessionName": "team a"`,
+		)
+
+		_, err = volumecontext.Parse(map[string]string{volumecontext.RoleExternalID: "team-a-external-id"})
+		assertErrorContains(t, err, `"roleExternalId" and "roleSessionName" can only be specified with "roleArn"`)
+	})
+
+	t.Run("Parses IAM Roles Anywhere configuration", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.AuthenticationSource:        volumecontext.AuthenticationSourceSecret,
+			volumecontext.SecretName:                  "s3-certificate",
+			volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+			volumecontext.RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			volumecontext.RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, &volumecontext.RolesAnywhereConfig{
+			TrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+			ProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			RoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+		}, attrs.RolesAnywhere)
+	})
+
+	t.Run("Rejects invalid IAM Roles Anywhere configuration", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.AuthenticationSource:        volumecontext.AuthenticationSourceSecret,
+			volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			volumecontext.RolesAnywhereRoleARN:        "data-lake",
+		})
+		assertErrorContains(t, err,
+			`invalid "rolesAnywhereTrustAnchorArn": "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8", must be an IAM Roles Anywhere trust anchor ARN`,
+			`invalid "rolesAnywhereProfileArn": "", must be an IAM Roles Anywhere profile ARN`,
+			`invalid "rolesAnywhereRoleArn": "data-lake", must be an IAM role ARN`,
+		)
+
+		_, err = volumecontext.Parse(map[string]string{
+			volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+		})
+		assertErrorContains(t, err, "IAM Roles Anywhere can only be configured with `authenticationSource: secret` or `authenticationSource: pod`")
+
+		_, err = volumecontext.Parse(map[string]string{
+			volumecontext.AuthenticationSource:        volumecontext.AuthenticationSourcePod,
+			volumecontext.SecretName:                  "s3-certificate",
+			volumecontext.SecretNamespace:             "team-a",
+			volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+			volumecontext.RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			volumecontext.RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+		})
+		assertErrorContains(t, err, `"secretNamespace" cannot be specified with pod-level IAM Roles Anywhere`)
+	})
+
+	t.Run("Parses pod-level IAM Roles Anywhere configuration", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.AuthenticationSource:        volumecontext.AuthenticationSourcePod,
+			volumecontext.SecretName:                  "s3-certificate",
+			volumecontext.RolesAnywhereTrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+			volumecontext.RolesAnywhereProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			volumecontext.RolesAnywhereRoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, "s3-certificate", attrs.SecretName)
+		assert.Equals(t, &volumecontext.RolesAnywhereConfig{
+			TrustAnchorARN: "arn:aws:rolesanywhere:us-east-1:111122223333:trust-anchor/a1b2c3d4",
+			ProfileARN:     "arn:aws:rolesanywhere:us-east-1:111122223333:profile/e5f6a7b8",
+			RoleARN:        "arn:aws:iam::111122223333:role/data-lake",
+		}, attrs.RolesAnywhere)
+	})
+
+	t.Run("Parses S3-compatible endpoint configuration", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.EndpointURL:     "http://minio.storage.svc:9000",
+			volumecontext.AddressingStyle: volumecontext.AddressingStylePath,
+			volumecontext.Region:          "default",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, "http://minio.storage.svc:9000", attrs.EndpointURL)
+		assert.Equals(t, volumecontext.AddressingStylePath, attrs.AddressingStyle)
+		assert.Equals(t, "default", attrs.Region)
+	})
+
+	t.Run("Rejects invalid S3-compatible endpoint configuration", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.EndpointURL:     "minio.storage.svc:9000",
+			volumecontext.AddressingStyle: "host",
+			volumecontext.Region:          "us east 1",
+		})
+		assertErrorContains(t, err,
+			`invalid "endpointUrl": "minio.storage.svc:9000", must be an absolute URL with "http" or "https" scheme`,
+			`unknown value for "addressingStyle": "host", only "virtual" and "path" supported`,
+			`invalid "region": "us east 1"`,
+		)
+	})
+
+	t.Run("Parses STS endpoint configuration", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{
+			volumecontext.STSRegion:            "us-gov-west-1",
+			volumecontext.UseFIPSEndpoint:      "true",
+			volumecontext.UseDualStackEndpoint: "false",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, true, attrs.UseFIPSEndpoint)
+		assert.Equals(t, false, attrs.UseDualStackEndpoint)
+
+		attrs, err = volumecontext.Parse(map[string]string{
+			volumecontext.STSEndpoint: "https://sts.us-gov-west-1.amazonaws.com",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, "https://sts.us-gov-west-1.amazonaws.com", attrs.STSEndpoint)
+	})
+
+	t.Run("Rejects invalid STS endpoint configuration", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.STSEndpoint:          "http://sts.us-east-1.amazonaws.com",
+			volumecontext.UseFIPSEndpoint:      "true",
+			volumecontext.UseDualStackEndpoint: "yes",
+		})
+		assertErrorContains(t, err,
+			`invalid "stsEndpoint": "http://sts.us-east-1.amazonaws.com", must be an absolute URL with "https" scheme`,
+			`"useFipsEndpoint" and "useDualStackEndpoint" cannot be specified with "stsEndpoint"`,
+			`invalid "useDualStackEndpoint": "yes", only "true" and "false" supported`,
+		)
+	})
+
+	t.Run("Returns all validation errors at once", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{
+			volumecontext.AuthenticationSource:                       "node",
+			volumecontext.Cache:                                      volumecontext.CacheTypeEphemeral,
+			volumecontext.MountpointContainerResourcesLimitsCpu:      "lots",
+			volumecontext.MountpointContainerResourcesRequestsMemory: "1Gb",
+			"mountpointEnv.FOO":                                      "BAR",
+		})
+		assertErrorContains(t, err,
+			"unknown `authenticationSource`: node",
+			`"cacheEphemeralStorageClassName" must be provided with "ephemeral" cache type`,
+			`"cacheEphemeralStorageResourceRequest" must be provided with "ephemeral" cache type`,
+			`failed to parse quantity "lots" for "mountpointContainerResourcesLimitsCpu"`,
+			`failed to parse quantity "1Gb" for "mountpointContainerResourcesRequestsMemory"`,
+			"environment variable not allowed: mountpointEnv.FOO",
+		)
+	})
+
+	t.Run("Accepts credential plugin names as authentication source", func(t *testing.T) {
+		attrs, err := volumecontext.Parse(map[string]string{volumecontext.AuthenticationSource: "vault.example.com"})
+		assert.NoError(t, err)
+		assert.Equals(t, "vault.example.com", attrs.AuthenticationSource)
+
+		for _, source := range []string{"vault", "Vault.example.com", "vault.example.com.", "-vault.example.com"} {
+			_, err := volumecontext.Parse(map[string]string{volumecontext.AuthenticationSource: source})
+			assertErrorContains(t, err, "unknown `authenticationSource`: "+source)
+		}
+	})
+
+	t.Run("Rejects unknown cache type and medium", func(t *testing.T) {
+		_, err := volumecontext.Parse(map[string]string{volumecontext.Cache: "hostPath"})
+		assertErrorContains(t, err, `unsupported local-cache type: "hostPath"`)
+
+		_, err = volumecontext.Parse(map[string]string{
+			volumecontext.Cache:               volumecontext.CacheTypeEmptyDir,
+			volumecontext.CacheEmptyDirMedium: string(corev1.StorageMediumHugePages),
+		})
+		assertErrorContains(t, err, `unknown value for "cacheEmptyDirMedium": "HugePages"`)
+	})
+
+	t.Run("Rejects unknown volume attributes unless lenient", func(t *testing.T) {
+		volumeCtx := map[string]string{
+			volumecontext.BucketName:      "test-bucket",
+			"bucket":                      "typo",
+			"mountpointEnvvv.HTTPS_PROXY": "proxy:3128",
+		}
+
+		_, err := volumecontext.Parse(volumeCtx)
+		assertErrorContains(t, err, "unknown volume attributes: bucket, mountpointEnvvv.HTTPS_PROXY")
+
+		attrs, err := volumecontext.ParseLenient(volumeCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, "test-bucket", attrs.BucketName)
+	})
+}
+
+func TestIsAWSEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]bool{
+		"":                                       true,
+		"https://s3.us-east-1.amazonaws.com":     true,
+		"https://s3.cn-north-1.amazonaws.com.cn": true,
+		"https://s3.dualstack.us-east-1.api.aws": true,
+		"https://s3.us-iso-east-1.c2s.ic.gov":    true,
+		"https://bucket.vpce-1a2b3c4d.s3.us-east-1.vpce.amazonaws.com": true,
+		"http://minio.storage.svc:9000":                                false,
+		"https://rgw.example.com":                                      false,
+		"https://amazonaws.com.example.com":                            false,
+	} {
+		assert.Equals(t, want, volumecontext.IsAWSEndpoint(endpoint))
+	}
+}
+
+func assertErrorContains(t *testing.T, err error, messages ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("Expected an error containing %q, got nil", messages)
+	}
+	for _, message := range messages {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected error %q to contain %q", err.Error(), message)
+		}
+	}
+}
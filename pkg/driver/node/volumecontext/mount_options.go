@@ -0,0 +1,107 @@
+package volumecontext
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// maxCacheSizeSafetyFactor accounts for Mountpoint overshooting its cache target by around 1-2%.
+const maxCacheSizeSafetyFactor = 0.95
+
+// ValidateMountOptions returns an error if mount options `args` are not supported by the CSI Driver,
+// or they conflict with volume attributes `attrs`.
+// It's used both while publishing volumes and while admitting PersistentVolumes.
+func ValidateMountOptions(args *mountpoint.Args, attrs VolumeAttributes) error {
+	if args.Has(mountpoint.ArgFsTab) {
+		return errors.New("Running mount-s3 with mount flag -o is not supported in CSI Driver.")
+	}
+
+	// Paths on the node are not visible to Mountpoint, custom CA bundles are provided via volume attributes instead.
+	if args.Has(mountpoint.ArgCABundle) {
+		return fmt.Errorf("Running mount-s3 with %s is not supported in CSI Driver, please use %q or %q volume attributes instead.",
+			mountpoint.ArgCABundle, CABundleConfigMap, CABundleSecret)
+	}
+
+	// `prefix` is set by the provisioner for dynamically provisioned volumes.
+	if attrs.Prefix != "" {
+		if existing, ok := args.Value(mountpoint.ArgPrefix); ok && existing != attrs.Prefix {
+			return fmt.Errorf("Mount option %s=%q conflicts with the volume prefix %q", mountpoint.ArgPrefix, existing, attrs.Prefix)
+		}
+	}
+
+	if attrs.EndpointURL != "" {
+		if existing, ok := args.Value(mountpoint.ArgEndpointURL); ok && existing != attrs.EndpointURL {
+			return fmt.Errorf("Mount option %s=%q conflicts with %q volume attribute %q", mountpoint.ArgEndpointURL, existing, EndpointURL, attrs.EndpointURL)
+		}
+	}
+	if attrs.Region != "" {
+		if existing, ok := args.Value(mountpoint.ArgRegion); ok && existing != attrs.Region {
+			return fmt.Errorf("Mount option %s=%q conflicts with %q volume attribute %q", mountpoint.ArgRegion, existing, Region, attrs.Region)
+		}
+	}
+	if attrs.AddressingStyle == AddressingStyleVirtual && args.Has(mountpoint.ArgForcePathStyle) {
+		return fmt.Errorf("Mount option %s conflicts with %q volume attribute %q", mountpoint.ArgForcePathStyle, AddressingStyle, attrs.AddressingStyle)
+	}
+
+	return nil
+}
+
+// ApplyEndpoint translates `endpointUrl`, `addressingStyle` and `region` volume attributes in `attrs` to Mountpoint flags in `args`.
+// Conflicting mount options are rejected by [ValidateMountOptions].
+func ApplyEndpoint(args *mountpoint.Args, attrs VolumeAttributes) {
+	if attrs.EndpointURL != "" {
+		args.Set(mountpoint.ArgEndpointURL, attrs.EndpointURL)
+	}
+	if attrs.AddressingStyle == AddressingStylePath {
+		args.SetIfAbsent(mountpoint.ArgForcePathStyle, mountpoint.ArgNoValue)
+	}
+	if attrs.Region != "" {
+		args.Set(mountpoint.ArgRegion, attrs.Region)
+	}
+}
+
+// ApplyCacheEmptyDirSizeLimit validates `--max-cache-size` in `args` against `cacheEmptyDirSizeLimit` in `attrs`
+// if `emptyDir` cache is used, and adjusts `--max-cache-size` for disk-backed `emptyDir` caches.
+//
+// For disk-backed (default) medium, statvfs on the cache directory reports the node's root filesystem
+// stats rather than the emptyDir's sizeLimit, so Mountpoint cannot self-limit correctly.
+// It sets `--max-cache-size` to 95% of the limit to ensure Mountpoint evicts before Kubernetes does,
+// unless a lower `--max-cache-size` is already set.
+// Memory medium has an isolated filesystem with accurate size reporting, so Mountpoint
+// can self-limit without this adjustment.
+func ApplyCacheEmptyDirSizeLimit(args *mountpoint.Args, attrs VolumeAttributes) error {
+	cache := attrs.Cache
+	if cache == nil || cache.Type != CacheTypeEmptyDir || cache.EmptyDirSizeLimit == nil {
+		return nil
+	}
+
+	emptyDirSizeLimit := cache.EmptyDirSizeLimit
+	emptyDirSizeLimitMiB := emptyDirSizeLimit.Value() / (1024 * 1024)
+	safeMaxCacheSizeMiB := int64(float64(emptyDirSizeLimit.Value()) * maxCacheSizeSafetyFactor / (1024 * 1024))
+	diskBacked := cache.IsDiskBackedEmptyDir()
+
+	if maxCacheSize, ok := args.Value(mountpoint.ArgMaxCacheSize); ok {
+		maxCacheSizeMiB, err := strconv.ParseInt(maxCacheSize, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid %s %q: %v", mountpoint.ArgMaxCacheSize, maxCacheSize, err)
+		}
+		if maxCacheSizeMiB > emptyDirSizeLimitMiB {
+			return fmt.Errorf("%s (%d MiB) exceeds %s (%s = %d MiB). Reduce %s or increase %s.",
+				mountpoint.ArgMaxCacheSize, maxCacheSizeMiB,
+				CacheEmptyDirSizeLimit, emptyDirSizeLimit.String(), emptyDirSizeLimitMiB,
+				mountpoint.ArgMaxCacheSize, CacheEmptyDirSizeLimit)
+		}
+		// Remove explicit `--max-cache-size` if it exceeds the safe threshold, allowing the safe default to be set below.
+		if diskBacked && maxCacheSizeMiB > safeMaxCacheSizeMiB {
+			args.Remove(mountpoint.ArgMaxCacheSize)
+		}
+	}
+
+	if diskBacked {
+		args.SetIfAbsent(mountpoint.ArgMaxCacheSize, strconv.FormatInt(safeMaxCacheSizeMiB, 10))
+	}
+	return nil
+}
@@ -5,6 +5,27 @@ const (
 	BucketName           = "bucketName"
 	AuthenticationSource = "authenticationSource"
 	STSRegion            = "stsRegion"
+	Prefix               = "prefix"
+	SecretName           = "secretName"
+	SecretNamespace      = "secretNamespace"
+
+	STSEndpoint          = "stsEndpoint"
+	UseFIPSEndpoint      = "useFipsEndpoint"
+	UseDualStackEndpoint = "useDualStackEndpoint"
+
+	RoleARN         = "roleArn"
+	RoleExternalID  = "roleExternalId"
+	RoleSessionName = "roleSessionName"
+
+	RolesAnywhereTrustAnchorARN = "rolesAnywhereTrustAnchorArn"
+	RolesAnywhereProfileARN     = "rolesAnywhereProfileArn"
+	RolesAnywhereRoleARN        = "rolesAnywhereRoleArn"
+
+	EndpointURL            = "endpointUrl"
+	AddressingStyle        = "addressingStyle"
+	AddressingStyleVirtual = "virtual"
+	AddressingStylePath    = "path"
+	Region                 = "region"
 
 	Cache                                = "cache"
 	CacheTypeEmptyDir                    = "emptyDir"
@@ -21,8 +42,14 @@ const (
 	MountpointContainerResourcesLimitsCpu      = "mountpointContainerResourcesLimitsCpu"
 	MountpointContainerResourcesLimitsMemory   = "mountpointContainerResourcesLimitsMemory"
 
+	CABundleConfigMap = "caBundleConfigMap"
+	CABundleSecret    = "caBundleSecret"
+	CABundleKey       = "caBundleKey"
+
 	CSIServiceAccountName   = "csi.storage.k8s.io/serviceAccount.name"
 	CSIServiceAccountTokens = "csi.storage.k8s.io/serviceAccount.tokens"
+	CSIPodName              = "csi.storage.k8s.io/pod.name"
 	CSIPodNamespace         = "csi.storage.k8s.io/pod.namespace"
 	CSIPodUID               = "csi.storage.k8s.io/pod.uid"
+	CSIEphemeral            = "csi.storage.k8s.io/ephemeral"
 )
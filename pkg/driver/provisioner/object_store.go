@@ -0,0 +1,83 @@
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// An ObjectStore manages objects of provisioned volumes.
+type ObjectStore interface {
+	// DeletePrefix deletes all objects under `prefix` in `bucket`.
+	// `region` is the region of the bucket, or empty to use the default region.
+	DeletePrefix(ctx context.Context, region, bucket, prefix string) error
+}
+
+// S3ObjectStore is an [ObjectStore] backed by Amazon S3.
+type S3ObjectStore struct {
+	optFns []func(*s3.Options)
+}
+
+var _ ObjectStore = &S3ObjectStore{}
+
+// NewS3ObjectStore creates a new [S3ObjectStore].
+// S3 clients are created using the default credential chain of the process and customized with `optFns`.
+func NewS3ObjectStore(optFns ...func(*s3.Options)) *S3ObjectStore {
+	return &S3ObjectStore{optFns: optFns}
+}
+
+// DeletePrefix deletes all objects under `prefix` in `bucket`.
+// For versioned buckets, this only deletes the current versions of the objects, i.e., adds delete markers.
+func (s *S3ObjectStore) DeletePrefix(ctx context.Context, region, bucket, prefix string) error {
+	var loadOpts []func(*config.LoadOptions) error
+	if region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg, s.optFns...)
+
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects under s3://%s/%s: %w", bucket, prefix, err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		// `ListObjectsV2` returns at most 1000 keys per page, which is also the limit of `DeleteObjects`.
+		objects := make([]types.ObjectIdentifier, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			objects = append(objects, types.ObjectIdentifier{Key: object.Key})
+		}
+
+		out, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete objects under s3://%s/%s: %w", bucket, prefix, err)
+		}
+		if len(out.Errors) > 0 {
+			errs := make([]error, 0, len(out.Errors))
+			for _, e := range out.Errors {
+				errs = append(errs, fmt.Errorf("%s: %s (%s)", aws.ToString(e.Key), aws.ToString(e.Message), aws.ToString(e.Code)))
+			}
+			return fmt.Errorf("failed to delete %d objects under s3://%s/%s: %w", len(out.Errors), bucket, prefix, errors.Join(errs...))
+		}
+	}
+
+	return nil
+}
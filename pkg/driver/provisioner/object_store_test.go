@@ -0,0 +1,192 @@
+package provisioner_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/provisioner"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testBucket = "test-bucket"
+
+func TestS3ObjectStoreDeletePrefix(t *testing.T) {
+	t.Run("Deletes only objects under the prefix", func(t *testing.T) {
+		s3Server := newFakeS3Server(t, testBucket, []string{
+			"base/ns/pvc-a/file1",
+			"base/ns/pvc-a/dir/file2",
+			"base/ns/pvc-a/dir/file3",
+			"base/ns/pvc-a/file4",
+			"base/ns/pvc-a/file5",
+			"base/ns/pvc-ab/file1",
+			"base/ns/pvc-b/file1",
+		})
+
+		store := newTestS3ObjectStore(s3Server)
+		assert.NoError(t, store.DeletePrefix(context.Background(), "", testBucket, "base/ns/pvc-a/"))
+
+		assert.Equals(t, []string{
+			"base/ns/pvc-ab/file1",
+			"base/ns/pvc-b/file1",
+		}, s3Server.keys())
+	})
+
+	t.Run("Succeeds if there are no objects under the prefix", func(t *testing.T) {
+		s3Server := newFakeS3Server(t, testBucket, []string{"base/ns/pvc-b/file1"})
+
+		store := newTestS3ObjectStore(s3Server)
+		assert.NoError(t, store.DeletePrefix(context.Background(), "", testBucket, "base/ns/pvc-a/"))
+
+		assert.Equals(t, []string{"base/ns/pvc-b/file1"}, s3Server.keys())
+	})
+
+	t.Run("Fails if the bucket does not exist", func(t *testing.T) {
+		s3Server := newFakeS3Server(t, testBucket, nil)
+
+		store := newTestS3ObjectStore(s3Server)
+		err := store.DeletePrefix(context.Background(), "", "non-existent-bucket", "base/ns/pvc-a/")
+		if err == nil {
+			t.Fatal("Expected DeletePrefix to fail for a non-existent bucket")
+		}
+	})
+}
+
+func newTestS3ObjectStore(s3Server *fakeS3Server) *provisioner.S3ObjectStore {
+	return provisioner.NewS3ObjectStore(func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(s3Server.URL)
+		o.UsePathStyle = true
+		o.Region = "us-east-1"
+		o.Credentials = credentials.NewStaticCredentialsProvider("test-access-key", "test-secret-key", "")
+	})
+}
+
+// fakeS3ServerPageSize is the number of keys returned per `ListObjectsV2` page, it's intentionally small to exercise pagination.
+const fakeS3ServerPageSize = 2
+
+// fakeS3Server is a local stand-in for S3 serving a single bucket.
+// It only implements path-style `ListObjectsV2` and `DeleteObjects`.
+type fakeS3Server struct {
+	*httptest.Server
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]struct{}
+}
+
+func newFakeS3Server(t *testing.T, bucket string, keys []string) *fakeS3Server {
+	s := &fakeS3Server{bucket: bucket, objects: make(map[string]struct{})}
+	for _, key := range keys {
+		s.objects[key] = struct{}{}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeS3Server) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string   `xml:"Name"`
+	Prefix                string   `xml:"Prefix"`
+	KeyCount              int      `xml:"KeyCount"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+	Contents              []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+type deleteRequest struct {
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name `xml:"DeleteResult"`
+}
+
+func (s *fakeS3Server) handle(w http.ResponseWriter, r *http.Request) {
+	bucket := strings.TrimPrefix(r.URL.Path, "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`))
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.listObjectsV2(w, query.Get("prefix"), query.Get("continuation-token"))
+	case r.Method == http.MethodPost && query.Has("delete"):
+		var req deleteRequest
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		for _, object := range req.Objects {
+			delete(s.objects, object.Key)
+		}
+		s.mu.Unlock()
+		writeXML(w, deleteResult{})
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *fakeS3Server) listObjectsV2(w http.ResponseWriter, prefix, continuationToken string) {
+	var matching []string
+	for _, key := range s.keys() {
+		if strings.HasPrefix(key, prefix) {
+			matching = append(matching, key)
+		}
+	}
+
+	// Continuation token is the last key of the previous page, like S3, keys are returned in lexicographical order.
+	start := 0
+	if continuationToken != "" {
+		start, _ = slices.BinarySearch(matching, continuationToken)
+		if start < len(matching) && matching[start] == continuationToken {
+			start++
+		}
+	}
+	end := min(start+fakeS3ServerPageSize, len(matching))
+
+	result := listBucketResult{Name: s.bucket, Prefix: prefix, KeyCount: end - start}
+	for _, key := range matching[start:end] {
+		result.Contents = append(result.Contents, struct {
+			Key string `xml:"Key"`
+		}{Key: key})
+	}
+	if end < len(matching) {
+		result.IsTruncated = true
+		result.NextContinuationToken = matching[end-1]
+	}
+	writeXML(w, result)
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,80 @@
+package provisioner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// StorageClass parameters consumed by the provisioner.
+// Any other parameter, and `region`, is passed through as-is to the volume context of the provisioned volume,
+// so it needs to be a volume attribute the CSI Driver understands (e.g., `authenticationSource` or `cache`).
+const (
+	ParamBucketName     = "bucketName"
+	ParamBasePrefix     = "basePrefix"
+	ParamDeletionPolicy = "deletionPolicy"
+	ParamRegion         = "region"
+)
+
+// Parameters passed by the external-provisioner if it's started with `--extra-create-metadata`.
+const (
+	paramPVCName      = "csi.storage.k8s.io/pvc/name"
+	paramPVCNamespace = "csi.storage.k8s.io/pvc/namespace"
+
+	// csiParamPrefix is the prefix reserved for parameters set by the external-provisioner.
+	csiParamPrefix = "csi.storage.k8s.io/"
+)
+
+// A DeletionPolicy specifies what happens to the objects under a provisioned volume's prefix once the volume is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps all objects under the prefix on volume deletion.
+	DeletionPolicyRetain DeletionPolicy = "retain"
+	// DeletionPolicyDelete deletes all objects under the prefix on volume deletion.
+	DeletionPolicyDelete DeletionPolicy = "delete"
+)
+
+// ParseDeletionPolicy parses given value as a [DeletionPolicy], an empty value defaults to [DeletionPolicyRetain].
+func ParseDeletionPolicy(value string) (DeletionPolicy, error) {
+	switch DeletionPolicy(strings.ToLower(value)) {
+	case "", DeletionPolicyRetain:
+		return DeletionPolicyRetain, nil
+	case DeletionPolicyDelete:
+		return DeletionPolicyDelete, nil
+	}
+	return "", fmt.Errorf("unsupported %s %q, must be one of %q or %q", ParamDeletionPolicy, value, DeletionPolicyRetain, DeletionPolicyDelete)
+}
+
+// volumePrefix returns the key prefix for a volume with given name and parameters.
+// The prefix is `<basePrefix>/<pvc namespace>/<pvc name>/` if the PVC metadata is available,
+// and `<basePrefix>/<volume name>/` otherwise. The returned prefix always ends with `/` and never starts with `/`.
+func volumePrefix(volumeName string, params map[string]string) string {
+	elems := []string{params[ParamBasePrefix]}
+
+	namespace, name := params[paramPVCNamespace], params[paramPVCName]
+	if namespace != "" && name != "" {
+		elems = append(elems, namespace, name)
+	} else {
+		elems = append(elems, volumeName)
+	}
+
+	prefix := strings.TrimPrefix(path.Join(elems...), "/")
+	return prefix + "/"
+}
+
+// passThroughParameters returns parameters to pass to the volume context of the provisioned volume.
+func passThroughParameters(params map[string]string) map[string]string {
+	volumeCtx := make(map[string]string, len(params))
+	for key, value := range params {
+		switch key {
+		case ParamBasePrefix, ParamDeletionPolicy:
+			continue
+		}
+		if strings.HasPrefix(key, csiParamPrefix) {
+			continue
+		}
+		volumeCtx[key] = value
+	}
+	return volumeCtx
+}
@@ -0,0 +1,136 @@
+// Package provisioner provides dynamic provisioning of prefix-scoped volumes.
+//
+// Each provisioned volume is a unique key prefix inside a bucket configured in the StorageClass,
+// and it's mounted by passing `--prefix` to Mountpoint.
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// supportedAccessModes is the list of access modes supported by the provisioned volumes.
+var supportedAccessModes = []csi.VolumeCapability_AccessMode_Mode{
+	csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+	csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+}
+
+// A Provisioner provisions and deletes prefix-scoped volumes.
+type Provisioner struct {
+	objectStore ObjectStore
+}
+
+// New creates a new [Provisioner] using `objectStore` to delete objects of deleted volumes.
+func New(objectStore ObjectStore) *Provisioner {
+	return &Provisioner{objectStore: objectStore}
+}
+
+// CreateVolume provisions a new volume.
+// There is nothing to create in S3, this just computes the prefix of the volume and returns the volume
+// with a deterministic ID, which makes this operation idempotent.
+func (p *Provisioner) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+	name := req.GetName()
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "Volume name not provided")
+	}
+
+	if err := validateVolumeCapabilities(req.GetVolumeCapabilities()); err != nil {
+		return nil, err
+	}
+
+	if req.GetVolumeContentSource() != nil {
+		return nil, status.Error(codes.InvalidArgument, "Volume content source is not supported")
+	}
+
+	params := req.GetParameters()
+	bucket := params[ParamBucketName]
+	if bucket == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "StorageClass parameter %q not provided", ParamBucketName)
+	}
+
+	deletionPolicy, err := ParseDeletionPolicy(params[ParamDeletionPolicy])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	volumeID := VolumeID{
+		DeletionPolicy: deletionPolicy,
+		Region:         params[ParamRegion],
+		Bucket:         bucket,
+		Prefix:         volumePrefix(name, params),
+	}
+
+	volumeCtx := passThroughParameters(params)
+	volumeCtx[volumecontext.Prefix] = volumeID.Prefix
+	// Reject parameters the volume would be rejected or fail to mount with, rather than provisioning an unusable volume
+	if _, err := volumecontext.Parse(volumeCtx); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid StorageClass parameters: %v", err)
+	}
+
+	klog.V(4).Infof("CreateVolume: provisioned volume %q as prefix %q in bucket %q", name, volumeID.Prefix, bucket)
+
+	return &csi.CreateVolumeResponse{
+		Volume: &csi.Volume{
+			VolumeId: volumeID.String(),
+			// S3 does not have a capacity limit, so just report the requested capacity
+			CapacityBytes: req.GetCapacityRange().GetRequiredBytes(),
+			VolumeContext: volumeCtx,
+		},
+	}, nil
+}
+
+// DeleteVolume deletes a provisioned volume.
+// Objects under the volume's prefix are only deleted if the volume was provisioned with [DeletionPolicyDelete].
+func (p *Provisioner) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+	rawVolumeID := req.GetVolumeId()
+	if rawVolumeID == "" {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	volumeID, err := ParseVolumeID(rawVolumeID)
+	if err != nil {
+		if errors.Is(err, errNotProvisionedVolumeID) {
+			// The volume is not provisioned by us, there is nothing to delete.
+			klog.Warningf("DeleteVolume: volume %q was not created by the provisioner, ignoring", rawVolumeID)
+			return &csi.DeleteVolumeResponse{}, nil
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if volumeID.DeletionPolicy != DeletionPolicyDelete {
+		klog.V(4).Infof("DeleteVolume: retaining objects under prefix %q in bucket %q", volumeID.Prefix, volumeID.Bucket)
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+
+	klog.V(4).Infof("DeleteVolume: deleting objects under prefix %q in bucket %q", volumeID.Prefix, volumeID.Bucket)
+	if err := p.objectStore.DeletePrefix(ctx, volumeID.Region, volumeID.Bucket, volumeID.Prefix); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to delete volume %q: %v", rawVolumeID, err)
+	}
+
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+// validateVolumeCapabilities returns an error if any of the given volume capabilities is not supported.
+func validateVolumeCapabilities(caps []*csi.VolumeCapability) error {
+	if len(caps) == 0 {
+		return status.Error(codes.InvalidArgument, "Volume capabilities not provided")
+	}
+	for _, c := range caps {
+		if c.GetMount() == nil {
+			return status.Error(codes.InvalidArgument, "Only filesystem volumes are supported")
+		}
+		mode := c.GetAccessMode().GetMode()
+		if !slices.Contains(supportedAccessModes, mode) {
+			return status.Errorf(codes.InvalidArgument, "Access mode %s is not supported", mode)
+		}
+	}
+	return nil
+}
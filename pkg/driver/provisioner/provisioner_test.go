@@ -0,0 +1,261 @@
+package provisioner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/provisioner"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+var mountVolumeCapability = &csi.VolumeCapability{
+	AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+	AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+}
+
+type deletePrefixCall struct {
+	region, bucket, prefix string
+}
+
+type fakeObjectStore struct {
+	calls []deletePrefixCall
+	err   error
+}
+
+func (f *fakeObjectStore) DeletePrefix(ctx context.Context, region, bucket, prefix string) error {
+	f.calls = append(f.calls, deletePrefixCall{region, bucket, prefix})
+	return f.err
+}
+
+func TestCreateVolume(t *testing.T) {
+	t.Run("Uses PVC namespace and name as prefix", func(t *testing.T) {
+		p := provisioner.New(&fakeObjectStore{})
+		resp, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			CapacityRange:      &csi.CapacityRange{RequiredBytes: 1024},
+			Parameters: map[string]string{
+				"bucketName":                       "test-bucket",
+				"basePrefix":                       "/tenants/",
+				"deletionPolicy":                   "delete",
+				"region":                           "eu-west-1",
+				"authenticationSource":             "pod",
+				"csi.storage.k8s.io/pvc/name":      "data",
+				"csi.storage.k8s.io/pvc/namespace": "team-a",
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Equals(t, "v2:delete:eu-west-1:test-bucket:tenants/team-a/data/", resp.GetVolume().GetVolumeId())
+		assert.Equals(t, int64(1024), resp.GetVolume().GetCapacityBytes())
+		assert.Equals(t, map[string]string{
+			volumecontext.BucketName:           "test-bucket",
+			volumecontext.Prefix:               "tenants/team-a/data/",
+			volumecontext.Region:               "eu-west-1",
+			volumecontext.AuthenticationSource: "pod",
+		}, resp.GetVolume().GetVolumeContext())
+	})
+
+	t.Run("Falls back to volume name and retain policy", func(t *testing.T) {
+		p := provisioner.New(&fakeObjectStore{})
+		resp, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equals(t, "v2:retain::test-bucket:pvc-1234/", resp.GetVolume().GetVolumeId())
+		assert.Equals(t, "pvc-1234/", resp.GetVolume().GetVolumeContext()[volumecontext.Prefix])
+	})
+
+	t.Run("Is idempotent", func(t *testing.T) {
+		p := provisioner.New(&fakeObjectStore{})
+		req := &csi.CreateVolumeRequest{
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket", "basePrefix": "base"},
+		}
+		first, err := p.CreateVolume(context.Background(), req)
+		assert.NoError(t, err)
+		second, err := p.CreateVolume(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equals(t, first.GetVolume().GetVolumeId(), second.GetVolume().GetVolumeId())
+	})
+
+	for name, req := range map[string]*csi.CreateVolumeRequest{
+		"missing name": {
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket"},
+		},
+		"missing volume capabilities": {
+			Name:       "pvc-1234",
+			Parameters: map[string]string{"bucketName": "test-bucket"},
+		},
+		"block volume": {
+			Name: "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{{
+				AccessType: &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+			}},
+			Parameters: map[string]string{"bucketName": "test-bucket"},
+		},
+		"unsupported access mode": {
+			Name: "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{{
+				AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+			}},
+			Parameters: map[string]string{"bucketName": "test-bucket"},
+		},
+		"missing bucket name": {
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+		},
+		"invalid deletion policy": {
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket", "deletionPolicy": "archive"},
+		},
+		"unknown parameter": {
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket", "cacheSize": "1Gi"},
+		},
+		"invalid parameter": {
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters:         map[string]string{"bucketName": "test-bucket", "authenticationSource": "node"},
+		},
+	} {
+		t.Run("Fails with "+name, func(t *testing.T) {
+			p := provisioner.New(&fakeObjectStore{})
+			_, err := p.CreateVolume(context.Background(), req)
+			assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestDeleteVolume(t *testing.T) {
+	t.Run("Retains objects by default", func(t *testing.T) {
+		store := &fakeObjectStore{}
+		p := provisioner.New(store)
+		_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{
+			VolumeId: "v2:retain::test-bucket:base/ns/pvc/",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(store.calls))
+	})
+
+	t.Run("Deletes objects with delete policy", func(t *testing.T) {
+		store := &fakeObjectStore{}
+		p := provisioner.New(store)
+		_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{
+			VolumeId: "v2:delete:eu-west-1:test-bucket:base/ns/pvc/",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 1, len(store.calls))
+		call := store.calls[0]
+		assert.Equals(t, "eu-west-1", call.region)
+		assert.Equals(t, "test-bucket", call.bucket)
+		assert.Equals(t, "base/ns/pvc/", call.prefix)
+	})
+
+	t.Run("Ignores statically provisioned volumes", func(t *testing.T) {
+		store := &fakeObjectStore{}
+		p := provisioner.New(store)
+		_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "s3-csi-driver-volume"})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(store.calls))
+	})
+
+	t.Run("Fails with missing volume ID", func(t *testing.T) {
+		p := provisioner.New(&fakeObjectStore{})
+		_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{})
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Fails if deleting objects fails", func(t *testing.T) {
+		p := provisioner.New(&fakeObjectStore{err: errors.New("access denied")})
+		_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{
+			VolumeId: "v2:delete::test-bucket:base/ns/pvc/",
+		})
+		assert.Equals(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Deletes objects from a local S3", func(t *testing.T) {
+		s3Server := newFakeS3Server(t, testBucket, []string{
+			"base/team-a/data/file1",
+			"base/team-a/data/dir/file2",
+			"base/team-a/logs/file1",
+		})
+		p := provisioner.New(newTestS3ObjectStore(s3Server))
+
+		resp, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name:               "pvc-1234",
+			VolumeCapabilities: []*csi.VolumeCapability{mountVolumeCapability},
+			Parameters: map[string]string{
+				"bucketName":                       testBucket,
+				"basePrefix":                       "base",
+				"deletionPolicy":                   "delete",
+				"csi.storage.k8s.io/pvc/name":      "data",
+				"csi.storage.k8s.io/pvc/namespace": "team-a",
+			},
+		})
+		assert.NoError(t, err)
+
+		_, err = p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: resp.GetVolume().GetVolumeId()})
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"base/team-a/logs/file1"}, s3Server.keys())
+	})
+}
+
+func TestParseVolumeID(t *testing.T) {
+	for name, id := range map[string]provisioner.VolumeID{
+		"prefix with colons": {
+			DeletionPolicy: provisioner.DeletionPolicyDelete,
+			Region:         "us-east-1",
+			Bucket:         "test-bucket",
+			Prefix:         "base:with:colons/ns/pvc/",
+		},
+		"bucket ARN": {
+			DeletionPolicy: provisioner.DeletionPolicyRetain,
+			Bucket:         "arn:aws:s3:us-east-1:111122223333:accesspoint/test-ap",
+			Prefix:         "ns/pvc%2F/",
+		},
+	} {
+		t.Run("Round-trips "+name, func(t *testing.T) {
+			parsed, err := provisioner.ParseVolumeID(id.String())
+			assert.NoError(t, err)
+			assert.Equals(t, id, parsed)
+		})
+	}
+
+	t.Run("Escapes fields", func(t *testing.T) {
+		id := provisioner.VolumeID{
+			DeletionPolicy: provisioner.DeletionPolicyDelete,
+			Bucket:         "arn:aws:s3:us-east-1:111122223333:accesspoint/test-ap",
+			Prefix:         "a:b%c/",
+		}
+		assert.Equals(t, "v2:delete::arn%3Aaws%3As3%3Aus-east-1%3A111122223333%3Aaccesspoint/test-ap:a%3Ab%25c/", id.String())
+	})
+
+	for _, invalid := range []string{
+		"v2:archive:us-east-1:test-bucket:prefix/",
+		"v2:delete:us-east-1::prefix/",
+		"v2:delete:us-east-1:arn:aws:s3:us-east-1:111122223333:accesspoint/test-ap:prefix/",
+		"v2:delete:us-east-1:test-bucket:prefix%/",
+		"v2:delete:us-east-1:test-bucket:",
+		"test-bucket",
+	} {
+		if _, err := provisioner.ParseVolumeID(invalid); err == nil {
+			t.Errorf("Expected ParseVolumeID(%q) to fail", invalid)
+		}
+	}
+}
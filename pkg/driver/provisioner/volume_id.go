@@ -0,0 +1,88 @@
+package provisioner
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	volumeIDVersion   = "v2"
+	volumeIDSeparator = ":"
+	volumeIDParts     = 5
+)
+
+// volumeIDEscaper escapes the separator in the fields of a volume ID, `%` is escaped as well to make it reversible.
+var volumeIDEscaper = strings.NewReplacer("%", "%25", volumeIDSeparator, "%3A")
+
+// A VolumeID represents the identifier of a dynamically provisioned volume.
+//
+// `DeleteVolume` only receives the volume ID, so everything needed to delete a volume is encoded into it.
+// It's formatted as `v2:<deletionPolicy>:<region>:<bucket>:<prefix>`, `region` might be empty.
+// Each field is escaped, as bucket ARNs (e.g., for access points and Outposts) and prefixes might contain `:`.
+type VolumeID struct {
+	DeletionPolicy DeletionPolicy
+	Region         string
+	Bucket         string
+	Prefix         string
+}
+
+// String returns the encoded form of the volume ID.
+func (v VolumeID) String() string {
+	fields := []string{string(v.DeletionPolicy), v.Region, v.Bucket, v.Prefix}
+	for i, field := range fields {
+		fields[i] = volumeIDEscaper.Replace(field)
+	}
+	return volumeIDVersion + volumeIDSeparator + strings.Join(fields, volumeIDSeparator)
+}
+
+// errNotProvisionedVolumeID is returned when the volume ID was not created by the provisioner,
+// i.e., it belongs to a statically provisioned volume.
+var errNotProvisionedVolumeID = errors.New("volume ID is not created by the provisioner")
+
+// ParseVolumeID parses given encoded volume ID.
+func ParseVolumeID(volumeID string) (VolumeID, error) {
+	parts, err := splitVolumeID(volumeID)
+	if err != nil {
+		return VolumeID{}, err
+	}
+
+	deletionPolicy, err := ParseDeletionPolicy(parts[1])
+	if err != nil {
+		return VolumeID{}, fmt.Errorf("invalid volume ID %q: %w", volumeID, err)
+	}
+
+	id := VolumeID{
+		DeletionPolicy: deletionPolicy,
+		Region:         parts[2],
+		Bucket:         parts[3],
+		Prefix:         parts[4],
+	}
+	if id.Bucket == "" || id.Prefix == "" {
+		return VolumeID{}, fmt.Errorf("invalid volume ID %q: bucket and prefix must be non-empty", volumeID)
+	}
+
+	return id, nil
+}
+
+// splitVolumeID splits given encoded volume ID into its version and unescaped fields.
+func splitVolumeID(volumeID string) ([]string, error) {
+	version, _, _ := strings.Cut(volumeID, volumeIDSeparator)
+	if version != volumeIDVersion {
+		return nil, errNotProvisionedVolumeID
+	}
+
+	parts := strings.Split(volumeID, volumeIDSeparator)
+	if len(parts) != volumeIDParts {
+		return nil, fmt.Errorf("invalid volume ID %q: expected %d parts but got %d", volumeID, volumeIDParts, len(parts))
+	}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume ID %q: %w", volumeID, err)
+		}
+		parts[i] = unescaped
+	}
+	return parts, nil
+}
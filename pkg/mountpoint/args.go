@@ -14,6 +14,8 @@ const (
 	ArgAllowOther      = "--allow-other"
 	ArgAllowRoot       = "--allow-root"
 	ArgRegion          = "--region"
+	ArgEndpointURL     = "--endpoint-url"
+	ArgForcePathStyle  = "--force-path-style"
 	ArgCache           = "--cache"
 	ArgMaxCacheSize    = "--max-cache-size"
 	ArgUserAgentPrefix = "--user-agent-prefix"
@@ -25,6 +27,7 @@ const (
 	ArgDebugCRT        = "--debug-crt"
 	ArgFsTab           = "-o"
 	ArgCABundle        = "--ca-bundle"
+	ArgPrefix          = "--prefix"
 )
 
 // An ArgKey represents the key of an argument.
@@ -142,6 +145,18 @@ func (a *Args) SortedList() []string {
 	return args
 }
 
+// Keys returns ordered list of normalized keys of arguments.
+func (a *Args) Keys() []ArgKey {
+	keys := make([]ArgKey, 0, a.args.Len())
+	for _, arg := range a.args.UnsortedList() {
+		if !slices.Contains(keys, arg.key) {
+			keys = append(keys, arg.key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 // find tries to find given key from [Args], and returns whole entry, and whether the key was found.
 func (a *Args) find(key ArgKey) (arg, bool) {
 	key = normalizeKey(key)
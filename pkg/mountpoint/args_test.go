@@ -570,6 +570,20 @@ func TestQueryingExistenceOfAKeyInMountpointArgs(t *testing.T) {
 	assert.Equals(t, false, args.Has(mountpoint.ArgRegion))
 }
 
+func TestListingKeysOfMountpointArgs(t *testing.T) {
+	args := mountpoint.ParseArgs([]string{
+		"--read-only",
+		"--cache /tmp/s3-cache",
+		"allow-other",
+		"--aws-max-attempts=5",
+	})
+
+	assert.Equals(t, []string{"--allow-other", "--aws-max-attempts", "--cache", "--read-only"}, args.Keys())
+
+	empty := mountpoint.ParseArgs(nil)
+	assert.Equals(t, []string{}, empty.Keys())
+}
+
 func TestCreatingMountpointArgsFromAlreadyParsedArgs(t *testing.T) {
 	args := mountpoint.ParseArgs([]string{
 		"--allow-other",
@@ -114,6 +114,22 @@ func (m *Mounter) CheckMountpoint(target Target) (bool, error) {
 	return false, nil
 }
 
+// ListMountpoints returns paths of all Mountpoint mounts, including bind mounts of Mountpoint mounts.
+func (m *Mounter) ListMountpoints() ([]Target, error) {
+	mountPoints, err := m.mount.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %w", err)
+	}
+
+	var targets []Target
+	for _, mp := range mountPoints {
+		if mp.Device == fsName {
+			targets = append(targets, mp.Path)
+		}
+	}
+	return targets, nil
+}
+
 // FindReferencesToMountpoint returns list of references to Mountpoint at `target`.
 func (m *Mounter) FindReferencesToMountpoint(target Target) ([]string, error) {
 	return m.mount.GetMountRefs(target)
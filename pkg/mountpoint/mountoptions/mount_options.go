@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -31,13 +30,39 @@ type Options struct {
 	VolumeId string `json:"volumeId,omitempty"`
 }
 
-// Send sends given mount `options` to given `sockPath` to be received by `Recv` function on the other end.
+// Send sends given mount `options` to given `sockPath` to be received by [Recv] function on the other end,
+// and waits for the receiver to reply. It returns a [ReplyError] if the receiver replies with an error.
+//
+// The receiver needs to implement [ProtocolVersion], use [SendLegacy] for receivers predating it.
 func Send(ctx context.Context, sockPath string, options Options) error {
 	sockPath = tryToMakeSockPathRelative(sockPath)
 
+	unixConn, err := dialWithRetry(ctx, sockPath)
+	if err != nil {
+		return fmt.Errorf("failed to dial to unix socket %s: %w", sockPath, err)
+	}
+	defer unixConn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := writeMessage(unixConn, &options, options.Fd, deadline); err != nil {
+		return fmt.Errorf("failed to send mount options to unix socket %s: %w", sockPath, err)
+	}
+
+	if err := readReply(unixConn); err != nil {
+		return fmt.Errorf("mount options sent to unix socket %s are not accepted: %w", sockPath, err)
+	}
+
+	return nil
+}
+
+// SendLegacy sends given mount `options` to given `sockPath` using the legacy framing, see [ProtocolVersion].
+// Legacy receivers never reply, so the caller needs to check the outcome by other means.
+func SendLegacy(ctx context.Context, sockPath string, options Options) error {
+	sockPath = tryToMakeSockPathRelative(sockPath)
+
 	message, err := json.Marshal(&options)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message to send %s: %w", sockPath, err)
+		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
 	unixConn, err := dialWithRetry(ctx, sockPath)
@@ -48,20 +73,19 @@ func Send(ctx context.Context, sockPath string, options Options) error {
 
 	// `unixConn.WriteMsgUnix` does not respect `ctx`'s deadline, we need to call `unixConn.SetDeadline` to ensure `unixConn.WriteMsgUnix` has a deadline.
 	if deadline, ok := ctx.Deadline(); ok {
-		err := unixConn.SetDeadline(deadline)
-		if err != nil {
-			return fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
+		if err := unixConn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("failed to set deadline on connection: %w", err)
 		}
 	}
 
 	unixRights := syscall.UnixRights(options.Fd)
 	messageN, unixRightsN, err := unixConn.WriteMsgUnix(message, unixRights, nil)
 	if err != nil {
-		return fmt.Errorf("failed to write to unix socket %s: %w", sockPath, err)
+		return fmt.Errorf("failed to write to connection: %w", err)
 	}
 	if len(message) != messageN || len(unixRights) != unixRightsN {
-		return fmt.Errorf("partial write to unix socket %s: message: size %d - written %d, unix rights: size %d - written %d",
-			sockPath, len(message), messageN, len(unixRights), unixRightsN)
+		return fmt.Errorf("partial write to connection: message: size %d - written %d, unix rights: size %d - written %d",
+			len(message), messageN, len(unixRights), unixRightsN)
 	}
 
 	return nil
@@ -100,20 +124,46 @@ func dialWithRetry(ctx context.Context, sockPath string) (*net.UnixConn, error)
 	return unixConn, err
 }
 
-var (
-	messageRecvSize = 1024
-	// We only pass one file descriptor and it's 32 bits
-	unixRightsRecvSize = syscall.CmsgSpace(4)
-)
+// We only pass one file descriptor and it's 32 bits
+var unixRightsRecvSize = syscall.CmsgSpace(4)
+
+// A Request represents mount options received from a sender, which waits for a reply.
+type Request struct {
+	Options Options
+	conn    *net.UnixConn
+	// legacy is set if the sender predates [ProtocolVersion], and it doesn't wait for a reply.
+	legacy bool
+}
+
+// Legacy returns whether the sender predates [ProtocolVersion]. Legacy senders don't wait for a reply,
+// and they check the outcome by other means (e.g., the error file written if Mountpoint fails).
+func (r *Request) Legacy() bool {
+	return r.legacy
+}
+
+// Reply replies to the sender with the result of processing the mount options and closes the connection.
+// A nil `err` replies [ReplyCodeOK], a [ReplyError] replies with its code, and any other error replies [ReplyCodeFailed].
+// Nothing is replied to legacy senders.
+func (r *Request) Reply(err error) error {
+	defer r.conn.Close()
+	if r.legacy {
+		return nil
+	}
+	if writeErr := writeMessage(r.conn, replyFor(err), -1, time.Now().Add(replyTimeout)); writeErr != nil {
+		return fmt.Errorf("failed to reply to mount options: %w", writeErr)
+	}
+	return nil
+}
 
-// Recv receives passed mount options via `Send` function through given `sockPath`.
-func Recv(ctx context.Context, sockPath string) (Options, error) {
+// Recv receives passed mount options via [Send] function through given `sockPath`.
+// The caller needs to reply to the returned [Request] via [Request.Reply].
+func Recv(ctx context.Context, sockPath string) (*Request, error) {
 	sockPath = tryToMakeSockPathRelative(sockPath)
 
 	var lc net.ListenConfig
 	l, err := lc.Listen(ctx, "unix", sockPath)
 	if err != nil {
-		return Options{}, fmt.Errorf("failed to listen unix socket %s: %w", sockPath, err)
+		return nil, fmt.Errorf("failed to listen unix socket %s: %w", sockPath, err)
 	}
 	defer l.Close()
 
@@ -122,70 +172,75 @@ func Recv(ctx context.Context, sockPath string) (Options, error) {
 		ul := l.(*net.UnixListener)
 		err := ul.SetDeadline(deadline)
 		if err != nil {
-			return Options{}, fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
+			return nil, fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
 		}
 	}
 
 	conn, err := l.Accept()
 	if err != nil {
-		return Options{}, fmt.Errorf("failed to accept connection from unix socket %s: %w", sockPath, err)
+		return nil, fmt.Errorf("failed to accept connection from unix socket %s: %w", sockPath, err)
 	}
 
 	deadline, _ := ctx.Deadline()
-	return RecvOnConn(conn.(*net.UnixConn), deadline)
+	req, err := RecvOnConn(conn.(*net.UnixConn), deadline)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return req, nil
 }
 
 // RecvOnConn receives mount options from an already-accepted connection.
 // If deadline is non-zero, a read deadline is set on the connection.
-func RecvOnConn(conn *net.UnixConn, deadline time.Time) (Options, error) {
+//
+// If the mount options cannot be received (e.g., the sender uses an unsupported protocol version),
+// an error is replied to the sender and returned. Otherwise, the caller needs to reply to the returned [Request] via [Request.Reply].
+func RecvOnConn(conn *net.UnixConn, deadline time.Time) (*Request, error) {
 	if !deadline.IsZero() {
 		if err := conn.SetReadDeadline(deadline); err != nil {
-			return Options{}, fmt.Errorf("failed to set read deadline on connection: %w", err)
+			return nil, fmt.Errorf("failed to set read deadline on connection: %w", err)
 		}
 	}
 
-	messageBuf := make([]byte, 0)
-	unixRightsBuf := make([]byte, 0)
-
-	// Read in a loop to consume the whole message
-	for {
-		message := make([]byte, messageRecvSize)
-		unixRights := make([]byte, unixRightsRecvSize)
-
-		messageN, unixRightsN, _, _, err := conn.ReadMsgUnix(message, unixRights)
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			closeFds(unixRightsBuf)
-			return Options{}, fmt.Errorf("failed to read message from connection: %w", err)
+	payload, unixRightsBuf, legacy, err := readFrame(conn, true)
+	req := &Request{conn: conn, legacy: legacy}
+	if err == nil {
+		if unmarshalErr := json.Unmarshal(payload, &req.Options); unmarshalErr != nil {
+			err = NewReplyError(ReplyCodeInvalidOptions, "failed to decode message: %v", unmarshalErr)
 		}
-
-		messageBuf = append(messageBuf, message[:messageN]...)
-		unixRightsBuf = append(unixRightsBuf, unixRights[:unixRightsN]...)
 	}
-
-	var options Options
-	err := json.Unmarshal(messageBuf, &options)
 	if err != nil {
 		closeFds(unixRightsBuf)
-		return Options{}, fmt.Errorf("failed to decode mount options from connection: %w", err)
+		return nil, req.replyRecvError(fmt.Errorf("failed to receive mount options from connection: %w", err))
 	}
 
 	fds, err := parseUnixRights(unixRightsBuf)
 	if err != nil {
-		return Options{}, fmt.Errorf("failed to decode unix rights from connection: %w", err)
+		return nil, req.replyRecvError(fmt.Errorf("failed to decode unix rights from connection: %w", err))
 	}
 
 	if len(fds) != 1 {
 		for _, fd := range fds {
 			syscall.Close(fd)
 		}
-		return Options{}, fmt.Errorf("expected one file descriptor from connection, but got %d", len(fds))
+		return nil, req.replyRecvError(NewReplyError(ReplyCodeInvalidOptions, "expected one file descriptor from connection, but got %d", len(fds)))
 	}
 
-	options.Fd = fds[0]
-	return options, nil
+	req.Options.Fd = fds[0]
+	return req, nil
+}
+
+// replyRecvError replies `err` to the sender of `r` on a best-effort basis, and returns `err`.
+func (r *Request) replyRecvError(err error) error {
+	var replyErr *ReplyError
+	if r.legacy || !errors.As(err, &replyErr) {
+		// Only reply errors caused by the sender, the connection is likely unusable otherwise
+		return err
+	}
+	if writeErr := writeMessage(r.conn, replyFor(err), -1, time.Now().Add(replyTimeout)); writeErr != nil {
+		klog.V(4).Infof("Failed to reply error to the sender: %v", writeErr)
+	}
+	return err
 }
 
 // closeFds attempts to parse and close any file descriptors in the raw unix rights buffer.
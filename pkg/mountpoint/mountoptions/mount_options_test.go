@@ -2,8 +2,10 @@ package mountoptions_test
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -59,9 +61,10 @@ func testRoundtrip(t *testing.T, mountSock string) {
 
 	c := make(chan mountoptions.Options)
 	go func() {
-		mountOptions, err := mountoptions.Recv(defaultContext(t), mountSock)
+		req, err := mountoptions.Recv(defaultContext(t), mountSock)
 		assert.NoError(t, err)
-		c <- mountOptions
+		assert.NoError(t, req.Reply(nil))
+		c <- req.Options
 	}()
 
 	want := mountoptions.Options{
@@ -126,7 +129,7 @@ func testRecvClosesFdsOnError(t *testing.T, message []byte, fdCount int) {
 		fds[i] = int(f.Fd())
 	}
 	unixRights := syscall.UnixRights(fds...)
-	_, _, err = client.WriteMsgUnix(message, unixRights, nil)
+	_, _, err = client.WriteMsgUnix(frame(mountoptions.ProtocolVersion, message), unixRights, nil)
 	assert.NoError(t, err)
 	client.Close()
 
@@ -162,6 +165,263 @@ func TestRecvOnConnRespectsDeadline(t *testing.T) {
 	}
 }
 
+func TestSendLargeMountOptions(t *testing.T) {
+	basePath := t.TempDir()
+	t.Chdir(basePath)
+	mountSock := filepath.Join(basePath, "m")
+
+	file, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	want := mountoptions.Options{
+		Fd:         int(file.Fd()),
+		BucketName: "test-bucket",
+		Args:       []string{"--allow-delete"},
+		Env:        []string{"HTTPS_PROXY=" + strings.Repeat("p", 1<<20)},
+	}
+
+	c := make(chan mountoptions.Options)
+	go func() {
+		req, err := mountoptions.Recv(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		assert.NoError(t, req.Reply(nil))
+		c <- req.Options
+	}()
+
+	err = mountoptions.Send(defaultContext(t), mountSock, want)
+	assert.NoError(t, err)
+
+	got := <-c
+	defer syscall.Close(got.Fd)
+	got.Fd = 0
+	want.Fd = 0
+	assert.Equals(t, want, got)
+}
+
+func TestSendReturnsReplyError(t *testing.T) {
+	basePath := t.TempDir()
+	t.Chdir(basePath)
+	mountSock := filepath.Join(basePath, "m")
+
+	file, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	go func() {
+		req, err := mountoptions.Recv(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		syscall.Close(req.Options.Fd)
+		assert.NoError(t, req.Reply(mountoptions.NewReplyError(mountoptions.ReplyCodeInvalidOptions, "invalid mount id %q", "../")))
+	}()
+
+	err = mountoptions.Send(defaultContext(t), mountSock, mountoptions.Options{Fd: int(file.Fd()), BucketName: "test-bucket"})
+	var replyErr *mountoptions.ReplyError
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("expected a reply error, got: %v", err)
+	}
+	assert.Equals(t, mountoptions.ReplyError{Code: mountoptions.ReplyCodeInvalidOptions, Message: `invalid mount id "../"`}, *replyErr)
+}
+
+func TestRecvOnConnRejectsUnsupportedVersion(t *testing.T) {
+	server, client, err := unixSocketPair(t)
+	assert.NoError(t, err)
+	defer server.Close()
+	defer client.Close()
+
+	file, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	message := mustMarshal(t, mountoptions.Options{BucketName: "b"})
+	_, _, err = client.WriteMsgUnix(frame(mountoptions.ProtocolVersion+1, message), syscall.UnixRights(int(file.Fd())), nil)
+	assert.NoError(t, err)
+
+	_, err = mountoptions.RecvOnConn(server, time.Now().Add(5*time.Second))
+	var replyErr *mountoptions.ReplyError
+	if !errors.As(err, &replyErr) || replyErr.Code != mountoptions.ReplyCodeUnsupportedVersion {
+		t.Fatalf("expected an unsupported version error, got: %v", err)
+	}
+
+	// The sender should be notified with the same error
+	header := make([]byte, 8)
+	_, err = io.ReadFull(client, header)
+	assert.NoError(t, err)
+	assert.Equals(t, mountoptions.ProtocolVersion, binary.BigEndian.Uint32(header[0:4]))
+	payload := make([]byte, binary.BigEndian.Uint32(header[4:8]))
+	_, err = io.ReadFull(client, payload)
+	assert.NoError(t, err)
+
+	var reply mountoptions.Reply
+	assert.NoError(t, json.Unmarshal(payload, &reply))
+	assert.Equals(t, mountoptions.ReplyCodeUnsupportedVersion, reply.Code)
+}
+
+func TestSendLegacyToLegacyReceivers(t *testing.T) {
+	basePath := t.TempDir()
+	t.Chdir(basePath)
+	mountSock := filepath.Join(basePath, "m")
+
+	file, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	l, err := net.Listen("unix", mountSock)
+	assert.NoError(t, err)
+	defer l.Close()
+
+	// Emulate a legacy receiver, which reads until the sender closes the connection,
+	// decodes the message as bare JSON and closes the connection without a reply
+	c := make(chan mountoptions.Options)
+	go func() {
+		conn, err := l.Accept()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var message []byte
+		for {
+			buf := make([]byte, 1024)
+			unixRights := make([]byte, syscall.CmsgSpace(4))
+			n, unixRightsN, _, _, err := conn.(*net.UnixConn).ReadMsgUnix(buf, unixRights)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			assert.NoError(t, err)
+			message = append(message, buf[:n]...)
+			if unixRightsN > 0 {
+				msgs, err := syscall.ParseSocketControlMessage(unixRights[:unixRightsN])
+				assert.NoError(t, err)
+				fds, err := syscall.ParseUnixRights(&msgs[0])
+				assert.NoError(t, err)
+				syscall.Close(fds[0])
+			}
+		}
+
+		var options mountoptions.Options
+		assert.NoError(t, json.Unmarshal(message, &options))
+		c <- options
+	}()
+
+	want := mountoptions.Options{
+		Fd:         int(file.Fd()),
+		BucketName: "test-bucket",
+		Args:       []string{"--allow-delete"},
+		Env:        []string{"TEST_ENV=testing"},
+	}
+	err = mountoptions.SendLegacy(defaultContext(t), mountSock, want)
+	assert.NoError(t, err)
+
+	want.Fd = 0
+	assert.Equals(t, want, <-c)
+}
+
+func TestAdvertisedProtocolVersion(t *testing.T) {
+	t.Run("Receivers listening via Listen advertise their version", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		l, err := mountoptions.Listen(mountSock)
+		assert.NoError(t, err)
+		defer l.Close()
+
+		version, err := mountoptions.AdvertisedProtocolVersion(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		assert.Equals(t, mountoptions.ProtocolVersion, version)
+
+		file, err := os.Open(os.DevNull)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		c := make(chan mountoptions.Options)
+		go func() {
+			conn, err := l.Accept()
+			assert.NoError(t, err)
+			req, err := mountoptions.RecvOnConn(conn.(*net.UnixConn), time.Now().Add(defaultTimeout))
+			assert.NoError(t, err)
+			syscall.Close(req.Options.Fd)
+			assert.NoError(t, req.Reply(nil))
+			c <- req.Options
+		}()
+
+		err = mountoptions.Send(defaultContext(t), mountSock, mountoptions.Options{Fd: int(file.Fd()), BucketName: "test-bucket"})
+		assert.NoError(t, err)
+		assert.Equals(t, "test-bucket", (<-c).BucketName)
+	})
+
+	t.Run("Receivers not advertising a version are legacy", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		l, err := net.Listen("unix", mountSock)
+		assert.NoError(t, err)
+		defer l.Close()
+
+		version, err := mountoptions.AdvertisedProtocolVersion(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		assert.Equals(t, uint32(0), version)
+	})
+
+	t.Run("Socket re-created by a legacy receiver after the version is advertised", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		l, err := mountoptions.Listen(mountSock)
+		assert.NoError(t, err)
+		l.Close()
+
+		// Legacy receivers remove the Unix socket and listen again, but leave the advertised version behind
+		advertisedAt := time.Now().Add(-time.Minute)
+		assert.NoError(t, os.Chtimes(mountSock+".version", advertisedAt, advertisedAt))
+		assert.NoError(t, os.Remove(mountSock))
+		l, err = net.Listen("unix", mountSock)
+		assert.NoError(t, err)
+		defer l.Close()
+
+		version, err := mountoptions.AdvertisedProtocolVersion(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		assert.Equals(t, uint32(0), version)
+	})
+
+	t.Run("Fails if the socket is not created in time", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := mountoptions.AdvertisedProtocolVersion(ctx, mountSock)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestRecvOnConnAcceptsLegacySenders(t *testing.T) {
+	server, client, err := unixSocketPair(t)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	file, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	// Emulate a legacy sender, which writes bare JSON and closes the connection without waiting for a reply
+	want := mountoptions.Options{BucketName: "test-bucket", Args: []string{"--allow-delete"}}
+	_, _, err = client.WriteMsgUnix(mustMarshal(t, want), syscall.UnixRights(int(file.Fd())), nil)
+	assert.NoError(t, err)
+	client.Close()
+
+	req, err := mountoptions.RecvOnConn(server, time.Now().Add(5*time.Second))
+	assert.NoError(t, err)
+	defer syscall.Close(req.Options.Fd)
+	assert.Equals(t, true, req.Legacy())
+	assert.NoError(t, req.Reply(nil))
+
+	got := req.Options
+	got.Fd = 0
+	assert.Equals(t, want, got)
+}
+
+// frame frames `payload` with given protocol `version`.
+func frame(version uint32, payload []byte) []byte {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:4], version)
+	binary.BigEndian.PutUint32(header[4:8], uint32(len(payload)))
+	return append(header, payload...)
+}
+
 func unixSocketPair(t *testing.T) (*net.UnixConn, *net.UnixConn, error) {
 	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
 	if err != nil {
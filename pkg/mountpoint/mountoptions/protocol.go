@@ -0,0 +1,302 @@
+package mountoptions
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Each message exchanged over the Unix socket is framed with a fixed-size header followed by a JSON payload:
+//
+//	+--------------------+---------------------------+-----------------+
+//	| version (uint32)   | payload length (uint32)   | payload (JSON)  |
+//	+--------------------+---------------------------+-----------------+
+//
+// Integers are big-endian. The FUSE file descriptor is passed alongside the mount options using `SCM_RIGHTS`.
+// Once the receiver processes the mount options, it sends a [Reply] back to the sender using the same framing.
+//
+// Senders and receivers predating [ProtocolVersion] use the legacy framing, where the sender writes a bare JSON payload
+// and closes the connection without waiting for a reply. Receivers accept both framings, but legacy receivers
+// fail on framed messages, so senders need to know the version of the receiver before sending anything.
+// Mountpoint Pods record their version in an annotation, and the mounter DaemonSet advertises its version
+// next to its Unix socket, see [Listen]. Senders use [SendLegacy] if the receiver doesn't record any version.
+
+// ProtocolVersion is the version of the mount options protocol implemented by this package.
+// Receivers reject messages with a different version by replying [ReplyCodeUnsupportedVersion].
+const ProtocolVersion uint32 = 1
+
+// versionFileSuffix is appended to the path of a Unix socket to get the path of the file advertising
+// the protocol version of the receiver listening on it, see [Listen].
+const versionFileSuffix = ".version"
+
+// headerSize is the size of the header preceding each payload.
+const headerSize = 8
+
+// maxReadChunkSize is the maximum number of bytes read from the connection at once.
+// Larger payloads are read in multiple chunks, so there is no limit on the payload size.
+const maxReadChunkSize = 64 * 1024
+
+// maxLegacyMessageSize is the maximum size of a message received with the legacy framing.
+const maxLegacyMessageSize = 1024 * 1024
+
+// replyTimeout is the timeout for sending a reply to the sender.
+const replyTimeout = 10 * time.Second
+
+// A ReplyCode represents the result of processing mount options on the receiving end.
+type ReplyCode string
+
+const (
+	// ReplyCodeOK is replied if the receiver successfully started Mountpoint with the mount options.
+	ReplyCodeOK ReplyCode = "OK"
+	// ReplyCodeUnsupportedVersion is replied if the receiver does not support the protocol version of the sender.
+	ReplyCodeUnsupportedVersion ReplyCode = "UnsupportedVersion"
+	// ReplyCodeInvalidOptions is replied if the mount options are malformed or rejected by the receiver.
+	ReplyCodeInvalidOptions ReplyCode = "InvalidOptions"
+	// ReplyCodeFailed is replied if the receiver failed to start Mountpoint with the mount options.
+	ReplyCodeFailed ReplyCode = "Failed"
+)
+
+// A Reply is sent back to the sender after processing mount options.
+type Reply struct {
+	Code    ReplyCode `json:"code"`
+	Message string    `json:"message,omitempty"`
+}
+
+// A ReplyError is returned from [Send] if the receiver replies with an error,
+// and can be passed to [Request.Reply] to reply with a specific [ReplyCode].
+type ReplyError struct {
+	Code    ReplyCode
+	Message string
+}
+
+// NewReplyError returns a new [ReplyError] with given `code` and formatted message.
+func NewReplyError(code ReplyCode, format string, args ...any) *ReplyError {
+	return &ReplyError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// replyFor returns the [Reply] to send for given processing result `err`.
+func replyFor(err error) Reply {
+	if err == nil {
+		return Reply{Code: ReplyCodeOK}
+	}
+
+	var replyErr *ReplyError
+	if errors.As(err, &replyErr) {
+		return Reply{Code: replyErr.Code, Message: replyErr.Message}
+	}
+	return Reply{Code: ReplyCodeFailed, Message: err.Error()}
+}
+
+// writeMessage marshals `v` and writes it as a single frame to `conn`, passing `fd` (using `SCM_RIGHTS`) if it's non-negative.
+func writeMessage(conn *net.UnixConn, v any, fd int, deadline time.Time) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	// `conn.WriteMsgUnix` does not respect `ctx`'s deadline, we need to call `conn.SetDeadline` to ensure `conn.WriteMsgUnix` has a deadline.
+	if !deadline.IsZero() {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("failed to set deadline on connection: %w", err)
+		}
+	}
+
+	frame := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], ProtocolVersion)
+	binary.BigEndian.PutUint32(frame[4:8], uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	var unixRights []byte
+	if fd >= 0 {
+		unixRights = syscall.UnixRights(fd)
+	}
+
+	frameN, unixRightsN, err := conn.WriteMsgUnix(frame, unixRights, nil)
+	if err != nil {
+		return fmt.Errorf("failed to write to connection: %w", err)
+	}
+	if len(unixRights) != unixRightsN {
+		return fmt.Errorf("partial write to connection: unix rights: size %d - written %d", len(unixRights), unixRightsN)
+	}
+	if frameN < len(frame) {
+		// Large frames might not fit into the socket buffer at once, write the rest without unix rights
+		if _, err := conn.Write(frame[frameN:]); err != nil {
+			return fmt.Errorf("failed to write to connection: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readMessage reads a single frame from `conn` and unmarshals its payload into `v`.
+// It returns the raw unix rights received alongside the frame, even on errors, so the caller can close any received file descriptors.
+func readMessage(conn *net.UnixConn, v any) ([]byte, error) {
+	payload, unixRightsBuf, _, err := readFrame(conn, false)
+	if err != nil {
+		return unixRightsBuf, err
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return unixRightsBuf, NewReplyError(ReplyCodeInvalidOptions, "failed to decode message: %v", err)
+	}
+
+	return unixRightsBuf, nil
+}
+
+// readFrame reads a single frame from `conn` and returns its payload alongside the raw unix rights received with it.
+// The raw unix rights are returned even on errors, so the caller can close any received file descriptors.
+//
+// If `allowLegacy` is set, messages sent by senders predating [ProtocolVersion] are also accepted and reported as legacy.
+// Legacy senders write a bare JSON payload and close the connection, so the payload is read until EOF.
+func readFrame(conn *net.UnixConn, allowLegacy bool) ([]byte, []byte, bool, error) {
+	var unixRightsBuf []byte
+	read := func(buf []byte) (int, error) {
+		unixRights := make([]byte, unixRightsRecvSize)
+		bufN, unixRightsN, _, _, err := conn.ReadMsgUnix(buf, unixRights)
+		unixRightsBuf = append(unixRightsBuf, unixRights[:unixRightsN]...)
+		return bufN, err
+	}
+	readFull := func(buf []byte) error {
+		for n := 0; n < len(buf); {
+			bufN, err := read(buf[n:])
+			n += bufN
+			if err != nil {
+				if errors.Is(err, io.EOF) && n > 0 {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	header := make([]byte, headerSize)
+	if err := readFull(header); err != nil {
+		return nil, unixRightsBuf, false, fmt.Errorf("failed to read message header from connection: %w", err)
+	}
+
+	if allowLegacy && header[0] == '{' {
+		payload := header
+		for {
+			chunk := make([]byte, maxReadChunkSize)
+			n, err := read(chunk)
+			payload = append(payload, chunk[:n]...)
+			if errors.Is(err, io.EOF) {
+				return payload, unixRightsBuf, true, nil
+			}
+			if err != nil {
+				return nil, unixRightsBuf, true, fmt.Errorf("failed to read legacy message from connection: %w", err)
+			}
+			if len(payload) > maxLegacyMessageSize {
+				return nil, unixRightsBuf, true, NewReplyError(ReplyCodeInvalidOptions, "legacy message is larger than %d bytes", maxLegacyMessageSize)
+			}
+		}
+	}
+
+	if version := binary.BigEndian.Uint32(header[0:4]); version != ProtocolVersion {
+		return nil, unixRightsBuf, false, NewReplyError(ReplyCodeUnsupportedVersion, "unsupported protocol version %d, expected %d", version, ProtocolVersion)
+	}
+
+	// Read the payload in chunks rather than allocating the advertised length upfront
+	length := int(binary.BigEndian.Uint32(header[4:8]))
+	payload := make([]byte, 0, min(length, maxReadChunkSize))
+	for len(payload) < length {
+		chunk := make([]byte, min(length-len(payload), maxReadChunkSize))
+		if err := readFull(chunk); err != nil {
+			return nil, unixRightsBuf, false, fmt.Errorf("failed to read message from connection: %w", err)
+		}
+		payload = append(payload, chunk...)
+	}
+
+	return payload, unixRightsBuf, false, nil
+}
+
+// readReply reads a [Reply] from `conn` and returns a [ReplyError] if the receiver replied with an error.
+func readReply(conn *net.UnixConn) error {
+	var reply Reply
+	if _, err := readMessage(conn, &reply); err != nil {
+		return fmt.Errorf("failed to read reply: %w", err)
+	}
+
+	if reply.Code != ReplyCodeOK {
+		return &ReplyError{Code: reply.Code, Message: reply.Message}
+	}
+	return nil
+}
+
+// Listen listens on Unix socket `sockPath` for mount options to receive via [RecvOnConn], and advertises [ProtocolVersion]
+// to senders by writing it to a file next to the Unix socket. The Unix socket is bound on a temporary path and only moved to `sockPath`
+// once the version is advertised, so senders never observe the Unix socket without an advertised version.
+func Listen(sockPath string) (net.Listener, error) {
+	sockPath = tryToMakeSockPathRelative(sockPath)
+	tmpSockPath := sockPath + ".tmp"
+	_ = os.Remove(tmpSockPath)
+	l, err := net.Listen("unix", tmpSockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen unix socket %s: %w", tmpSockPath, err)
+	}
+
+	versionPath := sockPath + versionFileSuffix
+	if err := os.WriteFile(versionPath, []byte(strconv.FormatUint(uint64(ProtocolVersion), 10)), 0644); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to advertise protocol version on %s: %w", versionPath, err)
+	}
+
+	if err := os.Rename(tmpSockPath, sockPath); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to move unix socket %s to %s: %w", tmpSockPath, sockPath, err)
+	}
+	// The listener would otherwise try to remove the temporary path on close
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	return l, nil
+}
+
+// AdvertisedProtocolVersion waits until Unix socket `sockPath` is created, and returns the protocol version advertised
+// by the receiver listening on it via [Listen]. It returns 0 if the receiver predates [ProtocolVersion], i.e., if there is no advertised version,
+// or if the Unix socket is re-created after the version is advertised (e.g., by a legacy receiver after a downgrade).
+func AdvertisedProtocolVersion(ctx context.Context, sockPath string) (uint32, error) {
+	var sockStat os.FileInfo
+	err := wait.PollUntilContextCancel(ctx, unixSocketDialRetryInterval, true, func(ctx context.Context) (bool, error) {
+		var err error
+		sockStat, err = os.Stat(sockPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to wait for unix socket %s: %w", sockPath, err)
+	}
+
+	versionPath := sockPath + versionFileSuffix
+	versionStat, err := os.Stat(versionPath)
+	if err != nil || versionStat.ModTime().Before(sockStat.ModTime()) {
+		return 0, nil
+	}
+
+	content, err := os.ReadFile(versionPath)
+	if err != nil {
+		return 0, nil
+	}
+	version, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 32)
+	if err != nil {
+		return 0, nil
+	}
+	return uint32(version), nil
+}
@@ -0,0 +1,147 @@
+// Package mountpolicy enforces MountpointS3MountPolicies on mount options of volumes used by workloads.
+package mountpolicy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// A Workload identifies the workload Pod a volume is mounted for.
+type Workload struct {
+	Namespace string
+	Name      string
+	// Labels of the workload Pod. They're fetched using `Name` if nil and a policy selects Pods by their labels.
+	Labels map[string]string
+}
+
+// An Enforcer enforces MountpointS3MountPolicies.
+type Enforcer struct {
+	reader client.Reader
+}
+
+// New returns a new [Enforcer] using `reader` to read policies, namespaces and workload Pods.
+func New(reader client.Reader) *Enforcer {
+	return &Enforcer{reader: reader}
+}
+
+// Apply applies all policies matching `workload` to `args` in order of their names.
+// Default mount options of matching policies are merged first, options set by the volume and earlier policies take precedence.
+// Then it returns a [crdv2.MountPolicyViolationError] if the resulting `args` has a denied
+// or is missing a required mount option of any of the matching policies.
+func (e *Enforcer) Apply(ctx context.Context, workload Workload, args *mountpoint.Args) error {
+	policies, err := e.matchingPolicies(ctx, workload)
+	if err != nil {
+		return err
+	}
+
+	for i := range policies {
+		policies[i].ApplyDefaults(args)
+	}
+
+	for i := range policies {
+		if err := policies[i].CheckDenied(args); err != nil {
+			return err
+		}
+		if err := policies[i].CheckRequired(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckDenied returns a [crdv2.MountPolicyViolationError] if `args` has a denied mount option of any of the policies matching `workload`.
+//
+// Unlike [Enforcer.Apply], it does not check required mount options, as some of them (e.g., `--read-only`)
+// are only added by the CSI Driver Node Pod while mounting the volume.
+func (e *Enforcer) CheckDenied(ctx context.Context, workload Workload, args *mountpoint.Args) error {
+	policies, err := e.matchingPolicies(ctx, workload)
+	if err != nil {
+		return err
+	}
+
+	for i := range policies {
+		if err := policies[i].CheckDenied(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PoliciesKey returns a key identifying the policies matching `workload` and their generations, or an empty string if none matches.
+// Workloads with the same key have the same policies enforced on their mount options.
+func (e *Enforcer) PoliciesKey(ctx context.Context, workload Workload) (string, error) {
+	policies, err := e.matchingPolicies(ctx, workload)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		keys = append(keys, fmt.Sprintf("%s/%d", policy.Name, policy.Generation))
+	}
+	return strings.Join(keys, ","), nil
+}
+
+// matchingPolicies returns policies matching `workload` in order of their names.
+// Labels of the namespace and the workload Pod are only fetched if any of the policies needs them.
+func (e *Enforcer) matchingPolicies(ctx context.Context, workload Workload) ([]crdv2.MountpointS3MountPolicy, error) {
+	policyList := &crdv2.MountpointS3MountPolicyList{}
+	if err := e.reader.List(ctx, policyList); err != nil {
+		if meta.IsNoMatchError(err) {
+			// MountpointS3MountPolicy CRD is not installed, nothing to enforce
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list MountpointS3MountPolicies: %w", err)
+	}
+
+	var namespaceLabels map[string]string
+	podLabels := workload.Labels
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		if policy.HasNamespaceSelector() && namespaceLabels == nil {
+			namespace := &corev1.Namespace{}
+			if err := e.reader.Get(ctx, client.ObjectKey{Name: workload.Namespace}, namespace); err != nil {
+				return nil, fmt.Errorf("failed to get namespace %q: %w", workload.Namespace, err)
+			}
+			namespaceLabels = namespace.Labels
+			if namespaceLabels == nil {
+				namespaceLabels = map[string]string{}
+			}
+		}
+		if policy.HasPodSelector() && podLabels == nil {
+			pod := &corev1.Pod{}
+			if err := e.reader.Get(ctx, client.ObjectKey{Namespace: workload.Namespace, Name: workload.Name}, pod); err != nil {
+				return nil, fmt.Errorf("failed to get workload Pod %s/%s: %w", workload.Namespace, workload.Name, err)
+			}
+			podLabels = pod.Labels
+			if podLabels == nil {
+				podLabels = map[string]string{}
+			}
+		}
+	}
+
+	var policies []crdv2.MountpointS3MountPolicy
+	for _, policy := range policyList.Items {
+		matches, err := policy.Matches(namespaceLabels, podLabels)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			policies = append(policies, policy)
+		}
+	}
+
+	slices.SortFunc(policies, func(a, b crdv2.MountpointS3MountPolicy) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return policies, nil
+}
@@ -0,0 +1,181 @@
+package mountpolicy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/awslabs/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountpolicy"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+var testWorkload = mountpolicy.Workload{Namespace: "team-a", Name: "workload"}
+
+func TestApplyingMountPolicies(t *testing.T) {
+	t.Run("Merges default mount options without overriding existing ones", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("b-defaults", crdv2.MountpointS3MountPolicySpec{DefaultMountOptions: []string{"aws-max-attempts 10", "read-part-size=8388608"}}),
+			newPolicy("a-defaults", crdv2.MountpointS3MountPolicySpec{DefaultMountOptions: []string{"--aws-max-attempts=5"}}),
+		)
+
+		args := mountpoint.ParseArgs([]string{"--read-only", "--read-part-size=1048576"})
+		assert.NoError(t, enforcer.Apply(context.Background(), testWorkload, &args))
+		assert.Equals(t, []string{"--aws-max-attempts=5", "--read-only", "--read-part-size=1048576"}, args.SortedList())
+	})
+
+	t.Run("Rejects denied mount options", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("no-writes", crdv2.MountpointS3MountPolicySpec{DeniedMountOptions: []string{"allow-delete", "--allow-overwrite"}}),
+		)
+
+		args := mountpoint.ParseArgs([]string{"--allow-delete", "--debug"})
+		err := enforcer.Apply(context.Background(), testWorkload, &args)
+		assertViolation(t, err, "no-writes", "denied mount options: --allow-delete")
+	})
+
+	t.Run("Rejects denied mount options added as defaults of another policy", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("debug", crdv2.MountpointS3MountPolicySpec{DefaultMountOptions: []string{"--debug-crt"}}),
+			newPolicy("no-debug", crdv2.MountpointS3MountPolicySpec{DeniedMountOptions: []string{"--debug-crt"}}),
+		)
+
+		args := mountpoint.ParseArgs(nil)
+		err := enforcer.Apply(context.Background(), testWorkload, &args)
+		assertViolation(t, err, "no-debug", "denied mount options: --debug-crt")
+	})
+
+	t.Run("Rejects missing required mount options", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("read-only", crdv2.MountpointS3MountPolicySpec{RequiredMountOptions: []string{"read-only"}}),
+		)
+
+		args := mountpoint.ParseArgs([]string{"--allow-other"})
+		err := enforcer.Apply(context.Background(), testWorkload, &args)
+		assertViolation(t, err, "read-only", "missing required mount options: --read-only")
+
+		args = mountpoint.ParseArgs([]string{"--read-only"})
+		assert.NoError(t, enforcer.Apply(context.Background(), testWorkload, &args))
+	})
+
+	t.Run("Only applies policies matching the namespace", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("restricted", crdv2.MountpointS3MountPolicySpec{
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"restricted": "true"}},
+				DeniedMountOptions: []string{"--allow-delete"},
+			}),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"restricted": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		)
+
+		args := mountpoint.ParseArgs([]string{"--allow-delete"})
+		err := enforcer.Apply(context.Background(), testWorkload, &args)
+		assertViolation(t, err, "restricted", "denied mount options: --allow-delete")
+
+		err = enforcer.Apply(context.Background(), mountpolicy.Workload{Namespace: "team-b", Name: "workload"}, &args)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Only applies policies matching the workload Pod", func(t *testing.T) {
+		enforcer := newEnforcer(
+			newPolicy("untrusted", crdv2.MountpointS3MountPolicySpec{
+				PodSelector:        &metav1.LabelSelector{MatchLabels: map[string]string{"trusted": "false"}},
+				DeniedMountOptions: []string{"--allow-delete"},
+			}),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "workload", Labels: map[string]string{"trusted": "false"}}},
+		)
+
+		args := mountpoint.ParseArgs([]string{"--allow-delete"})
+		err := enforcer.Apply(context.Background(), testWorkload, &args)
+		assertViolation(t, err, "untrusted", "denied mount options: --allow-delete")
+
+		err = enforcer.Apply(context.Background(), mountpolicy.Workload{
+			Namespace: "team-a",
+			Name:      "workload",
+			Labels:    map[string]string{"trusted": "true"},
+		}, &args)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Does nothing without policies", func(t *testing.T) {
+		enforcer := newEnforcer()
+
+		args := mountpoint.ParseArgs([]string{"--allow-delete"})
+		assert.NoError(t, enforcer.Apply(context.Background(), testWorkload, &args))
+		assert.Equals(t, []string{"--allow-delete"}, args.SortedList())
+	})
+}
+
+func TestCheckingDeniedMountOptions(t *testing.T) {
+	enforcer := newEnforcer(
+		newPolicy("strict", crdv2.MountpointS3MountPolicySpec{
+			DefaultMountOptions:  []string{"--debug"},
+			DeniedMountOptions:   []string{"--allow-delete"},
+			RequiredMountOptions: []string{"--read-only"},
+		}),
+	)
+
+	args := mountpoint.ParseArgs([]string{"--allow-other"})
+	assert.NoError(t, enforcer.CheckDenied(context.Background(), testWorkload, &args))
+	assert.Equals(t, []string{"--allow-other"}, args.SortedList())
+
+	args = mountpoint.ParseArgs([]string{"--allow-delete"})
+	err := enforcer.CheckDenied(context.Background(), testWorkload, &args)
+	assertViolation(t, err, "strict", "denied mount options: --allow-delete")
+}
+
+func newEnforcer(objs ...client.Object) *mountpolicy.Enforcer {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(crdv2.AddToScheme(scheme))
+	return mountpolicy.New(ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+}
+
+func newPolicy(name string, spec crdv2.MountpointS3MountPolicySpec) *crdv2.MountpointS3MountPolicy {
+	return &crdv2.MountpointS3MountPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func assertViolation(t *testing.T, err error, policy, reason string) {
+	t.Helper()
+	var violation *crdv2.MountPolicyViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("Expected a MountPolicyViolationError, got %v", err)
+	}
+	assert.Equals(t, policy, violation.Policy)
+	assert.Equals(t, reason, violation.Reason)
+}
+
+func TestPoliciesKey(t *testing.T) {
+	restricted := newPolicy("restricted", crdv2.MountpointS3MountPolicySpec{
+		NamespaceSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"restricted": "true"}},
+		RequiredMountOptions: []string{"--read-only"},
+	})
+	restricted.Generation = 2
+	enforcer := newEnforcer(
+		newPolicy("defaults", crdv2.MountpointS3MountPolicySpec{DefaultMountOptions: []string{"--debug"}}),
+		restricted,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"restricted": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+	)
+
+	key, err := enforcer.PoliciesKey(context.Background(), testWorkload)
+	assert.NoError(t, err)
+	assert.Equals(t, "defaults/0,restricted/2", key)
+
+	key, err = enforcer.PoliciesKey(context.Background(), mountpolicy.Workload{Namespace: "team-b", Name: "workload"})
+	assert.NoError(t, err)
+	assert.Equals(t, "defaults/0", key)
+
+	key, err = newEnforcer().PoliciesKey(context.Background(), testWorkload)
+	assert.NoError(t, err)
+	assert.Equals(t, "", key)
+}
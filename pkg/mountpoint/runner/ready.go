@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultFUSEInitTimeout is the default timeout for Mountpoint to read the FUSE INIT request in [WaitForFUSEInit].
+const DefaultFUSEInitTimeout = 1 * time.Minute
+
+// ErrFUSEInitTimeout is returned from [WaitForFUSEInit] if Mountpoint doesn't read the FUSE INIT request in time.
+var ErrFUSEInitTimeout = errors.New("runner: FUSE INIT request is not read in time")
+
+// fuseInitPollInterval is the maximum interval to check whether the FUSE INIT request is read in [WaitForFUSEInit].
+const fuseInitPollInterval = 10 * time.Millisecond
+
+// WaitForFUSEInit waits until Mountpoint reads the FUSE INIT request from FUSE file descriptor `fd`,
+// which means Mountpoint passed its startup checks and started serving the filesystem.
+// It returns an error if the FUSE connection is aborted, `ctx` is cancelled, or the FUSE INIT request isn't read within `timeout`.
+//
+// The kernel queues the FUSE INIT request while mounting and holds any other request until it's replied,
+// so `fd` stays readable until Mountpoint reads the FUSE INIT request.
+// `fd` needs to refer to the same FUSE device as the one passed to Mountpoint (e.g., a duplicate of it).
+func WaitForFUSEInit(ctx context.Context, fd int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, 0)
+		if err != nil && err != unix.EINTR {
+			return fmt.Errorf("runner: failed to poll FUSE file descriptor %d: %w", fd, err)
+		}
+		if err == nil {
+			if n == 0 {
+				return nil
+			}
+			if fds[0].Revents&(unix.POLLERR|unix.POLLHUP|unix.POLLNVAL) != 0 {
+				return fmt.Errorf("runner: FUSE connection of file descriptor %d is aborted", fd)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%w: file descriptor %d, timeout %v", ErrFUSEInitTimeout, fd, timeout)
+		}
+
+		// `fd` stays readable until the FUSE INIT request is read, so it can't be polled for readability.
+		// Instead, block until the FUSE connection is aborted (which is always polled for) or the next check is due.
+		fds[0].Events = 0
+		if _, err := unix.Poll(fds, int(min(remaining, fuseInitPollInterval).Milliseconds())); err != nil && err != unix.EINTR {
+			return fmt.Errorf("runner: failed to poll FUSE file descriptor %d: %w", fd, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/runner"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// A pipe emulates the FUSE device: its read end is readable until the pending "FUSE INIT request" is read,
+// and it's hung up once the write end is closed, as the FUSE device does once the FUSE connection is aborted.
+func newPipe(t *testing.T) (int, int) {
+	t.Helper()
+	fds := make([]int, 2)
+	assert.NoError(t, unix.Pipe(fds))
+	t.Cleanup(func() { unix.Close(fds[0]) })
+	return fds[0], fds[1]
+}
+
+func TestWaitForFUSEInit(t *testing.T) {
+	t.Run("Returns once the FUSE INIT request is read", func(t *testing.T) {
+		r, w := newPipe(t)
+		defer unix.Close(w)
+		_, err := unix.Write(w, []byte("INIT"))
+		assert.NoError(t, err)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, err := unix.Read(r, make([]byte, 4))
+			assert.NoError(t, err)
+		}()
+
+		assert.NoError(t, runner.WaitForFUSEInit(context.Background(), r, 5*time.Second))
+	})
+
+	t.Run("Returns immediately if the FUSE INIT request is already read", func(t *testing.T) {
+		r, w := newPipe(t)
+		defer unix.Close(w)
+		assert.NoError(t, runner.WaitForFUSEInit(context.Background(), r, 5*time.Second))
+	})
+
+	t.Run("Fails if the FUSE INIT request is not read in time", func(t *testing.T) {
+		r, w := newPipe(t)
+		defer unix.Close(w)
+		_, err := unix.Write(w, []byte("INIT"))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		err = runner.WaitForFUSEInit(context.Background(), r, 100*time.Millisecond)
+		if !errors.Is(err, runner.ErrFUSEInitTimeout) {
+			t.Fatalf("Expected runner.ErrFUSEInitTimeout, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected to time out shortly after 100ms, but took %v", elapsed)
+		}
+	})
+
+	t.Run("Fails if the FUSE connection is aborted", func(t *testing.T) {
+		r, w := newPipe(t)
+		_, err := unix.Write(w, []byte("INIT"))
+		assert.NoError(t, err)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			unix.Close(w)
+		}()
+
+		err = runner.WaitForFUSEInit(context.Background(), r, 5*time.Second)
+		if err == nil || !strings.Contains(err.Error(), "is aborted") {
+			t.Fatalf("Expected an aborted error, got %v", err)
+		}
+	})
+
+	t.Run("Fails if the file descriptor is invalid", func(t *testing.T) {
+		// Not a file descriptor in use by the process
+		fd := 1 << 20
+
+		err := runner.WaitForFUSEInit(context.Background(), fd, 5*time.Second)
+		if err == nil || !strings.Contains(err.Error(), "is aborted") {
+			t.Fatalf("Expected an aborted error, got %v", err)
+		}
+	})
+
+	t.Run("Fails if the context is cancelled", func(t *testing.T) {
+		r, w := newPipe(t)
+		defer unix.Close(w)
+		_, err := unix.Write(w, []byte("INIT"))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		err = runner.WaitForFUSEInit(ctx, r, 5*time.Second)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -2,9 +2,9 @@ package mppod
 
 import (
 	"errors"
-	"fmt"
 	"maps"
 	"path/filepath"
+	"strconv"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -15,6 +15,7 @@ import (
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
 )
 
 // Labels populated on spawned Mountpoint Pods.
@@ -41,6 +42,13 @@ const (
 	AnnotationNoNewWorkload = "s3.csi.aws.com/no-new-workload"
 	AnnotationVolumeName    = "s3.csi.aws.com/volume-name"
 	AnnotationVolumeId      = "s3.csi.aws.com/volume-id"
+	// AnnotationRetryCount is the number of failed Mountpoint Pods the Mountpoint Pod is retrying in succession.
+	// It's set on creation of the Mountpoint Pod, so the count survives failures to update MountpointS3PodAttachment status.
+	AnnotationRetryCount = "s3.csi.aws.com/retry-count"
+	// AnnotationMountOptionsProtocolVersion is the version of the mount options protocol the Mountpoint Pod implements.
+	// The node checks this annotation before sending mount options to the Mountpoint Pod, and falls back to the legacy framing
+	// if it's missing, as Mountpoint Pods predating the annotation can't parse framed messages.
+	AnnotationMountOptionsProtocolVersion = "s3.csi.aws.com/mount-options-protocol-version"
 	// AnnotationClusterAutoscalerDaemonsetPod tells the cluster autoscaler to treat this pod as if it's managed by a DaemonSet,
 	// preventing blocked scale-down when the autoscaler cannot reschedule the pod to another node.
 	// See: https://github.com/kubernetes/autoscaler/issues/2453
@@ -67,6 +75,17 @@ const (
 	PreemptingPriorityClass
 )
 
+func (k PriorityClassKind) String() string {
+	switch k {
+	case DefaultPriorityClass:
+		return "default"
+	case PreemptingPriorityClass:
+		return "preempting"
+	default:
+		return "unknown"
+	}
+}
+
 // A ContainerConfig represents configuration for containers in the spawned Mountpoint/Headroom Pods.
 type ContainerConfig struct {
 	Command         string
@@ -122,6 +141,7 @@ func (c *Creator) MountpointPod(node string, pv *corev1.PersistentVolume, priori
 				AnnotationVolumeName:                    pv.Name,
 				AnnotationVolumeId:                      pv.Spec.CSI.VolumeHandle,
 				AnnotationClusterAutoscalerDaemonsetPod: "true",
+				AnnotationMountOptionsProtocolVersion:   strconv.FormatUint(uint64(mountoptions.ProtocolVersion), 10),
 			},
 		},
 		Spec: corev1.PodSpec{
@@ -200,53 +220,89 @@ func (c *Creator) MountpointPod(node string, pv *corev1.PersistentVolume, priori
 	}
 
 	mpContainer := &mpPod.Spec.Containers[0]
-	volumeAttributes := ExtractVolumeAttributes(pv)
+	volumeAttrs, err := ParseVolumeAttributes(pv)
+	if err != nil {
+		return nil, err
+	}
 	mountpointArgs := mountpoint.ParseArgs(pv.Spec.MountOptions)
 
-	if err := c.configureLocalCache(mpPod, mpContainer, mountpointArgs, volumeAttributes); err != nil {
+	if err := c.configureLocalCache(mpPod, mpContainer, mountpointArgs, volumeAttrs.Cache); err != nil {
 		return nil, err
 	}
-	c.configureServiceAccount(mpPod, volumeAttributes)
-	if err := c.configureResourceRequests(mpContainer, volumeAttributes); err != nil {
-		return nil, err
+	c.configureCABundle(mpPod, mpContainer, volumeAttrs.CABundle)
+	c.configureServiceAccount(mpPod, volumeAttrs)
+	c.configureResources(mpContainer, volumeAttrs)
+
+	return mpPod, nil
+}
+
+// RetryMountpointPod returns a new Mountpoint Pod spec to retry failed `failedPod` for given `pv`,
+// as the `retryCount`th retry of failed Mountpoint Pods in succession.
+// The new Mountpoint Pod is built from the current configuration the same way as [Creator.MountpointPod],
+// so it picks up any changes since `failedPod` is spawned, for the same node and priority class as `failedPod`.
+// It serves its own source mount as the source mount of `failedPod` is no longer served.
+//
+// The new Mountpoint Pod is named after the UID of `failedPod`, so there is at most one retry of `failedPod`,
+// even if the caller needs multiple attempts to spawn it and to move the workloads of `failedPod` to it.
+func (c *Creator) RetryMountpointPod(failedPod *corev1.Pod, pv *corev1.PersistentVolume, retryCount int32) (*corev1.Pod, error) {
+	priorityClassKind := DefaultPriorityClass
+	if c.config.PreemptingPriorityClassName != "" && failedPod.Spec.PriorityClassName == c.config.PreemptingPriorityClassName {
+		priorityClassKind = PreemptingPriorityClass
 	}
-	if err := c.configureResourceLimits(mpContainer, volumeAttributes); err != nil {
+
+	mpPod, err := c.MountpointPod(failedPod.Spec.NodeName, pv, priorityClassKind)
+	if err != nil {
 		return nil, err
 	}
-
+	mpPod.GenerateName = ""
+	mpPod.Name = "mp-" + string(failedPod.UID)
+	mpPod.Annotations[AnnotationRetryCount] = strconv.Itoa(int(retryCount))
 	return mpPod, nil
 }
 
+// RetryCountOf returns the number of failed Mountpoint Pods `mpPod` is retrying in succession,
+// as recorded in its [AnnotationRetryCount] annotation.
+func RetryCountOf(mpPod *corev1.Pod) int32 {
+	retryCount, err := strconv.ParseInt(mpPod.Annotations[AnnotationRetryCount], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(retryCount)
+}
+
+// MountOptionsProtocolVersionOf returns the version of the mount options protocol `mpPod` implements,
+// as recorded in its [AnnotationMountOptionsProtocolVersion] annotation. It returns 0 if the annotation is missing or invalid.
+func MountOptionsProtocolVersionOf(mpPod *corev1.Pod) uint32 {
+	version, err := strconv.ParseUint(mpPod.Annotations[AnnotationMountOptionsProtocolVersion], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(version)
+}
+
 // configureLocalCache configures necessary cache volumes for the pod and the container if its enabled.
-func (c *Creator) configureLocalCache(mpPod *corev1.Pod, mpContainer *corev1.Container, args mountpoint.Args, volumeAttributes map[string]string) error {
+func (c *Creator) configureLocalCache(mpPod *corev1.Pod, mpContainer *corev1.Container, args mountpoint.Args, cache *volumecontext.CacheConfig) error {
 	cacheEnabledViaOptions := args.Has(mountpoint.ArgCache)
-	cacheType := volumeAttributes[volumecontext.Cache]
-	if !cacheEnabledViaOptions && cacheType == "" {
+	if !cacheEnabledViaOptions && cache == nil {
 		// Cache is not enabled
 		return nil
 	}
 
 	if cacheEnabledViaOptions {
-		if cacheType != "" {
+		if cache != nil {
 			return errors.New("Cache configured with both `mountOptions` and `volumeAttributes`, please remove the deprecated cache configuration in `mountOptions`")
 		}
 
-		cacheType = volumecontext.CacheTypeEmptyDir
+		cache = &volumecontext.CacheConfig{Type: volumecontext.CacheTypeEmptyDir}
 		c.log.Info("Configuring cache via `mountOptions` is deprecated, will fallback using `emptyDir`. We recommend setting `sizeLimit` on cache folders, please see https://github.com/awslabs/mountpoint-s3-csi-driver/blob/main/docs/CACHING.md for more details.")
 	}
 
 	var volumeSource corev1.VolumeSource
-	var err error
-	switch cacheType {
+	switch cache.Type {
 	case volumecontext.CacheTypeEmptyDir:
-		volumeSource, err = c.createCacheVolumeSourceForEmptyDir(volumeAttributes)
+		volumeSource = c.createCacheVolumeSourceForEmptyDir(cache)
 	case volumecontext.CacheTypeEphemeral:
-		volumeSource, err = c.createCacheVolumeSourceForEphemeral(volumeAttributes)
-	default:
-		return fmt.Errorf("unsupported local-cache type: %q, only %q and %q are supported", cacheType, volumecontext.CacheTypeEmptyDir, volumecontext.CacheTypeEphemeral)
-	}
-	if err != nil {
-		return fmt.Errorf("failed to configure %q local-cache: %w", cacheType, err)
+		volumeSource = c.createCacheVolumeSourceForEphemeral(cache)
 	}
 
 	mpContainer.VolumeMounts = append(mpContainer.VolumeMounts, corev1.VolumeMount{
@@ -261,56 +317,56 @@ func (c *Creator) configureLocalCache(mpPod *corev1.Pod, mpContainer *corev1.Con
 	return nil
 }
 
-// createCacheVolumeSourceForEmptyDir creates an `emptyDir` volume source to use as local-cache.
-func (c *Creator) createCacheVolumeSourceForEmptyDir(volumeAttributes map[string]string) (corev1.VolumeSource, error) {
-	emptyDir := &corev1.EmptyDirVolumeSource{}
-
-	sizeLimit := volumeAttributes[volumecontext.CacheEmptyDirSizeLimit]
-	medium := volumeAttributes[volumecontext.CacheEmptyDirMedium]
-
-	if sizeLimit == "" && medium == string(corev1.StorageMediumDefault) {
-		c.log.Info("`cacheEmptyDirSizeLimit` is not set for disk-backed emptyDir cache. " +
-			"Mountpoint may consume excessive node storage and cause pod eviction. " +
-			"Consider setting `cacheEmptyDirSizeLimit` in volumeAttributes.")
+// configureCABundle projects the custom CA bundle referenced by `source` into the container at [CABundlePath] if its configured.
+func (c *Creator) configureCABundle(mpPod *corev1.Pod, mpContainer *corev1.Container, source *volumecontext.CABundleSource) {
+	if source == nil {
+		return
 	}
 
-	if sizeLimit != "" {
-		quantity, err := resource.ParseQuantity(sizeLimit)
-		if err != nil {
-			return corev1.VolumeSource{}, failedToParseQuantityError(err, volumecontext.CacheEmptyDirSizeLimit, sizeLimit)
+	items := []corev1.KeyToPath{{Key: source.Key, Path: CABundleFileName}}
+	var volumeSource corev1.VolumeSource
+	switch source.Kind {
+	case volumecontext.CABundleKindConfigMap:
+		volumeSource.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: source.Name},
+			Items:                items,
 		}
-		emptyDir.SizeLimit = &quantity
-	}
-
-	if medium != "" {
-		switch medium {
-		case string(corev1.StorageMediumMemory):
-			emptyDir.Medium = corev1.StorageMediumMemory
-		default:
-			return corev1.VolumeSource{}, fmt.Errorf("unknown value for %q: %q. Only %q supported", volumecontext.CacheEmptyDirMedium, medium, corev1.StorageMediumMemory)
+	case volumecontext.CABundleKindSecret:
+		volumeSource.Secret = &corev1.SecretVolumeSource{
+			SecretName: source.Name,
+			Items:      items,
 		}
 	}
 
-	return corev1.VolumeSource{EmptyDir: emptyDir}, nil
+	mpContainer.VolumeMounts = append(mpContainer.VolumeMounts, corev1.VolumeMount{
+		Name:      CABundleDirName,
+		MountPath: filepath.Join("/", CABundleDirName),
+		ReadOnly:  true,
+	})
+	mpPod.Spec.Volumes = append(mpPod.Spec.Volumes, corev1.Volume{
+		Name:         CABundleDirName,
+		VolumeSource: volumeSource,
+	})
 }
 
-// createCacheVolumeSourceForEphemeral creates an `ephemeral` volume source to use as local-cache.
-func (c *Creator) createCacheVolumeSourceForEphemeral(volumeAttributes map[string]string) (corev1.VolumeSource, error) {
-	storageClassName := volumeAttributes[volumecontext.CacheEphemeralStorageClassName]
-	if storageClassName == "" {
-		return corev1.VolumeSource{}, fmt.Errorf("%q must be provided with %q cache type", volumecontext.CacheEphemeralStorageClassName, volumecontext.CacheTypeEphemeral)
-	}
-
-	storageResourceRequestStr := volumeAttributes[volumecontext.CacheEphemeralStorageResourceRequest]
-	if storageResourceRequestStr == "" {
-		return corev1.VolumeSource{}, fmt.Errorf("%q must be provided with %q cache type", volumecontext.CacheEphemeralStorageResourceRequest, volumecontext.CacheTypeEphemeral)
+// createCacheVolumeSourceForEmptyDir creates an `emptyDir` volume source to use as local-cache.
+func (c *Creator) createCacheVolumeSourceForEmptyDir(cache *volumecontext.CacheConfig) corev1.VolumeSource {
+	if cache.EmptyDirSizeLimit == nil && cache.IsDiskBackedEmptyDir() {
+		c.log.Info("`cacheEmptyDirSizeLimit` is not set for disk-backed emptyDir cache. " +
+			"Mountpoint may consume excessive node storage and cause pod eviction. " +
+			"Consider setting `cacheEmptyDirSizeLimit` in volumeAttributes.")
 	}
 
-	storageRequestSize, err := resource.ParseQuantity(storageResourceRequestStr)
-	if err != nil {
-		return corev1.VolumeSource{}, failedToParseQuantityError(err, volumecontext.CacheEphemeralStorageResourceRequest, storageResourceRequestStr)
+	return corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{
+			Medium:    cache.EmptyDirMedium,
+			SizeLimit: cache.EmptyDirSizeLimit,
+		},
 	}
+}
 
+// createCacheVolumeSourceForEphemeral creates an `ephemeral` volume source to use as local-cache.
+func (c *Creator) createCacheVolumeSourceForEphemeral(cache *volumecontext.CacheConfig) corev1.VolumeSource {
 	return corev1.VolumeSource{
 		Ephemeral: &corev1.EphemeralVolumeSource{
 			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
@@ -321,80 +377,31 @@ func (c *Creator) createCacheVolumeSourceForEphemeral(volumeAttributes map[strin
 				},
 				Spec: corev1.PersistentVolumeClaimSpec{
 					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-					StorageClassName: &storageClassName,
+					StorageClassName: &cache.EphemeralStorageClassName,
 					VolumeMode:       ptr.To(corev1.PersistentVolumeFilesystem),
 					Resources: corev1.VolumeResourceRequirements{
 						Requests: corev1.ResourceList{
-							corev1.ResourceStorage: storageRequestSize,
+							corev1.ResourceStorage: cache.EphemeralStorageResourceRequest,
 						},
 					},
 				},
 			},
 		},
-	}, nil
+	}
 }
 
 // configureServiceAccount configures service account of the pod if its specified in the volume attributes.
-func (c *Creator) configureServiceAccount(mpPod *corev1.Pod, volumeAttributes map[string]string) {
-	if saName := volumeAttributes[volumecontext.MountpointPodServiceAccountName]; saName != "" {
+func (c *Creator) configureServiceAccount(mpPod *corev1.Pod, volumeAttrs volumecontext.VolumeAttributes) {
+	if saName := volumeAttrs.MountpointPodServiceAccountName; saName != "" {
 		mpPod.Spec.ServiceAccountName = saName
 	}
 }
 
-// configureResourceRequests configures resource requests of the container if its specified in the volume attributes.
-func (c *Creator) configureResourceRequests(mpContainer *corev1.Container, volumeAttributes map[string]string) error {
-	resourceRequestsCpu := volumeAttributes[volumecontext.MountpointContainerResourcesRequestsCpu]
-	resourceRequestsMemory := volumeAttributes[volumecontext.MountpointContainerResourcesRequestsMemory]
-
-	if resourceRequestsCpu != "" || resourceRequestsMemory != "" {
-		mpContainer.Resources.Requests = make(corev1.ResourceList)
-
-		if resourceRequestsCpu != "" {
-			quantity, err := resource.ParseQuantity(resourceRequestsCpu)
-			if err != nil {
-				return failedToParseQuantityError(err, volumecontext.MountpointContainerResourcesRequestsCpu, resourceRequestsCpu)
-			}
-			mpContainer.Resources.Requests[corev1.ResourceCPU] = quantity
-		}
-
-		if resourceRequestsMemory != "" {
-			quantity, err := resource.ParseQuantity(resourceRequestsMemory)
-			if err != nil {
-				return failedToParseQuantityError(err, volumecontext.MountpointContainerResourcesRequestsMemory, resourceRequestsMemory)
-			}
-			mpContainer.Resources.Requests[corev1.ResourceMemory] = quantity
-		}
-	}
-
-	return nil
-}
-
-// configureResourceLimits configures resource limits of the container if its specified in the volume attributes.
-func (c *Creator) configureResourceLimits(mpContainer *corev1.Container, volumeAttributes map[string]string) error {
-	resourceLimitsCpu := volumeAttributes[volumecontext.MountpointContainerResourcesLimitsCpu]
-	resourceLimitsMemory := volumeAttributes[volumecontext.MountpointContainerResourcesLimitsMemory]
-
-	if resourceLimitsCpu != "" || resourceLimitsMemory != "" {
-		mpContainer.Resources.Limits = make(corev1.ResourceList)
-
-		if resourceLimitsCpu != "" {
-			quantity, err := resource.ParseQuantity(resourceLimitsCpu)
-			if err != nil {
-				return failedToParseQuantityError(err, volumecontext.MountpointContainerResourcesLimitsCpu, resourceLimitsCpu)
-			}
-			mpContainer.Resources.Limits[corev1.ResourceCPU] = quantity
-		}
-
-		if resourceLimitsMemory != "" {
-			quantity, err := resource.ParseQuantity(resourceLimitsMemory)
-			if err != nil {
-				return failedToParseQuantityError(err, volumecontext.MountpointContainerResourcesLimitsMemory, resourceLimitsMemory)
-			}
-			mpContainer.Resources.Limits[corev1.ResourceMemory] = quantity
-		}
-	}
-
-	return nil
+// configureResources configures resource requests and limits of the container if they're specified in the volume attributes.
+func (c *Creator) configureResources(container *corev1.Container, volumeAttrs volumecontext.VolumeAttributes) {
+	resources := volumeAttrs.MountpointContainerResources.DeepCopy()
+	container.Resources.Requests = resources.Requests
+	container.Resources.Limits = resources.Limits
 }
 
 // ExtractVolumeAttributes extracts volume attributes from given `pv`.
@@ -413,7 +420,8 @@ func ExtractVolumeAttributes(pv *corev1.PersistentVolume) map[string]string {
 	return volumeAttributes
 }
 
-// failedToParseQuantityError creates an error if provided quantity is not parsable.
-func failedToParseQuantityError(err error, field, value string) error {
-	return fmt.Errorf("failed to parse quantity %q for %q: %w", value, field, err)
+// ParseVolumeAttributes parses and validates volume attributes of `pv`.
+// Unknown volume attributes are ignored, as `pv` might have been created before they were rejected.
+func ParseVolumeAttributes(pv *corev1.PersistentVolume) (volumecontext.VolumeAttributes, error) {
+	return volumecontext.ParseLenient(ExtractVolumeAttributes(pv))
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/cluster"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/mountpoint/mountoptions"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/awslabs/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
@@ -254,6 +255,80 @@ func TestCreatingHeadroomPod(t *testing.T) {
 	})
 }
 
+func TestCreatingRetryMountpointPod(t *testing.T) {
+	creator := mppod.NewCreator(createTestConfig(cluster.DefaultKubernetes), testr.New(t))
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testVolName,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					VolumeHandle: testVolID,
+				},
+			},
+		},
+	}
+
+	failedPod, err := creator.MountpointPod(testNode, pv, mppod.PreemptingPriorityClass)
+	assert.NoError(t, err)
+	failedPod.Name = "mp-failed"
+	failedPod.UID = "7f4b9c2e-3a1d-4e5f-8b6a-0c9d8e7f6a5b"
+	failedPod.Spec.NodeName = testNode
+	failedPod.Annotations[mppod.AnnotationNeedsUnmount] = "true"
+	failedPod.Status.Phase = corev1.PodFailed
+
+	pv.Spec.MountOptions = []string{"--allow-other"}
+	pv.Spec.CSI.VolumeAttributes = map[string]string{
+		"cache":                  "emptyDir",
+		"cacheEmptyDirSizeLimit": "1Gi",
+	}
+
+	mpPod, err := creator.RetryMountpointPod(failedPod, pv, 3)
+	assert.NoError(t, err)
+	assert.Equals(t, "mp-7f4b9c2e-3a1d-4e5f-8b6a-0c9d8e7f6a5b", mpPod.Name)
+	assert.Equals(t, "", mpPod.GenerateName)
+	assert.Equals(t, namespace, mpPod.Namespace)
+	assert.Equals(t, failedPod.Labels, mpPod.Labels)
+	assert.Equals(t, map[string]string{
+		mppod.AnnotationVolumeName:                    testVolName,
+		mppod.AnnotationVolumeId:                      testVolID,
+		mppod.AnnotationClusterAutoscalerDaemonsetPod: "true",
+		mppod.AnnotationRetryCount:                    "3",
+		mppod.AnnotationMountOptionsProtocolVersion:   "1",
+	}, mpPod.Annotations)
+	assert.Equals(t, int32(3), mppod.RetryCountOf(mpPod))
+	assert.Equals(t, mountoptions.ProtocolVersion, mppod.MountOptionsProtocolVersionOf(mpPod))
+	assert.Equals(t, "", mpPod.Spec.NodeName)
+	assert.Equals(t, preemptingPriorityClassName, mpPod.Spec.PriorityClassName)
+	assert.Equals(t, []string{testNode}, mpPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values)
+	assert.Equals(t, corev1.PodStatus{}, mpPod.Status)
+	// The retry is built from the current volume, rather than copying `failedPod`
+	assert.Equals(t, len(failedPod.Spec.Volumes)+1, len(mpPod.Spec.Volumes))
+
+	// The failed Mountpoint Pod should not be modified
+	assert.Equals(t, "true", failedPod.Annotations[mppod.AnnotationNeedsUnmount])
+	assert.Equals(t, testNode, failedPod.Spec.NodeName)
+}
+
+func TestMountOptionsProtocolVersionOf(t *testing.T) {
+	for _, tc := range []struct {
+		annotations map[string]string
+		want        uint32
+	}{
+		{annotations: map[string]string{mppod.AnnotationMountOptionsProtocolVersion: "1"}, want: 1},
+		{annotations: map[string]string{mppod.AnnotationMountOptionsProtocolVersion: "2"}, want: 2},
+		// Mountpoint Pods predating the annotation only implement the legacy framing
+		{annotations: nil, want: 0},
+		{annotations: map[string]string{mppod.AnnotationMountOptionsProtocolVersion: "invalid"}, want: 0},
+		{annotations: map[string]string{mppod.AnnotationMountOptionsProtocolVersion: "-1"}, want: 0},
+	} {
+		mpPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		assert.Equals(t, tc.want, mppod.MountOptionsProtocolVersionOf(mpPod))
+	}
+}
+
 func createTestConfig(clusterVariant cluster.Variant) mppod.Config {
 	return mppod.Config{
 		Namespace:                   namespace,
@@ -289,7 +364,9 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 			mppod.AnnotationVolumeName:                    testVolName,
 			mppod.AnnotationVolumeId:                      testVolID,
 			mppod.AnnotationClusterAutoscalerDaemonsetPod: "true",
+			mppod.AnnotationMountOptionsProtocolVersion:   "1",
 		}, mpPod.Annotations)
+		assert.Equals(t, mountoptions.ProtocolVersion, mppod.MountOptionsProtocolVersionOf(mpPod))
 
 		assert.Equals(t, expectedPriorityClassName, mpPod.Spec.PriorityClassName)
 		assert.Equals(t, corev1.RestartPolicyOnFailure, mpPod.Spec.RestartPolicy)
@@ -685,6 +762,76 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 		})
 	})
 
+	t.Run("CA Bundle Configuration", func(t *testing.T) {
+		newPVWithCABundle := func(volumeAttributes map[string]string) *corev1.PersistentVolume {
+			return &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testVolName,
+				},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{
+							VolumeHandle:     testVolID,
+							VolumeAttributes: volumeAttributes,
+						},
+					},
+				},
+			}
+		}
+
+		t.Run("With CA bundle from ConfigMap", func(t *testing.T) {
+			mpPod, err := creator.MountpointPod(testNode, newPVWithCABundle(map[string]string{
+				"caBundleConfigMap": "corp-ca",
+			}), mppod.DefaultPriorityClass)
+
+			assert.NoError(t, err)
+			verifyDefaultValues(mpPod, priorityClassName)
+			assert.Equals(t, &corev1.Volume{
+				Name: mppod.CABundleDirName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "corp-ca"},
+						Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: mppod.CABundleFileName}},
+					},
+				},
+			}, findVolumeFromPod(mpPod, mppod.CABundleDirName))
+			assert.Equals(t, &corev1.VolumeMount{
+				Name:      mppod.CABundleDirName,
+				MountPath: "/" + mppod.CABundleDirName,
+				ReadOnly:  true,
+			}, findVolumeMountFromContainer(mpPod.Spec.Containers[0], mppod.CABundleDirName))
+		})
+
+		t.Run("With CA bundle from Secret and custom key", func(t *testing.T) {
+			mpPod, err := creator.MountpointPod(testNode, newPVWithCABundle(map[string]string{
+				"caBundleSecret": "corp-ca",
+				"caBundleKey":    "bundle.pem",
+			}), mppod.DefaultPriorityClass)
+
+			assert.NoError(t, err)
+			verifyDefaultValues(mpPod, priorityClassName)
+			assert.Equals(t, &corev1.Volume{
+				Name: mppod.CABundleDirName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: "corp-ca",
+						Items:      []corev1.KeyToPath{{Key: "bundle.pem", Path: mppod.CABundleFileName}},
+					},
+				},
+			}, findVolumeFromPod(mpPod, mppod.CABundleDirName))
+		})
+
+		t.Run("With both ConfigMap and Secret", func(t *testing.T) {
+			_, err := creator.MountpointPod(testNode, newPVWithCABundle(map[string]string{
+				"caBundleConfigMap": "corp-ca",
+				"caBundleSecret":    "corp-ca",
+			}), mppod.DefaultPriorityClass)
+			if err == nil {
+				t.Fatal("Expected an error if both caBundleConfigMap and caBundleSecret are set")
+			}
+		})
+	})
+
 	t.Run("With ServiceAccountName specified in PV", func(t *testing.T) {
 		mpPod, err := creator.MountpointPod(testNode, &corev1.PersistentVolume{
 			ObjectMeta: metav1.ObjectMeta{
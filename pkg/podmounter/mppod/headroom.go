@@ -96,14 +96,11 @@ func (c *Creator) HeadroomPod(workloadPod *corev1.Pod, pv *corev1.PersistentVolu
 	}
 
 	hrContainer := &hrPod.Spec.Containers[0]
-	volumeAttributes := ExtractVolumeAttributes(pv)
-
-	if err := c.configureResourceRequests(hrContainer, volumeAttributes); err != nil {
-		return nil, err
-	}
-	if err := c.configureResourceLimits(hrContainer, volumeAttributes); err != nil {
+	volumeAttrs, err := ParseVolumeAttributes(pv)
+	if err != nil {
 		return nil, err
 	}
+	c.configureResources(hrContainer, volumeAttrs)
 
 	return hrPod, nil
 }
@@ -32,6 +32,17 @@ const CommunicationDirName = "comm"
 // to use as a local cache.
 const LocalCacheDirName = "local-cache"
 
+// CABundleDirName is the name and the path of the volume containing the custom CA bundle mounted to Mountpoint Pod.
+// The controller will project the ConfigMap or the Secret referenced via `caBundleConfigMap` or `caBundleSecret`
+// volume attributes into this volume, and the CSI Driver Node Pod will pass [CABundlePath] to Mountpoint as `--ca-bundle`.
+const CABundleDirName = "ca-bundle"
+
+// CABundleFileName is the name of the custom CA bundle file inside [CABundleDirName].
+const CABundleFileName = "ca-bundle.crt"
+
+// CABundlePath is the path of the custom CA bundle inside Mountpoint Pod.
+const CABundlePath = "/" + CABundleDirName + "/" + CABundleFileName
+
 // PathOnHost returns the full path on the host that refers to `path` inside Mountpoint Pod.
 // This function should be used in the CSI Driver Node Pod which uses `hostPath` volume to mount kubelet.
 func PathOnHost(podPathOnHost string, path ...string) string {
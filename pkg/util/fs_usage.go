@@ -0,0 +1,74 @@
+package util
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// FilesystemUsage represents byte and inode usage of a filesystem or a directory.
+type FilesystemUsage struct {
+	TotalBytes     int64
+	UsedBytes      int64
+	AvailableBytes int64
+
+	TotalInodes     int64
+	UsedInodes      int64
+	AvailableInodes int64
+}
+
+// StatFilesystem returns usage of the filesystem containing `path`.
+func StatFilesystem(path string) (FilesystemUsage, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return FilesystemUsage{}, fmt.Errorf("failed to statfs %q: %w", path, err)
+	}
+
+	blockSize := int64(stat.Bsize)
+	return FilesystemUsage{
+		TotalBytes:      int64(stat.Blocks) * blockSize,
+		UsedBytes:       int64(stat.Blocks-stat.Bfree) * blockSize,
+		AvailableBytes:  int64(stat.Bavail) * blockSize,
+		TotalInodes:     int64(stat.Files),
+		UsedInodes:      int64(stat.Files - stat.Ffree),
+		AvailableInodes: int64(stat.Ffree),
+	}, nil
+}
+
+// DirectoryUsage returns usage of the directory at `path` by walking it, limited to `limitBytes`.
+// This should be used instead of [StatFilesystem] if the directory shares its filesystem with others,
+// i.e., a disk-backed `emptyDir` volume, as `statfs` would report usage of the whole filesystem.
+// Inode totals are not known for directories, so only used inodes are reported.
+func DirectoryUsage(path string, limitBytes int64) (FilesystemUsage, error) {
+	var usedBytes, usedInodes int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		usedInodes++
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			// `Blocks` is always in 512-byte units regardless of the filesystem's block size
+			usedBytes += int64(stat.Blocks) * 512
+		} else {
+			usedBytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return FilesystemUsage{}, fmt.Errorf("failed to walk %q: %w", path, err)
+	}
+
+	return FilesystemUsage{
+		TotalBytes:     limitBytes,
+		UsedBytes:      usedBytes,
+		AvailableBytes: max(limitBytes-usedBytes, 0),
+		UsedInodes:     usedInodes,
+	}, nil
+}
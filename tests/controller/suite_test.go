@@ -73,7 +73,7 @@ var _ = BeforeSuite(func() {
 	crdv2.AddToScheme(scheme.Scheme)
 	testEnv = &envtest.Environment{
 		CRDInstallOptions: envtest.CRDInstallOptions{
-			Paths: []string{"../crd/mountpoints3podattachments-crd.yaml"},
+			Paths: []string{"../crd/mountpoints3podattachments-crd.yaml", "../crd/mountpoints3mountpolicies-crd.yaml"},
 		},
 		ErrorIfCRDPathMissing: true,
 	}
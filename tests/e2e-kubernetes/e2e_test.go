@@ -73,6 +73,7 @@ var CSITestSuites = []func() framework.TestSuite{
 	custom_testsuites.InitS3TaintRemovalTestSuite,
 	custom_testsuites.InitS3CSIEvictionOrderTestSuite,
 	custom_testsuites.InitS3ProxyTestSuite,
+	custom_testsuites.InitS3CompatibleTestSuite,
 }
 
 func getCSITestSuites() []func() framework.TestSuite {
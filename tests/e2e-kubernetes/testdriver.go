@@ -85,6 +85,15 @@ func (d *s3Driver) CreateVolume(ctx context.Context, config *framework.PerTestCo
 		f.Failf("Unsupported volType: %v is specified", volumeType)
 	}
 
+	volumeAttributes := custom_testsuites.VolumeAttributesFromContext(ctx)
+	if _, ok := volumeAttributes["endpointUrl"]; ok {
+		// The bucket lives on a custom S3-compatible endpoint managed by the test itself, there is nothing to create in AWS
+		return &s3Volume{
+			bucketName:       volumeAttributes["bucketName"],
+			volumeAttributes: volumeAttributes,
+		}
+	}
+
 	var bucketName string
 	var deleteBucket s3client.DeleteBucketFunc
 	if config.Prefix == custom_testsuites.S3ExpressTestIdentifier {
@@ -96,7 +105,7 @@ func (d *s3Driver) CreateVolume(ctx context.Context, config *framework.PerTestCo
 	return &s3Volume{
 		bucketName:       bucketName,
 		deleteBucket:     deleteBucket,
-		volumeAttributes: custom_testsuites.VolumeAttributesFromContext(ctx),
+		volumeAttributes: volumeAttributes,
 	}
 }
 
@@ -119,6 +128,9 @@ func (d *s3Driver) GetPersistentVolumeSource(readOnly bool, fsType string, testV
 }
 
 func (v *s3Volume) DeleteVolume(ctx context.Context) {
+	if v.deleteBucket == nil {
+		return
+	}
 	err := v.deleteBucket(ctx)
 	f.ExpectNoError(err, "Failed to delete S3 Bucket: %s", v.bucketName)
 }
@@ -595,6 +595,7 @@ func matchesSpec(spec crdv2.MountpointS3PodAttachmentSpec, expected map[string]s
 		"WorkloadServiceAccountName":       spec.WorkloadServiceAccountName,
 		"WorkloadNamespace":                spec.WorkloadNamespace,
 		"WorkloadServiceAccountIAMRoleARN": spec.WorkloadServiceAccountIAMRoleARN,
+		"AssumeRoleARN":                    spec.AssumeRoleARN,
 	}
 
 	for k, v := range expected {
@@ -0,0 +1,158 @@
+package custom_testsuites
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+// s3CompatibleImage is a lightweight S3-compatible server used as a stand-in for on-premises S3-compatible services like MinIO or Ceph RGW.
+// It serves path-style requests over HTTP and accepts any credentials. The tag is pinned so the suite does not break
+// on upstream releases, e.g., changes to how `initialBuckets` is handled.
+const s3CompatibleImage = "docker.io/adobe/s3mock:3.12.0"
+
+const s3CompatiblePort int32 = 9090
+
+type s3CSIS3CompatibleTestSuite struct {
+	tsInfo storageframework.TestSuiteInfo
+}
+
+func InitS3CompatibleTestSuite() storageframework.TestSuite {
+	return &s3CSIS3CompatibleTestSuite{
+		tsInfo: storageframework.TestSuiteInfo{
+			Name: "s3compatible",
+			TestPatterns: []storageframework.TestPattern{
+				storageframework.DefaultFsPreprovisionedPV,
+			},
+		},
+	}
+}
+
+func (t *s3CSIS3CompatibleTestSuite) GetTestSuiteInfo() storageframework.TestSuiteInfo {
+	return t.tsInfo
+}
+
+func (t *s3CSIS3CompatibleTestSuite) SkipUnsupportedTests(_ storageframework.TestDriver, pattern storageframework.TestPattern) {
+	if pattern.VolType != storageframework.PreprovisionedPV {
+		e2eskipper.Skipf("Suite %q does not support %v", t.tsInfo.Name, pattern.VolType)
+	}
+}
+
+func (t *s3CSIS3CompatibleTestSuite) DefineTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	type local struct {
+		resources []*storageframework.VolumeResource
+		config    *storageframework.PerTestConfig
+	}
+	var (
+		l local
+	)
+
+	f := framework.NewFrameworkWithCustomTimeouts(NamespacePrefix+"s3compatible", storageframework.GetDriverTimeouts(driver))
+	// The S3-compatible stand-in does not run under Restricted level, the workload pod still does
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	cleanup := func(ctx context.Context) {
+		var errs []error
+		for _, resource := range l.resources {
+			errs = append(errs, resource.CleanupResource(ctx))
+		}
+		framework.ExpectNoError(errors.NewAggregate(errs), "while cleanup resource")
+	}
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		l = local{}
+		l.config = driver.PrepareTest(ctx, f)
+		ginkgo.DeferCleanup(cleanup)
+	})
+
+	ginkgo.It("should be able to mount a bucket from an S3-compatible endpoint", func(ctx context.Context) {
+		serverName := fmt.Sprintf("%s-s3", f.UniqueName)
+		bucketName := "s3-compatible-bucket"
+		serverLabels := map[string]string{
+			"app": serverName,
+		}
+
+		ginkgo.By("Creating S3-compatible server pod")
+		serverPod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   serverName,
+				Labels: serverLabels,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "s3",
+						Image: s3CompatibleImage,
+						Env:   []v1.EnvVar{{Name: "initialBuckets", Value: bucketName}},
+						Ports: []v1.ContainerPort{{ContainerPort: s3CompatiblePort}},
+					},
+				},
+			},
+		}
+		serverPod, err := createPod(ctx, f.ClientSet, f.Namespace.Name, serverPod)
+		framework.ExpectNoError(err)
+		defer func() {
+			_ = e2epod.DeletePodWithWait(ctx, f.ClientSet, serverPod)
+		}()
+
+		ginkgo.By("Creating S3-compatible server service")
+		serverService, err := f.ClientSet.CoreV1().Services(f.Namespace.Name).Create(ctx, &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: serverName,
+			},
+			Spec: v1.ServiceSpec{
+				Selector: serverLabels,
+				Ports:    []v1.ServicePort{{Port: s3CompatiblePort}},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		defer func() {
+			e2eservice.WaitForServiceDeletedWithFinalizer(ctx, f.ClientSet, f.Namespace.Name, serverService.Name)
+		}()
+
+		endpointURL := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", serverName, f.Namespace.Name, s3CompatiblePort)
+		resource := createVolumeResource(contextWithVolumeAttributes(ctx, map[string]string{
+			"bucketName":      bucketName,
+			"endpointUrl":     endpointURL,
+			"addressingStyle": "path",
+			"region":          "us-east-1",
+		}), l.config, pattern, v1.ReadWriteMany, []string{
+			fmt.Sprintf("uid=%d", defaultNonRootUser),
+			fmt.Sprintf("gid=%d", defaultNonRootGroup),
+			"allow-other",
+			"allow-delete",
+			"no-sign-request",
+		})
+		l.resources = append(l.resources, resource)
+
+		ginkgo.By("Creating workload pod with a volume")
+		pod := e2epod.MakePod(f.Namespace.Name, nil, []*v1.PersistentVolumeClaim{resource.Pvc}, admissionapi.LevelRestricted, "")
+		podModifierNonRoot(pod)
+		pod, err = createPod(ctx, f.ClientSet, f.Namespace.Name, pod)
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(ctx, f.ClientSet, pod))
+		}()
+
+		volPath := "/mnt/volume1"
+		fileInVol := fmt.Sprintf("%s/file.txt", volPath)
+		seed := time.Now().UTC().UnixNano()
+		toWrite := 1024 // 1KB
+
+		ginkgo.By("Checking write to and read from the S3-compatible bucket")
+		checkWriteToPathSucceed(ctx, f, pod, fileInVol, toWrite, seed)
+		checkReadFromPathSucceed(ctx, f, pod, fileInVol, toWrite, seed)
+		checkListingPathWithEntries(ctx, f, pod, volPath, []string{"file.txt"})
+		checkDeletingPathSucceed(ctx, f, pod, fileInVol)
+	})
+}